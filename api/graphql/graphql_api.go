@@ -2,35 +2,62 @@ package graphql
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"io"
 	"net/http"
-	"strconv"
 
 	gql "github.com/graph-gophers/graphql-go"
-	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 
+	"magento.GO/config"
+	"magento.GO/core/auth"
+	"magento.GO/core/events"
 	_ "magento.GO/custom"
 	graphqlpkg "magento.GO/graphql"
+	"magento.GO/graphql/dataloader"
 	gqlregistry "magento.GO/graphql/registry"
 	_ "magento.GO/graphql/resolvers"
+	attributeRepo "magento.GO/model/repository/attribute"
+	authRepo "magento.GO/model/repository/auth"
+	currencyRepo "magento.GO/model/repository/currency"
+	customerRepo "magento.GO/model/repository/customer"
+	storeRepo "magento.GO/model/repository/store"
+	productService "magento.GO/service/product"
 )
 
 type rootResolver struct {
 	db *gorm.DB
+	// query and mutation resolve Query and Mutation when typed extensions add fields to them.
+	query, mutation interface{}
 }
 
 func (r *rootResolver) Query() interface{} {
+	if r.query != nil {
+		return r.query
+	}
 	return gqlregistry.GetQueryResolver(r.db)
 }
 
+func (r *rootResolver) Mutation() interface{} {
+	if r.mutation != nil {
+		return r.mutation
+	}
+	return gqlregistry.GetMutationResolver(r.db)
+}
+
+func (r *rootResolver) Subscription() interface{} {
+	return gqlregistry.GetSubscriptionResolver(r.db)
+}
+
 // GraphQLRequest is the standard GraphQL request body
 type GraphQLRequest struct {
 	Query         string                 `json:"query"`
 	OperationName string                 `json:"operationName"`
 	Variables     map[string]interface{} `json:"variables"`
+	Extensions    struct {
+		PersistedQuery *persistedQuery `json:"persistedQuery"`
+	} `json:"extensions"`
 }
 
 // GraphQLResponse is the standard GraphQL response
@@ -40,65 +67,146 @@ type GraphQLResponse struct {
 }
 
 type GraphQLError struct {
-	Message string `json:"message"`
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
 func RegisterGraphQLRoutes(e *echo.Echo, db *gorm.DB) {
-	schema, err := gql.ParseSchema(graphqlpkg.Schema(), &rootResolver{db: db}, gql.UseFieldResolvers())
+	exts := gqlregistry.SchemaExtensions()
+	merged, err := graphqlpkg.MergeSchemaExtensions(schemaForDB(db), exts)
 	if err != nil {
 		panic("graphql schema: " + err.Error())
 	}
-	registerRoutes(e, schema)
+	sdl := merged.SDL
+	if FederationEnabledFromEnv() {
+		graphqlpkg.SetServiceSDL(sdl)
+		sdl += "\n\n" + graphqlpkg.FederationExtension
+	}
+	root, err := newRootResolver(db, sdl, merged, exts)
+	if err != nil {
+		panic("graphql schema: " + err.Error())
+	}
+	opts := append([]gql.SchemaOpt{gql.UseFieldResolvers()}, QueryLimitsFromEnv().SchemaOptions()...)
+	schema, err := gql.ParseSchema(sdl, root, opts...)
+	if err != nil {
+		panic("graphql schema: " + err.Error())
+	}
+	registerRoutes(e, schema, db)
+}
+
+// FederationEnabledFromEnv reads GRAPHQL_FEDERATION (default false): serve the Apollo Federation v2
+// subgraph fields _service and _entities, for use behind a federated gateway.
+func FederationEnabledFromEnv() bool {
+	return config.GetEnv("GRAPHQL_FEDERATION", "false") == "true"
+}
+
+// schemaForDB returns the core schema with the store's filterable EAV attributes
+// added to ProductAttributeFilterInput, as Magento generates it.
+func schemaForDB(db *gorm.DB) string {
+	sdl := graphqlpkg.CoreSchema()
+	attrs, err := attributeRepo.GetAttributeRepository(db).FilterableAttributes()
+	if err != nil {
+		return sdl
+	}
+	fields := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		fields[a.Code] = graphqlpkg.FilterInputTypeFor(a.FrontendInput, a.BackendType)
+	}
+	if ext := graphqlpkg.ProductFilterExtension(fields); ext != "" {
+		sdl += "\n\n" + ext
+	}
+	return sdl
 }
 
 // RegisterGraphQLRoutesWithSchema registers /graphql with a custom schema (for tests with mocks).
 func RegisterGraphQLRoutesWithSchema(e *echo.Echo, schema *gql.Schema) {
-	registerRoutes(e, schema)
+	registerRoutes(e, schema, nil)
 }
 
-func registerRoutes(e *echo.Echo, schema *gql.Schema) {
-	handler := &relay.Handler{Schema: schema}
-	h := storeContextMiddleware(handler)
-	e.POST("/graphql", echo.WrapHandler(h))
-	e.GET("/graphql", echo.WrapHandler(h))
+func registerRoutes(e *echo.Echo, schema *gql.Schema, db *gorm.DB) {
+	limits := QueryLimitsFromEnv()
+	h := &handler{schema: schema, limits: limits, cacheTTL: ResponseCacheTTLFromEnv()}
+	ws := &wsHandler{next: h, schema: schema, limits: limits, db: db}
+	if db != nil {
+		ws.poller = productService.NewChangePoller(db, events.GetInstance(), SubscriptionPollIntervalFromEnv())
+		if h.cacheTTL > 0 {
+			invalidateOnChanges(db, events.GetInstance())
+		}
+	}
+	e.POST("/graphql", echo.WrapHandler(storeContextMiddleware(h, db)), remoteIPMiddleware)
+	e.GET("/graphql", echo.WrapHandler(storeContextMiddleware(ws, db)), remoteIPMiddleware)
 	e.GET("/playground", echo.WrapHandler(playgroundHandler()))
 }
 
-func storeContextMiddleware(next http.Handler) http.Handler {
+// remoteIPMiddleware attaches the client IP (echo's RealIP, which honors the server's IPExtractor)
+// to the request context, e.g. for the remote_ip of review votes.
+func remoteIPMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := c.Request()
+		c.SetRequest(r.WithContext(graphqlpkg.WithRemoteIP(r.Context(), c.RealIP())))
+		return next(c)
+	}
+}
+
+func storeContextMiddleware(next http.Handler, db *gorm.DB) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		storeID := uint16(0)
-		if h := r.Header.Get("Store"); h != "" {
-			if id, err := strconv.ParseUint(h, 10, 16); err == nil {
-				storeID = uint16(id)
-			}
+		next.ServeHTTP(w, r.WithContext(requestContext(r, db)))
+	})
+}
+
+// requestContext returns the context of a GraphQL request: store view (Store header, __Store; an ID or a store code), customer
+// and customer group, display currency and the request's loaders.
+func requestContext(r *http.Request, db *gorm.DB) context.Context {
+	parse := graphqlpkg.StoreParser(graphqlpkg.ParseStoreID)
+	if db != nil {
+		parse = storeRepo.GetStoreRepository(db).ParseStore
+	}
+	storeID := uint16(0)
+	if h := r.Header.Get(graphqlpkg.HeaderStore); h != "" {
+		if id, ok := parse(h); ok {
+			storeID = id
 		}
-		if r.Method == http.MethodPost {
-			body, _ := io.ReadAll(r.Body)
-			r.Body = io.NopCloser(bytes.NewReader(body))
-			var req struct {
-				Variables map[string]interface{} `json:"variables"`
-			}
-			if json.Unmarshal(body, &req) == nil && req.Variables != nil {
-				if v, ok := req.Variables["__Store"]; ok {
-					switch val := v.(type) {
-					case string:
-						if id, err := strconv.ParseUint(val, 10, 16); err == nil {
-							storeID = uint16(id)
-						}
-					case float64:
-						storeID = uint16(val)
-					}
-				}
-			}
+	}
+	if r.Method == http.MethodPost {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if id, ok := graphqlpkg.ParseStoreFromVariables(body, parse); ok {
+			storeID = id
 		}
-		if q := r.URL.Query().Get("__Store"); q != "" {
-			if id, err := strconv.ParseUint(q, 10, 16); err == nil {
-				storeID = uint16(id)
-			}
+	}
+	if q := r.URL.Query().Get(graphqlpkg.QueryParamStore); q != "" {
+		if id, ok := parse(q); ok {
+			storeID = id
 		}
-		ctx := graphqlpkg.WithStoreID(r.Context(), storeID)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	}
+	ctx := graphqlpkg.WithStoreID(r.Context(), storeID)
+	customerID, groupID := customer(r, db)
+	ctx = graphqlpkg.WithCustomerID(ctx, customerID)
+	ctx = graphqlpkg.WithCustomerGroupID(ctx, groupID)
+	ctx = graphqlpkg.WithCurrency(ctx, r.Header.Get(currencyRepo.HeaderContentCurrency))
+	if db != nil {
+		// One set of loaders per request: lookups are batched and cached across its resolvers.
+		ctx = dataloader.WithLoaders(ctx, dataloader.NewLoaders(db))
+	}
+	return ctx
+}
+
+// customer resolves the customer and customer group of an authenticated request (customer token or
+// signed X-Customer-ID). Guests, invalid credentials and inactive customers get customer 0 and
+// NOT LOGGED IN.
+func customer(r *http.Request, db *gorm.DB) (customerID, groupID uint) {
+	if db == nil {
+		return 0, graphqlpkg.NotLoggedInGroupID
+	}
+	customerID, ok := auth.CustomerIDFromRequest(r, authRepo.NewAuthRepository(db))
+	if !ok {
+		return 0, graphqlpkg.NotLoggedInGroupID
+	}
+	groupID, err := customerRepo.GetCustomerRepository(db).GetGroupID(customerID)
+	if err != nil {
+		return 0, graphqlpkg.NotLoggedInGroupID
+	}
+	return customerID, groupID
 }
 
 func playgroundHandler() http.Handler {
@@ -0,0 +1,141 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	gql "github.com/graph-gophers/graphql-go"
+
+	graphqlpkg "magento.GO/graphql"
+)
+
+// handler executes GraphQL requests sent as a JSON POST body or as GET parameters (query,
+// operationName, and variables/extensions as JSON), with automatic persisted queries. GET requests
+// may only run queries, so they are safe for CDNs and browsers to cache and to prefetch. Operations
+// over the query limits, and documents the limits cannot be checked on, are rejected before
+// execution. Anonymous catalog queries are served from the response cache when cacheTTL is set.
+type handler struct {
+	schema   *gql.Schema
+	limits   QueryLimits
+	cacheTTL time.Duration
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRequest(r)
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, &GraphQLResponse{Errors: []GraphQLError{{Message: err.Error()}}})
+		return
+	}
+	if gqlErr := resolvePersistedQuery(req); gqlErr != nil {
+		writeResponse(w, http.StatusOK, &GraphQLResponse{Errors: []GraphQLError{*gqlErr}})
+		return
+	}
+	ctx := r.Context()
+	var op *queryOperation
+	doc, err := parseQueryDocument(req.Query)
+	if err == nil {
+		op = doc.operation(req.OperationName)
+	} else if r.Method == http.MethodGet {
+		// A GET request must be provably a query; the executor would run a mutation as well.
+		writeResponse(w, http.StatusMethodNotAllowed, &GraphQLResponse{Errors: []GraphQLError{{Message: "the query could not be read, send it with POST"}}})
+		return
+	}
+	if op != nil && op.kind == "mutation" && r.Method == http.MethodGet {
+		writeResponse(w, http.StatusMethodNotAllowed, &GraphQLResponse{Errors: []GraphQLError{{Message: "mutations must be sent with POST"}}})
+		return
+	}
+	if doc != nil {
+		if err := h.limits.check(h.schema.ASTSchema(), doc, req.OperationName, req.Variables); err != nil {
+			writeResponse(w, http.StatusOK, &GraphQLResponse{Errors: []GraphQLError{{Message: err.Error()}}})
+			return
+		}
+	}
+	_, customer := graphqlpkg.CustomerIDFromContext(ctx)
+	cacheable := op != nil && !customer && isCacheableOperation(doc, op)
+
+	id := cacheID(ctx)
+	w.Header().Set(headerCacheID, id)
+	w.Header().Set("Vary", varyHeaders)
+	key := ""
+	if cacheable && h.cacheTTL > 0 {
+		key = responseCacheKey(req, id)
+		if cached, ok := loadCachedResponse(key); ok {
+			h.setCacheHeaders(w, r, cached.tags)
+			w.Header().Set(headerCacheDebug, "HIT")
+			writeBody(w, http.StatusOK, cached.body)
+			return
+		}
+	}
+
+	ctx, tags := graphqlpkg.WithCacheTags(ctx)
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	body, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !cacheable || len(response.Errors) > 0 {
+		w.Header().Set("Cache-Control", "no-store")
+		writeBody(w, http.StatusOK, body)
+		return
+	}
+	tagList := tags.List()
+	h.setCacheHeaders(w, r, tagList)
+	if key != "" {
+		saveCachedResponse(key, cachedResponse{body: body, tags: tagList}, h.cacheTTL)
+		w.Header().Set(headerCacheDebug, "MISS")
+	}
+	writeBody(w, http.StatusOK, body)
+}
+
+// setCacheHeaders marks a cacheable response: its tags, and for GET a public max-age of cacheTTL.
+func (h *handler) setCacheHeaders(w http.ResponseWriter, r *http.Request, tags []string) {
+	if len(tags) > 0 {
+		w.Header().Set(headerCacheTags, strings.Join(tags, ","))
+	}
+	if r.Method == http.MethodGet && h.cacheTTL > 0 {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.cacheTTL/time.Second)))
+	}
+}
+
+func decodeRequest(r *http.Request) (*GraphQLRequest, error) {
+	var req GraphQLRequest
+	if r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	}
+	q := r.URL.Query()
+	req.Query = q.Get("query")
+	req.OperationName = q.Get("operationName")
+	if v := q.Get("variables"); v != "" {
+		if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+			return nil, err
+		}
+	}
+	if v := q.Get("extensions"); v != "" {
+		if err := json.Unmarshal([]byte(v), &req.Extensions); err != nil {
+			return nil, err
+		}
+	}
+	return &req, nil
+}
+
+func writeResponse(w http.ResponseWriter, status int, response interface{}) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeBody(w, status, body)
+}
+
+func writeBody(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
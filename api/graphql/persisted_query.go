@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"magento.GO/config"
+	"magento.GO/core/cache"
+)
+
+// Automatic persisted queries (Apollo APQ): a client sends only the sha256 hash of a query in
+// extensions.persistedQuery. An unknown hash gets PersistedQueryNotFound and the client retries with
+// the query and the hash, which registers the query for later hash-only requests.
+
+const (
+	persistedQueryVersion   = 1
+	persistedQueryKeyPrefix = "graphql:apq:"
+	persistedQueryTTL       = 24 * time.Hour
+
+	errPersistedQueryNotFound     = "PersistedQueryNotFound"
+	errPersistedQueryNotSupported = "PersistedQueryNotSupported"
+	errPersistedQueryHashMismatch = "provided sha does not match query"
+)
+
+// persistedQuery is the persistedQuery member of a request's extensions.
+type persistedQuery struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// resolvePersistedQuery fills req.Query from the persisted query store, or registers req.Query under
+// its hash when the client sends both. It returns a GraphQL error for the client otherwise.
+func resolvePersistedQuery(req *GraphQLRequest) *GraphQLError {
+	pq := req.Extensions.PersistedQuery
+	if pq == nil {
+		return nil
+	}
+	if pq.Version != persistedQueryVersion {
+		return &GraphQLError{Message: errPersistedQueryNotSupported, Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_SUPPORTED"}}
+	}
+	hash := strings.ToLower(pq.Sha256Hash)
+	if req.Query == "" {
+		query, ok := loadPersistedQuery(hash)
+		if !ok {
+			return &GraphQLError{Message: errPersistedQueryNotFound, Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_FOUND"}}
+		}
+		req.Query = query
+		return nil
+	}
+	sum := sha256.Sum256([]byte(req.Query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return &GraphQLError{Message: errPersistedQueryHashMismatch}
+	}
+	savePersistedQuery(hash, req.Query)
+	return nil
+}
+
+// loadPersistedQuery reads a query by hash from Redis when configured, else from the in-memory cache.
+func loadPersistedQuery(hash string) (string, bool) {
+	key := persistedQueryKeyPrefix + hash
+	if config.RedisClient != nil {
+		query, err := config.RedisClient.Get(config.RedisCtx(), key).Result()
+		return query, err == nil
+	}
+	v, ok := cache.GetInstance().Get(key)
+	if !ok {
+		return "", false
+	}
+	query, ok := v.(string)
+	return query, ok
+}
+
+func savePersistedQuery(hash, query string) {
+	key := persistedQueryKeyPrefix + hash
+	if config.RedisClient != nil {
+		config.RedisClient.Set(config.RedisCtx(), key, query, persistedQueryTTL)
+		return
+	}
+	cache.GetInstance().Set(key, query, int64(persistedQueryTTL/time.Second), nil)
+}
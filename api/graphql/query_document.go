@@ -0,0 +1,386 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// queryDocument is the part of a GraphQL request document needed before execution: operation types,
+// selections, aliases and integer arguments. graphql-go's own parser is internal, so the document is
+// parsed here. A document it cannot parse is left to the executor, which reports its own syntax
+// errors; only the depth limit, which graphql-go enforces too (QueryLimits.SchemaOptions), applies to it.
+type queryDocument struct {
+	operations []*queryOperation
+	fragments  map[string]*queryFragment
+}
+
+type queryOperation struct {
+	kind       string // query, mutation or subscription
+	name       string
+	variables  map[string]queryValue // variable defaults
+	selections []*querySelection
+}
+
+type queryFragment struct {
+	typeCondition string
+	selections    []*querySelection
+}
+
+// querySelection is a field, a fragment spread (spread set) or an inline fragment (inline set).
+type querySelection struct {
+	alias         string
+	name          string
+	args          map[string]queryValue
+	spread        string
+	inline        bool
+	typeCondition string
+	selections    []*querySelection
+}
+
+// queryValue is an argument or default value. Only integers and variable references are kept.
+type queryValue struct {
+	variable string
+	isInt    bool
+	intValue int
+}
+
+// operation returns the operation a request runs: the one named operationName, or the only one.
+func (d *queryDocument) operation(operationName string) *queryOperation {
+	if operationName == "" {
+		if len(d.operations) == 1 {
+			return d.operations[0]
+		}
+		return nil
+	}
+	for _, op := range d.operations {
+		if op.name == operationName {
+			return op
+		}
+	}
+	return nil
+}
+
+// resolveInt resolves an integer argument from a literal, a request variable or a variable default.
+func (v queryValue) resolveInt(op *queryOperation, variables map[string]interface{}) (int, bool) {
+	if v.variable == "" {
+		return v.intValue, v.isInt
+	}
+	switch n := variables[v.variable].(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	}
+	if def, ok := op.variables[v.variable]; ok && def.isInt {
+		return def.intValue, true
+	}
+	return 0, false
+}
+
+func parseQueryDocument(src string) (doc *queryDocument, err error) {
+	p := &queryParser{lex: queryLexer{src: src}}
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(queryParseError)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, perr
+		}
+	}()
+	p.next()
+	doc = &queryDocument{fragments: make(map[string]*queryFragment)}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.tok.is("{"):
+			doc.operations = append(doc.operations, &queryOperation{kind: "query", selections: p.selectionSet()})
+		case p.tok.kind == tokName && (p.tok.text == "query" || p.tok.text == "mutation" || p.tok.text == "subscription"):
+			doc.operations = append(doc.operations, p.operation())
+		case p.tok.kind == tokName && p.tok.text == "fragment":
+			p.next()
+			name := p.expectName()
+			if p.expectName() != "on" {
+				p.fail("expected on")
+			}
+			f := &queryFragment{typeCondition: p.expectName()}
+			p.directives()
+			f.selections = p.selectionSet()
+			doc.fragments[name] = f
+		default:
+			p.fail("unexpected %q", p.tok.text)
+		}
+	}
+	return doc, nil
+}
+
+type queryParseError string
+
+func (e queryParseError) Error() string { return string(e) }
+
+type queryParser struct {
+	lex queryLexer
+	tok queryToken
+}
+
+func (p *queryParser) fail(format string, args ...interface{}) {
+	panic(queryParseError(fmt.Sprintf(format, args...)))
+}
+
+func (p *queryParser) next() { p.tok = p.lex.next() }
+
+func (p *queryParser) expect(punct string) {
+	if !p.tok.is(punct) {
+		p.fail("expected %q, got %q", punct, p.tok.text)
+	}
+	p.next()
+}
+
+func (p *queryParser) expectName() string {
+	if p.tok.kind != tokName {
+		p.fail("expected a name, got %q", p.tok.text)
+	}
+	name := p.tok.text
+	p.next()
+	return name
+}
+
+func (p *queryParser) operation() *queryOperation {
+	op := &queryOperation{kind: p.tok.text, variables: make(map[string]queryValue)}
+	p.next()
+	if p.tok.kind == tokName {
+		op.name = p.expectName()
+	}
+	if p.tok.is("(") {
+		p.next()
+		for !p.tok.is(")") {
+			p.expect("$")
+			name := p.expectName()
+			p.expect(":")
+			p.typeRef()
+			if p.tok.is("=") {
+				p.next()
+				op.variables[name] = p.value()
+			}
+			p.directives()
+		}
+		p.next()
+	}
+	p.directives()
+	op.selections = p.selectionSet()
+	return op
+}
+
+func (p *queryParser) typeRef() {
+	if p.tok.is("[") {
+		p.next()
+		p.typeRef()
+		p.expect("]")
+	} else {
+		p.expectName()
+	}
+	if p.tok.is("!") {
+		p.next()
+	}
+}
+
+func (p *queryParser) selectionSet() []*querySelection {
+	p.expect("{")
+	var selections []*querySelection
+	for !p.tok.is("}") {
+		if p.tok.kind == tokEOF {
+			p.fail("unexpected end of document")
+		}
+		selections = append(selections, p.selection())
+	}
+	p.next()
+	return selections
+}
+
+func (p *queryParser) selection() *querySelection {
+	if p.tok.is("...") {
+		p.next()
+		if p.tok.kind == tokName && p.tok.text != "on" {
+			s := &querySelection{spread: p.expectName()}
+			p.directives()
+			return s
+		}
+		s := &querySelection{inline: true}
+		if p.tok.kind == tokName {
+			p.next()
+			s.typeCondition = p.expectName()
+		}
+		p.directives()
+		s.selections = p.selectionSet()
+		return s
+	}
+	s := &querySelection{name: p.expectName()}
+	if p.tok.is(":") {
+		p.next()
+		s.alias, s.name = s.name, p.expectName()
+	}
+	s.args = p.arguments()
+	p.directives()
+	if p.tok.is("{") {
+		s.selections = p.selectionSet()
+	}
+	return s
+}
+
+func (p *queryParser) arguments() map[string]queryValue {
+	if !p.tok.is("(") {
+		return nil
+	}
+	p.next()
+	args := make(map[string]queryValue)
+	for !p.tok.is(")") {
+		name := p.expectName()
+		p.expect(":")
+		args[name] = p.value()
+	}
+	p.next()
+	return args
+}
+
+func (p *queryParser) directives() {
+	for p.tok.is("@") {
+		p.next()
+		p.expectName()
+		p.arguments()
+	}
+}
+
+func (p *queryParser) value() queryValue {
+	tok := p.tok
+	switch {
+	case tok.is("$"):
+		p.next()
+		return queryValue{variable: p.expectName()}
+	case tok.is("["):
+		p.next()
+		for !p.tok.is("]") {
+			p.value()
+		}
+		p.next()
+	case tok.is("{"):
+		p.next()
+		for !p.tok.is("}") {
+			p.expectName()
+			p.expect(":")
+			p.value()
+		}
+		p.next()
+	case tok.kind == tokNumber:
+		p.next()
+		if n, err := strconv.Atoi(tok.text); err == nil {
+			return queryValue{isInt: true, intValue: n}
+		}
+	case tok.kind == tokString || tok.kind == tokName:
+		p.next()
+	default:
+		p.fail("unexpected %q", tok.text)
+	}
+	return queryValue{}
+}
+
+type queryTokenKind int
+
+const (
+	tokEOF queryTokenKind = iota
+	tokPunct
+	tokName
+	tokNumber
+	tokString
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+}
+
+func (t queryToken) is(punct string) bool { return t.kind == tokPunct && t.text == punct }
+
+const byteOrderMark = "\ufeff"
+
+type queryLexer struct {
+	src string
+	pos int
+}
+
+func (l *queryLexer) next() queryToken {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], byteOrderMark):
+			// The spec ignores a byte order mark like white space.
+			l.pos += len(byteOrderMark)
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "..."):
+			l.pos += 3
+			return queryToken{kind: tokPunct, text: "..."}
+		case strings.IndexByte("!$():=@[]{}|&", c) >= 0:
+			l.pos++
+			return queryToken{kind: tokPunct, text: string(c)}
+		case c == '"':
+			return l.stringToken()
+		case c == '-' || c >= '0' && c <= '9':
+			start := l.pos
+			l.pos++
+			for l.pos < len(l.src) && strings.IndexByte("0123456789.eE+-", l.src[l.pos]) >= 0 {
+				l.pos++
+			}
+			return queryToken{kind: tokNumber, text: l.src[start:l.pos]}
+		case isNameByte(c):
+			start := l.pos
+			for l.pos < len(l.src) && (isNameByte(l.src[l.pos]) || l.src[l.pos] >= '0' && l.src[l.pos] <= '9') {
+				l.pos++
+			}
+			return queryToken{kind: tokName, text: l.src[start:l.pos]}
+		default:
+			panic(queryParseError(fmt.Sprintf("unexpected character %q", c)))
+		}
+	}
+	return queryToken{kind: tokEOF}
+}
+
+func (l *queryLexer) stringToken() queryToken {
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		end := strings.Index(l.src[l.pos+3:], `"""`)
+		for end >= 0 && l.src[l.pos+3+end-1] == '\\' {
+			next := strings.Index(l.src[l.pos+3+end+1:], `"""`)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += next + 1
+		}
+		if end < 0 {
+			panic(queryParseError("unterminated block string"))
+		}
+		l.pos += end + 6
+		return queryToken{kind: tokString}
+	}
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos++
+		case '"':
+			l.pos++
+			return queryToken{kind: tokString}
+		case '\n':
+			panic(queryParseError("unterminated string"))
+		}
+	}
+	panic(queryParseError("unterminated string"))
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,271 @@
+package graphql
+
+import (
+	"expvar"
+	"fmt"
+	"strconv"
+	"strings"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+
+	"magento.GO/config"
+)
+
+// QueryLimits bound the size of one GraphQL operation. They are checked before execution; a zero
+// limit is not checked.
+type QueryLimits struct {
+	MaxDepth      int // nesting of selection sets
+	MaxComplexity int // fields, multiplied by the page size or defaultListSize of the lists they are in
+	MaxPageSize   int // value of any pageSize argument
+	MaxAliases    int // aliased fields
+}
+
+// defaultListSize is the assumed length of a list field without a page size (e.g. Category.children).
+const defaultListSize = 10
+
+// Limit names, used as rejection counter keys.
+const (
+	limitDepth      = "depth"
+	limitComplexity = "complexity"
+	limitPageSize   = "page_size"
+	limitAliases    = "aliases"
+)
+
+// rejectedQueries counts rejected operations per limit; published at /debug/vars as
+// graphql_rejected_queries when expvar is served.
+var rejectedQueries = expvar.NewMap("graphql_rejected_queries")
+
+// RejectedQueries returns the number of operations rejected by a limit ("depth", "complexity",
+// "page_size" or "aliases") since start.
+func RejectedQueries(limit string) int64 {
+	if v, ok := rejectedQueries.Get(limit).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// QueryLimitsFromEnv reads GRAPHQL_MAX_DEPTH, GRAPHQL_MAX_COMPLEXITY, GRAPHQL_MAX_PAGE_SIZE and
+// GRAPHQL_MAX_ALIASES; 0 disables a limit.
+func QueryLimitsFromEnv() QueryLimits {
+	return QueryLimits{
+		MaxDepth:      envInt("GRAPHQL_MAX_DEPTH", 15),
+		MaxComplexity: envInt("GRAPHQL_MAX_COMPLEXITY", 50000),
+		MaxPageSize:   envInt("GRAPHQL_MAX_PAGE_SIZE", 300),
+		MaxAliases:    envInt("GRAPHQL_MAX_ALIASES", 30),
+	}
+}
+
+// SchemaOptions returns the graphql-go options that enforce the depth limit in its own validation
+// as well, so it holds for documents check cannot measure (see parseQueryDocument).
+func (l QueryLimits) SchemaOptions() []gql.SchemaOpt {
+	if l.MaxDepth <= 0 {
+		return nil
+	}
+	// graphql-go counts the fields: a nesting of n selection sets has fields at depth n+1.
+	return []gql.SchemaOpt{gql.MaxDepth(l.MaxDepth + 1)}
+}
+
+func envInt(key string, defaultVal int) int {
+	n, err := strconv.Atoi(config.GetEnv(key, strconv.Itoa(defaultVal)))
+	if err != nil || n < 0 {
+		return defaultVal
+	}
+	return n
+}
+
+// check measures the operation that runs and returns an error for the first limit it exceeds. The
+// rejection is counted.
+func (l QueryLimits) check(schema *ast.Schema, doc *queryDocument, operationName string, variables map[string]interface{}) error {
+	op := doc.operation(operationName)
+	if op == nil || schema == nil {
+		return nil
+	}
+	root, _ := schema.RootOperationTypes[op.kind].(ast.NamedType)
+	a := &queryAnalysis{schema: schema, doc: doc, op: op, variables: variables, limits: l, visiting: make(map[string]bool)}
+	complexity, depth := a.selections(op.selections, root, 1, 0)
+
+	var limit string
+	var err error
+	switch {
+	case a.pageSizeErr != nil:
+		limit, err = limitPageSize, a.pageSizeErr
+	case l.MaxAliases > 0 && a.aliases > l.MaxAliases:
+		limit, err = limitAliases, fmt.Errorf("query has %d aliases, the maximum is %d", a.aliases, l.MaxAliases)
+	case l.MaxDepth > 0 && depth > l.MaxDepth:
+		limit, err = limitDepth, fmt.Errorf("query depth %d exceeds the maximum of %d", depth, l.MaxDepth)
+	case l.MaxComplexity > 0 && complexity > l.MaxComplexity:
+		limit, err = limitComplexity, fmt.Errorf("query complexity %d exceeds the maximum of %d", complexity, l.MaxComplexity)
+	default:
+		return nil
+	}
+	rejectedQueries.Add(limit, 1)
+	return err
+}
+
+type queryAnalysis struct {
+	schema      *ast.Schema
+	doc         *queryDocument
+	op          *queryOperation
+	variables   map[string]interface{}
+	limits      QueryLimits
+	visiting    map[string]bool // fragments being expanded, against cycles
+	aliases     int
+	pageSizeErr error
+}
+
+// maxComplexity caps the computed complexity so deep list nesting cannot overflow.
+const maxComplexity = 1 << 40
+
+// selections returns the complexity and depth of a selection set on parent. listSize is the page
+// size of the enclosing paged field (e.g. products), applied to its list fields (items); 0 means
+// lists count defaultListSize.
+func (a *queryAnalysis) selections(sels []*querySelection, parent ast.NamedType, depth, listSize int) (complexity, maxDepth int) {
+	for _, s := range sels {
+		var c, d int
+		switch {
+		case s.spread != "":
+			f := a.doc.fragments[s.spread]
+			if f == nil || a.visiting[s.spread] {
+				continue
+			}
+			a.visiting[s.spread] = true
+			c, d = a.selections(f.selections, a.schema.Types[f.typeCondition], depth, listSize)
+			delete(a.visiting, s.spread)
+		case s.inline:
+			t := parent
+			if s.typeCondition != "" {
+				t = a.schema.Types[s.typeCondition]
+			}
+			c, d = a.selections(s.selections, t, depth, listSize)
+		default:
+			c, d = a.field(s, parent, depth, listSize)
+		}
+		complexity = capComplexity(complexity + c)
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+	return complexity, maxDepth
+}
+
+func (a *queryAnalysis) field(s *querySelection, parent ast.NamedType, depth, listSize int) (complexity, maxDepth int) {
+	if strings.HasPrefix(s.name, "__") {
+		// introspection types are not in the schema: only its depth is measured
+		if len(s.selections) == 0 {
+			return 0, depth - 1
+		}
+		return 0, max(a.introspectionDepth(s.selections, depth+1), depth)
+	}
+	if s.alias != "" {
+		a.aliases++
+	}
+	def := fieldDefinition(parent, s.name)
+	var child ast.NamedType
+	isList := false
+	if def != nil {
+		child, isList = unwrapType(def.Type)
+	}
+
+	childListSize := 0
+	if pageSize, ok := a.pageSize(s, def); ok {
+		if a.limits.MaxPageSize > 0 && pageSize > a.limits.MaxPageSize && a.pageSizeErr == nil {
+			a.pageSizeErr = fmt.Errorf("pageSize %d of %s exceeds the maximum of %d", pageSize, s.name, a.limits.MaxPageSize)
+		}
+		childListSize = max(pageSize, 1)
+	}
+	multiplier := 1
+	if isList {
+		multiplier = defaultListSize
+		if listSize > 0 {
+			multiplier = listSize
+		}
+	}
+	if len(s.selections) == 0 {
+		return multiplier, depth - 1
+	}
+	childComplexity, childDepth := a.selections(s.selections, child, depth+1, childListSize)
+	return mulComplexity(multiplier, capComplexity(1+childComplexity)), max(childDepth, depth)
+}
+
+// introspectionDepth returns the depth of a selection set within __schema or __type.
+func (a *queryAnalysis) introspectionDepth(sels []*querySelection, depth int) (maxDepth int) {
+	for _, s := range sels {
+		var d int
+		switch {
+		case s.spread != "":
+			f := a.doc.fragments[s.spread]
+			if f == nil || a.visiting[s.spread] {
+				continue
+			}
+			a.visiting[s.spread] = true
+			d = a.introspectionDepth(f.selections, depth)
+			delete(a.visiting, s.spread)
+		case s.inline:
+			d = a.introspectionDepth(s.selections, depth)
+		case len(s.selections) == 0:
+			d = depth - 1
+		default:
+			d = max(a.introspectionDepth(s.selections, depth+1), depth)
+		}
+		maxDepth = max(maxDepth, d)
+	}
+	return maxDepth
+}
+
+// pageSize returns the pageSize argument of a field: given in the query, or the schema default.
+func (a *queryAnalysis) pageSize(s *querySelection, def *ast.FieldDefinition) (int, bool) {
+	if v, ok := s.args["pageSize"]; ok {
+		return v.resolveInt(a.op, a.variables)
+	}
+	if def == nil {
+		return 0, false
+	}
+	if arg := def.Arguments.Get("pageSize"); arg != nil && arg.Default != nil {
+		n, err := strconv.Atoi(arg.Default.String())
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func fieldDefinition(t ast.NamedType, name string) *ast.FieldDefinition {
+	switch t := t.(type) {
+	case *ast.ObjectTypeDefinition:
+		return t.Fields.Get(name)
+	case *ast.InterfaceTypeDefinition:
+		return t.Fields.Get(name)
+	}
+	return nil
+}
+
+// unwrapType returns the named type of a field type and whether it is a list.
+func unwrapType(t ast.Type) (ast.NamedType, bool) {
+	isList := false
+	for {
+		switch w := t.(type) {
+		case *ast.NonNull:
+			t = w.OfType
+		case *ast.List:
+			isList = true
+			t = w.OfType
+		case ast.NamedType:
+			return w, isList
+		default:
+			return nil, isList
+		}
+	}
+}
+
+func mulComplexity(a, b int) int {
+	if a != 0 && b > maxComplexity/a {
+		return maxComplexity
+	}
+	return capComplexity(a * b)
+}
+
+func capComplexity(n int) int {
+	if n > maxComplexity || n < 0 {
+		return maxComplexity
+	}
+	return n
+}
@@ -0,0 +1,199 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"magento.GO/core/cache"
+	"magento.GO/core/events"
+	graphqlpkg "magento.GO/graphql"
+	categoryRepo "magento.GO/model/repository/category"
+	productRepo "magento.GO/model/repository/product"
+)
+
+// Full-response cache for anonymous catalog queries. Entries are keyed by the query, operation,
+// variables, store, customer group and currency, and tagged with the products and categories the
+// response was built from (graphql.ProductTag, graphql.CategoryTag); graphql.InvalidateCacheTags
+// drops them. The same tags are sent as X-Magento-Tags, and X-Magento-Cache-Id identifies the
+// store/group/currency variant, so Varnish or a CDN can cache GET responses the same way; Vary
+// names the request headers the variant is derived from. Products published on the core/events
+// topics (importers, the product service, the change poller) and categories published on
+// events.TopicCategory are purged by invalidateOnChanges.
+
+const (
+	headerCacheTags  = "X-Magento-Tags"
+	headerCacheID    = "X-Magento-Cache-Id"
+	headerCacheDebug = "X-Magento-Cache-Debug" // HIT or MISS for responses of the internal cache
+
+	responseCacheKeyPrefix = "graphql:response:"
+)
+
+// varyHeaders are the request headers a response varies by: store view, display currency and the
+// customer (group) of a token or signed header.
+const varyHeaders = "Store, Content-Currency, Authorization, X-Customer-ID"
+
+// cacheableQueryFields are the Query fields whose result depends only on the catalog, store, customer
+// group and currency. An operation is cached only if it selects nothing else.
+var cacheableQueryFields = map[string]bool{
+	"products":                     true,
+	"product":                      true,
+	"categories":                   true,
+	"category":                     true,
+	"categoryTree":                 true,
+	"magentoCategories":            true,
+	"urlResolver":                  true,
+	"route":                        true,
+	"storeConfig":                  true,
+	"availableStores":              true,
+	"productReviewRatingsMetadata": true,
+	"currency":                     true,
+	"magentoProducts":              true,
+	"search":                       true,
+	"searchSuggestions":            true,
+}
+
+// ResponseCacheTTLFromEnv reads GRAPHQL_RESPONSE_CACHE_TTL in seconds; 0 (the default) disables the
+// response cache and Cache-Control max-age.
+func ResponseCacheTTLFromEnv() time.Duration {
+	return time.Duration(envInt("GRAPHQL_RESPONSE_CACHE_TTL", 0)) * time.Second
+}
+
+// cachedResponse is a response body with the tags it was stored under.
+type cachedResponse struct {
+	body []byte
+	tags []string
+}
+
+// isCacheableOperation reports whether op is a query selecting only cacheable fields.
+func isCacheableOperation(doc *queryDocument, op *queryOperation) bool {
+	return op.kind == "query" && cacheableSelections(doc, op.selections, make(map[string]bool))
+}
+
+func cacheableSelections(doc *queryDocument, sels []*querySelection, visiting map[string]bool) bool {
+	for _, s := range sels {
+		switch {
+		case s.spread != "":
+			f := doc.fragments[s.spread]
+			if f == nil || visiting[s.spread] {
+				return false
+			}
+			visiting[s.spread] = true
+			ok := cacheableSelections(doc, f.selections, visiting)
+			delete(visiting, s.spread)
+			if !ok {
+				return false
+			}
+		case s.inline:
+			if !cacheableSelections(doc, s.selections, visiting) {
+				return false
+			}
+		case s.name == "__typename":
+		default:
+			if !cacheableQueryFields[s.name] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cacheID identifies the variant of a response: store view, customer group and display currency.
+func cacheID(ctx context.Context) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("store=%d|group=%d|currency=%s",
+		graphqlpkg.StoreIDFromContext(ctx), graphqlpkg.CustomerGroupIDFromContext(ctx), strings.ToUpper(graphqlpkg.CurrencyFromContext(ctx)))))
+	return hex.EncodeToString(sum[:])
+}
+
+// responseCacheKey returns the cache key of a request in the variant identified by id.
+func responseCacheKey(req *GraphQLRequest, id string) string {
+	variables, _ := json.Marshal(req.Variables) // map keys are sorted
+	h := sha256.New()
+	for _, part := range []string{req.Query, req.OperationName, string(variables), id} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return responseCacheKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+func loadCachedResponse(key string) (cachedResponse, bool) {
+	v, ok := cache.GetInstance().Get(key)
+	if !ok {
+		return cachedResponse{}, false
+	}
+	cached, ok := v.(cachedResponse)
+	return cached, ok
+}
+
+func saveCachedResponse(key string, cached cachedResponse, ttl time.Duration) {
+	cache.GetInstance().Set(key, cached, int64(ttl/time.Second), cached.tags)
+}
+
+var (
+	// Databases whose changes already purge the cache: each test database gets its own invalidator.
+	invalidators   = make(map[*gorm.DB]bool)
+	invalidatorsMu sync.Mutex
+)
+
+// invalidateOnChanges purges the cached responses of the products published on hub's product,
+// stock and price topics, and of the categories published on its category topic, for the rest of
+// the process. Product listings (ProductListTag) are purged with the products, as a change can add a
+// product to or remove it from a listing, filter or sort order; the category list and tree
+// (CategoryListTag) likewise with the categories. It is started once per DB.
+func invalidateOnChanges(db *gorm.DB, hub *events.Hub) {
+	invalidatorsMu.Lock()
+	defer invalidatorsMu.Unlock()
+	if invalidators[db] {
+		return
+	}
+	invalidators[db] = true
+	product := hub.SubscribeAll(events.TopicProduct)
+	stock := hub.SubscribeAll(events.TopicStock)
+	price := hub.SubscribeAll(events.TopicPrice)
+	category := hub.SubscribeAll(events.TopicCategory)
+	go func() {
+		for {
+			select {
+			case <-product.C():
+				purgeProducts(db, product.Take())
+			case <-stock.C():
+				purgeProducts(db, stock.Take())
+			case <-price.C():
+				purgeProducts(db, price.Take())
+			case <-category.C():
+				purgeCategories(db, category.Take())
+			}
+		}
+	}()
+}
+
+// purgeProducts drops the cached responses tagged with the products of skus, and product listings.
+func purgeProducts(db *gorm.DB, skus []string) {
+	tags := []string{graphqlpkg.ProductListTag}
+	products, _ := productRepo.GetProductRepository(db).FindBySKUs(skus)
+	for _, p := range products {
+		tags = append(tags, graphqlpkg.ProductTag(p.EntityID))
+	}
+	graphqlpkg.InvalidateCacheTags(tags...)
+}
+
+// purgeCategories reloads the categories of the repository cache and drops the cached responses
+// tagged with the categories of ids, and the category list and tree.
+func purgeCategories(db *gorm.DB, ids []string) {
+	categoryRepo.GetCategoryRepository(db).InvalidateCache()
+	tags := []string{graphqlpkg.CategoryListTag}
+	for _, id := range ids {
+		if n, err := strconv.ParseUint(id, 10, 32); err == nil {
+			tags = append(tags, graphqlpkg.CategoryTag(uint(n)))
+		}
+	}
+	graphqlpkg.InvalidateCacheTags(tags...)
+}
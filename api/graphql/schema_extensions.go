@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+	"gorm.io/gorm"
+
+	graphqlpkg "magento.GO/graphql"
+	gqlregistry "magento.GO/graphql/registry"
+)
+
+// newRootResolver returns the root resolver of sdl, the merged schema. Without extension fields on
+// Query or Mutation the core resolvers serve them; otherwise see extendedRoot.
+func newRootResolver(db *gorm.DB, sdl string, merged *graphqlpkg.MergedSchema, exts []gqlregistry.SchemaExtension) (*rootResolver, error) {
+	root := &rootResolver{db: db}
+	if len(merged.Owners) == 0 {
+		return root, nil
+	}
+	schema, err := gql.ParseSchema(sdl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make(map[string]interface{}, len(exts))
+	for _, ext := range exts {
+		resolvers[ext.Name] = ext.Resolver(db)
+	}
+	for kind, owners := range merged.Owners {
+		byField := make(map[string]interface{}, len(owners))
+		for field, name := range owners {
+			byField[field] = resolvers[name]
+		}
+		t := schema.AST().RootOperationTypes[kind].(*ast.ObjectTypeDefinition)
+		switch kind {
+		case "query":
+			root.query, err = extendedRoot(t, gqlregistry.GetQueryResolver(db), byField)
+		case "mutation":
+			root.mutation, err = extendedRoot(t, gqlregistry.GetMutationResolver(db), byField)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// extendedRoot returns a resolver for the root type t whose fields are resolved by the methods of
+// the extension adding them (owners, by field) or else of core. graphql-go resolves the fields of a
+// type with one Go value, so the value is a struct built for t with a func field per GraphQL field,
+// bound to the resolving method.
+func extendedRoot(t *ast.ObjectTypeDefinition, core interface{}, owners map[string]interface{}) (interface{}, error) {
+	fields := make([]reflect.StructField, len(t.Fields))
+	methods := make([]reflect.Value, len(t.Fields))
+	for i, f := range t.Fields {
+		resolver, ok := owners[f.Name]
+		if !ok {
+			resolver = core
+		}
+		m := resolverMethod(reflect.ValueOf(resolver), f.Name)
+		if !m.IsValid() {
+			return nil, fmt.Errorf("%T does not resolve %q: missing method for field %q", resolver, t.Name, f.Name)
+		}
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: m.Type(),
+			Tag:  reflect.StructTag(`graphql:"` + f.Name + `"`),
+		}
+		methods[i] = m
+	}
+	v := reflect.New(reflect.StructOf(fields))
+	for i, m := range methods {
+		v.Elem().Field(i).Set(m)
+	}
+	return v.Interface(), nil
+}
+
+// resolverMethod returns the method of v resolving field, matched like graphql-go matches them:
+// ignoring case and underscores.
+func resolverMethod(v reflect.Value, field string) reflect.Value {
+	name := strings.ReplaceAll(field, "_", "")
+	for i := 0; i < v.NumMethod(); i++ {
+		if strings.EqualFold(strings.ReplaceAll(v.Type().Method(i).Name, "_", ""), name) {
+			return v.Method(i)
+		}
+	}
+	return reflect.Value{}
+}
@@ -0,0 +1,307 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+
+	"magento.GO/graphql/dataloader"
+	productService "magento.GO/service/product"
+)
+
+// GraphQL over WebSocket with the graphql-transport-ws protocol (the graphql-ws library), used for
+// subscriptions. Browsers cannot set headers on a WebSocket, so the connection_init payload may carry
+// the request headers (Store, Authorization, Content-Currency, ...); they replace those of the upgrade
+// request for every operation of the connection.
+// See https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+
+const wsSubprotocol = "graphql-transport-ws"
+
+// Message types of graphql-transport-ws.
+const (
+	wsConnectionInit = "connection_init"
+	wsConnectionAck  = "connection_ack"
+	wsPing           = "ping"
+	wsPong           = "pong"
+	wsSubscribe      = "subscribe"
+	wsNext           = "next"
+	wsError          = "error"
+	wsComplete       = "complete"
+)
+
+// Close codes of graphql-transport-ws.
+const (
+	wsCloseBadRequest       = 4400
+	wsCloseUnauthorized     = 4401
+	wsCloseInitTimeout      = 4408
+	wsCloseSubscriberExists = 4409
+	wsCloseTooManyInits     = 4429
+)
+
+// wsInitTimeout is how long a connection may stay open without connection_init.
+const wsInitTimeout = 10 * time.Second
+
+// SubscriptionPollIntervalFromEnv reads GRAPHQL_SUBSCRIPTION_POLL_INTERVAL in seconds (default 10):
+// how often the stock and price of subscribed SKUs are checked for changes made outside this
+// process. 0 disables polling; imports through this process still notify subscribers.
+func SubscriptionPollIntervalFromEnv() time.Duration {
+	return time.Duration(envInt("GRAPHQL_SUBSCRIPTION_POLL_INTERVAL", 10)) * time.Second
+}
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsHandler serves WebSocket upgrade requests and passes all others to next.
+type wsHandler struct {
+	next   http.Handler
+	schema *gql.Schema
+	limits QueryLimits
+	db     *gorm.DB
+	poller *productService.ChangePoller // nil without a DB
+}
+
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	websocket.Server{Handshake: wsHandshake, Handler: h.serve}.ServeHTTP(w, r)
+}
+
+// wsHandshake accepts only clients speaking graphql-transport-ws. Origins are not checked: the
+// connection is authenticated with the connection_init payload, not with cookies.
+func wsHandshake(config *websocket.Config, r *http.Request) error {
+	for _, p := range config.Protocol {
+		if p == wsSubprotocol {
+			config.Protocol = []string{wsSubprotocol}
+			return nil
+		}
+	}
+	return errors.New("unsupported WebSocket subprotocol, want " + wsSubprotocol)
+}
+
+// wsConn is one graphql-transport-ws connection and its running operations.
+type wsConn struct {
+	ws *websocket.Conn
+
+	mu         sync.Mutex
+	ctx        context.Context // set by connection_init
+	operations map[string]*wsOperation
+}
+
+type wsOperation struct {
+	cancel context.CancelFunc
+}
+
+func (h *wsHandler) serve(ws *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel() // stops all operations
+	c := &wsConn{ws: ws, operations: make(map[string]*wsOperation)}
+	initTimer := time.AfterFunc(wsInitTimeout, func() {
+		if c.initContext() == nil {
+			c.close(wsCloseInitTimeout)
+		}
+	})
+	defer initTimer.Stop()
+
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			return // closed by the client or by close
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type == "" {
+			c.close(wsCloseBadRequest)
+			return
+		}
+		switch msg.Type {
+		case wsConnectionInit:
+			if c.initContext() != nil {
+				c.close(wsCloseTooManyInits)
+				return
+			}
+			var headers map[string]interface{}
+			if len(msg.Payload) > 0 && json.Unmarshal(msg.Payload, &headers) != nil {
+				c.close(wsCloseBadRequest)
+				return
+			}
+			c.init(h.connectionContext(ctx, ws.Request(), headers))
+			c.send(wsMessage{Type: wsConnectionAck})
+		case wsPing:
+			c.send(wsMessage{Type: wsPong})
+		case wsPong:
+		case wsSubscribe:
+			opCtx := c.initContext()
+			if opCtx == nil {
+				c.close(wsCloseUnauthorized)
+				return
+			}
+			var req GraphQLRequest
+			if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil {
+				c.close(wsCloseBadRequest)
+				return
+			}
+			if !h.subscribe(c, opCtx, msg.ID, &req) {
+				c.close(wsCloseSubscriberExists)
+				return
+			}
+		case wsComplete:
+			c.stop(msg.ID)
+		default:
+			c.close(wsCloseBadRequest)
+			return
+		}
+	}
+}
+
+// connectionContext returns the context of the connection's operations: that of the upgrade
+// request, with the connection_init headers applied.
+func (h *wsHandler) connectionContext(ctx context.Context, r *http.Request, headers map[string]interface{}) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	r = r.Clone(ctx)
+	for k, v := range headers {
+		if s, ok := v.(string); ok {
+			r.Header.Set(k, s)
+		}
+	}
+	return requestContext(r, h.db)
+}
+
+// subscribe starts operation id and streams its results as next messages followed by complete.
+// It returns false if id is already running.
+func (h *wsHandler) subscribe(c *wsConn, ctx context.Context, id string, req *GraphQLRequest) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	op := &wsOperation{cancel: cancel}
+	if !c.start(id, op) {
+		cancel()
+		return false
+	}
+	if gqlErr := resolvePersistedQuery(req); gqlErr != nil {
+		c.fail(id, op, []GraphQLError{*gqlErr})
+		return true
+	}
+	// A document the limits cannot parse is left to the executor (see queryDocument).
+	doc, err := parseQueryDocument(req.Query)
+	if err == nil {
+		if err := h.limits.check(h.schema.ASTSchema(), doc, req.OperationName, req.Variables); err != nil {
+			c.fail(id, op, []GraphQLError{{Message: err.Error()}})
+			return true
+		}
+	}
+	if h.poller != nil {
+		if doc == nil {
+			h.poller.Start()
+		} else if o := doc.operation(req.OperationName); o != nil && o.kind == "subscription" {
+			h.poller.Start()
+		}
+	}
+	if h.db != nil {
+		// Fresh loaders per operation: the connection lives longer than values may be cached.
+		ctx = dataloader.WithLoaders(ctx, dataloader.NewLoaders(h.db))
+	}
+	responses, err := h.schema.Subscribe(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		c.fail(id, op, []GraphQLError{{Message: err.Error()}})
+		return true
+	}
+	go func() {
+		first := true
+		for r := range responses {
+			response := r.(*gql.Response)
+			// Errors before execution (syntax, validation, a failing subscription resolver) come
+			// without data and end the operation with an error message.
+			if first && len(response.Errors) > 0 && len(response.Data) == 0 {
+				errs := make([]GraphQLError, len(response.Errors))
+				for i, e := range response.Errors {
+					errs[i] = GraphQLError{Message: e.Message, Extensions: e.Extensions}
+				}
+				c.fail(id, op, errs)
+				return
+			}
+			first = false
+			payload, _ := json.Marshal(response)
+			c.send(wsMessage{ID: id, Type: wsNext, Payload: payload})
+		}
+		if c.finish(id, op) {
+			c.send(wsMessage{ID: id, Type: wsComplete})
+		}
+	}()
+	return true
+}
+
+func (c *wsConn) initContext() context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ctx
+}
+
+func (c *wsConn) init(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ctx = ctx
+}
+
+// start registers op as id, unless id is already running.
+func (c *wsConn) start(id string, op *wsOperation) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.operations[id]; ok {
+		return false
+	}
+	c.operations[id] = op
+	return true
+}
+
+// finish unregisters op and reports whether it was still running, i.e. not completed by the client.
+func (c *wsConn) finish(id string, op *wsOperation) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.operations[id] != op {
+		return false
+	}
+	delete(c.operations, id)
+	op.cancel()
+	return true
+}
+
+// stop ends operation id on the client's complete message.
+func (c *wsConn) stop(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if op, ok := c.operations[id]; ok {
+		delete(c.operations, id)
+		op.cancel()
+	}
+}
+
+// fail ends op with an error message.
+func (c *wsConn) fail(id string, op *wsOperation, errs []GraphQLError) {
+	if c.finish(id, op) {
+		payload, _ := json.Marshal(errs)
+		c.send(wsMessage{ID: id, Type: wsError, Payload: payload})
+	}
+}
+
+// send writes a message; it is safe for concurrent use.
+func (c *wsConn) send(msg wsMessage) {
+	websocket.JSON.Send(c.ws, msg)
+}
+
+// close sends a close frame with code and stops reading, which ends serve.
+func (c *wsConn) close(code int) {
+	c.ws.WriteClose(code)
+	c.ws.SetReadDeadline(time.Now())
+}
@@ -10,7 +10,10 @@ import (
 	"gorm.io/gorm"
 
 	"magento.GO/api"
+	"magento.GO/graphql"
+	currencyRepo "magento.GO/model/repository/currency"
 	productRepository "magento.GO/model/repository/product"
+	storeRepo "magento.GO/model/repository/store"
 	productService "magento.GO/service/product"
 )
 
@@ -18,8 +21,29 @@ func init() {
 	api.RegisterModule(RegisterProductRoutes)
 }
 
+// bundlePriceRanges returns the guest bundle_price_range of each bundle among the products for the
+// store's website.
+func bundlePriceRanges(repo *productRepository.ProductRepository, stores *storeRepo.StoreRepository, storeID uint16, products []map[string]interface{}) (map[uint]map[string]interface{}, error) {
+	store, _ := stores.GetByID(stores.ResolveStoreID(storeID))
+	return repo.BundlePriceRanges(products, store.WebsiteID, graphql.NotLoggedInGroupID)
+}
+
+// withBundlePriceRange returns p with its bundle_price_range set on a copy: flat products are shared
+// through the product cache.
+func withBundlePriceRange(p map[string]interface{}, priceRange map[string]interface{}) map[string]interface{} {
+	if priceRange == nil {
+		return p
+	}
+	out := make(map[string]interface{}, len(p)+1)
+	for k, v := range p {
+		out[k] = v
+	}
+	out["bundle_price_range"] = priceRange
+	return out
+}
+
 // Handler for /flat and /full endpoints
-func flatProductsHandler(repo *productRepository.ProductRepository) echo.HandlerFunc {
+func flatProductsHandler(repo *productRepository.ProductRepository, stores *storeRepo.StoreRepository, currencies *currencyRepo.CurrencyRepository) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		start := time.Now()
 		limit := 0
@@ -28,24 +52,41 @@ func flatProductsHandler(repo *productRepository.ProductRepository) echo.Handler
 				limit = l
 			}
 		}
-		flatProducts, err := repo.FetchWithAllAttributesFlatWithLimit(limit)
+		storeID := api.StoreIDFromRequest(c)
+		flatProducts, err := repo.FetchWithAllAttributesFlatWithLimit(limit, storeID)
 		duration := time.Since(start).Milliseconds()
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error(), "request_duration_ms": duration})
 		}
+		list := make([]map[string]interface{}, 0, len(flatProducts))
+		for _, p := range flatProducts {
+			list = append(list, p)
+		}
+		ranges, err := bundlePriceRanges(repo, stores, storeID, list)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error(), "request_duration_ms": duration})
+		}
+		cur := currencies.Converter(storeID, api.CurrencyFromRequest(c))
+		products := make(map[uint]map[string]interface{}, len(flatProducts))
+		for id, p := range flatProducts {
+			products[id] = cur.ConvertFlat(withBundlePriceRange(p, ranges[id]))
+		}
 		c.Response().Header().Set("X-Request-Duration-ms", strconv.FormatInt(duration, 10))
 		return c.JSON(http.StatusOK, echo.Map{
-			"products": flatProducts,
-			"count": len(flatProducts),
+			"products": products,
+			"count": len(products),
+			"currency": cur.Display,
 			"request_duration_ms": duration,
 		})
 	}
 }
 
-func RegisterProductRoutes(api *echo.Group, db *gorm.DB) {
+func RegisterProductRoutes(apiGroup *echo.Group, db *gorm.DB) {
 	repo := productRepository.GetProductRepository(db)
+	currencies := currencyRepo.GetCurrencyRepository(db)
+	stores := storeRepo.GetStoreRepository(db)
 	service := productService.NewProductService(repo)
-	g := api.Group("/products")
+	g := apiGroup.Group("/products")
 
 	g.GET("", func(c echo.Context) error {
 		start := time.Now()
@@ -94,6 +135,7 @@ func RegisterProductRoutes(api *echo.Group, db *gorm.DB) {
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error(), "request_duration_ms": duration})
 		}
+		graphql.InvalidateCacheTags(graphql.ProductListTag)
 		c.Response().Header().Set("X-Request-Duration-ms", strconv.FormatInt(duration, 10))
 		return c.JSON(http.StatusCreated, echo.Map{"product": product, "request_duration_ms": duration})
 	})
@@ -113,6 +155,7 @@ func RegisterProductRoutes(api *echo.Group, db *gorm.DB) {
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error(), "request_duration_ms": duration})
 		}
+		graphql.InvalidateCacheTags(graphql.ProductTag(uint(id)))
 		c.Response().Header().Set("X-Request-Duration-ms", strconv.FormatInt(duration, 10))
 		return c.JSON(http.StatusOK, echo.Map{"product": product, "request_duration_ms": duration})
 	})
@@ -128,12 +171,13 @@ func RegisterProductRoutes(api *echo.Group, db *gorm.DB) {
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error(), "request_duration_ms": duration})
 		}
+		graphql.InvalidateCacheTags(graphql.ProductTag(uint(id)), graphql.ProductListTag)
 		c.Response().Header().Set("X-Request-Duration-ms", strconv.FormatInt(duration, 10))
 		return c.NoContent(http.StatusNoContent)
 	})
 
-	g.GET("/flat", flatProductsHandler(repo))
-	g.GET("/full", flatProductsHandler(repo))
+	g.GET("/flat", flatProductsHandler(repo, stores, currencies))
+	g.GET("/full", flatProductsHandler(repo, stores, currencies))
 
 	g.GET("/flat/:ids", func(c echo.Context) error {
 		start := time.Now()
@@ -154,7 +198,8 @@ func RegisterProductRoutes(api *echo.Group, db *gorm.DB) {
 			}
 		}
 
-		flatProducts, err := repo.FetchWithAllAttributesFlatByIDs(ids)
+		storeID := api.StoreIDFromRequest(c)
+		flatProducts, err := repo.FetchWithAllAttributesFlatByIDs(ids, storeID)
 		duration := time.Since(start).Milliseconds()
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error(), "request_duration_ms": duration})
@@ -166,11 +211,21 @@ func RegisterProductRoutes(api *echo.Group, db *gorm.DB) {
 				result = append(result, prod)
 			}
 		}
+		ranges, err := bundlePriceRanges(repo, stores, storeID, result)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error(), "request_duration_ms": duration})
+		}
+		cur := currencies.Converter(storeID, api.CurrencyFromRequest(c))
+		for i, prod := range result {
+			id, _ := prod["entity_id"].(uint)
+			result[i] = cur.ConvertFlat(withBundlePriceRange(prod, ranges[id]))
+		}
 
 		c.Response().Header().Set("X-Request-Duration-ms", strconv.FormatInt(duration, 10))
 		return c.JSON(http.StatusOK, echo.Map{
 			"products": result,
 			"count":    len(result),
+			"currency": cur.Display,
 			"request_duration_ms": duration,
 		})
 	})
@@ -1,9 +1,6 @@
 package realtime
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"net/http"
 	"strconv"
 	"sync"
@@ -14,9 +11,11 @@ import (
 	"gorm.io/gorm"
 
 	"magento.GO/api"
-	"magento.GO/config"
+	"magento.GO/core/auth"
+	currencyRepo "magento.GO/model/repository/currency"
 	inventoryRepo "magento.GO/model/repository/inventory"
 	priceRepo "magento.GO/model/repository/price"
+	storeRepo "magento.GO/model/repository/store"
 )
 
 func init() {
@@ -25,9 +24,10 @@ func init() {
 
 // Response for price+inventory endpoint
 type PriceInventoryResponse struct {
-	SKU   string  `json:"sku"`
-	Price float64 `json:"price"`
-	Stock float64 `json:"stock"`
+	SKU      string  `json:"sku"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+	Stock    float64 `json:"stock"`
 }
 
 // Singleton repositories (created once per DB)
@@ -49,40 +49,25 @@ func getRepositories(db *gorm.DB) (*priceRepo.PriceRepository, *inventoryRepo.In
 	return priceRepoInstance, inventoryRepoInstance, repoErr
 }
 
-// getCryptKey returns the Magento crypt key from env
-func getCryptKey() string {
-	return config.GetEnv("MAGENTO_CRYPT_KEY", "")
-}
-
-// verifyCustomerSignature validates HMAC-SHA256 signature using constant-time comparison
-func verifyCustomerSignature(customerID, signature, cryptKey string) bool {
-	if cryptKey == "" || customerID == "" || signature == "" {
-		return false
-	}
-	mac := hmac.New(sha256.New, []byte(cryptKey))
-	mac.Write([]byte(customerID))
-	expected := mac.Sum(nil)
-	sig, err := hex.DecodeString(signature)
-	if err != nil {
-		return false
-	}
-	return hmac.Equal(expected, sig)
-}
-
 // RegisterRealtimeRoutes sets up the high-performance realtime pricing/inventory API
 func RegisterRealtimeRoutes(apiGroup *echo.Group, db *gorm.DB) {
 	g := apiGroup.Group("/realtime")
+	currencies := currencyRepo.GetCurrencyRepository(db)
+	stores := storeRepo.GetStoreRepository(db)
+
+	// Prices are those of the store's website, in the store's base currency or the display currency
+	// from Content-Currency / ?currency=.
 
 	// GET /api/realtime/price-inventory?sku=XXX&source=default
 	g.GET("/price-inventory", func(c echo.Context) error {
 		start := time.Now()
 
 		// Extract and verify customer signature
-		customerID := c.Request().Header.Get("X-Customer-ID")
-		customerSig := c.Request().Header.Get("X-Customer-Sig")
-		cryptKey := getCryptKey()
+		customerID := c.Request().Header.Get(auth.HeaderCustomerID)
+		customerSig := c.Request().Header.Get(auth.HeaderCustomerSig)
+		cryptKey := auth.CryptKey()
 
-		if cryptKey != "" && !verifyCustomerSignature(customerID, customerSig, cryptKey) {
+		if cryptKey != "" && !auth.VerifyCustomerSignature(customerID, customerSig, cryptKey) {
 			return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid signature"})
 		}
 
@@ -118,7 +103,7 @@ func RegisterRealtimeRoutes(apiGroup *echo.Group, db *gorm.DB) {
 		eg := new(errgroup.Group)
 
 		eg.Go(func() error {
-			price, priceFound = priceR.GetLowestPriceBySKU(sku, customerGroupID)
+			price, priceFound = priceR.GetLowestPriceBySKU(sku, stores.WebsiteID(api.StoreIDFromRequest(c)), customerGroupID)
 			return nil
 		})
 
@@ -139,10 +124,12 @@ func RegisterRealtimeRoutes(apiGroup *echo.Group, db *gorm.DB) {
 			})
 		}
 
+		cur := currencies.Converter(api.StoreIDFromRequest(c), api.CurrencyFromRequest(c))
 		return c.JSON(http.StatusOK, PriceInventoryResponse{
-			SKU:   sku,
-			Price: price,
-			Stock: stock,
+			SKU:      sku,
+			Price:    cur.Convert(price),
+			Currency: cur.Display,
+			Stock:    stock,
 		})
 	})
 
@@ -167,7 +154,7 @@ func RegisterRealtimeRoutes(apiGroup *echo.Group, db *gorm.DB) {
 			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "repository init failed"})
 		}
 
-		price, found := priceR.GetLowestPriceBySKU(sku, customerGroupID)
+		price, found := priceR.GetLowestPriceBySKU(sku, stores.WebsiteID(api.StoreIDFromRequest(c)), customerGroupID)
 		duration := time.Since(start).Milliseconds()
 		c.Response().Header().Set("X-Request-Duration-ms", strconv.FormatInt(duration, 10))
 
@@ -175,7 +162,8 @@ func RegisterRealtimeRoutes(apiGroup *echo.Group, db *gorm.DB) {
 			return c.JSON(http.StatusNotFound, echo.Map{"error": "price not found"})
 		}
 
-		return c.JSON(http.StatusOK, echo.Map{"sku": sku, "price": price})
+		cur := currencies.Converter(api.StoreIDFromRequest(c), api.CurrencyFromRequest(c))
+		return c.JSON(http.StatusOK, echo.Map{"sku": sku, "price": cur.Convert(price), "currency": cur.Display})
 	})
 
 	// GET /api/realtime/stock?sku=XXX&source=default - stock only
@@ -230,6 +218,10 @@ func RegisterRealtimeRoutes(apiGroup *echo.Group, db *gorm.DB) {
 			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 		}
 
-		return c.JSON(http.StatusOK, echo.Map{"sku": sku, "tier_prices": tiers})
+		cur := currencies.Converter(api.StoreIDFromRequest(c), api.CurrencyFromRequest(c))
+		for i := range tiers {
+			tiers[i].Value = cur.Convert(tiers[i].Value)
+		}
+		return c.JSON(http.StatusOK, echo.Map{"sku": sku, "tier_prices": tiers, "currency": cur.Display})
 	})
 }
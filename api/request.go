@@ -0,0 +1,33 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	currencyRepo "magento.GO/model/repository/currency"
+)
+
+// StoreIDFromRequest returns the store view of a REST or HTML request from the Store header or the
+// __Store query param; 0 (admin/default) when neither is set. StoreMiddleware has already replaced
+// store codes with their ID and set the store of a mapped Host or path prefix.
+func StoreIDFromRequest(c echo.Context) uint16 {
+	for _, v := range []string{c.Request().Header.Get("Store"), c.QueryParam("__Store")} {
+		if v == "" {
+			continue
+		}
+		if id, err := strconv.ParseUint(v, 10, 16); err == nil {
+			return uint16(id)
+		}
+	}
+	return 0
+}
+
+// CurrencyFromRequest returns the display currency a REST request asks for with the
+// Content-Currency header or the currency query param; "" for the store default.
+func CurrencyFromRequest(c echo.Context) string {
+	if v := c.Request().Header.Get(currencyRepo.HeaderContentCurrency); v != "" {
+		return v
+	}
+	return c.QueryParam("currency")
+}
@@ -0,0 +1,39 @@
+package search
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"magento.GO/api"
+	"magento.GO/graphql/resolvers"
+)
+
+func init() {
+	api.RegisterModule(RegisterSearchRoutes)
+}
+
+func RegisterSearchRoutes(apiGroup *echo.Group, db *gorm.DB) {
+	g := apiGroup.Group("/search")
+
+	// GET /api/search/suggest?q=duff&limit=5 – typeahead completions of the Store header's store view
+	g.GET("/suggest", func(c echo.Context) error {
+		start := time.Now()
+		q := c.QueryParam("q")
+		if q == "" {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "q required"})
+		}
+		limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+		suggestions, err := resolvers.SearchSuggestions(c.Request().Context(), db, api.StoreIDFromRequest(c), q, limit)
+		duration := time.Since(start).Milliseconds()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error(), "request_duration_ms": duration})
+		}
+		c.Response().Header().Set("X-Request-Duration-ms", strconv.FormatInt(duration, 10))
+		return c.JSON(http.StatusOK, suggestions)
+	})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"magento.GO/config"
+	configRepo "magento.GO/model/repository/config"
+	storeRepo "magento.GO/model/repository/store"
+)
+
+// StoreURLMappingFromEnv reports whether requests without a Store header or __Store param are
+// mapped to a store view by their Host and path prefix (STORE_URL_MAPPING, default false).
+func StoreURLMappingFromEnv() bool {
+	return config.GetEnv("STORE_URL_MAPPING", "false") == "true"
+}
+
+// StoreMiddleware resolves the store view of REST, GraphQL and HTML requests before routing. A
+// store code in the Store header or __Store param is replaced by its store_id. With urlMapping, a
+// request without either is matched to a store by its Host and path (StoreForURL); the store's
+// path prefix (e.g. /fr) is removed, so /fr/graphql is served by /graphql with Store set.
+// Register it with e.Pre.
+func StoreMiddleware(db *gorm.DB, urlMapping bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			resolveStore(c.Request(), db, urlMapping)
+			return next(c)
+		}
+	}
+}
+
+func resolveStore(r *http.Request, db *gorm.DB, urlMapping bool) {
+	stores := storeRepo.GetStoreRepository(db)
+	if v := r.Header.Get("Store"); v != "" {
+		if id, ok := stores.ParseStore(v); ok {
+			r.Header.Set("Store", strconv.FormatUint(uint64(id), 10))
+		}
+		return
+	}
+	q := r.URL.Query()
+	if v := q.Get("__Store"); v != "" {
+		if id, ok := stores.ParseStore(v); ok {
+			q.Set("__Store", strconv.FormatUint(uint64(id), 10))
+			r.URL.RawQuery = q.Encode()
+		}
+		return
+	}
+	if !urlMapping {
+		return
+	}
+	id, prefix, ok := configRepo.GetConfigRepository(db).StoreForURL(r.Host, r.URL.Path)
+	if !ok {
+		return
+	}
+	r.Header.Set("Store", strconv.FormatUint(uint64(id), 10))
+	if prefix != "" {
+		r.URL.Path = r.URL.Path[len(prefix):]
+		if r.URL.Path == "" {
+			r.URL.Path = "/"
+		}
+		r.URL.RawPath = ""
+	}
+}
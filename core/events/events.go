@@ -0,0 +1,165 @@
+package events
+
+import (
+	"sort"
+	"sync"
+)
+
+// Topics of catalog changes, published with the SKUs that changed.
+const (
+	TopicStock   = "stock"
+	TopicPrice   = "price"
+	TopicProduct = "product" // catalog data (attributes, categories, status) or deletion
+	// TopicCategory is published with the IDs (decimal) of categories whose data, position in the
+	// tree or deletion changed.
+	TopicCategory = "category"
+)
+
+// Hub is an in-process publish/subscribe of changed SKUs. Publishers (importers, the change poller)
+// only name what changed; subscribers reload the current values. Publish never blocks: SKUs
+// published while a subscriber is busy are merged into its pending set.
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[string]map[*Subscription]struct{} // topic -> subscriptions
+}
+
+var (
+	once     sync.Once
+	instance *Hub
+)
+
+// GetInstance returns the process-wide hub.
+func GetInstance() *Hub {
+	once.Do(func() {
+		instance = NewHub()
+	})
+	return instance
+}
+
+// NewHub creates an empty hub.
+func NewHub() *Hub {
+	return &Hub{subs: make(map[string]map[*Subscription]struct{})}
+}
+
+// Publish notifies the subscribers of topic that watch any of skus.
+func Publish(topic string, skus ...string) {
+	GetInstance().Publish(topic, skus...)
+}
+
+// Publish notifies the subscribers of topic that watch any of skus.
+func (h *Hub) Publish(topic string, skus ...string) {
+	if len(skus) == 0 {
+		return
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subs[topic] {
+		s.add(skus)
+	}
+}
+
+// Subscribe watches skus on topic until the subscription is closed.
+func (h *Hub) Subscribe(topic string, skus []string) *Subscription {
+	return h.subscribe(topic, skus, false)
+}
+
+// SubscribeAll watches every SKU published on topic until the subscription is closed. It adds
+// nothing to Watched.
+func (h *Hub) SubscribeAll(topic string) *Subscription {
+	return h.subscribe(topic, nil, true)
+}
+
+func (h *Hub) subscribe(topic string, skus []string, all bool) *Subscription {
+	s := &Subscription{
+		hub:     h,
+		topic:   topic,
+		all:     all,
+		skus:    make(map[string]struct{}, len(skus)),
+		pending: make(map[string]struct{}),
+		notify:  make(chan struct{}, 1),
+	}
+	for _, sku := range skus {
+		s.skus[sku] = struct{}{}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[topic] == nil {
+		h.subs[topic] = make(map[*Subscription]struct{})
+	}
+	h.subs[topic][s] = struct{}{}
+	return s
+}
+
+// Watched returns the SKUs subscribed to on topic, sorted.
+func (h *Hub) Watched(topic string) []string {
+	h.mu.RLock()
+	set := make(map[string]struct{})
+	for s := range h.subs[topic] {
+		for sku := range s.skus {
+			set[sku] = struct{}{}
+		}
+	}
+	h.mu.RUnlock()
+	return sortedKeys(set)
+}
+
+// Subscription receives the changed SKUs of one subscriber.
+type Subscription struct {
+	hub   *Hub
+	topic string
+	skus  map[string]struct{} // read-only after Subscribe
+	all   bool                // SubscribeAll; read-only
+
+	mu      sync.Mutex
+	pending map[string]struct{}
+	notify  chan struct{}
+}
+
+// C is signalled when changed SKUs are pending; read them with Take.
+func (s *Subscription) C() <-chan struct{} {
+	return s.notify
+}
+
+// Take returns the pending changed SKUs, sorted, and clears them.
+func (s *Subscription) Take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	skus := sortedKeys(s.pending)
+	s.pending = make(map[string]struct{})
+	return skus
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	delete(s.hub.subs[s.topic], s)
+}
+
+func (s *Subscription) add(skus []string) {
+	s.mu.Lock()
+	added := false
+	for _, sku := range skus {
+		if _, ok := s.skus[sku]; ok || s.all {
+			s.pending[sku] = struct{}{}
+			added = true
+		}
+	}
+	s.mu.Unlock()
+	if !added {
+		return
+	}
+	select {
+	case s.notify <- struct{}{}:
+	default: // already signalled
+	}
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
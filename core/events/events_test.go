@@ -0,0 +1,62 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPublish_MergesPendingSKUsOfSubscription(t *testing.T) {
+	h := NewHub()
+	s := h.Subscribe(TopicStock, []string{"A", "B"})
+	defer s.Close()
+
+	h.Publish(TopicStock, "B", "X")
+	h.Publish(TopicPrice, "A") // other topic
+	h.Publish(TopicStock, "A", "B")
+
+	select {
+	case <-s.C():
+	default:
+		t.Fatal("subscription not signalled")
+	}
+	if got := s.Take(); !reflect.DeepEqual(got, []string{"A", "B"}) {
+		t.Errorf("Take = %v, want [A B]", got)
+	}
+	if got := s.Take(); len(got) != 0 {
+		t.Errorf("second Take = %v, want none", got)
+	}
+}
+
+func TestSubscription_Close(t *testing.T) {
+	h := NewHub()
+	s := h.Subscribe(TopicPrice, []string{"A"})
+	other := h.Subscribe(TopicPrice, []string{"B", "A"})
+	defer other.Close()
+	if got := h.Watched(TopicPrice); !reflect.DeepEqual(got, []string{"A", "B"}) {
+		t.Errorf("Watched = %v, want [A B]", got)
+	}
+
+	s.Close()
+	h.Publish(TopicPrice, "A")
+	if got := s.Take(); len(got) != 0 {
+		t.Errorf("closed subscription got %v", got)
+	}
+	if got := h.Watched(TopicPrice); !reflect.DeepEqual(got, []string{"A", "B"}) {
+		t.Errorf("Watched after Close = %v, want [A B]", got)
+	}
+}
+
+func TestSubscribeAll(t *testing.T) {
+	h := NewHub()
+	s := h.SubscribeAll(TopicProduct)
+	defer s.Close()
+	if got := h.Watched(TopicProduct); len(got) != 0 {
+		t.Errorf("Watched = %v, want none", got)
+	}
+
+	h.Publish(TopicProduct, "B", "A")
+	h.Publish(TopicStock, "C")
+	if got := s.Take(); !reflect.DeepEqual(got, []string{"A", "B"}) {
+		t.Errorf("Take = %v, want [A B]", got)
+	}
+}
@@ -0,0 +1,340 @@
+// Package search is an in-process full-text index for catalogs without Elasticsearch.
+//
+// Documents are split into lower-case word tokens (HTML tags are skipped), plural endings are
+// stemmed, and each query token matches its stemmed term or, from MinPrefixLen characters on,
+// any term it prefixes. Every query token must match; documents are ranked by BM25 over the
+// field-weighted term frequencies.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// BM25 parameters (Lucene defaults).
+	k1 = 1.2
+	b  = 0.75
+
+	// MinPrefixLen is the shortest query token expanded to the terms it prefixes.
+	MinPrefixLen = 2
+	// maxExpansions caps the terms one prefix expands to (as Elasticsearch max_expansions).
+	maxExpansions = 50
+	// prefixBoost scales the score of a prefix match against an exact match.
+	prefixBoost = 0.5
+)
+
+// Field is a text of a document with its search weight (e.g. name 3, description 1).
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Document is what is indexed for one entity.
+type Document struct {
+	ID          uint
+	Fields      []Field
+	CategoryIDs []uint
+}
+
+// Query is a search request; CategoryID 0 searches all documents and Limit 0 returns all hits.
+type Query struct {
+	Text       string
+	CategoryID uint
+	Offset     int
+	Limit      int
+}
+
+// Hit is a matching document and its score.
+type Hit struct {
+	ID    uint
+	Score float64
+}
+
+type document struct {
+	terms      map[string]float64 // term -> weighted frequency
+	length     float64
+	categories []uint
+}
+
+// Index is safe for concurrent use. Put and Remove update it in place.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[uint]*document
+	postings map[string]map[uint]float64 // term -> document -> weighted frequency
+	totalLen float64
+	terms    []string // sorted keys of postings; nil after a change
+}
+
+// NewIndex creates an empty index.
+func NewIndex() *Index {
+	return &Index{docs: make(map[uint]*document), postings: make(map[string]map[uint]float64)}
+}
+
+// Len returns the number of indexed documents.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Put indexes doc, replacing a document with the same ID.
+func (ix *Index) Put(doc Document) {
+	d := &document{terms: make(map[string]float64), categories: doc.CategoryIDs}
+	for _, f := range doc.Fields {
+		if f.Weight <= 0 {
+			continue
+		}
+		for _, tok := range Tokenize(f.Text) {
+			d.terms[Stem(tok)] += f.Weight
+			d.length += f.Weight
+		}
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(doc.ID)
+	ix.docs[doc.ID] = d
+	ix.totalLen += d.length
+	for term, tf := range d.terms {
+		if ix.postings[term] == nil {
+			ix.postings[term] = make(map[uint]float64)
+			ix.terms = nil
+		}
+		ix.postings[term][doc.ID] = tf
+	}
+}
+
+// Remove drops the document with id, if indexed.
+func (ix *Index) Remove(id uint) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+func (ix *Index) remove(id uint) {
+	d, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for term := range d.terms {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+			ix.terms = nil
+		}
+	}
+	ix.totalLen -= d.length
+	delete(ix.docs, id)
+}
+
+// Search returns the page of hits for q, best first (ties by ID), and the total number of hits.
+func (ix *Index) Search(q Query) ([]Hit, int) {
+	tokens := queryTokens(q.Text)
+	if len(tokens) == 0 {
+		return nil, 0
+	}
+	ix.sortTerms()
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	n := float64(len(ix.docs))
+	if n == 0 {
+		return nil, 0
+	}
+	avgLen := ix.totalLen / n
+
+	var scores map[uint]float64
+	for _, tok := range tokens {
+		// A document matching several expansions of a token scores the best one.
+		best := make(map[uint]float64)
+		for _, m := range ix.expand(tok) {
+			idf := math.Log(1 + (n-float64(len(m.docs))+0.5)/(float64(len(m.docs))+0.5))
+			for id, tf := range m.docs {
+				d := ix.docs[id]
+				if q.CategoryID != 0 && !containsID(d.categories, q.CategoryID) {
+					continue
+				}
+				s := m.boost * idf * tf * (k1 + 1) / (tf + k1*(1-b+b*d.length/avgLen))
+				if s > best[id] {
+					best[id] = s
+				}
+			}
+		}
+		if scores == nil {
+			scores = best
+			continue
+		}
+		for id := range scores {
+			if s, ok := best[id]; ok {
+				scores[id] += s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, s := range scores {
+		hits = append(hits, Hit{ID: id, Score: s})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	total := len(hits)
+	if q.Offset > 0 {
+		if q.Offset >= len(hits) {
+			return []Hit{}, total
+		}
+		hits = hits[q.Offset:]
+	}
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, total
+}
+
+type expansion struct {
+	docs  map[uint]float64
+	boost float64
+}
+
+// expand returns the postings a query token matches: its stem, then up to maxExpansions terms
+// it prefixes. Requires ix.terms to be sorted.
+func (ix *Index) expand(tok string) []expansion {
+	stem := Stem(tok)
+	var out []expansion
+	if docs, ok := ix.postings[stem]; ok {
+		out = append(out, expansion{docs: docs, boost: 1})
+	}
+	if len([]rune(tok)) < MinPrefixLen {
+		return out
+	}
+	i := sort.SearchStrings(ix.terms, tok)
+	for n := 0; i < len(ix.terms) && n < maxExpansions && strings.HasPrefix(ix.terms[i], tok); i++ {
+		if ix.terms[i] == stem {
+			continue
+		}
+		out = append(out, expansion{docs: ix.postings[ix.terms[i]], boost: prefixBoost})
+		n++
+	}
+	return out
+}
+
+// sortTerms rebuilds the sorted term list used for prefix matching after a change.
+func (ix *Index) sortTerms() {
+	ix.mu.RLock()
+	sorted := ix.terms != nil
+	ix.mu.RUnlock()
+	if sorted {
+		return
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.terms != nil {
+		return
+	}
+	terms := make([]string, 0, len(ix.postings))
+	for term := range ix.postings {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	ix.terms = terms
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// stopWords are not indexed or searched; "s" is the rest of a possessive (men's).
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "at": {}, "by": {}, "for": {}, "in": {}, "of": {}, "on": {},
+	"or": {}, "s": {}, "the": {}, "to": {}, "with": {},
+}
+
+// Tokenize splits text into lower-case words of letters and digits, skipping HTML tags and
+// stop words.
+func Tokenize(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		w := word.String()
+		word.Reset()
+		if _, stop := stopWords[w]; !stop {
+			tokens = append(tokens, w)
+		}
+	}
+	inTag := false
+	for _, r := range text {
+		switch {
+		case inTag:
+			inTag = r != '>'
+		case r == '<':
+			flush()
+			inTag = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// queryTokens tokenizes a query, dropping repeated tokens.
+func queryTokens(text string) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	for _, tok := range Tokenize(text) {
+		if _, ok := seen[tok]; !ok {
+			seen[tok] = struct{}{}
+			out = append(out, tok)
+		}
+	}
+	return out
+}
+
+// Stem removes English plural endings and folds a final y or ie into i, so that plural and
+// singular meet: bags, bag -> bag; dresses -> dress; berries, berry -> berri; hoodies, hoodie,
+// hoody -> hoodi. Words with digits and words of up to three letters are unchanged.
+func Stem(w string) string {
+	if len(w) <= 3 || strings.IndexFunc(w, unicode.IsDigit) >= 0 {
+		return w
+	}
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "i"
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "xes"), strings.HasSuffix(w, "ches"),
+		strings.HasSuffix(w, "shes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+		return w
+	case strings.HasSuffix(w, "s"):
+		w = w[:len(w)-1]
+	}
+	if len(w) <= 3 {
+		return w
+	}
+	switch {
+	case strings.HasSuffix(w, "ie"):
+		return w[:len(w)-1]
+	case strings.HasSuffix(w, "y") && !strings.ContainsRune("aeiouy", rune(w[len(w)-2])):
+		return w[:len(w)-1] + "i"
+	}
+	return w
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeAndStem(t *testing.T) {
+	got := Tokenize("<p>The Men's <b>Running</b> Shoes, 24-MB01</p>")
+	if want := []string{"men", "running", "shoes", "24", "mb01"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize = %v, want %v", got, want)
+	}
+	for w, want := range map[string]string{
+		"bags": "bag", "dresses": "dress", "boxes": "box", "watches": "watch", "shoes": "shoe",
+		"berries": "berri", "berry": "berri", "hoodies": "hoodi", "hoodie": "hoodi", "hoody": "hoodi",
+		"toys": "toy", "dress": "dress", "ties": "tie", "mb01s": "mb01s", "bus": "bus",
+	} {
+		if got := Stem(w); got != want {
+			t.Errorf("Stem(%q) = %q, want %q", w, got, want)
+		}
+	}
+}
+
+func testIndex() *Index {
+	ix := NewIndex()
+	ix.Put(Document{ID: 1, CategoryIDs: []uint{3}, Fields: []Field{
+		{Text: "Joust Duffle Bag", Weight: 3}, {Text: "24-MB01", Weight: 2}, {Text: "<p>A sporty bag for the gym.</p>", Weight: 1},
+	}})
+	ix.Put(Document{ID: 2, CategoryIDs: []uint{3, 4}, Fields: []Field{
+		{Text: "Strive Shoulder Pack", Weight: 3}, {Text: "24-MB04", Weight: 2}, {Text: "Carry all your bags in this pack.", Weight: 1},
+	}})
+	ix.Put(Document{ID: 3, CategoryIDs: []uint{5}, Fields: []Field{
+		{Text: "Hero Hoodie", Weight: 3}, {Text: "MH07", Weight: 2}, {Text: "Warm hoodie", Weight: 1},
+	}})
+	return ix
+}
+
+func hitIDs(hits []Hit) []uint {
+	ids := make([]uint, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	return ids
+}
+
+func TestIndex_Search(t *testing.T) {
+	ix := testIndex()
+	cases := []struct {
+		q     Query
+		want  []uint
+		total int
+	}{
+		{Query{Text: "bags"}, []uint{1, 2}, 2},                  // name match ranks above description
+		{Query{Text: "bag gym"}, []uint{1}, 1},                  // every token must match
+		{Query{Text: "hood"}, []uint{3}, 1},                     // prefix
+		{Query{Text: "hoodies"}, []uint{3}, 1},                  // stemmed
+		{Query{Text: "24-mb04"}, []uint{2}, 1},                  // SKU
+		{Query{Text: "bag", CategoryID: 4}, []uint{2}, 1},       // category filter
+		{Query{Text: "bag", Offset: 1, Limit: 1}, []uint{2}, 2}, // paging
+		{Query{Text: "bag", Offset: 5}, []uint{}, 2},
+		{Query{Text: "the"}, []uint{}, 0},
+		{Query{Text: "x"}, []uint{}, 0},
+	}
+	for _, c := range cases {
+		hits, total := ix.Search(c.q)
+		if got := hitIDs(hits); !reflect.DeepEqual(got, c.want) || total != c.total {
+			t.Errorf("Search(%+v) = %v (%d), want %v (%d)", c.q, got, total, c.want, c.total)
+		}
+	}
+}
+
+func TestIndex_PutReplacesAndRemove(t *testing.T) {
+	ix := testIndex()
+	if hits, _ := ix.Search(Query{Text: "hoo"}); len(hits) != 1 {
+		t.Fatalf("hoo = %v, want one hit", hits)
+	}
+	ix.Put(Document{ID: 3, Fields: []Field{{Text: "Hero Jacket", Weight: 3}}})
+	if hits, _ := ix.Search(Query{Text: "hoo"}); len(hits) != 0 {
+		t.Errorf("hoo after update = %v, want none", hits)
+	}
+	if hits, _ := ix.Search(Query{Text: "jack"}); !reflect.DeepEqual(hitIDs(hits), []uint{3}) {
+		t.Errorf("jack = %v, want [3]", hits)
+	}
+	ix.Remove(3)
+	ix.Remove(9)
+	if hits, _ := ix.Search(Query{Text: "hero"}); len(hits) != 0 || ix.Len() != 2 {
+		t.Errorf("hero after remove = %v, Len = %d", hits, ix.Len())
+	}
+}
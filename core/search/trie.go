@@ -0,0 +1,235 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// MaxCompletions is the most completions Complete returns.
+	MaxCompletions = 20
+	// maxKeyLen bounds the indexed length of a phrase suffix; longer queries are cut to it.
+	maxKeyLen = 32
+)
+
+// Entry is a phrase offered as a completion. Key identifies it to the caller (e.g. an entity ID);
+// a higher Weight ranks it first.
+type Entry struct {
+	Text   string
+	Key    uint
+	Weight float64
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	entries  []int // entries whose key ends here
+	top      []int // best MaxCompletions entries of the subtree
+}
+
+// Trie completes typed prefixes to phrases. A phrase matches from the start of each of its words
+// ("duff" and "joust duff" both complete to "Joust Duffle Bag"), ignoring case and punctuation.
+// Trie is safe for concurrent use. Put and Remove update it in place.
+type Trie struct {
+	mu      sync.RWMutex
+	root    *trieNode
+	entries []Entry
+	keys    map[uint][]int // Entry.Key -> entries
+	free    []int          // entries of removed keys, reused by Put
+}
+
+// NewTrie indexes entries; empty phrases are skipped.
+func NewTrie(entries []Entry) *Trie {
+	t := &Trie{root: &trieNode{}, keys: make(map[uint][]int)}
+	for _, e := range entries {
+		t.add(e, nil)
+	}
+	t.rank(t.root)
+	return t
+}
+
+// Put replaces the entries with key by entries, which take that key.
+func (t *Trie) Put(key uint, entries []Entry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	touched := make(map[*trieNode]int)
+	t.remove(key, touched)
+	for _, e := range entries {
+		e.Key = key
+		t.add(e, touched)
+	}
+	t.rerank(touched)
+}
+
+// Remove drops the entries with key, if any.
+func (t *Trie) Remove(key uint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	touched := make(map[*trieNode]int)
+	t.remove(key, touched)
+	t.rerank(touched)
+}
+
+// add indexes e under each of its phrase suffixes, recording the nodes on the way in touched.
+func (t *Trie) add(e Entry, touched map[*trieNode]int) {
+	i := len(t.entries)
+	if n := len(t.free); n > 0 {
+		i, t.free = t.free[n-1], t.free[:n-1]
+		t.entries[i] = e
+	} else {
+		t.entries = append(t.entries, e)
+	}
+	t.keys[e.Key] = append(t.keys[e.Key], i)
+	for _, suffix := range phraseSuffixes(e.Text) {
+		n := t.node(suffix, touched)
+		n.entries = append(n.entries, i)
+	}
+}
+
+// remove unlinks the entries with key, recording the nodes on the way in touched.
+func (t *Trie) remove(key uint, touched map[*trieNode]int) {
+	for _, i := range t.keys[key] {
+		for _, suffix := range phraseSuffixes(t.entries[i].Text) {
+			n := t.node(suffix, touched)
+			kept := n.entries[:0]
+			for _, id := range n.entries {
+				if id != i {
+					kept = append(kept, id)
+				}
+			}
+			n.entries = kept
+		}
+		t.entries[i] = Entry{}
+		t.free = append(t.free, i)
+	}
+	delete(t.keys, key)
+}
+
+// node returns the node of key (cut to maxKeyLen), creating it if needed. With touched, the nodes
+// from the root to it are recorded with their depth.
+func (t *Trie) node(key string, touched map[*trieNode]int) *trieNode {
+	n := t.root
+	if touched != nil {
+		touched[n] = 0
+	}
+	for i, r := range []rune(key) {
+		if i == maxKeyLen {
+			break
+		}
+		if n.children == nil {
+			n.children = make(map[rune]*trieNode)
+		}
+		child, ok := n.children[r]
+		if !ok {
+			child = &trieNode{}
+			n.children[r] = child
+		}
+		n = child
+		if touched != nil {
+			touched[n] = i + 1
+		}
+	}
+	return n
+}
+
+// phraseSuffixes returns the keys a phrase is indexed under: its normalized text from each word on.
+func phraseSuffixes(text string) []string {
+	words := strings.Fields(normalizePhrase(text))
+	suffixes := make([]string, len(words))
+	for w := range words {
+		suffixes[w] = strings.Join(words[w:], " ")
+	}
+	return suffixes
+}
+
+// rank fills top of n and its subtree.
+func (t *Trie) rank(n *trieNode) {
+	for _, child := range n.children {
+		t.rank(child)
+	}
+	t.rankNode(n)
+}
+
+// rerank fills top of the touched nodes again, deepest first so each reads updated children.
+func (t *Trie) rerank(touched map[*trieNode]int) {
+	nodes := make([]*trieNode, 0, len(touched))
+	for n := range touched {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return touched[nodes[i]] > touched[nodes[j]] })
+	for _, n := range nodes {
+		t.rankNode(n)
+	}
+}
+
+// rankNode fills top of n from its entries and the top of its children: weight first, then the
+// shorter and alphabetically first text.
+func (t *Trie) rankNode(n *trieNode) {
+	seen := make(map[int]struct{})
+	var candidates []int
+	add := func(ids []int) {
+		for _, id := range ids {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				candidates = append(candidates, id)
+			}
+		}
+	}
+	add(n.entries)
+	for _, child := range n.children {
+		add(child.top)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := t.entries[candidates[i]], t.entries[candidates[j]]
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		if len(a.Text) != len(b.Text) {
+			return len(a.Text) < len(b.Text)
+		}
+		return a.Text < b.Text
+	})
+	if len(candidates) > MaxCompletions {
+		candidates = candidates[:MaxCompletions]
+	}
+	n.top = candidates
+}
+
+// Complete returns up to limit (at most MaxCompletions) best entries matching prefix.
+func (t *Trie) Complete(prefix string, limit int) []Entry {
+	key := []rune(strings.Join(strings.Fields(normalizePhrase(prefix)), " "))
+	if len(key) == 0 || limit <= 0 {
+		return nil
+	}
+	if len(key) > maxKeyLen {
+		key = key[:maxKeyLen]
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	n := t.root
+	for _, r := range key {
+		if n = n.children[r]; n == nil {
+			return nil
+		}
+	}
+	top := n.top
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	out := make([]Entry, len(top))
+	for i, id := range top {
+		out[i] = t.entries[id]
+	}
+	return out
+}
+
+// normalizePhrase lower-cases text and replaces everything but letters and digits with spaces.
+func normalizePhrase(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, text)
+}
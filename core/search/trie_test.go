@@ -0,0 +1,74 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func completionTexts(entries []Entry) []string {
+	texts := make([]string, len(entries))
+	for i, e := range entries {
+		texts[i] = e.Text
+	}
+	return texts
+}
+
+func TestTrie_Complete(t *testing.T) {
+	trie := NewTrie([]Entry{
+		{Text: "Joust Duffle Bag", Key: 1, Weight: 2},
+		{Text: "24-MB01", Key: 1, Weight: 1},
+		{Text: "Duffle Coat", Key: 2, Weight: 2},
+		{Text: "Bag Bag", Key: 3, Weight: 1},
+		{Text: "", Key: 4, Weight: 5},
+	})
+	cases := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{"duff", 5, []string{"Duffle Coat", "Joust Duffle Bag"}}, // same weight: shorter first
+		{"JOUST duffle b", 5, []string{"Joust Duffle Bag"}},
+		{"24-mb", 5, []string{"24-MB01"}},
+		{"mb01", 5, []string{"24-MB01"}},
+		{"bag", 5, []string{"Joust Duffle Bag", "Bag Bag"}}, // weight first, no duplicates
+		{"bag", 1, []string{"Joust Duffle Bag"}},
+		{"coat x", 5, []string{}},
+		{"  ", 5, []string{}},
+	}
+	for _, c := range cases {
+		if got := completionTexts(trie.Complete(c.prefix, c.limit)); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Complete(%q, %d) = %v, want %v", c.prefix, c.limit, got, c.want)
+		}
+	}
+}
+
+func TestTrie_PutRemove(t *testing.T) {
+	trie := NewTrie([]Entry{
+		{Text: "Joust Duffle Bag", Key: 1, Weight: 2},
+		{Text: "24-MB01", Key: 1, Weight: 1},
+		{Text: "Duffle Coat", Key: 2, Weight: 2},
+	})
+	trie.Put(1, []Entry{{Text: "Duffel Shoulder Pack", Weight: 2}, {Text: "24-MB01", Weight: 1}})
+	trie.Put(3, []Entry{{Text: "Duffle Coat Deluxe", Weight: 3}})
+	trie.Remove(2)
+	trie.Remove(4) // not indexed
+	cases := []struct {
+		prefix string
+		want   []string
+	}{
+		{"duff", []string{"Duffle Coat Deluxe", "Duffel Shoulder Pack"}},
+		{"joust", []string{}},
+		{"coat", []string{"Duffle Coat Deluxe"}},
+		{"mb01", []string{"24-MB01"}},
+	}
+	for _, c := range cases {
+		if got := completionTexts(trie.Complete(c.prefix, 5)); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Complete(%q) = %v, want %v", c.prefix, got, c.want)
+		}
+	}
+	for _, e := range trie.Complete("duff", 5) {
+		if e.Text == "Duffel Shoulder Pack" && e.Key != 1 {
+			t.Errorf("Put entry key = %d, want 1", e.Key)
+		}
+	}
+}
@@ -19,6 +19,16 @@ func init() {
 		return map[string]string{"pong": "ok"}, nil
 	})
 
+	// Typed GraphQL extension: clients get a real type, introspection and field selection
+	gqlregistry.RegisterSchema(gqlregistry.SchemaExtension{
+		Name:  "customPing",
+		Types: "type CustomPong {\n  pong: String!\n}",
+		Query: "customPing: CustomPong!",
+		Resolver: func(db interface{}) interface{} {
+			return &pingResolver{}
+		},
+	})
+
 	// CLI command
 	cmd.Register(&cobra.Command{
 		Use:   "custom:hello",
@@ -43,3 +53,13 @@ func init() {
 		return c.JSON(200, map[string]string{"pong": "ok"})
 	})
 }
+
+type pingResolver struct{}
+
+type customPong struct {
+	Pong string
+}
+
+func (r *pingResolver) CustomPing() *customPong {
+	return &customPong{Pong: "ok"}
+}
@@ -0,0 +1,76 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"magento.GO/core/cache"
+)
+
+// Cache tags name what a GraphQL response was built from, as Magento's X-Magento-Tags: cat_p_<id>
+// for a product and cat_c_<id> for a category. Product listings also get ProductListTag and the
+// full category list or tree gets CategoryListTag; purge those when products or categories are
+// added or removed.
+const (
+	ProductListTag  = "cat_p"
+	CategoryListTag = "cat_c"
+)
+
+const CtxKeyCacheTags contextKey = "cacheTags"
+
+// ProductTag returns the cache tag of a product.
+func ProductTag(id uint) string {
+	return ProductListTag + "_" + strconv.FormatUint(uint64(id), 10)
+}
+
+// CategoryTag returns the cache tag of a category.
+func CategoryTag(id uint) string {
+	return CategoryListTag + "_" + strconv.FormatUint(uint64(id), 10)
+}
+
+// CacheTags collects the cache tags of one response. Safe for concurrent use by resolvers.
+type CacheTags struct {
+	mu   sync.Mutex
+	tags map[string]struct{}
+}
+
+// WithCacheTags attaches an empty tag collector to ctx.
+func WithCacheTags(ctx context.Context) (context.Context, *CacheTags) {
+	t := &CacheTags{tags: make(map[string]struct{})}
+	return context.WithValue(ctx, CtxKeyCacheTags, t), t
+}
+
+// AddCacheTags records tags for the response being built; without a collector in ctx it does nothing.
+func AddCacheTags(ctx context.Context, tags ...string) {
+	t, ok := ctx.Value(CtxKeyCacheTags).(*CacheTags)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tag := range tags {
+		t.tags[tag] = struct{}{}
+	}
+}
+
+// List returns the collected tags, sorted.
+func (t *CacheTags) List() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	list := make([]string, 0, len(t.tags))
+	for tag := range t.tags {
+		list = append(list, tag)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// InvalidateCacheTags drops the cached GraphQL responses tagged with any of tags.
+func InvalidateCacheTags(tags ...string) {
+	c := cache.GetInstance()
+	for _, tag := range tags {
+		c.DeleteByTag(tag)
+	}
+}
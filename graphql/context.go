@@ -10,7 +10,16 @@ import (
 // Context keys for resolver injection (avoids circular imports).
 type contextKey string
 
-const CtxKeyStoreID contextKey = "storeID"
+const (
+	CtxKeyStoreID         contextKey = "storeID"
+	CtxKeyCustomerGroupID contextKey = "customerGroupID"
+	CtxKeyCustomerID      contextKey = "customerID"
+	CtxKeyCurrency        contextKey = "currency"
+	CtxKeyRemoteIP        contextKey = "remoteIP"
+)
+
+// NotLoggedInGroupID is Magento's customer_group_id for guests.
+const NotLoggedInGroupID uint = 0
 
 // StoreIDFromContext returns the store ID for the current request.
 func StoreIDFromContext(ctx context.Context) uint16 {
@@ -27,6 +36,58 @@ func WithStoreID(ctx context.Context, storeID uint16) context.Context {
 	return context.WithValue(ctx, CtxKeyStoreID, storeID)
 }
 
+// CustomerGroupIDFromContext returns the customer group prices are resolved for; guests get NOT LOGGED IN (0).
+func CustomerGroupIDFromContext(ctx context.Context) uint {
+	if v := ctx.Value(CtxKeyCustomerGroupID); v != nil {
+		if id, ok := v.(uint); ok {
+			return id
+		}
+	}
+	return NotLoggedInGroupID
+}
+
+// WithCustomerGroupID attaches the customer group to context.
+func WithCustomerGroupID(ctx context.Context, groupID uint) context.Context {
+	return context.WithValue(ctx, CtxKeyCustomerGroupID, groupID)
+}
+
+// CustomerIDFromContext returns the authenticated customer of the request; ok is false for guests.
+func CustomerIDFromContext(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(CtxKeyCustomerID).(uint)
+	return id, ok && id != 0
+}
+
+// WithCustomerID attaches the authenticated customer to context.
+func WithCustomerID(ctx context.Context, customerID uint) context.Context {
+	return context.WithValue(ctx, CtxKeyCustomerID, customerID)
+}
+
+// CurrencyFromContext returns the display currency requested by the client (Content-Currency), or "".
+func CurrencyFromContext(ctx context.Context) string {
+	if v, ok := ctx.Value(CtxKeyCurrency).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// WithCurrency attaches the requested display currency to context.
+func WithCurrency(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, CtxKeyCurrency, code)
+}
+
+// RemoteIPFromContext returns the client IP of the request, or "".
+func RemoteIPFromContext(ctx context.Context) string {
+	if v, ok := ctx.Value(CtxKeyRemoteIP).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// WithRemoteIP attaches the client IP to context.
+func WithRemoteIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, CtxKeyRemoteIP, ip)
+}
+
 // StoreContext holds store_id for the current request.
 // Resolved from: Store header > __Store query param > JSON variables.__Store
 const (
@@ -35,20 +96,30 @@ const (
 	VarStore        = "__Store"
 )
 
+// StoreParser resolves a client's store value, a store_id or a store code, to a store_id.
+// StoreRepository.ParseStore resolves codes; ParseStoreID accepts store IDs only.
+type StoreParser func(v string) (uint16, bool)
+
+// ParseStoreID parses a numeric store_id.
+func ParseStoreID(v string) (uint16, bool) {
+	id, err := strconv.ParseUint(v, 10, 16)
+	return uint16(id), err == nil
+}
+
 // GetStoreID extracts store_id from request.
 // Priority: 1) Store header, 2) __Store query param, 3) JSON body variables.__Store
-func GetStoreID(r *http.Request) uint16 {
+func GetStoreID(r *http.Request, parse StoreParser) uint16 {
 	// 1. Header
 	if h := r.Header.Get(HeaderStore); h != "" {
-		if id, err := strconv.ParseUint(h, 10, 16); err == nil {
-			return uint16(id)
+		if id, ok := parse(h); ok {
+			return id
 		}
 	}
 
 	// 2. Query param
 	if q := r.URL.Query().Get(QueryParamStore); q != "" {
-		if id, err := strconv.ParseUint(q, 10, 16); err == nil {
-			return uint16(id)
+		if id, ok := parse(q); ok {
+			return id
 		}
 	}
 
@@ -58,7 +129,7 @@ func GetStoreID(r *http.Request) uint16 {
 }
 
 // ParseStoreFromVariables parses variables from JSON body for Store
-func ParseStoreFromVariables(body []byte) (uint16, bool) {
+func ParseStoreFromVariables(body []byte, parse StoreParser) (uint16, bool) {
 	var payload struct {
 		Variables map[string]interface{} `json:"variables"`
 	}
@@ -68,9 +139,7 @@ func ParseStoreFromVariables(body []byte) (uint16, bool) {
 	if v, ok := payload.Variables[VarStore]; ok {
 		switch val := v.(type) {
 		case string:
-			if id, err := strconv.ParseUint(val, 10, 16); err == nil {
-				return uint16(id), true
-			}
+			return parse(val)
 		case float64:
 			return uint16(val), true
 		}
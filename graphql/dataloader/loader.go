@@ -0,0 +1,167 @@
+// Package dataloader batches and deduplicates the lookups GraphQL resolvers make during one request.
+// Keys requested within a short window are fetched together, and LoadMany fetches a whole key set
+// at once, so resolving a field for every item of a list costs one query instead of one per item,
+// and a key is fetched at most once per request.
+package dataloader
+
+import (
+	"sync"
+	"time"
+)
+
+// Default batching settings used by NewLoaders.
+const (
+	DefaultWait     = time.Millisecond
+	DefaultMaxBatch = 500
+)
+
+// BatchFunc fetches the values of keys in one go. Keys without a value are left out of the map.
+type BatchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// Loader collects the keys requested by Load within wait of the first one (or until maxBatch keys)
+// and fetches them with one BatchFunc call; LoadMany fetches its keys without waiting. Results are cached for the loader's lifetime; failed keys
+// are fetched again on the next request. Safe for concurrent use.
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	cache map[K]*result[V]
+	batch *batch[K, V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results []*result[V]
+}
+
+// NewLoader returns a Loader fetching with fetch. maxBatch <= 0 means no size limit.
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, wait: wait, maxBatch: maxBatch, cache: make(map[K]*result[V])}
+}
+
+// Load returns the value of key; found is false when the batch function returned none.
+func (l *Loader[K, V]) Load(key K) (value V, found bool, err error) {
+	r := l.enqueue([]K{key})[key]
+	<-r.done
+	return r.value, r.found, r.err
+}
+
+// LoadMany returns the values of keys that have one. The caller has the whole key set, so the
+// uncached keys are fetched right away (in batches of maxBatch) instead of waiting for other keys.
+func (l *Loader[K, V]) LoadMany(keys []K) (map[K]V, error) {
+	results, batches := l.claim(keys)
+	for _, b := range batches {
+		l.run(b)
+	}
+	values := make(map[K]V, len(results))
+	var firstErr error
+	for key, r := range results {
+		<-r.done
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		if r.found {
+			values[key] = r.value
+		}
+	}
+	return values, firstErr
+}
+
+// enqueue returns the pending or cached result of each key, adding uncached keys to the open batch.
+func (l *Loader[K, V]) enqueue(keys []K) map[K]*result[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	results := make(map[K]*result[V], len(keys))
+	for _, key := range keys {
+		if r, ok := l.cache[key]; ok {
+			results[key] = r
+			continue
+		}
+		r := &result[V]{done: make(chan struct{})}
+		l.cache[key] = r
+		results[key] = r
+		if l.batch == nil {
+			b := &batch[K, V]{}
+			l.batch = b
+			time.AfterFunc(l.wait, func() { l.dispatch(b) })
+		}
+		l.batch.keys = append(l.batch.keys, key)
+		l.batch.results = append(l.batch.results, r)
+		if l.maxBatch > 0 && len(l.batch.keys) >= l.maxBatch {
+			b := l.batch
+			l.batch = nil
+			go l.run(b)
+		}
+	}
+	return results
+}
+
+// claim returns the pending or cached result of each key and batches of the uncached keys, which
+// the caller runs.
+func (l *Loader[K, V]) claim(keys []K) (map[K]*result[V], []*batch[K, V]) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	results := make(map[K]*result[V], len(keys))
+	var batches []*batch[K, V]
+	var b *batch[K, V]
+	for _, key := range keys {
+		if r, ok := l.cache[key]; ok {
+			results[key] = r
+			continue
+		}
+		r := &result[V]{done: make(chan struct{})}
+		l.cache[key] = r
+		results[key] = r
+		if b == nil || (l.maxBatch > 0 && len(b.keys) >= l.maxBatch) {
+			b = &batch[K, V]{}
+			batches = append(batches, b)
+		}
+		b.keys = append(b.keys, key)
+		b.results = append(b.results, r)
+	}
+	return results, batches
+}
+
+// dispatch runs b when its wait is over, unless it was already run for reaching maxBatch.
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+	l.run(b)
+}
+
+func (l *Loader[K, V]) run(b *batch[K, V]) {
+	values, err := l.fetch(b.keys)
+	if err != nil {
+		l.mu.Lock()
+		for _, key := range b.keys {
+			delete(l.cache, key)
+		}
+		l.mu.Unlock()
+	}
+	for i, key := range b.keys {
+		r := b.results[i]
+		if err != nil {
+			r.err = err
+		} else {
+			r.value, r.found = values[key]
+		}
+		close(r.done)
+	}
+}
@@ -0,0 +1,149 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+
+	productEntity "magento.GO/model/entity/product"
+	categoryRepo "magento.GO/model/repository/category"
+	inventoryRepo "magento.GO/model/repository/inventory"
+	priceRepo "magento.GO/model/repository/price"
+	productRepo "magento.GO/model/repository/product"
+)
+
+// Loaders holds the loaders of one GraphQL request. Create one per request with NewLoaders and
+// attach it with WithLoaders; values are cached until the request ends.
+type Loaders struct {
+	db *gorm.DB
+
+	mu            sync.Mutex
+	products      map[uint16]*Loader[uint, map[string]interface{}]
+	categories    map[uint16]*Loader[uint, categoryRepo.CategoryWithAttributes]
+	prices        map[priceScope]*Loader[string, float64]
+	tierPrices    *Loader[uint, []priceRepo.TierPriceResult]
+	productsBySKU *Loader[string, productEntity.Product]
+	stock         *Loader[string, inventoryRepo.Stock]
+}
+
+// NewLoaders returns empty loaders reading from db.
+func NewLoaders(db *gorm.DB) *Loaders {
+	l := &Loaders{
+		db:         db,
+		products:   make(map[uint16]*Loader[uint, map[string]interface{}]),
+		categories: make(map[uint16]*Loader[uint, categoryRepo.CategoryWithAttributes]),
+		prices:     make(map[priceScope]*Loader[string, float64]),
+	}
+	l.productsBySKU = NewLoader(func(skus []string) (map[string]productEntity.Product, error) {
+		return productRepo.GetProductRepository(db).FindBySKUs(skus)
+	}, DefaultWait, DefaultMaxBatch)
+	l.stock = NewLoader(func(skus []string) (map[string]inventoryRepo.Stock, error) {
+		repo, err := inventoryRepo.GetInventoryRepository(db)
+		if err != nil {
+			return nil, err
+		}
+		return repo.GetStockBySKUs(skus)
+	}, DefaultWait, DefaultMaxBatch)
+	l.tierPrices = NewLoader(func(ids []uint) (map[uint][]priceRepo.TierPriceResult, error) {
+		repo, err := priceRepo.GetPriceRepository(db)
+		if err != nil {
+			return nil, err
+		}
+		return repo.GetTierPricesByProductIDs(ids)
+	}, DefaultWait, DefaultMaxBatch)
+	return l
+}
+
+// Products loads flat products (as FetchWithAllAttributesFlatByIDs) of a store view by entity_id.
+func (l *Loaders) Products(storeID uint16) *Loader[uint, map[string]interface{}] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if loader, ok := l.products[storeID]; ok {
+		return loader
+	}
+	loader := NewLoader(func(ids []uint) (map[uint]map[string]interface{}, error) {
+		return productRepo.GetProductRepository(l.db).FetchWithAllAttributesFlatByIDs(ids, storeID)
+	}, DefaultWait, DefaultMaxBatch)
+	l.products[storeID] = loader
+	return loader
+}
+
+// ProductsBySKU loads catalog_product_entity rows, without attributes, by SKU.
+func (l *Loaders) ProductsBySKU() *Loader[string, productEntity.Product] {
+	return l.productsBySKU
+}
+
+// Categories loads categories with their flat attributes of a store view by entity_id.
+func (l *Loaders) Categories(storeID uint16) *Loader[uint, categoryRepo.CategoryWithAttributes] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if loader, ok := l.categories[storeID]; ok {
+		return loader
+	}
+	loader := NewLoader(func(ids []uint) (map[uint]categoryRepo.CategoryWithAttributes, error) {
+		cats, flats, err := categoryRepo.GetCategoryRepository(l.db).GetByIDsWithAttributesAndFlat(ids, storeID)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[uint]categoryRepo.CategoryWithAttributes, len(cats))
+		for i, cat := range cats {
+			attrs := map[string]map[string]interface{}{}
+			if i < len(flats) {
+				attrs = flats[i]
+			}
+			result[cat.EntityID] = categoryRepo.CategoryWithAttributes{Category: cat, Attributes: attrs}
+		}
+		return result, nil
+	}, DefaultWait, DefaultMaxBatch)
+	l.categories[storeID] = loader
+	return loader
+}
+
+// priceScope is the website and customer group prices are loaded for.
+type priceScope struct {
+	websiteID       uint16
+	customerGroupID uint
+}
+
+// Prices loads the lowest price (as GetLowestPriceBySKU) of a website and customer group by SKU.
+func (l *Loaders) Prices(websiteID uint16, customerGroupID uint) *Loader[string, float64] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	scope := priceScope{websiteID: websiteID, customerGroupID: customerGroupID}
+	if loader, ok := l.prices[scope]; ok {
+		return loader
+	}
+	loader := NewLoader(func(skus []string) (map[string]float64, error) {
+		repo, err := priceRepo.GetPriceRepository(l.db)
+		if err != nil {
+			return nil, err
+		}
+		return repo.GetLowestPricesBySKUs(skus, websiteID, int(customerGroupID))
+	}, DefaultWait, DefaultMaxBatch)
+	l.prices[scope] = loader
+	return loader
+}
+
+// TierPrices loads all tier prices (of every website and customer group) by entity_id.
+func (l *Loaders) TierPrices() *Loader[uint, []priceRepo.TierPriceResult] {
+	return l.tierPrices
+}
+
+// Stock loads the salable stock (as IsSalable) by SKU.
+func (l *Loaders) Stock() *Loader[string, inventoryRepo.Stock] {
+	return l.stock
+}
+
+type contextKey struct{}
+
+// WithLoaders attaches the request's loaders to ctx.
+func WithLoaders(ctx context.Context, l *Loaders) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the loaders attached to ctx, or nil.
+func FromContext(ctx context.Context) *Loaders {
+	l, _ := ctx.Value(contextKey{}).(*Loaders)
+	return l
+}
@@ -0,0 +1,84 @@
+package graphql
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Apollo Federation v2 subgraph support: the @key directives on Product and Category live in the base
+// schema; FederationExtension adds the _service and _entities fields a gateway queries.
+// See https://www.apollographql.com/docs/federation/subgraph-spec/
+
+// FederationLink opts the subgraph SDL into Federation v2 and imports the directives the schema uses.
+const FederationLink = `extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@key", "FieldSet"])`
+
+// FederationExtension is added to the schema when federation is enabled.
+const FederationExtension = `scalar _Any
+
+union _Entity = Product | Category
+
+type _Service {
+  sdl: String
+}
+
+extend type Query {
+  """Entities of this subgraph by their @key fields (Apollo Federation)."""
+  _entities(representations: [_Any!]!): [_Entity]!
+  """SDL of this subgraph (Apollo Federation)."""
+  _service: _Service!
+}`
+
+var (
+	serviceSDL   string
+	serviceSDLMu sync.Mutex
+)
+
+// SetServiceSDL sets the schema returned by _service: sdl, the schema clients see, with FederationLink.
+// Call once the schema is built.
+func SetServiceSDL(sdl string) {
+	serviceSDLMu.Lock()
+	defer serviceSDLMu.Unlock()
+	serviceSDL = FederationLink + "\n\n" + sdl
+}
+
+// ServiceSDL returns the schema set by SetServiceSDL.
+func ServiceSDL() string {
+	serviceSDLMu.Lock()
+	defer serviceSDLMu.Unlock()
+	return serviceSDL
+}
+
+// EntityRepresentation is an _Any value of _entities: the __typename and key fields of an entity.
+type EntityRepresentation map[string]interface{}
+
+func (EntityRepresentation) ImplementsGraphQLType(name string) bool { return name == "_Any" }
+
+func (r *EntityRepresentation) UnmarshalGraphQL(input interface{}) error {
+	m, ok := input.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("_Any: want an object, got %T", input)
+	}
+	*r = m
+	return nil
+}
+
+// TypeName returns the representation's __typename.
+func (r EntityRepresentation) TypeName() string {
+	s, _ := r["__typename"].(string)
+	return s
+}
+
+// Key returns key field name as a string; numbers are formatted, other values are not keys.
+func (r EntityRepresentation) Key(name string) (string, bool) {
+	switch v := r[name].(type) {
+	case string:
+		return v, true
+	case float64, int, int32, int64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+type EntitiesArgs struct {
+	Representations []EntityRepresentation
+}
@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Magento filter input type names (see schema.graphqls).
+const (
+	FilterEqualType = "FilterEqualTypeInput"
+	FilterMatchType = "FilterMatchTypeInput"
+	FilterRangeType = "FilterRangeTypeInput"
+)
+
+// FilterCondition is one attribute condition of a ProductAttributeFilterInput.
+// Only the fields matching the input type (eq/in, match, from/to) are set.
+type FilterCondition struct {
+	Eq        *string
+	In        []string
+	Match     *string
+	MatchType string
+	From      *string
+	To        *string
+}
+
+// ProductAttributeFilter is ProductAttributeFilterInput decoded into attribute_code -> condition.
+// It implements graphql-go's Unmarshaler so that filterable EAV attributes added to the
+// input at startup (see ProductFilterExtension) reach resolvers without a Go struct field each.
+type ProductAttributeFilter struct {
+	Conditions map[string]FilterCondition
+}
+
+func (f *ProductAttributeFilter) ImplementsGraphQLType(name string) bool {
+	return name == "ProductAttributeFilterInput"
+}
+
+// Nullable lets graphql-go pass an omitted filter as the zero value.
+func (f *ProductAttributeFilter) Nullable() {}
+
+func (f *ProductAttributeFilter) UnmarshalGraphQL(input interface{}) error {
+	if input == nil {
+		return nil
+	}
+	m, ok := input.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("ProductAttributeFilterInput: expected object, got %T", input)
+	}
+	f.Conditions = make(map[string]FilterCondition, len(m))
+	for code, raw := range m {
+		if raw == nil {
+			continue
+		}
+		cm, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("ProductAttributeFilterInput.%s: expected object, got %T", code, raw)
+		}
+		var c FilterCondition
+		c.Eq = optString(cm["eq"])
+		c.Match = optString(cm["match"])
+		c.From = optString(cm["from"])
+		c.To = optString(cm["to"])
+		if mt := optString(cm["match_type"]); mt != nil {
+			c.MatchType = *mt
+		}
+		switch in := cm["in"].(type) {
+		case []interface{}:
+			for _, v := range in {
+				if s := optString(v); s != nil {
+					c.In = append(c.In, *s)
+				}
+			}
+		case nil:
+		default:
+			if s := optString(in); s != nil {
+				c.In = []string{*s}
+			}
+		}
+		f.Conditions[code] = c
+	}
+	return nil
+}
+
+// IsEmpty reports whether no attribute condition was sent.
+func (f ProductAttributeFilter) IsEmpty() bool {
+	return len(f.Conditions) == 0
+}
+
+// Get returns the condition for an attribute code.
+func (f ProductAttributeFilter) Get(code string) (FilterCondition, bool) {
+	c, ok := f.Conditions[code]
+	return c, ok
+}
+
+// Codes returns the filtered attribute codes in sorted order.
+func (f ProductAttributeFilter) Codes() []string {
+	codes := make([]string, 0, len(f.Conditions))
+	for c := range f.Conditions {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Values returns eq and in values combined.
+func (c FilterCondition) Values() []string {
+	vals := make([]string, 0, len(c.In)+1)
+	if c.Eq != nil {
+		vals = append(vals, *c.Eq)
+	}
+	return append(vals, c.In...)
+}
+
+func optString(v interface{}) *string {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return &val
+	default:
+		s := fmt.Sprint(val)
+		return &s
+	}
+}
+
+// ProductAttributeSortInput mirrors Magento's sort input; each field is ASC or DESC.
+type ProductAttributeSortInput struct {
+	Position  *string
+	Price     *string
+	Name      *string
+	Relevance *string
+}
+
+// baseFilterFields are declared in schema.graphqls and must not be re-added.
+var baseFilterFields = map[string]bool{
+	"category_id": true, "category_uid": true, "category_url_path": true,
+	"price": true, "name": true, "sku": true, "url_key": true,
+	"description": true, "short_description": true,
+}
+
+// FilterInputTypeFor maps an EAV frontend_input/backend_type to its Magento filter input type.
+func FilterInputTypeFor(frontendInput, backendType string) string {
+	switch frontendInput {
+	case "price":
+		return FilterRangeType
+	case "text", "textarea":
+		if backendType == "decimal" || backendType == "int" {
+			return FilterRangeType
+		}
+		return FilterMatchType
+	}
+	if backendType == "decimal" {
+		return FilterRangeType
+	}
+	return FilterEqualType
+}
+
+// ProductFilterExtension returns an `extend input ProductAttributeFilterInput` block for the
+// given attribute_code -> filter input type map, skipping fields already in the base schema.
+// Returns "" when there is nothing to add.
+func ProductFilterExtension(fields map[string]string) string {
+	codes := make([]string, 0, len(fields))
+	for code := range fields {
+		if baseFilterFields[code] || !isGraphQLName(code) {
+			continue
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		return ""
+	}
+	sort.Strings(codes)
+	var b strings.Builder
+	b.WriteString("extend input ProductAttributeFilterInput {\n")
+	for _, code := range codes {
+		fmt.Fprintf(&b, "  %s: %s\n", code, fields[code])
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func isGraphQLName(s string) bool {
+	if s == "" || strings.HasPrefix(s, "__") {
+		return false
+	}
+	for i, ch := range s {
+		switch {
+		case ch == '_', ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z':
+		case ch >= '0' && ch <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
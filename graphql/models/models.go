@@ -3,21 +3,59 @@ package models
 // --- Product ---
 
 type Product struct {
-	EntityID         string                 `json:"entity_id" mapstructure:"entity_id"`
-	SKU              string                 `json:"sku" mapstructure:"sku"`
-	Name             *string                `json:"name,omitempty" mapstructure:"name"`
-	Price            *float64               `json:"price,omitempty" mapstructure:"price"`
-	FinalPrice       *float64               `json:"final_price,omitempty" mapstructure:"final_price"`
-	URLKey           *string                `json:"url_key,omitempty" mapstructure:"url_key"`
-	Image            *string                `json:"image,omitempty" mapstructure:"image"`
-	ShortDescription *string                `json:"short_description,omitempty" mapstructure:"short_description"`
-	Description      *string                `json:"description,omitempty" mapstructure:"description"`
-	IsInStock        *bool                  `json:"is_in_stock,omitempty" mapstructure:"is_in_stock"`
-	Qty              *float64               `json:"qty,omitempty" mapstructure:"qty"`
-	TypeID           *string                `json:"type_id,omitempty" mapstructure:"type_id"`
-	CategoryIDs      *[]string              `json:"category_ids,omitempty" mapstructure:"category_ids"`
-	MediaGallery     *[]*MediaGalleryItem   `json:"media_gallery,omitempty" mapstructure:"media_gallery"`
-	Attributes       map[string]interface{} `json:"attributes,omitempty" mapstructure:"-"`
+	EntityID         string                   `json:"entity_id" mapstructure:"entity_id"`
+	SKU              string                   `json:"sku" mapstructure:"sku"`
+	Name             *string                  `json:"name,omitempty" mapstructure:"name"`
+	Price            *float64                 `json:"price,omitempty" mapstructure:"price"`
+	FinalPrice       *float64                 `json:"final_price,omitempty" mapstructure:"final_price"`
+	URLKey           *string                  `json:"url_key,omitempty" mapstructure:"url_key"`
+	Image            *string                  `json:"image,omitempty" mapstructure:"image"`
+	ShortDescription *string                  `json:"short_description,omitempty" mapstructure:"short_description"`
+	Description      *string                  `json:"description,omitempty" mapstructure:"description"`
+	IsInStock        *bool                    `json:"is_in_stock,omitempty" mapstructure:"is_in_stock"`
+	Qty              *float64                 `json:"qty,omitempty" mapstructure:"qty"`
+	TypeID           *string                  `json:"type_id,omitempty" mapstructure:"type_id"`
+	CategoryIDs      *[]string                `json:"category_ids,omitempty" mapstructure:"category_ids"`
+	MediaGallery     *[]*MediaGalleryItem     `json:"media_gallery,omitempty" mapstructure:"media_gallery"`
+	BundleOptions    *[]*ProductBundleOption  `json:"bundle_options,omitempty" mapstructure:"bundle_options"`
+	BundlePriceRange *ProductBundlePriceRange `json:"bundle_price_range,omitempty" mapstructure:"bundle_price_range"`
+	GroupedItems     *[]*ProductGroupedItem   `json:"grouped_items,omitempty" mapstructure:"grouped_items"`
+	Attributes       map[string]interface{}   `json:"attributes,omitempty" mapstructure:"-"`
+}
+
+// ProductBundleOption mirrors the flat "bundle_options" entry of a bundle product.
+type ProductBundleOption struct {
+	OptionID   string                     `json:"option_id" mapstructure:"option_id"`
+	Title      *string                    `json:"title,omitempty" mapstructure:"title"`
+	Required   *bool                      `json:"required,omitempty" mapstructure:"required"`
+	Type       *string                    `json:"type,omitempty" mapstructure:"type"`
+	Position   *int32                     `json:"position,omitempty" mapstructure:"position"`
+	Selections *[]*ProductBundleSelection `json:"selections,omitempty" mapstructure:"selections"`
+}
+
+type ProductBundleSelection struct {
+	SelectionID  string   `json:"selection_id" mapstructure:"selection_id"`
+	ProductID    string   `json:"product_id" mapstructure:"product_id"`
+	SKU          string   `json:"sku" mapstructure:"sku"`
+	Position     *int32   `json:"position,omitempty" mapstructure:"position"`
+	IsDefault    *bool    `json:"is_default,omitempty" mapstructure:"is_default"`
+	PriceType    *int32   `json:"price_type,omitempty" mapstructure:"price_type"`
+	PriceValue   *float64 `json:"price_value,omitempty" mapstructure:"price_value"`
+	Qty          *float64 `json:"qty,omitempty" mapstructure:"qty"`
+	CanChangeQty *bool    `json:"can_change_qty,omitempty" mapstructure:"can_change_qty"`
+}
+
+type ProductBundlePriceRange struct {
+	MinimumPrice float64 `json:"minimum_price" mapstructure:"minimum_price"`
+	MaximumPrice float64 `json:"maximum_price" mapstructure:"maximum_price"`
+}
+
+// ProductGroupedItem mirrors the flat "grouped_items" entry of a grouped product.
+type ProductGroupedItem struct {
+	ProductID string   `json:"product_id" mapstructure:"product_id"`
+	SKU       string   `json:"sku" mapstructure:"sku"`
+	Position  *int32   `json:"position,omitempty" mapstructure:"position"`
+	Qty       *float64 `json:"qty,omitempty" mapstructure:"qty"`
 }
 
 type MediaGalleryItem struct {
@@ -43,9 +81,10 @@ type Category struct {
 // --- Search ---
 
 type ProductSearchResult struct {
-	Items      []*Product `json:"items"`
-	TotalCount int32      `json:"total_count"`
-	PageInfo   *PageInfo  `json:"page_info"`
+	Items        []*Product      `json:"items"`
+	TotalCount   int32           `json:"total_count"`
+	PageInfo     *PageInfo       `json:"page_info"`
+	Aggregations *[]*Aggregation `json:"aggregations"`
 }
 
 type PageInfo struct {
@@ -54,6 +93,35 @@ type PageInfo struct {
 	TotalPages  int32 `json:"total_pages"`
 }
 
+// SearchSuggestions are the typeahead completions of a search query.
+type SearchSuggestions struct {
+	Products   []*ProductSuggestion    `json:"products"`
+	Categories []*CategorySuggestion   `json:"categories"`
+	Terms      []*SearchTermSuggestion `json:"terms"`
+}
+
+// ProductSuggestion is a product whose name or SKU (Text) completes the query.
+type ProductSuggestion struct {
+	Text   string  `json:"text"`
+	SKU    string  `json:"sku"`
+	Name   *string `json:"name"`
+	URLKey *string `json:"url_key"`
+}
+
+type CategorySuggestion struct {
+	ID      int32   `json:"id"`
+	UID     string  `json:"uid"`
+	Name    string  `json:"name"`
+	URLPath *string `json:"url_path"`
+}
+
+// SearchTermSuggestion is a popular search term from search_query.
+type SearchTermSuggestion struct {
+	QueryText  string `json:"query_text"`
+	NumResults int32  `json:"num_results"`
+	Popularity int32  `json:"popularity"`
+}
+
 // --- Magento-compatible types (Venia/PWA) ---
 
 type CategoryTree struct {
@@ -63,6 +131,7 @@ type CategoryTree struct {
 	MetaDescription *string `json:"meta_description,omitempty"`
 	URLPath         *string `json:"url_path,omitempty"`
 	URLKey          *string `json:"url_key,omitempty"`
+	RoutableUrl
 }
 
 type CategoryResult struct {
@@ -75,7 +144,8 @@ type Money struct {
 }
 
 type ProductDiscount struct {
-	AmountOff *float64 `json:"amount_off,omitempty"`
+	AmountOff  *float64 `json:"amount_off,omitempty"`
+	PercentOff *float64 `json:"percent_off,omitempty"`
 }
 
 type ProductPrice struct {
@@ -85,31 +155,605 @@ type ProductPrice struct {
 }
 
 type PriceRange struct {
+	MinimumPrice ProductPrice `json:"minimum_price"`
 	MaximumPrice ProductPrice `json:"maximum_price"`
 }
 
+// TierPrice is a quantity discount of a product for the requester's website and customer group.
+type TierPrice struct {
+	Quantity   *float64         `json:"quantity,omitempty"`
+	FinalPrice *Money           `json:"final_price,omitempty"`
+	Discount   *ProductDiscount `json:"discount,omitempty"`
+}
+
 type ProductImage struct {
 	URL string `json:"url"`
 }
 
 type MagentoProduct struct {
-	ID            int32         `json:"id"`
-	UID           string        `json:"uid"`
-	Name          *string       `json:"name,omitempty"`
-	PriceRange    PriceRange    `json:"price_range"`
-	SKU           string        `json:"sku"`
-	SmallImage    *ProductImage `json:"small_image,omitempty"`
-	StockStatus   string        `json:"stock_status"`
-	RatingSummary float64       `json:"rating_summary"`
-	URLKey        *string       `json:"url_key,omitempty"`
+	ID          int32                  `json:"id"`
+	UID         string                 `json:"uid"`
+	Name        *string                `json:"name,omitempty"`
+	PriceRange  PriceRange             `json:"price_range"`
+	SKU         string                 `json:"sku"`
+	SmallImage  *ProductImage          `json:"small_image,omitempty"`
+	StockStatus string                 `json:"stock_status"`
+	URLKey      *string                `json:"url_key,omitempty"`
+	Options     *[]*CustomizableOption `json:"options,omitempty"`
+	// SpecialPrice is the special_price within its special_from_date/special_to_date window.
+	SpecialPrice *float64 `json:"special_price,omitempty"`
+	RoutableUrl
+	// LoadReviewSummary loads rating_summary and review_count; nil for products resolved without review access.
+	LoadReviewSummary func() (ratingSummary float64, reviewCount int32) `json:"-"`
+	// LoadReviews loads a page of approved reviews; nil for products resolved without review access.
+	LoadReviews func(pageSize, currentPage int32) *ProductReviews `json:"-"`
+	// LoadLinkedProducts loads the "related", "upsell" or "crosssell" products; nil for linked products.
+	LoadLinkedProducts func(linkType string) []*ProductInterface `json:"-"`
+	// LoadPriceTiers loads the tier prices; nil for products resolved without tier prices.
+	LoadPriceTiers func() []*TierPrice `json:"-"`
 }
 
+// ProductReviewsArgs are the arguments of reviews(pageSize, currentPage).
+type ProductReviewsArgs struct {
+	PageSize    int32
+	CurrentPage int32
+}
+
+// RatingSummary resolves rating_summary through LoadReviewSummary.
+func (p *MagentoProduct) RatingSummary() float64 {
+	if p.LoadReviewSummary == nil {
+		return 0
+	}
+	ratingSummary, _ := p.LoadReviewSummary()
+	return ratingSummary
+}
+
+// ReviewCount resolves review_count through LoadReviewSummary.
+func (p *MagentoProduct) ReviewCount() int32 {
+	if p.LoadReviewSummary == nil {
+		return 0
+	}
+	_, reviewCount := p.LoadReviewSummary()
+	return reviewCount
+}
+
+// Reviews resolves reviews(pageSize, currentPage) through LoadReviews.
+func (p *MagentoProduct) Reviews(args ProductReviewsArgs) *ProductReviews {
+	if p.LoadReviews == nil {
+		return &ProductReviews{Items: []*ProductReview{}, PageInfo: SearchResultPageInfo{CurrentPage: &args.CurrentPage, PageSize: &args.PageSize}}
+	}
+	return p.LoadReviews(args.PageSize, args.CurrentPage)
+}
+
+// RelatedProducts resolves related_products.
+func (p *MagentoProduct) RelatedProducts() *[]*ProductInterface {
+	return p.linkedProducts("related")
+}
+
+// UpsellProducts resolves upsell_products.
+func (p *MagentoProduct) UpsellProducts() *[]*ProductInterface {
+	return p.linkedProducts("upsell")
+}
+
+// CrosssellProducts resolves crosssell_products.
+func (p *MagentoProduct) CrosssellProducts() *[]*ProductInterface {
+	return p.linkedProducts("crosssell")
+}
+
+// PriceTiers resolves price_tiers through LoadPriceTiers.
+func (p *MagentoProduct) PriceTiers() *[]*TierPrice {
+	tiers := []*TierPrice{}
+	if p.LoadPriceTiers != nil {
+		tiers = p.LoadPriceTiers()
+	}
+	return &tiers
+}
+
+func (p *MagentoProduct) linkedProducts(linkType string) *[]*ProductInterface {
+	if p.LoadLinkedProducts == nil {
+		return nil
+	}
+	products := p.LoadLinkedProducts(linkType)
+	return &products
+}
+
+// ProductInterface resolves Magento's ProductInterface. The shared fields live in the embedded
+// MagentoProduct; a type-specific part (e.g. Configurable) selects the concrete GraphQL type.
+type ProductInterface struct {
+	MagentoProduct
+	Configurable *ConfigurableProduct `json:"-"`
+	Bundle       *BundleProduct       `json:"-"`
+	Grouped      *GroupedProduct      `json:"-"`
+}
+
+func (p *ProductInterface) ToMagentoProduct() (*MagentoProduct, bool) {
+	return &p.MagentoProduct, p.Configurable == nil && p.Bundle == nil && p.Grouped == nil
+}
+
+func (p *ProductInterface) ToConfigurableProduct() (*ConfigurableProduct, bool) {
+	return p.Configurable, p.Configurable != nil
+}
+
+func (p *ProductInterface) ToBundleProduct() (*BundleProduct, bool) {
+	return p.Bundle, p.Bundle != nil
+}
+
+func (p *ProductInterface) ToGroupedProduct() (*GroupedProduct, bool) {
+	return p.Grouped, p.Grouped != nil
+}
+
+type ConfigurableProduct struct {
+	MagentoProduct
+	Variants            *[]*ConfigurableVariant        `json:"variants,omitempty"`
+	ConfigurableOptions *[]*ConfigurableProductOptions `json:"configurable_options,omitempty"`
+}
+
+type ConfigurableVariant struct {
+	Attributes *[]*ConfigurableAttributeOption `json:"attributes,omitempty"`
+	Product    *MagentoProduct                 `json:"product,omitempty"`
+}
+
+type ConfigurableAttributeOption struct {
+	Code       *string `json:"code,omitempty"`
+	Label      *string `json:"label,omitempty"`
+	ValueIndex *int32  `json:"value_index,omitempty"`
+	UID        string  `json:"uid"`
+}
+
+type ConfigurableProductOptions struct {
+	ID            *int32                               `json:"id,omitempty"`
+	AttributeID   *string                              `json:"attribute_id,omitempty"`
+	AttributeUID  string                               `json:"attribute_uid"`
+	AttributeCode *string                              `json:"attribute_code,omitempty"`
+	Label         *string                              `json:"label,omitempty"`
+	Position      *int32                               `json:"position,omitempty"`
+	UID           string                               `json:"uid"`
+	Values        *[]*ConfigurableProductOptionsValues `json:"values,omitempty"`
+}
+
+type ConfigurableProductOptionsValues struct {
+	ValueIndex      *int32      `json:"value_index,omitempty"`
+	Label           *string     `json:"label,omitempty"`
+	DefaultLabel    *string     `json:"default_label,omitempty"`
+	StoreLabel      *string     `json:"store_label,omitempty"`
+	UseDefaultValue *bool       `json:"use_default_value,omitempty"`
+	UID             *string     `json:"uid,omitempty"`
+	SwatchData      *SwatchData `json:"swatch_data,omitempty"`
+}
+
+type BundleProduct struct {
+	MagentoProduct
+	DynamicPrice *bool          `json:"dynamic_price,omitempty"`
+	Items        *[]*BundleItem `json:"items,omitempty"`
+}
+
+type BundleItem struct {
+	OptionID *int32               `json:"option_id,omitempty"`
+	UID      string               `json:"uid"`
+	Title    *string              `json:"title,omitempty"`
+	Required *bool                `json:"required,omitempty"`
+	Type     *string              `json:"type,omitempty"`
+	Position *int32               `json:"position,omitempty"`
+	SKU      *string              `json:"sku,omitempty"`
+	Options  *[]*BundleItemOption `json:"options,omitempty"`
+}
+
+type BundleItemOption struct {
+	ID                *int32          `json:"id,omitempty"`
+	UID               string          `json:"uid"`
+	Label             *string         `json:"label,omitempty"`
+	Quantity          *float64        `json:"quantity,omitempty"`
+	Position          *int32          `json:"position,omitempty"`
+	IsDefault         *bool           `json:"is_default,omitempty"`
+	Price             *float64        `json:"price,omitempty"`
+	PriceType         *string         `json:"price_type,omitempty"`
+	CanChangeQuantity *bool           `json:"can_change_quantity,omitempty"`
+	Product           *MagentoProduct `json:"product,omitempty"`
+}
+
+// CustomizableOptionBase holds the CustomizableOptionInterface fields.
+type CustomizableOptionBase struct {
+	OptionID  *int32  `json:"option_id,omitempty"`
+	UID       string  `json:"uid"`
+	Title     *string `json:"title,omitempty"`
+	Required  *bool   `json:"required,omitempty"`
+	SortOrder *int32  `json:"sort_order,omitempty"`
+}
+
+// CustomizableOption resolves CustomizableOptionInterface. Type is the Magento option type
+// (field, area, date, date_time, time, drop_down, radio, checkbox, multiple) and selects the
+// concrete GraphQL type; Single or Select holds it.
+type CustomizableOption struct {
+	CustomizableOptionBase
+	Type   string                    `json:"type"`
+	Single *CustomizableValueOption  `json:"-"`
+	Select *CustomizableSelectOption `json:"-"`
+}
+
+func (o *CustomizableOption) ToCustomizableFieldOption() (*CustomizableValueOption, bool) {
+	return o.Single, o.Single != nil && o.Type == "field"
+}
+
+func (o *CustomizableOption) ToCustomizableAreaOption() (*CustomizableValueOption, bool) {
+	return o.Single, o.Single != nil && o.Type == "area"
+}
+
+func (o *CustomizableOption) ToCustomizableDateOption() (*CustomizableValueOption, bool) {
+	return o.Single, o.Single != nil && (o.Type == "date" || o.Type == "date_time" || o.Type == "time")
+}
+
+func (o *CustomizableOption) ToCustomizableDropDownOption() (*CustomizableSelectOption, bool) {
+	return o.Select, o.Select != nil && o.Type == "drop_down"
+}
+
+func (o *CustomizableOption) ToCustomizableRadioOption() (*CustomizableSelectOption, bool) {
+	return o.Select, o.Select != nil && o.Type == "radio"
+}
+
+func (o *CustomizableOption) ToCustomizableCheckboxOption() (*CustomizableSelectOption, bool) {
+	return o.Select, o.Select != nil && o.Type == "checkbox"
+}
+
+func (o *CustomizableOption) ToCustomizableMultipleOption() (*CustomizableSelectOption, bool) {
+	return o.Select, o.Select != nil && o.Type == "multiple"
+}
+
+// CustomizableValueOption resolves the field, area and date option types, which have one value.
+type CustomizableValueOption struct {
+	CustomizableOptionBase
+	ProductSKU *string            `json:"product_sku,omitempty"`
+	Value      *CustomizableValue `json:"value,omitempty"`
+}
+
+// CustomizableSelectOption resolves the drop_down, radio, checkbox and multiple option types.
+type CustomizableSelectOption struct {
+	CustomizableOptionBase
+	Value *[]*CustomizableValue `json:"value,omitempty"`
+}
+
+// CustomizableValue resolves all Customizable*Value types; each reads only its own fields.
+type CustomizableValue struct {
+	UID           string   `json:"uid"`
+	OptionTypeID  *int32   `json:"option_type_id,omitempty"`
+	Title         *string  `json:"title,omitempty"`
+	SortOrder     *int32   `json:"sort_order,omitempty"`
+	Price         *float64 `json:"price,omitempty"`
+	PriceType     *string  `json:"price_type,omitempty"`
+	SKU           *string  `json:"sku,omitempty"`
+	MaxCharacters *int32   `json:"max_characters,omitempty"`
+	Type          *string  `json:"type,omitempty"`
+}
+
+type GroupedProduct struct {
+	MagentoProduct
+	Items *[]*GroupedProductItem `json:"items,omitempty"`
+}
+
+type GroupedProductItem struct {
+	Position *int32          `json:"position,omitempty"`
+	Qty      *float64        `json:"qty,omitempty"`
+	Product  *MagentoProduct `json:"product,omitempty"`
+}
+
+// Swatch types (eav_attribute_option_swatch.type).
+const (
+	SwatchText  = 0
+	SwatchColor = 1
+	SwatchImage = 2
+)
+
+// SwatchData resolves SwatchDataInterface; Type picks Text, Color or Image swatch.
+type SwatchData struct {
+	Type      int     `json:"-"`
+	Value     *string `json:"value,omitempty"`
+	Thumbnail *string `json:"thumbnail,omitempty"`
+}
+
+func (s *SwatchData) ToTextSwatchData() (*SwatchData, bool)  { return s, s.Type == SwatchText }
+func (s *SwatchData) ToColorSwatchData() (*SwatchData, bool) { return s, s.Type == SwatchColor }
+func (s *SwatchData) ToImageSwatchData() (*SwatchData, bool) { return s, s.Type == SwatchImage }
+
 type SearchResultPageInfo struct {
-	TotalPages int32 `json:"total_pages"`
+	CurrentPage *int32 `json:"current_page,omitempty"`
+	PageSize    *int32 `json:"page_size,omitempty"`
+	TotalPages  int32  `json:"total_pages"`
 }
 
+// Products is a page of magentoProducts. TypedItems resolve as their concrete product types; items
+// keeps listing them as MagentoProduct for existing clients.
 type Products struct {
-	Items      []*MagentoProduct    `json:"items"`
-	PageInfo   SearchResultPageInfo `json:"page_info"`
-	TotalCount int32                `json:"total_count"`
+	TypedItems   []*ProductInterface  `json:"typed_items"`
+	PageInfo     SearchResultPageInfo `json:"page_info"`
+	TotalCount   int32                `json:"total_count"`
+	Aggregations *[]*Aggregation      `json:"aggregations,omitempty"`
+}
+
+// Items resolves items: the shared fields of every product, as MagentoProduct.
+func (p *Products) Items() []*MagentoProduct {
+	items := make([]*MagentoProduct, len(p.TypedItems))
+	for i, item := range p.TypedItems {
+		items[i] = &item.MagentoProduct
+	}
+	return items
+}
+
+// Aggregation is a layered navigation filter with per-option product counts.
+type Aggregation struct {
+	AttributeCode string                `json:"attribute_code"`
+	Label         *string               `json:"label,omitempty"`
+	Count         *int32                `json:"count"`
+	Options       *[]*AggregationOption `json:"options"`
+}
+
+type AggregationOption struct {
+	Label *string `json:"label,omitempty"`
+	Value string  `json:"value"`
+	Count *int32  `json:"count"`
+}
+
+// --- URL resolver ---
+
+// EntityUrl is the urlResolver(url:) result.
+type EntityUrl struct {
+	ID           *int32  `json:"id,omitempty"`
+	EntityUID    *string `json:"entity_uid,omitempty"`
+	CanonicalURL *string `json:"canonical_url,omitempty"`
+	RelativeURL  *string `json:"relative_url,omitempty"`
+	RedirectCode int32   `json:"redirectCode"`
+	Type         *string `json:"type,omitempty"`
+}
+
+// RoutableUrl holds the RoutableInterface fields. Products and categories embed it; it is set when
+// they are resolved through route(url:).
+type RoutableUrl struct {
+	RelativeURL  *string `json:"relative_url,omitempty"`
+	RedirectCode int32   `json:"redirect_code,omitempty"`
+	Type         *string `json:"type,omitempty"`
+}
+
+// Routable resolves RoutableInterface. Product or Category selects the concrete type; with neither
+// set it resolves as RoutableUrl.
+type Routable struct {
+	RoutableUrl
+	Product  *ProductInterface `json:"-"`
+	Category *CategoryTree     `json:"-"`
+}
+
+func (r *Routable) ToMagentoProduct() (*MagentoProduct, bool) {
+	if r.Product == nil {
+		return nil, false
+	}
+	return r.Product.ToMagentoProduct()
+}
+
+func (r *Routable) ToConfigurableProduct() (*ConfigurableProduct, bool) {
+	if r.Product == nil {
+		return nil, false
+	}
+	return r.Product.ToConfigurableProduct()
+}
+
+func (r *Routable) ToBundleProduct() (*BundleProduct, bool) {
+	if r.Product == nil {
+		return nil, false
+	}
+	return r.Product.ToBundleProduct()
+}
+
+func (r *Routable) ToGroupedProduct() (*GroupedProduct, bool) {
+	if r.Product == nil {
+		return nil, false
+	}
+	return r.Product.ToGroupedProduct()
+}
+
+func (r *Routable) ToCategoryTree() (*CategoryTree, bool) {
+	return r.Category, r.Category != nil
+}
+
+func (r *Routable) ToRoutableUrl() (*RoutableUrl, bool) {
+	return &r.RoutableUrl, r.Product == nil && r.Category == nil
+}
+
+// SetRoutable sets the RoutableInterface fields on the product and its concrete type.
+func (p *ProductInterface) SetRoutable(u RoutableUrl) {
+	p.RoutableUrl = u
+	if p.Configurable != nil {
+		p.Configurable.RoutableUrl = u
+	}
+	if p.Bundle != nil {
+		p.Bundle.RoutableUrl = u
+	}
+	if p.Grouped != nil {
+		p.Grouped.RoutableUrl = u
+	}
+}
+
+// --- Store config ---
+
+// StoreConfig is Magento's StoreConfig: one store view with its core_config_data settings.
+type StoreConfig struct {
+	ID                         int32   `json:"id"`
+	StoreCode                  string  `json:"store_code"`
+	StoreName                  *string `json:"store_name,omitempty"`
+	StoreSortOrder             *int32  `json:"store_sort_order,omitempty"`
+	IsDefaultStore             *bool   `json:"is_default_store,omitempty"`
+	StoreGroupCode             *string `json:"store_group_code,omitempty"`
+	StoreGroupName             *string `json:"store_group_name,omitempty"`
+	IsDefaultStoreGroup        *bool   `json:"is_default_store_group,omitempty"`
+	WebsiteID                  *int32  `json:"website_id,omitempty"`
+	WebsiteCode                *string `json:"website_code,omitempty"`
+	WebsiteName                *string `json:"website_name,omitempty"`
+	RootCategoryID             *int32  `json:"root_category_id,omitempty"`
+	RootCategoryUID            *string `json:"root_category_uid,omitempty"`
+	Locale                     *string `json:"locale,omitempty"`
+	Timezone                   *string `json:"timezone,omitempty"`
+	WeightUnit                 *string `json:"weight_unit,omitempty"`
+	BaseCurrencyCode           *string `json:"base_currency_code,omitempty"`
+	DefaultDisplayCurrencyCode *string `json:"default_display_currency_code,omitempty"`
+	BaseURL                    *string `json:"base_url,omitempty"`
+	BaseLinkURL                *string `json:"base_link_url,omitempty"`
+	BaseMediaURL               *string `json:"base_media_url,omitempty"`
+	SecureBaseURL              *string `json:"secure_base_url,omitempty"`
+	SecureBaseLinkURL          *string `json:"secure_base_link_url,omitempty"`
+	SecureBaseMediaURL         *string `json:"secure_base_media_url,omitempty"`
+	UseStoreInURL              *bool   `json:"use_store_in_url,omitempty"`
+	ProductURLSuffix           *string `json:"product_url_suffix,omitempty"`
+	CategoryURLSuffix          *string `json:"category_url_suffix,omitempty"`
+	TitleSeparator             *string `json:"title_separator,omitempty"`
+	DefaultTitle               *string `json:"default_title,omitempty"`
+	DefaultDescription         *string `json:"default_description,omitempty"`
+	DefaultKeywords            *string `json:"default_keywords,omitempty"`
+	CmsHomePage                *string `json:"cms_home_page,omitempty"`
+	CatalogDefaultSortBy       *string `json:"catalog_default_sort_by,omitempty"`
+	GridPerPage                *int32  `json:"grid_per_page,omitempty"`
+	ListPerPage                *int32  `json:"list_per_page,omitempty"`
+}
+
+type Currency struct {
+	AvailableCurrencyCodes       *[]*string       `json:"available_currency_codes,omitempty"`
+	BaseCurrencyCode             *string          `json:"base_currency_code,omitempty"`
+	BaseCurrencySymbol           *string          `json:"base_currency_symbol,omitempty"`
+	DefaultDisplayCurrencyCode   *string          `json:"default_display_currency_code,omitempty"`
+	DefaultDisplayCurrencySymbol *string          `json:"default_display_currency_symbol,omitempty"`
+	ExchangeRates                *[]*ExchangeRate `json:"exchange_rates,omitempty"`
+}
+
+type ExchangeRate struct {
+	CurrencyTo *string  `json:"currency_to,omitempty"`
+	Rate       *float64 `json:"rate,omitempty"`
+}
+
+type ProductReview struct {
+	AverageRating    float64                `json:"average_rating"`
+	CreatedAt        string                 `json:"created_at"`
+	Nickname         string                 `json:"nickname"`
+	RatingsBreakdown []*ProductReviewRating `json:"ratings_breakdown"`
+	Summary          string                 `json:"summary"`
+	Text             string                 `json:"text"`
+}
+
+type ProductReviewRating struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ProductReviews struct {
+	Items    []*ProductReview     `json:"items"`
+	PageInfo SearchResultPageInfo `json:"page_info"`
+}
+
+type ProductReviewRatingsMetadata struct {
+	Items []*ProductReviewRatingMetadata `json:"items"`
+}
+
+type ProductReviewRatingMetadata struct {
+	ID     string                              `json:"id"`
+	Name   string                              `json:"name"`
+	Values []*ProductReviewRatingValueMetadata `json:"values"`
+}
+
+type ProductReviewRatingValueMetadata struct {
+	ValueID string `json:"value_id"`
+	Value   string `json:"value"`
+}
+
+type CreateProductReviewOutput struct {
+	Review *ProductReview `json:"review"`
+}
+
+// Cart resolves Cart for a guest cart.
+type Cart struct {
+	ID            string       `json:"id"`
+	Items         *[]*CartItem `json:"items"`
+	TotalQuantity float64      `json:"total_quantity"`
+	IsVirtual     bool         `json:"is_virtual"`
+	Prices        *CartPrices  `json:"prices,omitempty"`
+}
+
+// CartItem resolves CartItemInterface. Virtual selects VirtualCartItem over SimpleCartItem.
+type CartItem struct {
+	ID       string            `json:"id"`
+	UID      string            `json:"uid"`
+	Quantity float64           `json:"quantity"`
+	Product  *ProductInterface `json:"product"`
+	Prices   *CartItemPrices   `json:"prices,omitempty"`
+	Virtual  bool              `json:"-"`
+}
+
+func (i *CartItem) ToSimpleCartItem() (*CartItem, bool) {
+	return i, !i.Virtual
+}
+
+func (i *CartItem) ToVirtualCartItem() (*CartItem, bool) {
+	return i, i.Virtual
+}
+
+type CartItemPrices struct {
+	Price                Money `json:"price"`
+	RowTotal             Money `json:"row_total"`
+	RowTotalIncludingTax Money `json:"row_total_including_tax"`
+}
+
+type CartPrices struct {
+	GrandTotal                       *Money `json:"grand_total,omitempty"`
+	SubtotalExcludingTax             *Money `json:"subtotal_excluding_tax,omitempty"`
+	SubtotalIncludingTax             *Money `json:"subtotal_including_tax,omitempty"`
+	SubtotalWithDiscountExcludingTax *Money `json:"subtotal_with_discount_excluding_tax,omitempty"`
+}
+
+type CartUserInputError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type AddProductsToCartOutput struct {
+	Cart       *Cart                 `json:"cart"`
+	UserErrors []*CartUserInputError `json:"user_errors"`
+}
+
+type UpdateCartItemsOutput struct {
+	Cart *Cart `json:"cart"`
+}
+
+type RemoveItemFromCartOutput struct {
+	Cart *Cart `json:"cart"`
+}
+
+// --- Subscriptions ---
+
+// StockChange is an event of the stockChanged subscription.
+type StockChange struct {
+	SKU         string  `json:"sku"`
+	Qty         float64 `json:"qty"`
+	IsInStock   bool    `json:"is_in_stock"`
+	StockStatus string  `json:"stock_status"`
+}
+
+// PriceChange is an event of the priceChanged subscription.
+type PriceChange struct {
+	SKU      string  `json:"sku"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+}
+
+// --- Federation ---
+
+// Entity resolves the _Entity union of _entities: exactly one of Product and Category is set.
+type Entity struct {
+	Product  *Product  `json:"-"`
+	Category *Category `json:"-"`
+}
+
+func (e *Entity) ToProduct() (*Product, bool) {
+	return e.Product, e.Product != nil
+}
+
+func (e *Entity) ToCategory() (*Category, bool) {
+	return e.Category, e.Category != nil
+}
+
+// Service is the _Service type of _service.
+type Service struct {
+	SDL *string `json:"sdl"`
 }
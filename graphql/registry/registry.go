@@ -15,9 +15,17 @@ type ResolverFunc func(ctx context.Context, args map[string]interface{}) (interf
 // QueryResolverFactory creates the Query resolver for graphql-go. Call from init().
 type QueryResolverFactory func(db interface{}) interface{}
 
+// MutationResolverFactory creates the Mutation resolver for graphql-go. Call from init().
+type MutationResolverFactory func(db interface{}) interface{}
+
+// SubscriptionResolverFactory creates the Subscription resolver for graphql-go. Call from init().
+type SubscriptionResolverFactory func(db interface{}) interface{}
+
 var mu sync.Mutex
 var graphqlLocked int32
 var queryResolverFactory QueryResolverFactory
+var mutationResolverFactory MutationResolverFactory
+var subscriptionResolverFactory SubscriptionResolverFactory
 
 // RegisterQueryResolverFactory sets the factory for the main Query resolver.
 func RegisterQueryResolverFactory(fn QueryResolverFactory) {
@@ -34,6 +42,36 @@ func GetQueryResolver(db interface{}) interface{} {
 	return queryResolverFactory(db)
 }
 
+// RegisterMutationResolverFactory sets the factory for the main Mutation resolver.
+func RegisterMutationResolverFactory(fn MutationResolverFactory) {
+	mu.Lock()
+	defer mu.Unlock()
+	mutationResolverFactory = fn
+}
+
+// GetMutationResolver returns the Mutation resolver. Panics if not registered.
+func GetMutationResolver(db interface{}) interface{} {
+	if mutationResolverFactory == nil {
+		panic("graphql/registry: MutationResolverFactory not registered")
+	}
+	return mutationResolverFactory(db)
+}
+
+// RegisterSubscriptionResolverFactory sets the factory for the main Subscription resolver.
+func RegisterSubscriptionResolverFactory(fn SubscriptionResolverFactory) {
+	mu.Lock()
+	defer mu.Unlock()
+	subscriptionResolverFactory = fn
+}
+
+// GetSubscriptionResolver returns the Subscription resolver. Panics if not registered.
+func GetSubscriptionResolver(db interface{}) interface{} {
+	if subscriptionResolverFactory == nil {
+		panic("graphql/registry: SubscriptionResolverFactory not registered")
+	}
+	return subscriptionResolverFactory(db)
+}
+
 func getEntries() map[string]ResolverFunc {
 	if v, ok := registry.GlobalRegistry.GetGlobal(registry.KeyRegistryGraphQL); ok && v != nil {
 		return v.(map[string]ResolverFunc)
@@ -91,3 +129,61 @@ func Names() []string {
 	}
 	return names
 }
+
+// SchemaExtension is a typed extension of the GraphQL schema: its own SDL types, extensions of existing
+// types and fields on Query and Mutation, resolved by Resolver like the core fields (graphql-go method
+// matching). Unlike Register, clients get real types, introspection and field selection.
+type SchemaExtension struct {
+	// Name identifies the extension in conflict errors. Must be unique.
+	Name string
+	// Types is SDL of the extension's own types (type, input, enum, interface, union, scalar) and of
+	// extensions of existing types (extend type ...). Fields added to object types other than Query and
+	// Mutation are resolved by the type's Go value, which must have a matching method or field.
+	Types string
+	// Query and Mutation are SDL field definitions added to Query and Mutation.
+	Query    string
+	Mutation string
+	// Resolver creates the value whose methods resolve the Query and Mutation fields. db is *gorm.DB.
+	Resolver func(db interface{}) interface{}
+}
+
+var schemaExtensions []SchemaExtension
+
+// RegisterSchema adds a typed schema extension. Call from init() in custom packages. Name must be
+// unique. Panics if locked. Conflicts with the core schema or other extensions are reported when the
+// schema is built at startup.
+func RegisterSchema(ext SchemaExtension) {
+	mu.Lock()
+	defer mu.Unlock()
+	if registry.GlobalRegistry.IsLocked(registry.KeyRegistryGraphQL) {
+		panic("graphql/registry: locked (register only during init before first request)")
+	}
+	if ext.Name == "" || ext.Resolver == nil {
+		panic("graphql/registry: schema extension needs a Name and a Resolver")
+	}
+	for _, e := range schemaExtensions {
+		if e.Name == ext.Name {
+			panic("graphql/registry: duplicate schema extension " + ext.Name)
+		}
+	}
+	schemaExtensions = append(schemaExtensions, ext)
+}
+
+// UnregisterSchema removes a typed schema extension (for tests).
+func UnregisterSchema(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, e := range schemaExtensions {
+		if e.Name == name {
+			schemaExtensions = append(schemaExtensions[:i:i], schemaExtensions[i+1:]...)
+			return
+		}
+	}
+}
+
+// SchemaExtensions returns the typed schema extensions in registration order.
+func SchemaExtensions() []SchemaExtension {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]SchemaExtension(nil), schemaExtensions...)
+}
@@ -0,0 +1,248 @@
+package resolvers
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+
+	gqlmodels "magento.GO/graphql/models"
+	attributeRepo "magento.GO/model/repository/attribute"
+)
+
+// minPriceRange is the smallest price bucket width (Magento's MIN_RANGE_POWER).
+const minPriceRange = 10
+
+// buildAggregations computes layered navigation buckets over the full (unpaginated) result set:
+// price ranges, categories and every is_filterable attribute. Options with no products are omitted.
+func (r *QueryResolver) buildAggregations(ctx context.Context, items []map[string]interface{}, excludeCategories []uint) *[]*gqlmodels.Aggregation {
+	aggs := make([]*gqlmodels.Aggregation, 0)
+	if len(items) == 0 {
+		return &aggs
+	}
+	storeID := r.storeID(ctx)
+
+	if agg := priceAggregation(items); agg != nil {
+		aggs = append(aggs, agg)
+	}
+	if agg := r.categoryAggregation(storeID, items, excludeCategories); agg != nil {
+		aggs = append(aggs, agg)
+	}
+
+	repo := r.attributeRepo()
+	attrs, err := repo.FilterableAttributes()
+	if err != nil {
+		return &aggs
+	}
+	options, _ := repo.ProductAttributeOptions(storeID)
+	for _, a := range attrs {
+		if a.Code == "price" {
+			continue
+		}
+		if agg := attributeAggregation(a, options[a.AttributeID], items); agg != nil {
+			aggs = append(aggs, agg)
+		}
+	}
+	return &aggs
+}
+
+// priceAggregation buckets final prices using Magento's automatic range: the largest power of ten
+// below the max price, shrunk until at least two buckets exist (never below minPriceRange).
+func priceAggregation(items []map[string]interface{}) *gqlmodels.Aggregation {
+	prices := make([]priceCount, 0, len(items))
+	for _, p := range items {
+		if p["price"] == nil && p["final_price"] == nil {
+			continue
+		}
+		prices = append(prices, priceCount{price: productFinalPrice(p), count: 1})
+	}
+	return priceRangeAggregation(prices)
+}
+
+// priceCount is a final price and the number of products at it.
+type priceCount struct {
+	price float64
+	count int32
+}
+
+// priceRangeAggregation is priceAggregation over counted prices. Any price of a minPriceRange wide
+// bucket (e.g. an Elasticsearch histogram key) stands for the bucket, as every range is a multiple of it.
+func priceRangeAggregation(prices []priceCount) *gqlmodels.Aggregation {
+	if len(prices) == 0 {
+		return nil
+	}
+	maxPrice := 0.0
+	for _, pc := range prices {
+		if pc.price > maxPrice {
+			maxPrice = pc.price
+		}
+	}
+
+	digits := len(strconv.FormatFloat(math.Floor(maxPrice), 'f', 0, 64))
+	var step float64
+	var counts map[int]int32
+	for index := 1; ; index++ {
+		step = math.Pow(10, float64(digits-index))
+		counts = make(map[int]int32)
+		for _, pc := range prices {
+			counts[int(math.Floor(pc.price/step))] += pc.count
+		}
+		if step <= minPriceRange || len(counts) >= 2 {
+			break
+		}
+	}
+	if step < minPriceRange {
+		step = minPriceRange
+		counts = make(map[int]int32)
+		for _, pc := range prices {
+			counts[int(math.Floor(pc.price/step))] += pc.count
+		}
+	}
+
+	buckets := make([]int, 0, len(counts))
+	for b := range counts {
+		buckets = append(buckets, b)
+	}
+	sort.Ints(buckets)
+	opts := make([]*gqlmodels.AggregationOption, len(buckets))
+	for i, b := range buckets {
+		from := formatPrice(float64(b) * step)
+		to := formatPrice(float64(b+1) * step)
+		opts[i] = newAggregationOption(from+"-"+to, from+"_"+to, counts[b])
+	}
+	return newAggregation("price", "Price", opts)
+}
+
+// categoryAggregation counts products per assigned category, skipping root categories
+// and the categories the listing is already filtered by.
+func (r *QueryResolver) categoryAggregation(storeID uint16, items []map[string]interface{}, exclude []uint) *gqlmodels.Aggregation {
+	counts := make(map[uint]int32)
+	for _, p := range items {
+		ids, _ := p["category_ids"].([]uint)
+		for _, id := range ids {
+			counts[id]++
+		}
+	}
+	return r.categoryCountAggregation(storeID, counts, exclude)
+}
+
+// categoryCountAggregation labels product counts per category ID; see categoryAggregation.
+func (r *QueryResolver) categoryCountAggregation(storeID uint16, counts map[uint]int32, exclude []uint) *gqlmodels.Aggregation {
+	if len(counts) == 0 {
+		return nil
+	}
+	for _, id := range exclude {
+		delete(counts, id)
+	}
+	cats, err := r.categoryRepo().FetchAllWithAttributesMap(storeID)
+	if err != nil {
+		return nil
+	}
+
+	type entry struct {
+		id       uint
+		position int
+		label    string
+	}
+	entries := make([]entry, 0, len(counts))
+	for id := range counts {
+		cat, ok := cats[id]
+		if !ok || cat.Level < 2 {
+			continue
+		}
+		label := ""
+		if a, ok := cat.Attributes["name"]; ok {
+			label, _ = a["value"].(string)
+		}
+		entries = append(entries, entry{id: id, position: cat.Position, label: label})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].position != entries[j].position {
+			return entries[i].position < entries[j].position
+		}
+		return entries[i].id < entries[j].id
+	})
+	opts := make([]*gqlmodels.AggregationOption, len(entries))
+	for i, e := range entries {
+		opts[i] = newAggregationOption(e.label, uidEncode(e.id), counts[e.id])
+	}
+	return newAggregation("category_uid", "Category", opts)
+}
+
+// attributeAggregation counts products per attribute value. Select/multiselect values are option IDs
+// labelled from eav_attribute_option_value and ordered by sort_order; other values label themselves.
+func attributeAggregation(a attributeRepo.ProductAttribute, options []attributeRepo.AttributeOption, items []map[string]interface{}) *gqlmodels.Aggregation {
+	counts := make(map[string]int32)
+	var seen []string
+	for _, p := range items {
+		v, ok := p[a.Code]
+		if !ok || v == nil {
+			continue
+		}
+		for _, s := range aggregationValues(v) {
+			if s == "" {
+				continue
+			}
+			if _, ok := counts[s]; !ok {
+				seen = append(seen, s)
+			}
+			counts[s]++
+		}
+	}
+	return attributeCountAggregation(a, options, counts, seen)
+}
+
+// attributeCountAggregation labels product counts per attribute value; seen lists the values
+// without an option, which follow the options in sorted order. See attributeAggregation.
+func attributeCountAggregation(a attributeRepo.ProductAttribute, options []attributeRepo.AttributeOption, counts map[string]int32, seen []string) *gqlmodels.Aggregation {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	opts := make([]*gqlmodels.AggregationOption, 0, len(counts))
+	done := make(map[string]bool, len(counts))
+	for _, o := range options {
+		value := strconv.FormatUint(uint64(o.OptionID), 10)
+		if c, ok := counts[value]; ok {
+			opts = append(opts, newAggregationOption(o.Label, value, c))
+			done[value] = true
+		}
+	}
+	sort.Strings(seen)
+	for _, s := range seen {
+		if !done[s] {
+			opts = append(opts, newAggregationOption(s, s, counts[s]))
+		}
+	}
+	label := a.Label
+	if label == "" {
+		label = a.Code
+	}
+	return newAggregation(a.Code, label, opts)
+}
+
+// aggregationValues splits a flat value into the values it counts towards; multiselect
+// values ("12,34") count once per option.
+func aggregationValues(v interface{}) []string {
+	vals := flatValueStrings(v)
+	if s, ok := v.(string); ok && len(vals) > 1 && vals[0] == s {
+		return vals[1:]
+	}
+	return vals
+}
+
+func newAggregation(code, label string, opts []*gqlmodels.AggregationOption) *gqlmodels.Aggregation {
+	if len(opts) == 0 {
+		return nil
+	}
+	count := int32(len(opts))
+	return &gqlmodels.Aggregation{AttributeCode: code, Label: &label, Count: &count, Options: &opts}
+}
+
+func newAggregationOption(label, value string, count int32) *gqlmodels.AggregationOption {
+	return &gqlmodels.AggregationOption{Label: &label, Value: value, Count: &count}
+}
+
+func formatPrice(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
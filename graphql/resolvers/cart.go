@@ -0,0 +1,339 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"magento.GO/graphql"
+	gqlmodels "magento.GO/graphql/models"
+	quoteEntity "magento.GO/model/entity/quote"
+	quoteRepo "magento.GO/model/repository/quote"
+)
+
+// Magento's CartUserInputErrorType values.
+const (
+	cartErrorProductNotFound   = "PRODUCT_NOT_FOUND"
+	cartErrorNotSalable        = "NOT_SALABLE"
+	cartErrorInsufficientStock = "INSUFFICIENT_STOCK"
+	cartErrorUndefined         = "UNDEFINED"
+)
+
+// maskedCartIDLength is the length of Magento's masked cart IDs; client-chosen IDs must match it.
+const maskedCartIDLength = 32
+
+func (r *QueryResolver) quoteRepo() *quoteRepo.QuoteRepository {
+	return quoteRepo.GetQuoteRepository(r.db)
+}
+
+func cartNotFound(cartID string) error {
+	return fmt.Errorf("could not find a cart with ID %q", cartID)
+}
+
+// findCart returns the active cart with the given masked ID. A customer's cart is only returned to
+// that customer and a guest cart only to guests, as in Magento's GetCartForUser.
+func (r *QueryResolver) findCart(ctx context.Context, cartID string) (*quoteEntity.Quote, error) {
+	customerID, _ := graphql.CustomerIDFromContext(ctx)
+	q, err := r.quoteRepo().FindByMaskedID(cartID, customerID)
+	if err != nil {
+		if errors.Is(err, quoteRepo.ErrCartNotFound) {
+			return nil, cartNotFound(cartID)
+		}
+		if errors.Is(err, quoteRepo.ErrCartNotOwned) {
+			return nil, fmt.Errorf("the current user cannot perform operations on cart %q", cartID)
+		}
+		return nil, errors.New("unable to load the cart")
+	}
+	return q, nil
+}
+
+// Cart returns the guest cart with the given masked ID.
+func (r *QueryResolver) Cart(ctx context.Context, args graphql.CartArgs) (*gqlmodels.Cart, error) {
+	q, err := r.findCart(ctx, args.CartID)
+	if err != nil {
+		return nil, err
+	}
+	return r.cartModel(ctx, args.CartID, q), nil
+}
+
+// cartModel maps a quote and its items to Cart. Items whose product no longer exists are left out.
+func (r *QueryResolver) cartModel(ctx context.Context, cartID string, q *quoteEntity.Quote) *gqlmodels.Cart {
+	currency := ""
+	if q.QuoteCurrencyCode != nil {
+		currency = *q.QuoteCurrencyCode
+	}
+	money := func(v float64) gqlmodels.Money { return gqlmodels.Money{Currency: currency, Value: v} }
+	moneyPtr := func(v float64) *gqlmodels.Money { m := money(v); return &m }
+
+	cart := &gqlmodels.Cart{
+		ID:            cartID,
+		TotalQuantity: q.ItemsQty,
+		IsVirtual:     q.IsVirtual == 1,
+		Prices: &gqlmodels.CartPrices{
+			GrandTotal:                       moneyPtr(q.GrandTotal),
+			SubtotalExcludingTax:             moneyPtr(q.Subtotal),
+			SubtotalIncludingTax:             moneyPtr(q.Subtotal),
+			SubtotalWithDiscountExcludingTax: moneyPtr(q.SubtotalWithDiscount),
+		},
+	}
+	items := []*gqlmodels.CartItem{}
+	cart.Items = &items
+	quoteItems, err := r.quoteRepo().Items(q.EntityID)
+	if err != nil || len(quoteItems) == 0 {
+		return cart
+	}
+	ids := make([]uint, 0, len(quoteItems))
+	for _, item := range quoteItems {
+		if item.ProductID != nil {
+			ids = append(ids, *item.ProductID)
+		}
+	}
+	flat, _ := r.loaders(ctx).Products(r.storeID(ctx)).LoadMany(ids)
+	groupID := r.customerGroupID(ctx)
+	var flatItems []map[string]interface{}
+	for _, id := range ids {
+		if p, ok := flat[id]; ok {
+			flatItems = append(flatItems, filterPriceForGroup(p, groupID))
+		}
+	}
+	products := make(map[uint]*gqlmodels.ProductInterface, len(flatItems))
+	for _, pi := range r.toProductInterfaces(ctx, flatItems, "", false) {
+		products[uint(pi.ID)] = pi
+	}
+	for _, item := range quoteItems {
+		if item.ProductID == nil || products[*item.ProductID] == nil {
+			continue
+		}
+		rowTotalInclTax := item.RowTotal
+		if item.RowTotalInclTax != nil {
+			rowTotalInclTax = *item.RowTotalInclTax
+		}
+		items = append(items, &gqlmodels.CartItem{
+			ID:       strconv.FormatUint(uint64(item.ItemID), 10),
+			UID:      uidEncode(item.ItemID),
+			Quantity: item.Qty,
+			Product:  products[*item.ProductID],
+			Prices: &gqlmodels.CartItemPrices{
+				Price:                money(item.Price),
+				RowTotal:             money(item.RowTotal),
+				RowTotalIncludingTax: money(rowTotalInclTax),
+			},
+			Virtual: item.IsVirtual != nil && *item.IsVirtual == 1,
+		})
+	}
+	return cart
+}
+
+// CreateEmptyCart creates a cart in the current store view and display currency: a guest cart, or
+// for a logged-in customer one owned by them and priced for their group. A client may choose the
+// 32-character masked ID itself.
+func (m *MutationResolver) CreateEmptyCart(ctx context.Context, args graphql.CreateEmptyCartArgs) (*string, error) {
+	maskedID := ""
+	if args.Input != nil && args.Input.CartID != nil {
+		maskedID = *args.Input.CartID
+		if len(maskedID) != maskedCartIDLength {
+			return nil, fmt.Errorf("cart ID length should be %d characters", maskedCartIDLength)
+		}
+	}
+	cur := m.currency(ctx)
+	storeID := m.storeRepo().ResolveStoreID(m.storeID(ctx))
+	customerID, _ := graphql.CustomerIDFromContext(ctx)
+	created, err := m.quoteRepo().CreateCart(storeID, customerID, m.customerGroupID(ctx), quoteRepo.Currency{Base: cur.Base, Quote: cur.Display, Rate: cur.Rate}, maskedID)
+	if err != nil {
+		if errors.Is(err, quoteRepo.ErrMaskedIDTaken) {
+			return nil, fmt.Errorf("cart with ID %q already exists", maskedID)
+		}
+		return nil, errors.New("unable to create the cart")
+	}
+	return &created, nil
+}
+
+// AddProductsToCart adds simple and virtual products to a guest cart. The price comes from the price
+// repository for the cart's customer group and the stock from the inventory repository, each read
+// once for all items; products that cannot be added are reported in user_errors while the others
+// are still added.
+func (m *MutationResolver) AddProductsToCart(ctx context.Context, args graphql.AddProductsToCartArgs) (*gqlmodels.AddProductsToCartOutput, error) {
+	q, err := m.findCart(ctx, args.CartID)
+	if err != nil {
+		return nil, err
+	}
+	loaders := m.loaders(ctx)
+	skus := make([]string, 0, len(args.CartItems))
+	for _, in := range args.CartItems {
+		if in != nil {
+			skus = append(skus, in.Sku)
+		}
+	}
+	entities, err := loaders.ProductsBySKU().LoadMany(skus)
+	if err != nil {
+		return nil, errors.New("unable to load products")
+	}
+	ids := make([]uint, 0, len(entities))
+	for _, product := range entities {
+		ids = append(ids, product.EntityID)
+	}
+	flat, err := loaders.Products(q.StoreID).LoadMany(ids)
+	if err != nil {
+		return nil, errors.New("unable to load products")
+	}
+	prices, err := loaders.Prices(m.storeRepo().WebsiteID(q.StoreID), q.CustomerGroupID).LoadMany(skus)
+	if err != nil {
+		return nil, errors.New("unable to load prices")
+	}
+	stock, err := loaders.Stock().LoadMany(skus)
+	if err != nil {
+		return nil, errors.New("unable to load stock")
+	}
+	userErrors := []*gqlmodels.CartUserInputError{}
+	addError := func(code, message string) {
+		userErrors = append(userErrors, &gqlmodels.CartUserInputError{Code: code, Message: message})
+	}
+	for _, in := range args.CartItems {
+		if in == nil {
+			continue
+		}
+		if in.Quantity <= 0 {
+			addError(cartErrorUndefined, "The product quantity should be greater than 0")
+			continue
+		}
+		if (in.SelectedOptions != nil && len(*in.SelectedOptions) > 0) || (in.EnteredOptions != nil && len(*in.EnteredOptions) > 0) {
+			addError(cartErrorUndefined, fmt.Sprintf("Product options of %q cannot be added to the cart yet", in.Sku))
+			continue
+		}
+		product, ok := entities[in.Sku]
+		if !ok {
+			addError(cartErrorProductNotFound, fmt.Sprintf("Could not find a product with SKU %q", in.Sku))
+			continue
+		}
+		if product.TypeID != "simple" && product.TypeID != "virtual" {
+			addError(cartErrorUndefined, fmt.Sprintf("Product type %q of %q cannot be added to the cart yet", product.TypeID, in.Sku))
+			continue
+		}
+		p, ok := flat[product.EntityID]
+		if !ok || toUint(p["status"]) == productStatusDisabled {
+			addError(cartErrorNotSalable, fmt.Sprintf("Product %q is not available", in.Sku))
+			continue
+		}
+		basePrice, ok := prices[in.Sku]
+		if !ok {
+			addError(cartErrorNotSalable, fmt.Sprintf("Product %q has no price", in.Sku))
+			continue
+		}
+		qty := in.Quantity
+		if existing, err := m.quoteRepo().FindItemBySKU(q.EntityID, in.Sku); err == nil && existing != nil {
+			qty += existing.Qty
+		}
+		if !stock[in.Sku].IsSalable(qty) {
+			addError(cartErrorInsufficientStock, fmt.Sprintf("The requested qty of %q is not available", in.Sku))
+			continue
+		}
+		name, _ := p["name"].(string)
+		weight, _ := toFloat(p["weight"])
+		err := m.quoteRepo().AddItem(q, quoteRepo.NewItem{
+			ProductID:   product.EntityID,
+			SKU:         product.SKU,
+			Name:        name,
+			ProductType: product.TypeID,
+			IsVirtual:   product.TypeID == "virtual",
+			Weight:      weight,
+			Qty:         in.Quantity,
+			BasePrice:   basePrice,
+		})
+		if errors.Is(err, quoteRepo.ErrCartNotFound) {
+			return nil, cartNotFound(args.CartID)
+		}
+		if err != nil {
+			addError(cartErrorUndefined, fmt.Sprintf("Unable to add %q to the cart", in.Sku))
+		}
+	}
+	return &gqlmodels.AddProductsToCartOutput{Cart: m.cartModel(ctx, args.CartID, q), UserErrors: userErrors}, nil
+}
+
+// cartItemID returns the quote_item ID from cart_item_uid or, for older clients, cart_item_id.
+func cartItemID(id *int32, uid *string) (uint, bool) {
+	if uid != nil {
+		return uidDecode(*uid)
+	}
+	if id != nil && *id > 0 {
+		return uint(*id), true
+	}
+	return 0, false
+}
+
+// UpdateCartItems sets the quantities of guest cart items after checking stock; a quantity of 0
+// removes the item.
+func (m *MutationResolver) UpdateCartItems(ctx context.Context, args graphql.UpdateCartItemsArgs) (*gqlmodels.UpdateCartItemsOutput, error) {
+	if args.Input == nil {
+		return nil, errors.New(`required parameter "cart_id" is missing`)
+	}
+	q, err := m.findCart(ctx, args.Input.CartID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := m.quoteRepo().Items(q.EntityID)
+	if err != nil {
+		return nil, errors.New("unable to load the cart items")
+	}
+	skus := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.SKU != nil {
+			skus = append(skus, *item.SKU)
+		}
+	}
+	stock, err := m.loaders(ctx).Stock().LoadMany(skus)
+	if err != nil {
+		return nil, errors.New("unable to load stock")
+	}
+	for _, in := range args.Input.CartItems {
+		if in == nil {
+			continue
+		}
+		itemID, ok := cartItemID(in.CartItemID, in.CartItemUID)
+		if !ok {
+			return nil, errors.New(`required parameter "cart_item_uid" is missing`)
+		}
+		if in.Quantity == nil {
+			return nil, errors.New(`required parameter "quantity" is missing`)
+		}
+		item, err := m.quoteRepo().FindItem(q.EntityID, itemID)
+		if err != nil {
+			return nil, fmt.Errorf("could not find cart item with ID %d", itemID)
+		}
+		if *in.Quantity > 0 && item.SKU != nil && !stock[*item.SKU].IsSalable(*in.Quantity) {
+			return nil, fmt.Errorf("the requested qty of %q is not available", *item.SKU)
+		}
+		if err := m.quoteRepo().UpdateItemQty(q, itemID, *in.Quantity); err != nil {
+			if errors.Is(err, quoteRepo.ErrCartNotFound) {
+				return nil, cartNotFound(args.Input.CartID)
+			}
+			return nil, errors.New("unable to update the cart item")
+		}
+	}
+	return &gqlmodels.UpdateCartItemsOutput{Cart: m.cartModel(ctx, args.Input.CartID, q)}, nil
+}
+
+// RemoveItemFromCart removes an item from a guest cart.
+func (m *MutationResolver) RemoveItemFromCart(ctx context.Context, args graphql.RemoveItemFromCartArgs) (*gqlmodels.RemoveItemFromCartOutput, error) {
+	if args.Input == nil {
+		return nil, errors.New(`required parameter "cart_id" is missing`)
+	}
+	q, err := m.findCart(ctx, args.Input.CartID)
+	if err != nil {
+		return nil, err
+	}
+	itemID, ok := cartItemID(args.Input.CartItemID, args.Input.CartItemUID)
+	if !ok {
+		return nil, errors.New(`required parameter "cart_item_uid" is missing`)
+	}
+	if err := m.quoteRepo().RemoveItem(q, itemID); err != nil {
+		if errors.Is(err, quoteRepo.ErrCartItemNotFound) {
+			return nil, fmt.Errorf("could not find cart item with ID %d", itemID)
+		}
+		if errors.Is(err, quoteRepo.ErrCartNotFound) {
+			return nil, cartNotFound(args.Input.CartID)
+		}
+		return nil, errors.New("unable to remove the cart item")
+	}
+	return &gqlmodels.RemoveItemFromCartOutput{Cart: m.cartModel(ctx, args.Input.CartID, q)}, nil
+}
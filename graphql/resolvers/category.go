@@ -7,6 +7,7 @@ import (
 	categoryEntity "magento.GO/model/entity/category"
 	categoryRepoModel "magento.GO/model/repository/category"
 
+	"magento.GO/graphql"
 	gqlmodels "magento.GO/graphql/models"
 )
 
@@ -15,6 +16,7 @@ func (r *QueryResolver) Categories(ctx context.Context) ([]*gqlmodels.Category,
 	if err != nil {
 		return nil, err
 	}
+	graphql.AddCacheTags(ctx, graphql.CategoryListTag)
 	result := make([]*gqlmodels.Category, len(cats))
 	for i, c := range cats {
 		result[i] = categoryToGraphQL(&c)
@@ -27,11 +29,12 @@ func (r *QueryResolver) Category(ctx context.Context, args struct{ ID string })
 	if err != nil {
 		return nil, nil
 	}
-	cats, err := r.categoryRepo().GetByIDsWithAttributes([]uint{uint(idUint)}, r.storeID(ctx))
-	if err != nil || len(cats) == 0 {
+	c, ok, err := r.loaders(ctx).Categories(r.storeID(ctx)).Load(uint(idUint))
+	if err != nil || !ok {
 		return nil, nil
 	}
-	return categoryToGraphQL(&cats[0]), nil
+	tagCategories(ctx, c.EntityID)
+	return categoryToGraphQLWithAttrs(&c.Category, c.Attributes), nil
 }
 
 func (r *QueryResolver) CategoryTree(ctx context.Context) ([]*gqlmodels.Category, error) {
@@ -39,6 +42,7 @@ func (r *QueryResolver) CategoryTree(ctx context.Context) ([]*gqlmodels.Category
 	if err != nil {
 		return nil, err
 	}
+	graphql.AddCacheTags(ctx, graphql.CategoryListTag)
 	return categoryTreeToGraphQL(tree), nil
 }
 
@@ -0,0 +1,158 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+
+	gqlmodels "magento.GO/graphql/models"
+	currencyRepo "magento.GO/model/repository/currency"
+	productRepo "magento.GO/model/repository/product"
+)
+
+// loadCompositeChildren loads the bundle selection and grouped item products of the given flat
+// products in one batch, keyed by entity_id, with guest prices applied.
+func (r *QueryResolver) loadCompositeChildren(ctx context.Context, composites []map[string]interface{}) map[uint]map[string]interface{} {
+	var ids []uint
+	for _, p := range composites {
+		if options, ok := p["bundle_options"].([]map[string]interface{}); ok {
+			for _, o := range options {
+				sels, _ := o["selections"].([]map[string]interface{})
+				for _, s := range sels {
+					ids = append(ids, toUint(s["product_id"]))
+				}
+			}
+		}
+		if items, ok := p["grouped_items"].([]map[string]interface{}); ok {
+			for _, item := range items {
+				ids = append(ids, toUint(item["product_id"]))
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return map[uint]map[string]interface{}{}
+	}
+	children, err := r.loaders(ctx).Products(r.storeID(ctx)).LoadMany(ids)
+	if err != nil {
+		return map[uint]map[string]interface{}{}
+	}
+	tagProducts(ctx, ids...)
+	for id, child := range children {
+		children[id] = filterPriceForGroup(child, r.customerGroupID(ctx))
+	}
+	return children
+}
+
+// attachBundle resolves a bundle as BundleProduct, pricing it from its selections.
+func attachBundle(pi *gqlmodels.ProductInterface, p map[string]interface{}, children map[uint]map[string]interface{}, baseURL string, cur currencyRepo.Converter) {
+	dynamic := toUint(p["price_type"]) == productRepo.BundlePriceDynamic
+	options, _ := p["bundle_options"].([]map[string]interface{})
+	items := make([]*gqlmodels.BundleItem, 0, len(options))
+	for _, o := range options {
+		optionID := toUint(o["option_id"])
+		sels, _ := o["selections"].([]map[string]interface{})
+		values := make([]*gqlmodels.BundleItemOption, 0, len(sels))
+		for _, s := range sels {
+			child, ok := children[toUint(s["product_id"])]
+			if !ok || toUint(child["status"]) == productStatusDisabled {
+				continue
+			}
+			product := flatToMagentoProduct(child, baseURL, cur)
+			id := int32(toUint(s["selection_id"]))
+			qty, _ := toFloat(s["qty"])
+			position := int32(toUint(s["position"]))
+			isDefault, _ := s["is_default"].(bool)
+			canChange, _ := s["can_change_qty"].(bool)
+			price, priceType := product.PriceRange.MinimumPrice.FinalPrice.Value, "DYNAMIC"
+			if !dynamic {
+				price, _ = toFloat(s["price_value"])
+				priceType = "FIXED"
+				if toUint(s["price_type"]) == productRepo.SelectionPricePercent {
+					priceType = "PERCENT"
+				} else {
+					price = cur.Convert(price)
+				}
+			}
+			values = append(values, &gqlmodels.BundleItemOption{
+				ID:                &id,
+				UID:               bundleUID(optionID, toUint(s["selection_id"]), strconv.FormatFloat(qty, 'f', -1, 64)),
+				Label:             product.Name,
+				Quantity:          &qty,
+				Position:          &position,
+				IsDefault:         &isDefault,
+				Price:             &price,
+				PriceType:         &priceType,
+				CanChangeQuantity: &canChange,
+				Product:           product,
+			})
+		}
+		oid := int32(optionID)
+		title, _ := o["title"].(string)
+		required, _ := o["required"].(bool)
+		optionType, _ := o["type"].(string)
+		position := int32(toUint(o["position"]))
+		items = append(items, &gqlmodels.BundleItem{
+			OptionID: &oid,
+			UID:      bundleUID(optionID, 0, ""),
+			Title:    &title,
+			Required: &required,
+			Type:     &optionType,
+			Position: &position,
+			SKU:      &pi.SKU,
+			Options:  &values,
+		})
+	}
+
+	minPrice, maxPrice := productRepo.BundlePriceRange(p, func(productID uint) (float64, bool) {
+		child, ok := children[productID]
+		if !ok || toUint(child["status"]) == productStatusDisabled {
+			return 0, false
+		}
+		return productFinalPrice(child), true
+	})
+	if minPrice > 0 || maxPrice > 0 {
+		pi.PriceRange = gqlmodels.PriceRange{MinimumPrice: bundlePrice(minPrice, cur), MaximumPrice: bundlePrice(maxPrice, cur)}
+	}
+	pi.Bundle = &gqlmodels.BundleProduct{MagentoProduct: pi.MagentoProduct, DynamicPrice: &dynamic, Items: &items}
+}
+
+// attachGrouped resolves a grouped product as GroupedProduct. A grouped product has no price of its
+// own, so its price range spans its children.
+func attachGrouped(pi *gqlmodels.ProductInterface, p map[string]interface{}, children map[uint]map[string]interface{}, baseURL string, cur currencyRepo.Converter) {
+	linked, _ := p["grouped_items"].([]map[string]interface{})
+	items := make([]*gqlmodels.GroupedProductItem, 0, len(linked))
+	products := make([]*gqlmodels.MagentoProduct, 0, len(linked))
+	for _, l := range linked {
+		child, ok := children[toUint(l["product_id"])]
+		if !ok || toUint(child["status"]) == productStatusDisabled {
+			continue
+		}
+		product := flatToMagentoProduct(child, baseURL, cur)
+		position := int32(toUint(l["position"]))
+		qty, _ := toFloat(l["qty"])
+		items = append(items, &gqlmodels.GroupedProductItem{Position: &position, Qty: &qty, Product: product})
+		products = append(products, product)
+	}
+	if pi.PriceRange.MaximumPrice.RegularPrice.Value == 0 {
+		pi.PriceRange = childPriceRange(products, pi.PriceRange)
+	}
+	pi.Grouped = &gqlmodels.GroupedProduct{MagentoProduct: pi.MagentoProduct, Items: &items}
+}
+
+func bundlePrice(v float64, cur currencyRepo.Converter) gqlmodels.ProductPrice {
+	v = cur.Convert(v)
+	return gqlmodels.ProductPrice{
+		RegularPrice: gqlmodels.Money{Value: v, Currency: cur.Display},
+		FinalPrice:   gqlmodels.Money{Value: v, Currency: cur.Display},
+	}
+}
+
+// bundleUID encodes Magento's bundle UIDs: base64("bundle/<option_id>") for an option and
+// base64("bundle/<option_id>/<selection_id>/<qty>") for a selection.
+func bundleUID(optionID, selectionID uint, qty string) string {
+	s := "bundle/" + strconv.FormatUint(uint64(optionID), 10)
+	if selectionID != 0 {
+		s += "/" + strconv.FormatUint(uint64(selectionID), 10) + "/" + qty
+	}
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
@@ -0,0 +1,224 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+
+	gqlmodels "magento.GO/graphql/models"
+	attributeRepo "magento.GO/model/repository/attribute"
+)
+
+const productStatusDisabled = 2
+
+// attachConfigurable resolves a configurable parent as ConfigurableProduct. A parent without its own
+// price is priced from its most expensive variant.
+func attachConfigurable(pi *gqlmodels.ProductInterface, cp *gqlmodels.ConfigurableProduct) {
+	if cp == nil {
+		cp = &gqlmodels.ConfigurableProduct{}
+	}
+	if pi.PriceRange.MaximumPrice.RegularPrice.Value == 0 && cp.Variants != nil {
+		pi.PriceRange = variantPriceRange(*cp.Variants, pi.PriceRange)
+	}
+	cp.MagentoProduct = pi.MagentoProduct
+	pi.Configurable = cp
+}
+
+// loadConfigurableProducts builds variants and configurable_options for the given parents from
+// catalog_product_super_link / catalog_product_super_attribute. Children are loaded through
+// ProductRepository in one batch; disabled children and children missing a super attribute value are skipped.
+func (r *QueryResolver) loadConfigurableProducts(ctx context.Context, parentIDs []uint, baseURL string) map[uint]*gqlmodels.ConfigurableProduct {
+	result := make(map[uint]*gqlmodels.ConfigurableProduct, len(parentIDs))
+	storeID := r.storeID(ctx)
+	cur := r.currency(ctx)
+	repo := r.productRepo()
+
+	childIDs, err := repo.FetchConfigurableChildIDs(parentIDs)
+	if err != nil {
+		return result
+	}
+	superAttrs, err := repo.FetchConfigurableAttributes(parentIDs)
+	if err != nil {
+		return result
+	}
+	var allChildIDs []uint
+	for _, ids := range childIDs {
+		allChildIDs = append(allChildIDs, ids...)
+	}
+	children := map[uint]map[string]interface{}{}
+	if len(allChildIDs) > 0 {
+		if children, err = r.loaders(ctx).Products(storeID).LoadMany(allChildIDs); err != nil {
+			return result
+		}
+		tagProducts(ctx, allChildIDs...)
+	}
+
+	attrRepo := r.attributeRepo()
+	attrs, _ := attrRepo.ProductAttributes()
+	attrByID := make(map[uint16]attributeRepo.ProductAttribute, len(attrs))
+	for _, a := range attrs {
+		attrByID[a.AttributeID] = a
+	}
+	options, _ := attrRepo.ProductAttributeOptions(storeID)
+	swatches, _ := attrRepo.OptionSwatches(storeID)
+
+	for _, parentID := range parentIDs {
+		supers := superAttrs[parentID]
+		used := make(map[uint16]map[uint32]bool, len(supers))
+		variants := make([]*gqlmodels.ConfigurableVariant, 0, len(childIDs[parentID]))
+
+	children:
+		for _, childID := range childIDs[parentID] {
+			child, ok := children[childID]
+			if !ok || toUint(child["status"]) == productStatusDisabled {
+				continue
+			}
+			variantAttrs := make([]*gqlmodels.ConfigurableAttributeOption, 0, len(supers))
+			for _, sa := range supers {
+				attr, ok := attrByID[sa.AttributeID]
+				if !ok {
+					continue children
+				}
+				valueIndex := uint32(toUint(child[attr.Code]))
+				if valueIndex == 0 {
+					continue children
+				}
+				if used[sa.AttributeID] == nil {
+					used[sa.AttributeID] = make(map[uint32]bool)
+				}
+				used[sa.AttributeID][valueIndex] = true
+				code := attr.Code
+				vi := int32(valueIndex)
+				variantAttrs = append(variantAttrs, &gqlmodels.ConfigurableAttributeOption{
+					Code:       &code,
+					Label:      optionLabel(options[sa.AttributeID], valueIndex),
+					ValueIndex: &vi,
+					UID:        configurableUID(uint(sa.AttributeID), uint(valueIndex)),
+				})
+			}
+			child = filterPriceForGroup(child, r.customerGroupID(ctx))
+			variants = append(variants, &gqlmodels.ConfigurableVariant{
+				Attributes: &variantAttrs,
+				Product:    flatToMagentoProduct(child, baseURL, cur),
+			})
+		}
+
+		configurableOptions := make([]*gqlmodels.ConfigurableProductOptions, 0, len(supers))
+		for _, sa := range supers {
+			attr, ok := attrByID[sa.AttributeID]
+			if !ok {
+				continue
+			}
+			values := make([]*gqlmodels.ConfigurableProductOptionsValues, 0)
+			for _, o := range options[sa.AttributeID] {
+				if !used[sa.AttributeID][o.OptionID] {
+					continue
+				}
+				values = append(values, configurableOptionValue(sa.AttributeID, o, swatches, baseURL))
+			}
+			id := int32(sa.ProductSuperAttributeID)
+			attributeID := strconv.FormatUint(uint64(sa.AttributeID), 10)
+			code := attr.Code
+			label := attr.Label
+			position := int32(sa.Position)
+			configurableOptions = append(configurableOptions, &gqlmodels.ConfigurableProductOptions{
+				ID:            &id,
+				AttributeID:   &attributeID,
+				AttributeUID:  uidEncode(uint(sa.AttributeID)),
+				AttributeCode: &code,
+				Label:         &label,
+				Position:      &position,
+				UID:           configurableUID(sa.ProductSuperAttributeID),
+				Values:        &values,
+			})
+		}
+
+		result[parentID] = &gqlmodels.ConfigurableProduct{
+			Variants:            &variants,
+			ConfigurableOptions: &configurableOptions,
+		}
+	}
+	return result
+}
+
+func configurableOptionValue(attributeID uint16, o attributeRepo.AttributeOption, swatches map[uint32]attributeRepo.OptionSwatch, baseURL string) *gqlmodels.ConfigurableProductOptionsValues {
+	vi := int32(o.OptionID)
+	label := o.Label
+	defaultLabel := o.DefaultLabel
+	useDefault := o.Label == o.DefaultLabel
+	uid := configurableUID(uint(attributeID), uint(o.OptionID))
+	v := &gqlmodels.ConfigurableProductOptionsValues{
+		ValueIndex:      &vi,
+		Label:           &label,
+		DefaultLabel:    &defaultLabel,
+		StoreLabel:      &label,
+		UseDefaultValue: &useDefault,
+		UID:             &uid,
+	}
+	if sw, ok := swatches[o.OptionID]; ok {
+		sd := &gqlmodels.SwatchData{Type: int(sw.Type)}
+		value := sw.Value
+		if sw.Type == gqlmodels.SwatchImage && value != "" {
+			value = swatchURL(baseURL, "swatch_image/30x20", sw.Value)
+			thumb := swatchURL(baseURL, "swatch_thumb/110x90", sw.Value)
+			sd.Thumbnail = &thumb
+		}
+		sd.Value = &value
+		v.SwatchData = sd
+	}
+	return v
+}
+
+// variantPriceRange spans the cheapest to the most expensive variant.
+func variantPriceRange(variants []*gqlmodels.ConfigurableVariant, fallback gqlmodels.PriceRange) gqlmodels.PriceRange {
+	products := make([]*gqlmodels.MagentoProduct, 0, len(variants))
+	for _, v := range variants {
+		if v.Product != nil {
+			products = append(products, v.Product)
+		}
+	}
+	return childPriceRange(products, fallback)
+}
+
+// childPriceRange spans the lowest minimum to the highest maximum final price of the children.
+func childPriceRange(children []*gqlmodels.MagentoProduct, fallback gqlmodels.PriceRange) gqlmodels.PriceRange {
+	if len(children) == 0 {
+		return fallback
+	}
+	pr := children[0].PriceRange
+	for _, c := range children[1:] {
+		if c.PriceRange.MinimumPrice.FinalPrice.Value < pr.MinimumPrice.FinalPrice.Value {
+			pr.MinimumPrice = c.PriceRange.MinimumPrice
+		}
+		if c.PriceRange.MaximumPrice.FinalPrice.Value > pr.MaximumPrice.FinalPrice.Value {
+			pr.MaximumPrice = c.PriceRange.MaximumPrice
+		}
+	}
+	return pr
+}
+
+func optionLabel(options []attributeRepo.AttributeOption, optionID uint32) *string {
+	for _, o := range options {
+		if o.OptionID == optionID {
+			label := o.Label
+			return &label
+		}
+	}
+	return nil
+}
+
+// configurableUID encodes Magento's configurable option UIDs: base64("configurable/<id>/<id>...").
+func configurableUID(ids ...uint) string {
+	s := "configurable"
+	for _, id := range ids {
+		s += "/" + strconv.FormatUint(uint64(id), 10)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func swatchURL(baseURL, size, path string) string {
+	if baseURL == "" {
+		return path
+	}
+	return baseURL + "/media/attribute/swatch/" + size + path
+}
@@ -0,0 +1,47 @@
+package resolvers
+
+import (
+	"context"
+
+	"magento.GO/graphql"
+	gqlmodels "magento.GO/graphql/models"
+	currencyRepo "magento.GO/model/repository/currency"
+)
+
+func (r *QueryResolver) currencyRepo() *currencyRepo.CurrencyRepository {
+	return currencyRepo.GetCurrencyRepository(r.db)
+}
+
+// currency is the price converter of the request: the store's base currency into the display
+// currency requested with Content-Currency, or the store's default display currency.
+func (r *QueryResolver) currency(ctx context.Context) currencyRepo.Converter {
+	return r.currencyRepo().Converter(r.storeID(ctx), graphql.CurrencyFromContext(ctx))
+}
+
+// Currency returns the store's base and default display currencies, the available currencies and
+// their exchange rates from the base currency.
+func (r *QueryResolver) Currency(ctx context.Context) *gqlmodels.Currency {
+	repo := r.currencyRepo()
+	storeID := r.storeID(ctx)
+	base := repo.BaseCurrency(storeID)
+	display := repo.DefaultDisplayCurrency(storeID)
+	baseSymbol, displaySymbol := currencyRepo.Symbol(base), currencyRepo.Symbol(display)
+
+	available := repo.AvailableCurrencies(storeID)
+	codes := make([]*string, 0, len(available))
+	rates := make([]*gqlmodels.ExchangeRate, 0, len(available))
+	for _, code := range available {
+		code := code
+		rate, _ := repo.Rate(base, code)
+		codes = append(codes, &code)
+		rates = append(rates, &gqlmodels.ExchangeRate{CurrencyTo: &code, Rate: &rate})
+	}
+	return &gqlmodels.Currency{
+		AvailableCurrencyCodes:       &codes,
+		BaseCurrencyCode:             &base,
+		BaseCurrencySymbol:           &baseSymbol,
+		DefaultDisplayCurrencyCode:   &display,
+		DefaultDisplayCurrencySymbol: &displaySymbol,
+		ExchangeRates:                &rates,
+	}
+}
@@ -0,0 +1,92 @@
+package resolvers
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	gqlmodels "magento.GO/graphql/models"
+	currencyRepo "magento.GO/model/repository/currency"
+	productRepo "magento.GO/model/repository/product"
+)
+
+// customOptions maps the flat "options" of a product to CustomizableOptionInterface values with
+// their resolved prices in cur's display currency.
+func customOptions(p map[string]interface{}, cur currencyRepo.Converter) *[]*gqlmodels.CustomizableOption {
+	flat, _ := p["options"].([]map[string]interface{})
+	options := make([]*gqlmodels.CustomizableOption, 0, len(flat))
+	for _, o := range flat {
+		optionID := toUint(o["option_id"])
+		optionType, _ := o["type"].(string)
+		id := int32(optionID)
+		required, _ := o["required"].(bool)
+		sortOrder := int32(toUint(o["sort_order"]))
+		base := gqlmodels.CustomizableOptionBase{
+			OptionID:  &id,
+			UID:       customOptionUID(optionID, 0),
+			Title:     optionalString(o["title"]),
+			Required:  &required,
+			SortOrder: &sortOrder,
+		}
+		option := &gqlmodels.CustomizableOption{CustomizableOptionBase: base, Type: optionType}
+		if productRepo.IsSelectOptionType(optionType) {
+			flatValues, _ := o["values"].([]map[string]interface{})
+			values := make([]*gqlmodels.CustomizableValue, 0, len(flatValues))
+			for _, v := range flatValues {
+				optionTypeID := toUint(v["option_type_id"])
+				typeID := int32(optionTypeID)
+				valueSortOrder := int32(toUint(v["sort_order"]))
+				value := customOptionValue(v, cur)
+				value.UID = customOptionUID(optionID, optionTypeID)
+				value.OptionTypeID = &typeID
+				value.Title = optionalString(v["title"])
+				value.SortOrder = &valueSortOrder
+				values = append(values, value)
+			}
+			option.Select = &gqlmodels.CustomizableSelectOption{CustomizableOptionBase: base, Value: &values}
+		} else {
+			value := customOptionValue(o, cur)
+			value.UID = base.UID
+			if _, ok := o["max_characters"]; ok {
+				maxCharacters := int32(toUint(o["max_characters"]))
+				value.MaxCharacters = &maxCharacters
+			}
+			if dateType, ok := customDateTypes[optionType]; ok {
+				value.Type = &dateType
+			}
+			option.Single = &gqlmodels.CustomizableValueOption{CustomizableOptionBase: base, ProductSKU: optionalString(p["sku"]), Value: value}
+		}
+		options = append(options, option)
+	}
+	return &options
+}
+
+var customDateTypes = map[string]string{"date": "DATE", "date_time": "DATE_TIME", "time": "TIME"}
+
+func customOptionValue(v map[string]interface{}, cur currencyRepo.Converter) *gqlmodels.CustomizableValue {
+	price, _ := toFloat(v["price"])
+	price = cur.Convert(price)
+	priceType := productRepo.OptionPriceFixed
+	if t, ok := v["price_type"].(string); ok && t != "" {
+		priceType = t
+	}
+	priceType = strings.ToUpper(priceType)
+	return &gqlmodels.CustomizableValue{Price: &price, PriceType: &priceType, SKU: optionalString(v["sku"])}
+}
+
+// customOptionUID encodes Magento's custom option UIDs: base64("custom-option/<option_id>") for an
+// option and base64("custom-option/<option_id>/<option_type_id>") for a value.
+func customOptionUID(optionID, optionTypeID uint) string {
+	s := "custom-option/" + strconv.FormatUint(uint64(optionID), 10)
+	if optionTypeID > 0 {
+		s += "/" + strconv.FormatUint(uint64(optionTypeID), 10)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func optionalString(v interface{}) *string {
+	if s, ok := v.(string); ok && s != "" {
+		return &s
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"magento.GO/graphql"
+	gqlmodels "magento.GO/graphql/models"
+)
+
+// Entities resolves the entities a federation gateway references by @key, in the order of the
+// representations: Product by sku or entity_id, Category by entity_id. Unknown keys resolve to null.
+// The lookups of all representations are batched through the request's loaders.
+func (r *QueryResolver) Entities(ctx context.Context, args graphql.EntitiesArgs) ([]*gqlmodels.Entity, error) {
+	loaders := r.loaders(ctx)
+	storeID := r.storeID(ctx)
+
+	var skus []string
+	var productIDs, categoryIDs []uint
+	for _, rep := range args.Representations {
+		switch rep.TypeName() {
+		case "Product":
+			if sku, ok := rep.Key("sku"); ok {
+				skus = append(skus, sku)
+			} else if id, ok := entityID(rep); ok {
+				productIDs = append(productIDs, id)
+			}
+		case "Category":
+			if id, ok := entityID(rep); ok {
+				categoryIDs = append(categoryIDs, id)
+			}
+		default:
+			return nil, fmt.Errorf("_entities: unknown entity type %q", rep.TypeName())
+		}
+	}
+
+	bySKU, err := loaders.ProductsBySKU().LoadMany(skus)
+	if err != nil {
+		return nil, errors.New("unable to load products")
+	}
+	for _, p := range bySKU {
+		productIDs = append(productIDs, p.EntityID)
+	}
+	products, err := loaders.Products(storeID).LoadMany(productIDs)
+	if err != nil {
+		return nil, errors.New("unable to load products")
+	}
+	categories, err := loaders.Categories(storeID).LoadMany(categoryIDs)
+	if err != nil {
+		return nil, errors.New("unable to load categories")
+	}
+
+	entities := make([]*gqlmodels.Entity, len(args.Representations))
+	for i, rep := range args.Representations {
+		if rep.TypeName() == "Category" {
+			if id, ok := entityID(rep); ok {
+				if c, ok := categories[id]; ok {
+					tagCategories(ctx, id)
+					entities[i] = &gqlmodels.Entity{Category: categoryToGraphQLWithAttrs(&c.Category, c.Attributes)}
+				}
+			}
+			continue
+		}
+		id, ok := entityID(rep)
+		if sku, isSKU := rep.Key("sku"); isSKU {
+			id, ok = bySKU[sku].EntityID, bySKU[sku].EntityID != 0
+		}
+		if p, found := products[id]; ok && found {
+			tagProducts(ctx, id)
+			entities[i] = &gqlmodels.Entity{Product: r.toProducts(ctx, []map[string]interface{}{p})[0]}
+		}
+	}
+	return entities, nil
+}
+
+// Service returns the subgraph SDL for a federation gateway.
+func (r *QueryResolver) Service() *gqlmodels.Service {
+	sdl := graphql.ServiceSDL()
+	return &gqlmodels.Service{SDL: &sdl}
+}
+
+// entityID returns the entity_id key of a representation.
+func entityID(rep graphql.EntityRepresentation) (uint, bool) {
+	s, ok := rep.Key("entity_id")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(s, 10, 64)
+	return uint(id), err == nil
+}
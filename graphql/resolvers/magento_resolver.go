@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/base64"
 	"strconv"
-	"strings"
+	"time"
+
+	gql "github.com/graph-gophers/graphql-go"
 
 	categoryEntity "magento.GO/model/entity/category"
 
 	"magento.GO/graphql"
 	gqlmodels "magento.GO/graphql/models"
+	currencyRepo "magento.GO/model/repository/currency"
 )
 
 // --- Magento UID helpers ---
@@ -30,7 +33,8 @@ func uidDecode(uid string) (uint, bool) {
 	return uint(n), true
 }
 
-func flatToMagentoProduct(p map[string]interface{}, baseURL string) *gqlmodels.MagentoProduct {
+// flatToMagentoProduct maps a flat product to MagentoProduct with its prices in cur's display currency.
+func flatToMagentoProduct(p map[string]interface{}, baseURL string, cur currencyRepo.Converter) *gqlmodels.MagentoProduct {
 	entityID := uint(toUint(p["entity_id"]))
 	sku := ""
 	if s, ok := p["sku"].(string); ok {
@@ -53,10 +57,11 @@ func flatToMagentoProduct(p map[string]interface{}, baseURL string) *gqlmodels.M
 	if fp, ok := p["final_price"].(float64); ok {
 		finalPrice = fp
 	}
-	discount := price - finalPrice
-	if discount < 0 {
-		discount = 0
+	special, hasSpecial := activeSpecialPrice(p, time.Now())
+	if hasSpecial && special < finalPrice {
+		finalPrice = special
 	}
+	price, finalPrice = cur.Convert(price), cur.Convert(finalPrice)
 
 	stockStatus := "OUT_OF_STOCK"
 	if si, ok := p["stock_item"].(map[string]interface{}); ok {
@@ -78,19 +83,25 @@ func flatToMagentoProduct(p map[string]interface{}, baseURL string) *gqlmodels.M
 		}
 	}
 
+	productPrice := gqlmodels.ProductPrice{
+		FinalPrice:   gqlmodels.Money{Currency: cur.Display, Value: finalPrice},
+		RegularPrice: gqlmodels.Money{Currency: cur.Display, Value: price},
+		Discount:     productDiscount(price, finalPrice),
+	}
 	mp := &gqlmodels.MagentoProduct{
-		ID:            int32(entityID),
-		UID:           uidEncode(entityID),
-		SKU:           sku,
-		StockStatus:   stockStatus,
-		RatingSummary: 0,
+		ID:          int32(entityID),
+		UID:         uidEncode(entityID),
+		SKU:         sku,
+		StockStatus: stockStatus,
 		PriceRange: gqlmodels.PriceRange{
-			MaximumPrice: gqlmodels.ProductPrice{
-				FinalPrice:   gqlmodels.Money{Currency: "USD", Value: finalPrice},
-				RegularPrice: gqlmodels.Money{Currency: "USD", Value: price},
-			},
+			MinimumPrice: productPrice,
+			MaximumPrice: productPrice,
 		},
 	}
+	if hasSpecial {
+		special = cur.Convert(special)
+		mp.SpecialPrice = &special
+	}
 	if name != "" {
 		mp.Name = &name
 	}
@@ -100,12 +111,50 @@ func flatToMagentoProduct(p map[string]interface{}, baseURL string) *gqlmodels.M
 	if imgURL != "" {
 		mp.SmallImage = &gqlmodels.ProductImage{URL: imgURL}
 	}
-	if discount > 0 {
-		mp.PriceRange.MaximumPrice.Discount = &gqlmodels.ProductDiscount{AmountOff: &discount}
-	}
+	mp.Options = customOptions(p, cur)
 	return mp
 }
 
+// toProductInterfaces maps flat products to ProductInterface. Configurable, bundle and grouped
+// products resolve as their Magento types. Configurable variants are loaded for the whole page
+// only when withConfigurable is set; bundle and grouped children are always loaded in one batch.
+func (r *QueryResolver) toProductInterfaces(ctx context.Context, items []map[string]interface{}, baseURL string, withConfigurable bool) []*gqlmodels.ProductInterface {
+	result := make([]*gqlmodels.ProductInterface, len(items))
+	var configurableIDs []uint
+	var composites []map[string]interface{}
+	cur := r.currency(ctx)
+	for i, p := range items {
+		result[i] = &gqlmodels.ProductInterface{MagentoProduct: *flatToMagentoProduct(p, baseURL, cur)}
+		tagProducts(ctx, toUint(p["entity_id"]))
+		switch typeID, _ := p["type_id"].(string); typeID {
+		case "configurable":
+			configurableIDs = append(configurableIDs, toUint(p["entity_id"]))
+		case "bundle", "grouped":
+			composites = append(composites, p)
+		}
+	}
+
+	var configurables map[uint]*gqlmodels.ConfigurableProduct
+	if withConfigurable && len(configurableIDs) > 0 {
+		configurables = r.loadConfigurableProducts(ctx, configurableIDs, baseURL)
+	}
+	children := r.loadCompositeChildren(ctx, composites)
+	r.attachReviews(ctx, result)
+	r.attachPriceTiers(ctx, result, items)
+	r.attachProductLinks(ctx, result, baseURL)
+	for i, pi := range result {
+		switch typeID, _ := items[i]["type_id"].(string); typeID {
+		case "configurable":
+			attachConfigurable(pi, configurables[uint(pi.ID)])
+		case "bundle":
+			attachBundle(pi, items[i], children, baseURL, cur)
+		case "grouped":
+			attachGrouped(pi, items[i], children, baseURL, cur)
+		}
+	}
+	return result
+}
+
 func categoryToCategoryTree(c *categoryEntity.Category, attrs map[string]map[string]interface{}) *gqlmodels.CategoryTree {
 	ct := &gqlmodels.CategoryTree{
 		UID: uidEncode(c.EntityID),
@@ -168,25 +217,23 @@ func (r *QueryResolver) MagentoCategories(ctx context.Context, args *struct {
 		return empty, nil
 	}
 
-	cats, flats, err := r.categoryRepo().GetByIDsWithAttributesAndFlat(ids, r.storeID(ctx))
+	cats, err := r.loaders(ctx).Categories(r.storeID(ctx)).LoadMany(ids)
 	if err != nil || len(cats) == 0 {
 		return empty, nil
 	}
 
-	items := make([]*gqlmodels.CategoryTree, len(cats))
-	for i := range cats {
-		attrs := map[string]map[string]interface{}{}
-		if i < len(flats) {
-			attrs = flats[i]
+	items := make([]*gqlmodels.CategoryTree, 0, len(cats))
+	for _, id := range ids {
+		if c, ok := cats[id]; ok {
+			items = append(items, categoryToCategoryTree(&c.Category, c.Attributes))
+			tagCategories(ctx, id)
+			delete(cats, id)
 		}
-		items[i] = categoryToCategoryTree(&cats[i], attrs)
 	}
 	return &gqlmodels.CategoryResult{Items: items}, nil
 }
 
 func (r *QueryResolver) MagentoProducts(ctx context.Context, args graphql.MagentoProductsArgs) (*gqlmodels.Products, error) {
-	emptyResult := &gqlmodels.Products{Items: []*gqlmodels.MagentoProduct{}, PageInfo: gqlmodels.SearchResultPageInfo{TotalPages: 1}, TotalCount: 0}
-
 	ps := int(args.PageSize)
 	if ps <= 0 {
 		ps = 12
@@ -195,76 +242,34 @@ func (r *QueryResolver) MagentoProducts(ctx context.Context, args graphql.Magent
 	if cp <= 0 {
 		cp = 1
 	}
+	currentPage, pageSize := int32(cp), int32(ps)
+	emptyResult := &gqlmodels.Products{TypedItems: []*gqlmodels.ProductInterface{}, PageInfo: gqlmodels.SearchResultPageInfo{CurrentPage: &currentPage, PageSize: &pageSize, TotalPages: 1}, TotalCount: 0}
 
-	repo := r.productRepo()
-	storeID := r.storeID(ctx)
-
-	var productIDs []uint
-	var categoryID uint
-	if args.Filter != nil && args.Filter.CategoryUID != nil {
-		if args.Filter.CategoryUID.Eq != nil {
-			if id, ok := uidDecode(*args.Filter.CategoryUID.Eq); ok {
-				categoryID = id
-			}
-		}
-		if args.Filter.CategoryUID.In != nil && len(*args.Filter.CategoryUID.In) > 0 {
-			if u := (*args.Filter.CategoryUID.In)[0]; u != nil && *u != "" {
-				if id, ok := uidDecode(*u); ok {
-					categoryID = id
-				}
-			}
-		}
-	}
-
-	asc := true
-	if args.Sort != nil && args.Sort.Position != nil {
-		asc = strings.ToUpper(*args.Sort.Position) != "DESC"
-	}
-
-	if categoryID > 0 {
-		ids, err := repo.FetchProductIDsByCategoryWithPosition(categoryID, asc)
-		if err != nil {
-			return emptyResult, nil
-		}
-		productIDs = ids
-	} else {
-		flat, err := repo.FetchWithAllAttributesFlat(storeID)
-		if err != nil {
-			return emptyResult, nil
-		}
-		for id := range flat {
-			productIDs = append(productIDs, id)
-		}
-	}
-
-	flat, err := repo.FetchWithAllAttributesFlatByIDs(productIDs, storeID)
+	allItems, err := r.listProducts(ctx, productListArgs{filter: args.Filter, sort: args.Sort})
 	if err != nil {
 		return emptyResult, nil
 	}
 
-	allItems := make([]map[string]interface{}, 0, len(productIDs))
-	for _, id := range productIDs {
-		if p, ok := flat[id]; ok {
-			allItems = append(allItems, filterPriceForGuest(p, guestGroupID))
-		}
-	}
-
 	total := len(allItems)
 	items := paginate(allItems, cp, ps)
 
 	baseURL := ""
-	magentoItems := make([]*gqlmodels.MagentoProduct, len(items))
-	for i, p := range items {
-		magentoItems[i] = flatToMagentoProduct(p, baseURL)
-	}
+	withConfigurable := gql.HasSelectedField(ctx, "typed_items.variants") || gql.HasSelectedField(ctx, "typed_items.configurable_options")
+	graphql.AddCacheTags(ctx, graphql.ProductListTag)
+	magentoItems := r.toProductInterfaces(ctx, items, baseURL, withConfigurable)
 
 	totalPages := (total + ps - 1) / ps
 	if totalPages < 1 {
 		totalPages = 1
 	}
-	return &gqlmodels.Products{
-		Items:      magentoItems,
-		PageInfo:   gqlmodels.SearchResultPageInfo{TotalPages: int32(totalPages)},
+	result := &gqlmodels.Products{
+		TypedItems: magentoItems,
+		PageInfo:   gqlmodels.SearchResultPageInfo{CurrentPage: &currentPage, PageSize: &pageSize, TotalPages: int32(totalPages)},
 		TotalCount: int32(total),
-	}, nil
+	}
+	if gql.HasSelectedField(ctx, "aggregations") {
+		categoryIDs, _ := r.filterCategoryIDs(r.storeID(ctx), args.Filter)
+		result.Aggregations = r.buildAggregations(ctx, allItems, categoryIDs)
+	}
+	return result, nil
 }
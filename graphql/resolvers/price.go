@@ -0,0 +1,128 @@
+package resolvers
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	gqlmodels "magento.GO/graphql/models"
+	currencyRepo "magento.GO/model/repository/currency"
+	priceRepo "magento.GO/model/repository/price"
+)
+
+// productDiscount returns the discount of finalPrice off regularPrice, or nil without a discount.
+func productDiscount(regularPrice, finalPrice float64) *gqlmodels.ProductDiscount {
+	amountOff := regularPrice - finalPrice
+	if amountOff <= 0 || regularPrice <= 0 {
+		return nil
+	}
+	percentOff := math.Round(amountOff/regularPrice*10000) / 100
+	return &gqlmodels.ProductDiscount{AmountOff: &amountOff, PercentOff: &percentOff}
+}
+
+// activeSpecialPrice returns the flat product's special_price when today is within its
+// special_from_date and special_to_date (both inclusive, either may be empty).
+func activeSpecialPrice(p map[string]interface{}, now time.Time) (float64, bool) {
+	special, ok := p["special_price"].(float64)
+	if !ok {
+		return 0, false
+	}
+	today := now.Format("2006-01-02")
+	if from, ok := flatDate(p["special_from_date"]); ok && today < from {
+		return 0, false
+	}
+	if to, ok := flatDate(p["special_to_date"]); ok && today > to {
+		return 0, false
+	}
+	return special, true
+}
+
+// flatDate returns the YYYY-MM-DD date of a flat datetime attribute.
+func flatDate(v interface{}) (string, bool) {
+	switch d := v.(type) {
+	case time.Time:
+		if d.IsZero() {
+			return "", false
+		}
+		return d.Format("2006-01-02"), true
+	case string:
+		if len(d) >= 10 {
+			return d[:10], true
+		}
+	}
+	return "", false
+}
+
+// websiteID is the website of the request's store view, which tier prices are filtered by.
+func (r *QueryResolver) websiteID(ctx context.Context) uint16 {
+	return r.storeRepo().WebsiteID(r.storeID(ctx))
+}
+
+// attachPriceTiers lets price_tiers of the products load the tier prices of the whole page in one
+// batch, the first time one of them asks. items are the flat products the products were mapped from.
+func (r *QueryResolver) attachPriceTiers(ctx context.Context, products []*gqlmodels.ProductInterface, items []map[string]interface{}) {
+	if len(products) == 0 {
+		return
+	}
+	ids := make([]uint, len(products))
+	for i, pi := range products {
+		ids[i] = uint(pi.ID)
+	}
+	var once sync.Once
+	var tiers map[uint][]priceRepo.TierPriceResult
+	load := func() map[uint][]priceRepo.TierPriceResult {
+		once.Do(func() {
+			tiers, _ = r.loaders(ctx).TierPrices().LoadMany(ids)
+		})
+		return tiers
+	}
+	cur := r.currency(ctx)
+	websiteID, groupID := r.websiteID(ctx), r.customerGroupID(ctx)
+	for i, pi := range products {
+		id := uint(pi.ID)
+		price, _ := items[i]["price"].(float64)
+		pi.LoadPriceTiers = func() []*gqlmodels.TierPrice {
+			return priceTiers(load()[id], price, websiteID, groupID, cur)
+		}
+	}
+}
+
+// priceTiers returns the tier prices of a product of the given regular price for a website and
+// customer group, by ascending quantity. As in Magento, a quantity keeps its lowest price and a
+// tier is only listed when it is cheaper than the regular price and every smaller quantity.
+func priceTiers(tiers []priceRepo.TierPriceResult, price float64, websiteID uint16, customerGroupID uint, cur currencyRepo.Converter) []*gqlmodels.TierPrice {
+	lowest := make(map[float64]float64)
+	for _, t := range tiers {
+		if !t.AppliesTo(websiteID, customerGroupID) {
+			continue
+		}
+		if v, ok := lowest[t.Qty]; !ok || t.Price(price) < v {
+			lowest[t.Qty] = t.Price(price)
+		}
+	}
+	qtys := make([]float64, 0, len(lowest))
+	for qty := range lowest {
+		qtys = append(qtys, qty)
+	}
+	sort.Float64s(qtys)
+
+	result := []*gqlmodels.TierPrice{}
+	best := price
+	for _, qty := range qtys {
+		tierPrice := lowest[qty]
+		if tierPrice >= best {
+			continue
+		}
+		best = tierPrice
+		qty := qty
+		regular, final := cur.Convert(price), cur.Convert(tierPrice)
+		result = append(result, &gqlmodels.TierPrice{
+			Quantity:   &qty,
+			FinalPrice: &gqlmodels.Money{Currency: cur.Display, Value: final},
+			Discount:   productDiscount(regular, final),
+		})
+	}
+	return result
+}
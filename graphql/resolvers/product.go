@@ -3,14 +3,17 @@ package resolvers
 import (
 	"context"
 
+	"magento.GO/graphql"
 	gqlmodels "magento.GO/graphql/models"
 )
 
 func (r *QueryResolver) Products(ctx context.Context, args struct {
 	PageSize    int32
 	CurrentPage int32
-	Skus       *[]string
-	CategoryID *string
+	Skus        *[]string
+	CategoryID  *string
+	Filter      graphql.ProductAttributeFilter
+	Sort        *graphql.ProductAttributeSortInput
 }) (*gqlmodels.ProductSearchResult, error) {
 	ps := int(args.PageSize)
 	if ps <= 0 {
@@ -21,48 +24,21 @@ func (r *QueryResolver) Products(ctx context.Context, args struct {
 		cp = 1
 	}
 
-	repo := r.productRepo()
-	storeID := r.storeID(ctx)
-
-	var skus []string
+	list := productListArgs{filter: withCategoryID(args.Filter, args.CategoryID), sort: args.Sort}
 	if args.Skus != nil {
-		skus = *args.Skus
+		list.skus = *args.Skus
 	}
 
-	// Resolve IDs by SKU filter
-	var ids []uint
-	if len(skus) > 0 {
-		flat, err := repo.FetchWithAllAttributesFlat(storeID)
-		if err == nil {
-			skuSet := make(map[string]bool, len(skus))
-			for _, s := range skus {
-				skuSet[s] = true
-			}
-			for _, p := range flat {
-				if sku, ok := p["sku"].(string); ok && skuSet[sku] {
-					ids = append(ids, uint(toUint(p["entity_id"])))
-				}
-			}
-		}
-	}
-
-	var flat map[uint]map[string]interface{}
-	var err error
-	if len(ids) > 0 {
-		flat, err = repo.FetchWithAllAttributesFlatByIDs(ids, storeID)
-	} else {
-		flat, err = repo.FetchWithAllAttributesFlat(storeID)
-	}
+	allItems, err := r.listProducts(ctx, list)
 	if err != nil {
 		return nil, err
 	}
-
-	allItems := filterProductsForGuest(flat, guestGroupID)
 	total := len(allItems)
 	items := paginate(allItems, cp, ps)
-	products := make([]*gqlmodels.Product, len(items))
-	for i, p := range items {
-		products[i] = flatToProduct(p)
+	products := r.toProducts(ctx, items)
+	graphql.AddCacheTags(ctx, graphql.ProductListTag)
+	for _, p := range items {
+		tagProducts(ctx, toUint(p["entity_id"]))
 	}
 	totalPages := (total + ps - 1) / ps
 	if totalPages < 1 {
@@ -90,14 +66,33 @@ func (r *QueryResolver) Product(ctx context.Context, args struct {
 	for _, p := range flat {
 		if args.Sku != nil {
 			if s, ok := p["sku"].(string); ok && s == *args.Sku {
-				return flatToProduct(filterPriceForGuest(p, guestGroupID)), nil
+				tagProducts(ctx, toUint(p["entity_id"]))
+				return r.toProducts(ctx, []map[string]interface{}{p})[0], nil
 			}
 		}
 		if args.URLKey != nil {
 			if u, ok := p["url_key"].(string); ok && u == *args.URLKey {
-				return flatToProduct(filterPriceForGuest(p, guestGroupID)), nil
+				tagProducts(ctx, toUint(p["entity_id"]))
+				return r.toProducts(ctx, []map[string]interface{}{p})[0], nil
 			}
 		}
 	}
 	return nil, nil
 }
+
+// toProducts maps flat products to Product with the prices of the request's customer group and,
+// for bundles, the bundle_price_range of the group on the store's website.
+func (r *QueryResolver) toProducts(ctx context.Context, items []map[string]interface{}) []*gqlmodels.Product {
+	groupID := r.customerGroupID(ctx)
+	ranges, _ := r.productRepo().BundlePriceRanges(items, r.websiteID(ctx), groupID)
+	products := make([]*gqlmodels.Product, len(items))
+	for i, p := range items {
+		p = filterPriceForGroup(p, groupID)
+		if priceRange := ranges[toUint(p["entity_id"])]; priceRange != nil {
+			p = copyFlat(p, 1)
+			p["bundle_price_range"] = priceRange
+		}
+		products[i] = flatToProduct(p)
+	}
+	return products
+}
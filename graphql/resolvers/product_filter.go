@@ -0,0 +1,360 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"magento.GO/graphql"
+)
+
+// productListArgs is the listing input shared by products and magentoProducts.
+type productListArgs struct {
+	filter graphql.ProductAttributeFilter
+	sort   *graphql.ProductAttributeSortInput
+	skus   []string
+}
+
+// listProducts loads flat products for the current store, applies guest prices,
+// the Magento attribute filter and sort. It returns every match; callers paginate.
+func (r *QueryResolver) listProducts(ctx context.Context, args productListArgs) ([]map[string]interface{}, error) {
+	repo := r.productRepo()
+	storeID := r.storeID(ctx)
+
+	var positions map[uint]int
+	var flat map[uint]map[string]interface{}
+	var err error
+	if categoryIDs, ok := r.filterCategoryIDs(storeID, args.filter); ok {
+		var ids []uint
+		ids, positions, err = r.categoryProductIDs(categoryIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return []map[string]interface{}{}, nil
+		}
+		flat, err = repo.FetchWithAllAttributesFlatByIDs(ids, storeID)
+	} else {
+		flat, err = repo.FetchWithAllAttributesFlat(storeID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var skuSet map[string]bool
+	if len(args.skus) > 0 {
+		skuSet = make(map[string]bool, len(args.skus))
+		for _, s := range args.skus {
+			skuSet[s] = true
+		}
+	}
+
+	items := make([]map[string]interface{}, 0, len(flat))
+	for _, p := range flat {
+		if skuSet != nil {
+			if sku, _ := p["sku"].(string); !skuSet[sku] {
+				continue
+			}
+		}
+		p = filterPriceForGroup(p, r.customerGroupID(ctx))
+		if matchesProductFilter(p, args.filter) {
+			items = append(items, p)
+		}
+	}
+	sortProducts(items, args.sort, positions, nil)
+	return items, nil
+}
+
+// withCategoryID applies the categoryId argument of products and search, shorthand for
+// filter: { category_id: { eq } }, unless the filter has its own category_id condition.
+func withCategoryID(f graphql.ProductAttributeFilter, categoryID *string) graphql.ProductAttributeFilter {
+	if categoryID == nil || *categoryID == "" {
+		return f
+	}
+	if _, ok := f.Get("category_id"); ok {
+		return f
+	}
+	conditions := make(map[string]graphql.FilterCondition, len(f.Conditions)+1)
+	for code, c := range f.Conditions {
+		conditions[code] = c
+	}
+	conditions["category_id"] = graphql.FilterCondition{Eq: categoryID}
+	return graphql.ProductAttributeFilter{Conditions: conditions}
+}
+
+// filterCategoryIDs resolves category_id, category_uid and category_url_path conditions.
+// ok is false when the filter has no category condition.
+func (r *QueryResolver) filterCategoryIDs(storeID uint16, f graphql.ProductAttributeFilter) ([]uint, bool) {
+	var ids []uint
+	found := false
+	if c, ok := f.Get("category_id"); ok {
+		found = true
+		for _, v := range c.Values() {
+			if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+				ids = append(ids, uint(id))
+			}
+		}
+	}
+	if c, ok := f.Get("category_uid"); ok {
+		found = true
+		for _, v := range c.Values() {
+			if id, ok := uidDecode(v); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if c, ok := f.Get("category_url_path"); ok {
+		found = true
+		paths := c.Values()
+		if len(paths) > 0 {
+			if cats, err := r.categoryRepo().FetchAllWithAttributesMap(storeID); err == nil {
+				for id, cat := range cats {
+					if a, ok := cat.Attributes["url_path"]; ok {
+						if v, ok := a["value"].(string); ok && containsString(paths, v) {
+							ids = append(ids, id)
+						}
+					}
+				}
+			}
+		}
+	}
+	return ids, found
+}
+
+// categoryProductIDs returns the union of product IDs assigned to the categories,
+// keeping the catalog_category_product position order of the first category listing each product.
+func (r *QueryResolver) categoryProductIDs(categoryIDs []uint) ([]uint, map[uint]int, error) {
+	var ids []uint
+	positions := make(map[uint]int)
+	for _, cid := range categoryIDs {
+		catIDs, err := r.productRepo().FetchProductIDsByCategoryWithPosition(cid, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, id := range catIDs {
+			if _, seen := positions[id]; seen {
+				continue
+			}
+			positions[id] = len(ids)
+			ids = append(ids, id)
+		}
+	}
+	return ids, positions, nil
+}
+
+// matchesProductFilter reports whether a flat product satisfies every non-category condition.
+func matchesProductFilter(p map[string]interface{}, f graphql.ProductAttributeFilter) bool {
+	for code, c := range f.Conditions {
+		switch code {
+		case "category_id", "category_uid", "category_url_path":
+			continue
+		case "price":
+			if !matchesRange(productFinalPrice(p), c) {
+				return false
+			}
+			continue
+		}
+		v, ok := p[code]
+		if !ok || v == nil {
+			return false
+		}
+		if vals := c.Values(); len(vals) > 0 && !matchesAny(flatValueStrings(v), vals) {
+			return false
+		}
+		if c.Match != nil && !matchesText(fmt.Sprint(v), *c.Match, c.MatchType) {
+			return false
+		}
+		if c.From != nil || c.To != nil {
+			f, ok := toFloat(v)
+			if !ok || !matchesRange(f, c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchesRange(v float64, c graphql.FilterCondition) bool {
+	if c.From != nil && *c.From != "" {
+		if from, err := strconv.ParseFloat(*c.From, 64); err == nil && v < from {
+			return false
+		}
+	}
+	if c.To != nil && *c.To != "" {
+		if to, err := strconv.ParseFloat(*c.To, 64); err == nil && v > to {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(have, want []string) bool {
+	for _, h := range have {
+		if containsString(want, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesText implements FilterMatchTypeInput: every query word must occur in the value.
+// PARTIAL (default) matches substrings, FULL matches whole words.
+func matchesText(value, query, matchType string) bool {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return true
+	}
+	value = strings.ToLower(value)
+	if strings.ToUpper(matchType) == "FULL" {
+		tokens := strings.FieldsFunc(value, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+		})
+		for _, w := range words {
+			if !containsString(tokens, w) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, w := range words {
+		if !strings.Contains(value, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// flatValueStrings returns a flat attribute value as strings; multiselect values
+// ("12,34") also yield their individual option IDs.
+func flatValueStrings(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		if strings.Contains(val, ",") {
+			return append([]string{val}, strings.Split(val, ",")...)
+		}
+		return []string{val}
+	case []uint:
+		out := make([]string, len(val))
+		for i, u := range val {
+			out[i] = strconv.FormatUint(uint64(u), 10)
+		}
+		return out
+	case float64:
+		return []string{strconv.FormatFloat(val, 'f', -1, 64)}
+	default:
+		return []string{fmt.Sprint(val)}
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case uint:
+		return float64(val), true
+	case uint16:
+		return float64(val), true
+	case uint32:
+		return float64(val), true
+	case string:
+		f, err := strconv.ParseFloat(val, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func productFinalPrice(p map[string]interface{}) float64 {
+	if fp, ok := toFloat(p["final_price"]); ok {
+		return fp
+	}
+	price, _ := toFloat(p["price"])
+	return price
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortDesc(dir *string) bool {
+	return dir != nil && strings.ToUpper(*dir) == "DESC"
+}
+
+// sortProducts orders flat products by the Magento sort input. Keys apply in the order
+// relevance, price, name, position; ties fall back to category position, then entity_id.
+// scores holds search relevance per entity_id (nil outside search).
+func sortProducts(items []map[string]interface{}, s *graphql.ProductAttributeSortInput, positions map[uint]int, scores map[uint]float64) {
+	type key func(a, b map[string]interface{}) int
+	var keys []key
+	cmpFloat := func(x, y float64) int {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	withDir := func(desc bool, k key) key {
+		if !desc {
+			return k
+		}
+		return func(a, b map[string]interface{}) int { return -k(a, b) }
+	}
+	position := func(a, b map[string]interface{}) int {
+		pa, oka := positions[toUint(a["entity_id"])]
+		pb, okb := positions[toUint(b["entity_id"])]
+		if oka && okb {
+			return cmpFloat(float64(pa), float64(pb))
+		}
+		return 0
+	}
+
+	if s != nil {
+		if s.Relevance != nil && scores != nil {
+			// relevance: DESC (what Magento clients send) puts the best match first.
+			keys = append(keys, withDir(sortDesc(s.Relevance), func(a, b map[string]interface{}) int {
+				return cmpFloat(scores[toUint(a["entity_id"])], scores[toUint(b["entity_id"])])
+			}))
+		}
+		if s.Price != nil {
+			keys = append(keys, withDir(sortDesc(s.Price), func(a, b map[string]interface{}) int {
+				return cmpFloat(productFinalPrice(a), productFinalPrice(b))
+			}))
+		}
+		if s.Name != nil {
+			keys = append(keys, withDir(sortDesc(s.Name), func(a, b map[string]interface{}) int {
+				na, _ := a["name"].(string)
+				nb, _ := b["name"].(string)
+				return strings.Compare(strings.ToLower(na), strings.ToLower(nb))
+			}))
+		}
+		if s.Position != nil {
+			keys = append(keys, withDir(sortDesc(s.Position), position))
+		}
+	}
+	keys = append(keys, position, func(a, b map[string]interface{}) int {
+		return cmpFloat(float64(toUint(a["entity_id"])), float64(toUint(b["entity_id"])))
+	})
+
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, k := range keys {
+			if c := k(items[i], items[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
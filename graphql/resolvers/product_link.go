@@ -0,0 +1,123 @@
+package resolvers
+
+import (
+	"context"
+	"sync"
+
+	gqlmodels "magento.GO/graphql/models"
+	productRepo "magento.GO/model/repository/product"
+)
+
+// productLinkTypes maps the link names used by LoadLinkedProducts to catalog_product_link_type IDs.
+var productLinkTypes = map[string]uint16{
+	"related":   productRepo.LinkTypeRelated,
+	"upsell":    productRepo.LinkTypeUpsell,
+	"crosssell": productRepo.LinkTypeCrosssell,
+}
+
+// productLinkBatch loads the linked products of one product page. Each link type is loaded once,
+// for all products of the page, the first time one of them asks for it.
+type productLinkBatch struct {
+	r        *QueryResolver
+	ctx      context.Context
+	ids      []uint
+	baseURL  string
+	mu       sync.Mutex
+	once     map[string]*sync.Once
+	products map[string]map[uint][]*gqlmodels.ProductInterface
+}
+
+// attachProductLinks lets related_products, upsell_products and crosssell_products of the products
+// load their links in one batch per link type.
+func (r *QueryResolver) attachProductLinks(ctx context.Context, products []*gqlmodels.ProductInterface, baseURL string) {
+	if len(products) == 0 {
+		return
+	}
+	batch := &productLinkBatch{
+		r:        r,
+		ctx:      ctx,
+		baseURL:  baseURL,
+		once:     make(map[string]*sync.Once, len(productLinkTypes)),
+		products: make(map[string]map[uint][]*gqlmodels.ProductInterface, len(productLinkTypes)),
+	}
+	for name := range productLinkTypes {
+		batch.once[name] = &sync.Once{}
+	}
+	for _, pi := range products {
+		id := uint(pi.ID)
+		batch.ids = append(batch.ids, id)
+		pi.LoadLinkedProducts = func(linkType string) []*gqlmodels.ProductInterface {
+			return batch.get(linkType, id)
+		}
+	}
+}
+
+func (b *productLinkBatch) get(linkType string, productID uint) []*gqlmodels.ProductInterface {
+	once, ok := b.once[linkType]
+	if !ok {
+		return []*gqlmodels.ProductInterface{}
+	}
+	once.Do(func() {
+		loaded := b.load(productLinkTypes[linkType])
+		b.mu.Lock()
+		b.products[linkType] = loaded
+		b.mu.Unlock()
+	})
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if products := b.products[linkType][productID]; products != nil {
+		return products
+	}
+	return []*gqlmodels.ProductInterface{}
+}
+
+// load reads the links of one type and their target products. Disabled and out-of-stock targets
+// are left out; the rest keep the link position order.
+func (b *productLinkBatch) load(linkTypeID uint16) map[uint][]*gqlmodels.ProductInterface {
+	result := make(map[uint][]*gqlmodels.ProductInterface)
+	r, ctx := b.r, b.ctx
+	links, err := r.productRepo().FetchProductLinks(b.ids, linkTypeID)
+	if err != nil || len(links) == 0 {
+		return result
+	}
+	var targetIDs []uint
+	for _, ls := range links {
+		for _, l := range ls {
+			targetIDs = append(targetIDs, l.LinkedProductID)
+		}
+	}
+	targets, err := r.loaders(ctx).Products(r.storeID(ctx)).LoadMany(targetIDs)
+	if err != nil {
+		return result
+	}
+	cur := r.currency(ctx)
+	groupID := r.customerGroupID(ctx)
+	mapped := make(map[uint]*gqlmodels.ProductInterface, len(targets))
+	var all []*gqlmodels.ProductInterface
+	var flats []map[string]interface{}
+	for id, p := range targets {
+		if toUint(p["status"]) == productStatusDisabled {
+			continue
+		}
+		p = filterPriceForGroup(p, groupID)
+		mp := flatToMagentoProduct(p, b.baseURL, cur)
+		if mp.StockStatus != "IN_STOCK" {
+			continue
+		}
+		pi := &gqlmodels.ProductInterface{MagentoProduct: *mp}
+		tagProducts(ctx, id)
+		mapped[id] = pi
+		all = append(all, pi)
+		flats = append(flats, p)
+	}
+	r.attachReviews(ctx, all)
+	r.attachPriceTiers(ctx, all, flats)
+	for productID, ls := range links {
+		for _, l := range ls {
+			if pi, ok := mapped[l.LinkedProductID]; ok {
+				result[productID] = append(result[productID], pi)
+			}
+		}
+	}
+	return result
+}
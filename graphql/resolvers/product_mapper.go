@@ -9,28 +9,44 @@ import (
 	gqlmodels "magento.GO/graphql/models"
 )
 
-func filterProductsForGuest(flat map[uint]map[string]interface{}, customerGroupID uint) []map[string]interface{} {
+func filterProductsForGroup(flat map[uint]map[string]interface{}, customerGroupID uint) []map[string]interface{} {
 	result := make([]map[string]interface{}, 0, len(flat))
 	for _, p := range flat {
-		result = append(result, filterPriceForGuest(p, customerGroupID))
+		result = append(result, filterPriceForGroup(p, customerGroupID))
 	}
 	return result
 }
 
-func filterPriceForGuest(p map[string]interface{}, customerGroupID uint) map[string]interface{} {
+// filterPriceForGroup sets price and final_price from the customer group's index_prices row.
+// Flat products may be shared through the flat cache, so prices are set on a shallow copy; p is
+// returned as is when its prices already match.
+func filterPriceForGroup(p map[string]interface{}, customerGroupID uint) map[string]interface{} {
 	if prices, ok := p["index_prices"].([]map[string]interface{}); ok {
 		for _, ip := range prices {
-			cg := toUint(ip["customer_group_id"])
-			if cg == customerGroupID {
-				p["price"] = ip["price"]
-				p["final_price"] = ip["final_price"]
-				break
+			if toUint(ip["customer_group_id"]) != customerGroupID {
+				continue
 			}
+			if p["price"] == ip["price"] && p["final_price"] == ip["final_price"] {
+				return p
+			}
+			out := copyFlat(p, 0)
+			out["price"] = ip["price"]
+			out["final_price"] = ip["final_price"]
+			return out
 		}
 	}
 	return p
 }
 
+// copyFlat returns a shallow copy of a flat product with room for extra keys.
+func copyFlat(p map[string]interface{}, extra int) map[string]interface{} {
+	out := make(map[string]interface{}, len(p)+extra)
+	for k, v := range p {
+		out[k] = v
+	}
+	return out
+}
+
 func numberToStringHook() mapstructure.DecodeHookFunc {
 	return func(f, t reflect.Type, data interface{}) (interface{}, error) {
 		if t.Kind() != reflect.String {
@@ -93,7 +109,10 @@ var flatToProductDecodeHook = mapstructure.ComposeDecodeHookFunc(
 	uintSliceToStringSliceHook(),
 )
 
+// flatToProduct maps a flat product to Product. p may be shared through the flat cache, so the
+// keys prepared for mapstructure are set on a copy.
 func flatToProduct(p map[string]interface{}) *gqlmodels.Product {
+	p = copyFlat(p, 2)
 	// Prepare map for mapstructure: flatten stock_item for is_in_stock, qty
 	if si, ok := p["stock_item"].(map[string]interface{}); ok {
 		if v, ok := si["is_in_stock"]; ok {
@@ -147,6 +166,12 @@ func toUint(v interface{}) uint {
 	switch val := v.(type) {
 	case uint:
 		return val
+	case uint16:
+		return uint(val)
+	case uint32:
+		return uint(val)
+	case uint64:
+		return uint(val)
 	case int:
 		return uint(val)
 	case float64:
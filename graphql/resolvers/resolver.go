@@ -6,10 +6,12 @@ import (
 
 	"gorm.io/gorm"
 
+	attributeRepo "magento.GO/model/repository/attribute"
 	categoryRepo "magento.GO/model/repository/category"
 	productRepo "magento.GO/model/repository/product"
 
 	"magento.GO/graphql"
+	"magento.GO/graphql/dataloader"
 	gqlregistry "magento.GO/graphql/registry"
 )
 
@@ -17,22 +19,71 @@ func init() {
 	gqlregistry.RegisterQueryResolverFactory(func(db interface{}) interface{} {
 		return &QueryResolver{db: db.(*gorm.DB)}
 	})
+	gqlregistry.RegisterMutationResolverFactory(func(db interface{}) interface{} {
+		return &MutationResolver{QueryResolver: &QueryResolver{db: db.(*gorm.DB)}}
+	})
+	gqlregistry.RegisterSubscriptionResolverFactory(func(db interface{}) interface{} {
+		return &SubscriptionResolver{QueryResolver: &QueryResolver{db: db.(*gorm.DB)}}
+	})
 }
 
 // QueryResolver is the single resolver for all Query fields.
 // Methods live in product.go, category.go, search.go, magento_resolver.go.
 // New Query fields: use RegisterSchemaExtension + add method on QueryResolver,
-// or use _extension for fully dynamic resolvers.
+// gqlregistry.RegisterSchema for typed fields with their own resolver (custom packages),
+// or _extension for fully dynamic resolvers.
 type QueryResolver struct {
 	db *gorm.DB
 }
 
-const guestGroupID uint = 0
+// MutationResolver is the single resolver for all Mutation fields. It embeds QueryResolver for
+// its repository and context helpers.
+type MutationResolver struct {
+	*QueryResolver
+}
+
+// SubscriptionResolver is the single resolver for all Subscription fields (subscription.go). It
+// embeds QueryResolver for its repository and context helpers.
+type SubscriptionResolver struct {
+	*QueryResolver
+}
 
 func (r *QueryResolver) storeID(ctx context.Context) uint16 {
 	return graphql.StoreIDFromContext(ctx)
 }
 
+// customerGroupID is the customer group prices are resolved for (NOT LOGGED IN for guests).
+func (r *QueryResolver) customerGroupID(ctx context.Context) uint {
+	return graphql.CustomerGroupIDFromContext(ctx)
+}
+
+// loaders returns the request's batching loaders. Requests that did not come through the GraphQL
+// handler get fresh loaders, so lookups still work but are not shared with other fields.
+func (r *QueryResolver) loaders(ctx context.Context) *dataloader.Loaders {
+	if l := dataloader.FromContext(ctx); l != nil {
+		return l
+	}
+	return dataloader.NewLoaders(r.db)
+}
+
+// tagProducts records products a response is built from, for its cache tags (X-Magento-Tags).
+func tagProducts(ctx context.Context, ids ...uint) {
+	tags := make([]string, len(ids))
+	for i, id := range ids {
+		tags[i] = graphql.ProductTag(id)
+	}
+	graphql.AddCacheTags(ctx, tags...)
+}
+
+// tagCategories records categories a response is built from, for its cache tags.
+func tagCategories(ctx context.Context, ids ...uint) {
+	tags := make([]string, len(ids))
+	for i, id := range ids {
+		tags[i] = graphql.CategoryTag(id)
+	}
+	graphql.AddCacheTags(ctx, tags...)
+}
+
 func (r *QueryResolver) productRepo() *productRepo.ProductRepository {
 	return productRepo.GetProductRepository(r.db)
 }
@@ -41,6 +92,10 @@ func (r *QueryResolver) categoryRepo() *categoryRepo.CategoryRepository {
 	return categoryRepo.GetCategoryRepository(r.db)
 }
 
+func (r *QueryResolver) attributeRepo() *attributeRepo.AttributeRepository {
+	return attributeRepo.GetAttributeRepository(r.db)
+}
+
 func (r *QueryResolver) searchService() *SearchService {
 	return GetSearchService()
 }
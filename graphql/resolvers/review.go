@@ -0,0 +1,173 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"magento.GO/graphql"
+	gqlmodels "magento.GO/graphql/models"
+	configRepo "magento.GO/model/repository/config"
+	reviewRepo "magento.GO/model/repository/review"
+)
+
+func (r *QueryResolver) reviewRepo() *reviewRepo.ReviewRepository {
+	return reviewRepo.GetReviewRepository(r.db)
+}
+
+// reviewStoreID is the store view reviews are read and written for; store 0 resolves to the default store view.
+func (r *QueryResolver) reviewStoreID(ctx context.Context) uint16 {
+	return r.storeRepo().ResolveStoreID(r.storeID(ctx))
+}
+
+// attachReviews lets rating_summary and review_count of the products load the summaries of the
+// whole page in one query, the first time one of them asks, and reviews(pageSize, currentPage)
+// load their approved reviews on demand.
+func (r *QueryResolver) attachReviews(ctx context.Context, products []*gqlmodels.ProductInterface) {
+	if len(products) == 0 {
+		return
+	}
+	storeID := r.reviewStoreID(ctx)
+	ids := make([]uint, len(products))
+	for i, pi := range products {
+		ids[i] = uint(pi.ID)
+	}
+	var once sync.Once
+	var summaries map[uint]reviewRepo.Summary
+	load := func() map[uint]reviewRepo.Summary {
+		once.Do(func() {
+			summaries, _ = r.reviewRepo().Summaries(ids, storeID)
+		})
+		return summaries
+	}
+	for _, pi := range products {
+		id := uint(pi.ID)
+		pi.LoadReviewSummary = func() (float64, int32) {
+			s := load()[id]
+			return s.RatingSummary, int32(s.ReviewCount)
+		}
+		pi.LoadReviews = r.reviewLoader(id, storeID)
+	}
+}
+
+func (r *QueryResolver) reviewLoader(productID uint, storeID uint16) func(pageSize, currentPage int32) *gqlmodels.ProductReviews {
+	return func(pageSize, currentPage int32) *gqlmodels.ProductReviews {
+		if pageSize <= 0 {
+			pageSize = 20
+		}
+		if currentPage <= 0 {
+			currentPage = 1
+		}
+		result := &gqlmodels.ProductReviews{
+			Items:    []*gqlmodels.ProductReview{},
+			PageInfo: gqlmodels.SearchResultPageInfo{CurrentPage: &currentPage, PageSize: &pageSize},
+		}
+		reviews, total, err := r.reviewRepo().ProductReviews(productID, storeID, int(pageSize), int(currentPage))
+		if err != nil {
+			return result
+		}
+		result.PageInfo.TotalPages = int32((total + int64(pageSize) - 1) / int64(pageSize))
+		for _, rv := range reviews {
+			result.Items = append(result.Items, productReview(rv))
+		}
+		return result
+	}
+}
+
+func productReview(rv reviewRepo.ProductReview) *gqlmodels.ProductReview {
+	breakdown := make([]*gqlmodels.ProductReviewRating, len(rv.Votes))
+	for i, v := range rv.Votes {
+		breakdown[i] = &gqlmodels.ProductReviewRating{Name: v.RatingCode, Value: strconv.Itoa(int(v.Value))}
+	}
+	return &gqlmodels.ProductReview{
+		AverageRating:    rv.AverageRating(),
+		CreatedAt:        rv.CreatedAt.Format("2006-01-02 15:04:05"),
+		Nickname:         rv.Nickname,
+		RatingsBreakdown: breakdown,
+		Summary:          rv.Title,
+		Text:             rv.Detail,
+	}
+}
+
+// ProductReviewRatingsMetadata returns the active ratings of the store view. Rating and option ids
+// are base64-encoded, as createProductReview expects them.
+func (r *QueryResolver) ProductReviewRatingsMetadata(ctx context.Context) *gqlmodels.ProductReviewRatingsMetadata {
+	result := &gqlmodels.ProductReviewRatingsMetadata{Items: []*gqlmodels.ProductReviewRatingMetadata{}}
+	ratings, err := r.reviewRepo().ActiveRatings(r.reviewStoreID(ctx))
+	if err != nil {
+		return result
+	}
+	for _, rt := range ratings {
+		values := make([]*gqlmodels.ProductReviewRatingValueMetadata, len(rt.Options))
+		for i, o := range rt.Options {
+			values[i] = &gqlmodels.ProductReviewRatingValueMetadata{ValueID: uidEncode(o.OptionID), Value: strconv.Itoa(int(o.Value))}
+		}
+		result.Items = append(result.Items, &gqlmodels.ProductReviewRatingMetadata{
+			ID:     uidEncode(uint(rt.RatingID)),
+			Name:   rt.RatingCode,
+			Values: values,
+		})
+	}
+	return result
+}
+
+// CreateProductReview saves a pending review for the product with the given SKU. Guests may review
+// only when catalog/review/allow_guest is enabled.
+func (m *MutationResolver) CreateProductReview(ctx context.Context, args graphql.CreateProductReviewArgs) (*gqlmodels.CreateProductReviewOutput, error) {
+	in := args.Input
+	storeID := m.reviewStoreID(ctx)
+	cfg := m.configRepo()
+	if cfg.Get(configRepo.PathReviewActive, storeID) == "0" {
+		return nil, errors.New("product reviews are not enabled")
+	}
+	customerID, loggedIn := graphql.CustomerIDFromContext(ctx)
+	if !loggedIn && cfg.Get(configRepo.PathReviewAllowGuest, storeID) == "0" {
+		return nil, errors.New("guest customers aren't allowed to add product reviews")
+	}
+	nickname, summary, text := strings.TrimSpace(in.Nickname), strings.TrimSpace(in.Summary), strings.TrimSpace(in.Text)
+	if nickname == "" || summary == "" || text == "" {
+		return nil, errors.New("nickname, summary and text are required")
+	}
+	product, err := m.productRepo().FindBySKU(in.Sku)
+	if err != nil {
+		return nil, fmt.Errorf("could not find a product with SKU %q", in.Sku)
+	}
+
+	votes := make(map[uint16]uint, len(in.Ratings))
+	for _, rating := range in.Ratings {
+		if rating == nil {
+			continue
+		}
+		ratingID, ok := uidDecode(rating.ID)
+		optionID, ok2 := uidDecode(rating.ValueID)
+		// rating_id is a smallint: a larger ID must not wrap around to another rating
+		if !ok || !ok2 || ratingID > math.MaxUint16 {
+			return nil, reviewRepo.ErrInvalidRating
+		}
+		votes[uint16(ratingID)] = optionID
+	}
+	review := reviewRepo.NewReview{
+		ProductID: product.EntityID,
+		StoreID:   storeID,
+		Nickname:  nickname,
+		Title:     summary,
+		Detail:    text,
+		Votes:     votes,
+		RemoteIP:  graphql.RemoteIPFromContext(ctx),
+	}
+	if loggedIn {
+		review.CustomerID = &customerID
+	}
+	created, err := m.reviewRepo().CreatePending(review)
+	if err != nil {
+		if errors.Is(err, reviewRepo.ErrInvalidRating) {
+			return nil, err
+		}
+		return nil, errors.New("unable to save the review")
+	}
+	return &gqlmodels.CreateProductReviewOutput{Review: productReview(*created)}, nil
+}
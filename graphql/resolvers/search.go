@@ -4,16 +4,26 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/elastic/go-elasticsearch/v8"
-	productRepo "magento.GO/model/repository/product"
+	gql "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
 
+	"magento.GO/graphql"
 	gqlmodels "magento.GO/graphql/models"
+	configRepo "magento.GO/model/repository/config"
+	storeRepo "magento.GO/model/repository/store"
 )
 
+// defaultIndexPattern is Magento's product index name; see indexName.
+const defaultIndexPattern = "{prefix}_catalog_product_{store_id}"
+
 var (
 	searchServiceInstance *SearchService
 	searchServiceOnce     sync.Once
@@ -27,9 +37,21 @@ func GetSearchService() *SearchService {
 	return searchServiceInstance
 }
 
+// ResetSearchService drops the SearchService so the next GetSearchService reads the
+// ELASTICSEARCH_* settings again (for testing)
+func ResetSearchService() {
+	searchServiceOnce = sync.Once{}
+	searchServiceInstance = nil
+}
+
+// errElasticsearchUnavailable wraps transport errors: the cluster could not be reached at all, as
+// opposed to an error response to the search.
+var errElasticsearchUnavailable = errors.New("elasticsearch is unavailable")
+
 type SearchService struct {
-	client *elasticsearch.Client
-	prefix string
+	client  *elasticsearch.Client
+	prefix  string
+	pattern string
 }
 
 func NewSearchService() *SearchService {
@@ -41,26 +63,49 @@ func NewSearchService() *SearchService {
 	if prefix == "" {
 		prefix = "magento2"
 	}
+	pattern := os.Getenv("ELASTICSEARCH_INDEX_PATTERN")
+	if pattern == "" {
+		pattern = defaultIndexPattern
+	}
 
 	cfg := elasticsearch.Config{
 		Addresses: []string{host},
 	}
 	client, err := elasticsearch.NewClient(cfg)
 	if err != nil {
-		return &SearchService{prefix: prefix}
+		return &SearchService{prefix: prefix, pattern: pattern}
 	}
 
 	return &SearchService{
-		client: client,
-		prefix: prefix,
+		client:  client,
+		prefix:  prefix,
+		pattern: pattern,
 	}
 }
 
+// searchRequest is a search query with its filter (categoryId merged in), sort and page.
+type searchRequest struct {
+	query        string
+	filter       graphql.ProductAttributeFilter
+	sort         *graphql.ProductAttributeSortInput
+	pageSize     int
+	currentPage  int
+	aggregations bool // aggregations is selected
+
+	categoryIDs []uint // resolved category_id, category_uid and category_url_path conditions
+	byCategory  bool   // the filter has a category condition
+}
+
+// Search runs a full-text product search on Elasticsearch or, with SEARCH_ENGINE=memory or without
+// an Elasticsearch client, on the in-process index (see search_memory.go). filter and sort take
+// the products inputs; aggregations are computed over every match.
 func (r *QueryResolver) Search(ctx context.Context, args struct {
 	Query       string
 	PageSize    int32
 	CurrentPage int32
 	CategoryID  *string
+	Filter      graphql.ProductAttributeFilter
+	Sort        *graphql.ProductAttributeSortInput
 }) (*gqlmodels.ProductSearchResult, error) {
 	ps := int(args.PageSize)
 	if ps <= 0 {
@@ -70,72 +115,114 @@ func (r *QueryResolver) Search(ctx context.Context, args struct {
 	if cp <= 0 {
 		cp = 1
 	}
-	return r.searchService().search(ctx, r.storeID(ctx), args.Query, &ps, &cp, args.CategoryID, r.productRepo(), guestGroupID)
-}
+	storeID := r.storeID(ctx)
+	req := searchRequest{
+		query:        args.Query,
+		filter:       withCategoryID(args.Filter, args.CategoryID),
+		sort:         args.Sort,
+		pageSize:     ps,
+		currentPage:  cp,
+		aggregations: gql.HasSelectedField(ctx, "aggregations"),
+	}
+	req.categoryIDs, req.byCategory = r.filterCategoryIDs(storeID, req.filter)
 
-// search queries Magento Elasticsearch index: magento2_catalog_product_{storeID}
-func (s *SearchService) search(
-	ctx context.Context,
-	storeID uint16,
-	query string,
-	pageSize *int,
-	currentPage *int,
-	categoryID *string,
-	productRepo *productRepo.ProductRepository,
-	customerGroupID uint,
-) (*gqlmodels.ProductSearchResult, error) {
-	if s.client == nil {
-		return nil, fmt.Errorf("elasticsearch not configured")
-	}
-
-	ps := 20
-	if pageSize != nil && *pageSize > 0 {
-		ps = *pageSize
-	}
-	cp := 1
-	if currentPage != nil && *currentPage > 0 {
-		cp = *currentPage
-	}
-
-	indexName := fmt.Sprintf("%s_catalog_product_%d", s.prefix, storeID)
-	if storeID == 0 {
-		indexName = "magento2_catalog_product_1"
-	}
-
-	from := (cp - 1) * ps
-
-	body := map[string]interface{}{
-		"from": from,
-		"size": ps,
-		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"must": []map[string]interface{}{
-					{
-						"multi_match": map[string]interface{}{
-							"query":  query,
-							"fields": []string{"name^3", "sku^2", "description", "short_description"},
-						},
-					},
-				},
-			},
-		},
+	var ids []uint
+	var total int
+	var aggs *[]*gqlmodels.Aggregation
+	var err error
+	switch s := r.searchService(); {
+	case req.byCategory && len(req.categoryIDs) == 0:
+		// unknown categories match nothing
+	case s.client != nil && SearchEngineFromEnv() == SearchEngineElasticsearch:
+		ids, total, aggs, err = r.searchElasticsearch(ctx, s, storeID, req)
+		if errors.Is(err, errElasticsearchUnavailable) {
+			// a cluster that cannot be reached must not take search down with it
+			ids, total, aggs, err = r.searchMemory(ctx, storeID, req)
+		}
+	default:
+		ids, total, aggs, err = r.searchMemory(ctx, storeID, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.searchResult(ctx, storeID, ids, total, ps, cp)
+	if err != nil {
+		return nil, err
+	}
+	if req.aggregations {
+		if aggs == nil {
+			aggs = &[]*gqlmodels.Aggregation{}
+		}
+		result.Aggregations = aggs
 	}
+	return result, nil
+}
 
-	if categoryID != nil && *categoryID != "" {
-		body["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"] = []map[string]interface{}{
-			{"term": map[string]interface{}{"category_ids": *categoryID}},
+// indexName is the product index of a store view: ELASTICSEARCH_INDEX_PATTERN (default
+// {prefix}_catalog_product_{store_id}) with {prefix}, {store_id} and {store_code} replaced. The
+// prefix is catalog/search/<engine>_index_prefix of the store (e.g. elasticsearch7_index_prefix,
+// resolved store → website → default), else ELASTICSEARCH_INDEX_PREFIX. The admin store and
+// unknown stores search the index of the default store view.
+func (s *SearchService) indexName(db *gorm.DB, storeID uint16) string {
+	stores := storeRepo.GetStoreRepository(db)
+	storeID = stores.ResolveStoreID(storeID)
+	code := strconv.FormatUint(uint64(storeID), 10)
+	if st, ok := stores.GetByID(storeID); ok {
+		code = st.Code
+	}
+	prefix := s.prefix
+	config := configRepo.GetConfigRepository(db)
+	if engine, ok := config.Lookup(configRepo.PathSearchEngine, storeID); ok && engine != "" {
+		if p, ok := config.Lookup("catalog/search/"+engine+"_index_prefix", storeID); ok && p != "" {
+			prefix = p
 		}
 	}
+	return strings.NewReplacer(
+		"{prefix}", prefix,
+		"{store_id}", strconv.FormatUint(uint64(storeID), 10),
+		"{store_code}", code,
+	).Replace(s.pattern)
+}
+
+// esResult is the part of a search response the resolvers read.
+type esResult struct {
+	ids          []uint
+	total        int
+	aggregations map[string]esAggregation
+}
+
+type esAggregation struct {
+	Buckets []esBucket `json:"buckets"`
+}
+
+type esBucket struct {
+	Key      interface{} `json:"key"`
+	DocCount int32       `json:"doc_count"`
+}
+
+// key returns the bucket key as a filter value: option IDs and numbers without a fraction.
+func (b esBucket) key() string {
+	switch k := b.Key.(type) {
+	case float64:
+		return strconv.FormatFloat(k, 'f', -1, 64)
+	case string:
+		return k
+	}
+	return fmt.Sprint(b.Key)
+}
 
+// run sends a search request to an index and returns the entity_id of each hit, the total number
+// of hits and the aggregations.
+func (s *SearchService) run(ctx context.Context, index string, body map[string]interface{}) (*esResult, error) {
 	bodyBytes, _ := json.Marshal(body)
 
 	res, err := s.client.Search(
 		s.client.Search.WithContext(ctx),
-		s.client.Search.WithIndex(indexName),
+		s.client.Search.WithIndex(index),
 		s.client.Search.WithBody(bytes.NewReader(bodyBytes)),
 	)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", errElasticsearchUnavailable, err)
 	}
 	defer res.Body.Close()
 
@@ -152,38 +239,44 @@ func (s *SearchService) search(
 				Source map[string]interface{} `json:"_source"`
 			} `json:"hits"`
 		} `json:"hits"`
+		Aggregations map[string]esAggregation `json:"aggregations"`
 	}
 	if err := json.NewDecoder(res.Body).Decode(&esResp); err != nil {
 		return nil, err
 	}
 
-	var ids []uint
+	result := &esResult{total: esResp.Hits.Total.Value, aggregations: esResp.Aggregations}
 	for _, hit := range esResp.Hits.Hits {
 		if entityID, ok := hit.Source["entity_id"].(float64); ok {
-			ids = append(ids, uint(entityID))
+			result.ids = append(result.ids, uint(entityID))
 		}
 	}
+	return result, nil
+}
 
-	flat, err := productRepo.FetchWithAllAttributesFlatByIDs(ids, storeID)
-	if err != nil {
-		return nil, err
-	}
-
-	products := make([]*gqlmodels.Product, 0, len(ids))
-	for _, id := range ids {
-		if p, ok := flat[id]; ok {
-			filtered := filterPriceForGuest(p, customerGroupID)
-			products = append(products, flatToProduct(filtered))
+// searchResult loads the flat products of a page of search hits, keeping the hit order.
+func (r *QueryResolver) searchResult(ctx context.Context, storeID uint16, ids []uint, total, ps, cp int) (*gqlmodels.ProductSearchResult, error) {
+	items := make([]map[string]interface{}, 0, len(ids))
+	graphql.AddCacheTags(ctx, graphql.ProductListTag)
+	if len(ids) > 0 {
+		flat, err := r.productRepo().FetchWithAllAttributesFlatByIDs(ids, storeID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if p, ok := flat[id]; ok {
+				items = append(items, p)
+				tagProducts(ctx, id)
+			}
 		}
 	}
 
-	total := esResp.Hits.Total.Value
 	totalPages := (total + ps - 1) / ps
 	if totalPages < 1 {
 		totalPages = 1
 	}
 	return &gqlmodels.ProductSearchResult{
-		Items:      products,
+		Items:      r.toProducts(ctx, items),
 		TotalCount: int32(total),
 		PageInfo: &gqlmodels.PageInfo{
 			PageSize:    int32(ps),
@@ -0,0 +1,203 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"magento.GO/graphql"
+	gqlmodels "magento.GO/graphql/models"
+	attributeRepo "magento.GO/model/repository/attribute"
+)
+
+// Magento's search request aggregation names.
+const (
+	priceBucket    = "price_bucket"
+	categoryBucket = "category_bucket"
+	bucketSuffix   = "_bucket"
+)
+
+// maxBuckets bounds the options of a terms aggregation.
+const maxBuckets = 500
+
+// searchElasticsearch runs a search on the store's index and maps its aggregations like
+// buildAggregations does for the products query.
+func (r *QueryResolver) searchElasticsearch(ctx context.Context, s *SearchService, storeID uint16, req searchRequest) ([]uint, int, *[]*gqlmodels.Aggregation, error) {
+	var attrs []attributeRepo.ProductAttribute
+	if req.aggregations {
+		attrs, _ = r.attributeRepo().FilterableAttributes()
+	}
+	priceField := fmt.Sprintf("price_%d_%d", r.customerGroupID(ctx), r.websiteID(ctx))
+	res, err := s.run(ctx, s.indexName(r.db, storeID), esSearchBody(req, priceField, attrs))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if !req.aggregations {
+		return res.ids, res.total, nil, nil
+	}
+	return res.ids, res.total, r.bucketAggregations(storeID, res.aggregations, attrs, req.categoryIDs), nil
+}
+
+// esSearchBody builds the request body of a search: the query on Magento's weighted fields,
+// filters for every condition, the sort and, for each attribute in attrs, an aggregation.
+// Prices are the indexed final prices of the customer group and website (priceField).
+func esSearchBody(req searchRequest, priceField string, attrs []attributeRepo.ProductAttribute) map[string]interface{} {
+	must := []map[string]interface{}{}
+	if req.query != "" {
+		must = append(must, map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  req.query,
+				"fields": []string{"name^3", "sku^2", "description", "short_description"},
+			},
+		})
+	}
+	filter := []map[string]interface{}{}
+	if req.byCategory {
+		filter = append(filter, map[string]interface{}{"terms": map[string]interface{}{"category_ids": req.categoryIDs}})
+	}
+	for _, code := range req.filter.Codes() {
+		switch code {
+		case "category_id", "category_uid", "category_url_path":
+			continue
+		}
+		field := code
+		if code == "price" {
+			field = priceField
+		}
+		filter = append(filter, esFilterClauses(field, req.filter.Conditions[code])...)
+	}
+
+	body := map[string]interface{}{
+		"from":             (req.currentPage - 1) * req.pageSize,
+		"size":             req.pageSize,
+		"_source":          []string{"entity_id"},
+		"track_total_hits": true,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{"must": must, "filter": filter},
+		},
+		"sort": esSort(req, priceField),
+	}
+	if req.aggregations {
+		aggs := map[string]interface{}{
+			priceBucket: map[string]interface{}{
+				"histogram": map[string]interface{}{"field": priceField, "interval": minPriceRange, "min_doc_count": 1},
+			},
+			categoryBucket: map[string]interface{}{
+				"terms": map[string]interface{}{"field": "category_ids", "size": maxBuckets},
+			},
+		}
+		for _, a := range attrs {
+			if a.Code != "price" {
+				aggs[a.Code+bucketSuffix] = map[string]interface{}{
+					"terms": map[string]interface{}{"field": a.Code, "size": maxBuckets},
+				}
+			}
+		}
+		body["aggs"] = aggs
+	}
+	return body
+}
+
+// esFilterClauses maps a filter condition to term, match and range clauses on a field.
+func esFilterClauses(field string, c graphql.FilterCondition) []map[string]interface{} {
+	var clauses []map[string]interface{}
+	if vals := c.Values(); len(vals) > 0 {
+		clauses = append(clauses, map[string]interface{}{"terms": map[string]interface{}{field: vals}})
+	}
+	if c.Match != nil && *c.Match != "" {
+		clauses = append(clauses, map[string]interface{}{
+			"match": map[string]interface{}{field: map[string]interface{}{"query": *c.Match, "operator": "and"}},
+		})
+	}
+	bounds := map[string]interface{}{}
+	if c.From != nil && *c.From != "" {
+		if from, err := strconv.ParseFloat(*c.From, 64); err == nil {
+			bounds["gte"] = from
+		}
+	}
+	if c.To != nil && *c.To != "" {
+		if to, err := strconv.ParseFloat(*c.To, 64); err == nil {
+			bounds["lte"] = to
+		}
+	}
+	if len(bounds) > 0 {
+		clauses = append(clauses, map[string]interface{}{"range": map[string]interface{}{field: bounds}})
+	}
+	return clauses
+}
+
+// esSort maps the sort input to Magento's index fields, in the keys order of sortProducts:
+// relevance (_score), price, name (name.sort_name) and position (position_category_<id>, when
+// one category is filtered). Without a sort the best match comes first; entity_id breaks ties.
+func esSort(req searchRequest, priceField string) []map[string]interface{} {
+	order := func(dir *string) map[string]interface{} {
+		if sortDesc(dir) {
+			return map[string]interface{}{"order": "desc"}
+		}
+		return map[string]interface{}{"order": "asc"}
+	}
+	var keys []map[string]interface{}
+	if s := req.sort; s != nil {
+		if s.Relevance != nil {
+			keys = append(keys, map[string]interface{}{"_score": order(s.Relevance)})
+		}
+		if s.Price != nil {
+			keys = append(keys, map[string]interface{}{priceField: order(s.Price)})
+		}
+		if s.Name != nil {
+			keys = append(keys, map[string]interface{}{"name.sort_name": order(s.Name)})
+		}
+		if s.Position != nil && len(req.categoryIDs) == 1 {
+			keys = append(keys, map[string]interface{}{fmt.Sprintf("position_category_%d", req.categoryIDs[0]): order(s.Position)})
+		}
+	}
+	if len(keys) == 0 {
+		keys = append(keys, map[string]interface{}{"_score": map[string]interface{}{"order": "desc"}})
+	}
+	return append(keys, map[string]interface{}{"entity_id": map[string]interface{}{"order": "asc"}})
+}
+
+// bucketAggregations maps the aggregations of a search response: price ranges from the price
+// histogram, categories and the filterable attributes, ordered and labelled as in buildAggregations.
+func (r *QueryResolver) bucketAggregations(storeID uint16, aggs map[string]esAggregation, attrs []attributeRepo.ProductAttribute, excludeCategories []uint) *[]*gqlmodels.Aggregation {
+	result := make([]*gqlmodels.Aggregation, 0)
+
+	var prices []priceCount
+	for _, b := range aggs[priceBucket].Buckets {
+		if price, err := strconv.ParseFloat(b.key(), 64); err == nil && b.DocCount > 0 {
+			prices = append(prices, priceCount{price: price, count: b.DocCount})
+		}
+	}
+	if agg := priceRangeAggregation(prices); agg != nil {
+		result = append(result, agg)
+	}
+
+	categories := make(map[uint]int32)
+	for _, b := range aggs[categoryBucket].Buckets {
+		if id, err := strconv.ParseUint(b.key(), 10, 64); err == nil {
+			categories[uint(id)] = b.DocCount
+		}
+	}
+	if agg := r.categoryCountAggregation(storeID, categories, excludeCategories); agg != nil {
+		result = append(result, agg)
+	}
+
+	options, _ := r.attributeRepo().ProductAttributeOptions(storeID)
+	for _, a := range attrs {
+		if a.Code == "price" {
+			continue
+		}
+		counts := make(map[string]int32)
+		var seen []string
+		for _, b := range aggs[a.Code+bucketSuffix].Buckets {
+			if k := b.key(); k != "" && b.DocCount > 0 {
+				counts[k] = b.DocCount
+				seen = append(seen, k)
+			}
+		}
+		if agg := attributeCountAggregation(a, options[a.AttributeID], counts, seen); agg != nil {
+			result = append(result, agg)
+		}
+	}
+	return &result
+}
@@ -0,0 +1,335 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"magento.GO/config"
+	"magento.GO/core/events"
+	"magento.GO/core/search"
+	"magento.GO/graphql"
+	gqlmodels "magento.GO/graphql/models"
+	"magento.GO/model/repository"
+	attributeRepo "magento.GO/model/repository/attribute"
+	productRepo "magento.GO/model/repository/product"
+)
+
+// Search engines selected with SEARCH_ENGINE.
+const (
+	SearchEngineElasticsearch = "elasticsearch"
+	SearchEngineMemory        = "memory"
+)
+
+// Magento visibility values of products that are not shown in search results.
+const (
+	visibilityNotVisible = 1
+	visibilityCatalog    = 2
+)
+
+// SearchEngineFromEnv returns the backend of the search query (SEARCH_ENGINE): "elasticsearch" or
+// "memory", the in-process index of the flat products. Without SEARCH_ENGINE it is elasticsearch
+// only when ELASTICSEARCH_HOST is set, so a store without a cluster searches in memory.
+func SearchEngineFromEnv() string {
+	switch config.GetEnv("SEARCH_ENGINE", "") {
+	case SearchEngineMemory:
+		return SearchEngineMemory
+	case SearchEngineElasticsearch:
+		return SearchEngineElasticsearch
+	}
+	if config.GetEnv("ELASTICSEARCH_HOST", "") != "" {
+		return SearchEngineElasticsearch
+	}
+	return SearchEngineMemory
+}
+
+var memorySearches repository.PerDB[*memorySearch]
+
+// memorySearch indexes the flat products of each store on its first search. Products published
+// on events.TopicProduct are reloaded and reindexed before the next search, and updated in the
+// product suggestion tries (see search_suggest.go).
+type memorySearch struct {
+	db      *gorm.DB
+	changes *events.Subscription
+
+	mu      sync.Mutex
+	stores  map[uint16]*search.Index
+	suggest map[uint16]*suggestIndex
+	skus    map[string]uint // indexed SKU -> entity_id, to remove deleted products
+}
+
+func getMemorySearch(db *gorm.DB) *memorySearch {
+	return memorySearches.Get(db, func(db *gorm.DB) *memorySearch {
+		return &memorySearch{
+			db:      db,
+			changes: events.GetInstance().SubscribeAll(events.TopicProduct),
+			stores:  make(map[uint16]*search.Index),
+			suggest: make(map[uint16]*suggestIndex),
+			skus:    make(map[string]uint),
+		}
+	})
+}
+
+// searchMemory runs a search on the in-process index. Without filter, sort and aggregations a
+// page of hits is returned as ranked; otherwise every hit is loaded, filtered, sorted (best match
+// first by default) and aggregated as in the products query.
+func (r *QueryResolver) searchMemory(ctx context.Context, storeID uint16, req searchRequest) ([]uint, int, *[]*gqlmodels.Aggregation, error) {
+	hits, err := getMemorySearch(r.db).hits(storeID, req.query)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	offset := (req.currentPage - 1) * req.pageSize
+	if req.filter.IsEmpty() && req.sort == nil && !req.aggregations {
+		ids := make([]uint, 0, req.pageSize)
+		for i := offset; i < len(hits) && i < offset+req.pageSize; i++ {
+			ids = append(ids, hits[i].ID)
+		}
+		return ids, len(hits), nil, nil
+	}
+
+	ids := make([]uint, len(hits))
+	scores := make(map[uint]float64, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+		scores[h.ID] = h.Score
+	}
+	var positions map[uint]int
+	if req.byCategory {
+		if _, positions, err = r.categoryProductIDs(req.categoryIDs); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+	flat, err := r.productRepo().FetchWithAllAttributesFlatByIDs(ids, storeID)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	items := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		p, ok := flat[id]
+		if !ok {
+			continue
+		}
+		if _, ok := positions[id]; req.byCategory && !ok {
+			continue
+		}
+		if p = filterPriceForGroup(p, r.customerGroupID(ctx)); matchesProductFilter(p, req.filter) {
+			items = append(items, p)
+		}
+	}
+	order := req.sort
+	if order == nil {
+		desc := "DESC"
+		order = &graphql.ProductAttributeSortInput{Relevance: &desc}
+	}
+	sortProducts(items, order, positions, scores)
+
+	var aggs *[]*gqlmodels.Aggregation
+	if req.aggregations {
+		aggs = r.buildAggregations(ctx, items, req.categoryIDs)
+	}
+	page := paginate(items, req.currentPage, req.pageSize)
+	pageIDs := make([]uint, len(page))
+	for i, p := range page {
+		pageIDs[i] = toUint(p["entity_id"])
+	}
+	return pageIDs, len(items), aggs, nil
+}
+
+// hits returns every match of a query in the index of a store, best first.
+func (s *memorySearch) hits(storeID uint16, query string) ([]search.Hit, error) {
+	ix, err := s.index(storeID)
+	if err != nil {
+		return nil, err
+	}
+	hits, _ := ix.Search(search.Query{Text: query})
+	return hits, nil
+}
+
+// index returns the index of a store after applying pending product changes, building it first
+// if needed.
+func (s *memorySearch) index(storeID uint16) (*search.Index, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.applyChanges(); err != nil {
+		return nil, err
+	}
+	if ix, ok := s.stores[storeID]; ok {
+		return ix, nil
+	}
+	flat, err := productRepo.GetProductRepository(s.db).FetchWithAllAttributesFlat(storeID)
+	if err != nil {
+		return nil, err
+	}
+	ix := search.NewIndex()
+	fields := s.fieldsFor(storeID)
+	for id, p := range flat {
+		if doc, ok := fields.document(id, p); ok {
+			ix.Put(doc)
+		}
+		if sku, ok := p["sku"].(string); ok {
+			s.skus[sku] = id
+		}
+	}
+	s.stores[storeID] = ix
+	return ix, nil
+}
+
+// applyChanges reindexes the products published since the last call in every built index and
+// product suggestion trie. Changed SKUs are reloaded with RefreshFlatProducts, which also updates
+// the flat cache.
+func (s *memorySearch) applyChanges() error {
+	skus := s.changes.Take()
+	if len(skus) == 0 {
+		return nil
+	}
+	storeIDs := make(map[uint16]struct{})
+	for storeID := range s.stores {
+		storeIDs[storeID] = struct{}{}
+	}
+	for storeID, sx := range s.suggest {
+		if sx.products != nil {
+			storeIDs[storeID] = struct{}{}
+		}
+	}
+	if len(storeIDs) == 0 {
+		return nil
+	}
+
+	repo := productRepo.GetProductRepository(s.db)
+	found, err := repo.FindBySKUs(skus)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(skus))
+	for _, sku := range skus {
+		if p, ok := found[sku]; ok {
+			ids = append(ids, p.EntityID)
+		} else if id, ok := s.skus[sku]; ok {
+			ids = append(ids, id) // deleted or renamed
+		}
+		delete(s.skus, sku)
+	}
+	for sku, p := range found {
+		s.skus[sku] = p.EntityID
+	}
+	for storeID := range storeIDs {
+		flat, err := repo.RefreshFlatProducts(ids, storeID)
+		if err != nil {
+			return err
+		}
+		if sx, ok := s.suggest[storeID]; ok && sx.products != nil {
+			for _, id := range ids {
+				sx.putProduct(id, flat[id])
+			}
+		}
+		ix, ok := s.stores[storeID]
+		if !ok {
+			continue
+		}
+		fields := s.fieldsFor(storeID)
+		for _, id := range ids {
+			if doc, ok := fields.document(id, flat[id]); ok {
+				ix.Put(doc)
+			} else {
+				ix.Remove(id)
+			}
+		}
+	}
+	return nil
+}
+
+// searchField is an attribute that is indexed, with the labels of its options for select and
+// multiselect attributes.
+type searchField struct {
+	code    string
+	weight  float64
+	options map[string]string // option_id -> store label
+}
+
+type searchFields []searchField
+
+// fieldsFor returns name, sku, description and short_description (weighted as in the
+// Elasticsearch query) followed by the other searchable attributes by code. Without attribute
+// metadata only the first four are indexed.
+func (s *memorySearch) fieldsFor(storeID uint16) searchFields {
+	fields := searchFields{
+		{code: "name", weight: 3},
+		{code: "sku", weight: 2},
+		{code: "description", weight: 1},
+		{code: "short_description", weight: 1},
+	}
+	repo := attributeRepo.GetAttributeRepository(s.db)
+	attrs, err := repo.ProductAttributes()
+	if err != nil {
+		return fields
+	}
+	codes := make([]string, 0, len(attrs))
+	for code, a := range attrs {
+		switch code {
+		case "name", "sku", "description", "short_description":
+			continue
+		}
+		if a.IsSearchable && a.BackendType != "decimal" && a.BackendType != "datetime" {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	options, _ := repo.ProductAttributeOptions(storeID)
+	for _, code := range codes {
+		a := attrs[code]
+		f := searchField{code: code, weight: a.SearchWeight}
+		if a.FrontendInput == "select" || a.FrontendInput == "multiselect" {
+			f.options = make(map[string]string)
+			for _, o := range options[a.AttributeID] {
+				f.options[strconv.FormatUint(uint64(o.OptionID), 10)] = o.Label
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// searchable reports whether a flat product is shown in search: it exists, is enabled and is
+// visible in search.
+func searchable(p map[string]interface{}) bool {
+	if p == nil || toUint(p["status"]) == productStatusDisabled {
+		return false
+	}
+	v := toUint(p["visibility"])
+	return v != visibilityNotVisible && v != visibilityCatalog
+}
+
+// document maps a flat product to its search document; false for products that are not
+// searchable.
+func (fields searchFields) document(id uint, p map[string]interface{}) (search.Document, bool) {
+	if !searchable(p) {
+		return search.Document{}, false
+	}
+	doc := search.Document{ID: id}
+	doc.CategoryIDs, _ = p["category_ids"].([]uint)
+	for _, f := range fields {
+		v, ok := p[f.code]
+		if !ok || v == nil {
+			continue
+		}
+		text := fmt.Sprint(v)
+		if f.options != nil {
+			var labels []string
+			for _, optionID := range strings.Split(text, ",") {
+				if label := f.options[strings.TrimSpace(optionID)]; label != "" {
+					labels = append(labels, label)
+				}
+			}
+			text = strings.Join(labels, " ")
+		}
+		if text != "" {
+			doc.Fields = append(doc.Fields, search.Field{Text: text, Weight: f.weight})
+		}
+	}
+	return doc, true
+}
@@ -0,0 +1,298 @@
+package resolvers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"magento.GO/core/search"
+	"magento.GO/graphql"
+	gqlmodels "magento.GO/graphql/models"
+	categoryRepo "magento.GO/model/repository/category"
+	productRepo "magento.GO/model/repository/product"
+	searchRepo "magento.GO/model/repository/search"
+)
+
+// suggestTTL is how long the category and term tries of a store are used before they are
+// reloaded in the background.
+const suggestTTL = 5 * time.Minute
+
+// Completion weights: a product name ranks before a SKU.
+const (
+	suggestWeightName = 2
+	suggestWeightSKU  = 1
+)
+
+// suggestIndex holds the completion tries of one store view. The product trie is updated in place
+// as products change (see memorySearch.applyChanges); categories and terms are replaced as a whole.
+type suggestIndex struct {
+	mu          sync.RWMutex
+	products    *search.Trie // nil when Elasticsearch completes products
+	productInfo map[uint]*gqlmodels.ProductSuggestion
+	lists       *suggestLists
+	refreshing  bool // lists are being reloaded
+}
+
+// suggestLists are the category and popular term tries of a store view.
+type suggestLists struct {
+	builtAt      time.Time
+	categories   *search.Trie
+	terms        *search.Trie
+	categoryInfo map[uint]*gqlmodels.CategorySuggestion
+	termInfo     []*gqlmodels.SearchTermSuggestion // by Entry.Key
+}
+
+func (r *QueryResolver) SearchSuggestions(ctx context.Context, args struct {
+	Query    string
+	PageSize int32
+}) (*gqlmodels.SearchSuggestions, error) {
+	return SearchSuggestions(ctx, r.db, r.storeID(ctx), args.Query, int(args.PageSize))
+}
+
+// SearchSuggestions returns up to limit (default 5, at most search.MaxCompletions) of each kind of
+// completion of a partly typed query: products whose name or SKU completes it, active categories
+// and popular search terms of the store. Products come from an Elasticsearch prefix query when
+// search runs on Elasticsearch, otherwise from an in-memory trie like categories and terms.
+// Serves searchSuggestions and GET /api/search/suggest.
+func SearchSuggestions(ctx context.Context, db *gorm.DB, storeID uint16, query string, limit int) (*gqlmodels.SearchSuggestions, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+	if limit > search.MaxCompletions {
+		limit = search.MaxCompletions
+	}
+	result := &gqlmodels.SearchSuggestions{
+		Products:   []*gqlmodels.ProductSuggestion{},
+		Categories: []*gqlmodels.CategorySuggestion{},
+		Terms:      []*gqlmodels.SearchTermSuggestion{},
+	}
+	if strings.TrimSpace(query) == "" {
+		return result, nil
+	}
+	graphql.AddCacheTags(ctx, graphql.ProductListTag)
+
+	es := GetSearchService()
+	useES := es.client != nil && SearchEngineFromEnv() == SearchEngineElasticsearch
+	sx, err := getMemorySearch(db).suggestions(storeID, !useES)
+	if err != nil {
+		return nil, err
+	}
+
+	if useES {
+		products, err := es.suggestProducts(ctx, db, storeID, query, limit)
+		if err != nil {
+			return nil, err
+		}
+		result.Products = products
+	}
+	sx.mu.RLock()
+	defer sx.mu.RUnlock()
+	if !useES {
+		seen := make(map[uint]bool)
+		for _, e := range sx.products.Complete(query, search.MaxCompletions) {
+			info, ok := sx.productInfo[e.Key]
+			if !ok || seen[e.Key] || len(result.Products) == limit {
+				continue
+			}
+			seen[e.Key] = true
+			p := *info
+			p.Text = e.Text
+			result.Products = append(result.Products, &p)
+		}
+	}
+	for _, e := range sx.lists.categories.Complete(query, limit) {
+		result.Categories = append(result.Categories, sx.lists.categoryInfo[e.Key])
+	}
+	for _, e := range sx.lists.terms.Complete(query, limit) {
+		result.Terms = append(result.Terms, sx.lists.termInfo[e.Key])
+	}
+	return result, nil
+}
+
+// suggestions returns the suggestion tries of a store after applying pending product changes,
+// building them on first use and the product trie when withProducts asks for it. Categories and
+// terms older than suggestTTL are served while they are reloaded.
+func (s *memorySearch) suggestions(storeID uint16, withProducts bool) (*suggestIndex, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.applyChanges(); err != nil {
+		return nil, err
+	}
+	sx, ok := s.suggest[storeID]
+	if !ok {
+		sx = &suggestIndex{lists: loadSuggestLists(s.db, storeID)}
+		s.suggest[storeID] = sx
+	} else {
+		sx.refreshLists(s.db, storeID)
+	}
+	if withProducts && sx.products == nil {
+		flat, err := productRepo.GetProductRepository(s.db).FetchWithAllAttributesFlat(storeID)
+		if err != nil {
+			return nil, err
+		}
+		info := make(map[uint]*gqlmodels.ProductSuggestion)
+		var entries []search.Entry
+		for id, p := range flat {
+			if sku, _ := p["sku"].(string); sku != "" {
+				s.skus[sku] = id
+			}
+			if ps, completions, ok := productSuggestion(p); ok {
+				info[id] = ps
+				for _, e := range completions {
+					e.Key = id
+					entries = append(entries, e)
+				}
+			}
+		}
+		trie := search.NewTrie(entries)
+		sx.mu.Lock()
+		sx.products, sx.productInfo = trie, info
+		sx.mu.Unlock()
+	}
+	return sx, nil
+}
+
+// putProduct updates the completions of a changed product from its flat product (nil when
+// deleted). The caller holds memorySearch.mu.
+func (sx *suggestIndex) putProduct(id uint, p map[string]interface{}) {
+	info, entries, ok := productSuggestion(p)
+	sx.mu.Lock()
+	defer sx.mu.Unlock()
+	if !ok {
+		sx.products.Remove(id)
+		delete(sx.productInfo, id)
+		return
+	}
+	sx.products.Put(id, entries)
+	sx.productInfo[id] = info
+}
+
+// productSuggestion returns the suggestion of a flat product and its name and SKU completions;
+// false when it is not searchable.
+func productSuggestion(p map[string]interface{}) (*gqlmodels.ProductSuggestion, []search.Entry, bool) {
+	if !searchable(p) {
+		return nil, nil, false
+	}
+	sku, _ := p["sku"].(string)
+	info := &gqlmodels.ProductSuggestion{SKU: sku}
+	var entries []search.Entry
+	if name, ok := p["name"].(string); ok && name != "" {
+		info.Name = &name
+		entries = append(entries, search.Entry{Text: name, Weight: suggestWeightName})
+	}
+	if urlKey, ok := p["url_key"].(string); ok && urlKey != "" {
+		info.URLKey = &urlKey
+	}
+	entries = append(entries, search.Entry{Text: sku, Weight: suggestWeightSKU})
+	return info, entries, true
+}
+
+// refreshLists reloads the category and term tries in the background once they are older than
+// suggestTTL; completions use the old ones until the new ones are swapped in.
+func (sx *suggestIndex) refreshLists(db *gorm.DB, storeID uint16) {
+	sx.mu.Lock()
+	defer sx.mu.Unlock()
+	if sx.refreshing || time.Since(sx.lists.builtAt) < suggestTTL {
+		return
+	}
+	sx.refreshing = true
+	go func() {
+		lists := loadSuggestLists(db, storeID)
+		sx.mu.Lock()
+		sx.lists, sx.refreshing = lists, false
+		sx.mu.Unlock()
+	}()
+}
+
+// loadSuggestLists builds the category and popular term tries of a store. Both are optional: a
+// store without them still gets product suggestions.
+func loadSuggestLists(db *gorm.DB, storeID uint16) *suggestLists {
+	l := &suggestLists{builtAt: time.Now(), categoryInfo: make(map[uint]*gqlmodels.CategorySuggestion)}
+	var categories []search.Entry
+	if cats, err := categoryRepo.GetCategoryRepository(db).FetchAllWithAttributesMap(storeID); err == nil {
+		for id, cat := range cats {
+			name, _ := categoryAttrValue(cat, "name").(string)
+			if cat.Level < 2 || name == "" || toUint(categoryAttrValue(cat, "is_active")) != 1 {
+				continue
+			}
+			info := &gqlmodels.CategorySuggestion{ID: int32(id), UID: uidEncode(id), Name: name}
+			if urlPath, ok := categoryAttrValue(cat, "url_path").(string); ok && urlPath != "" {
+				info.URLPath = &urlPath
+			}
+			l.categoryInfo[id] = info
+			categories = append(categories, search.Entry{Text: name, Key: id})
+		}
+	}
+	l.categories = search.NewTrie(categories)
+
+	var terms []search.Entry
+	if rows, err := searchRepo.GetSearchQueryRepository(db).PopularTerms(storeID); err == nil {
+		for _, q := range rows {
+			terms = append(terms, search.Entry{Text: q.QueryText, Key: uint(len(l.termInfo)), Weight: float64(q.Popularity)})
+			l.termInfo = append(l.termInfo, &gqlmodels.SearchTermSuggestion{
+				QueryText:  q.QueryText,
+				NumResults: int32(q.NumResults),
+				Popularity: int32(q.Popularity),
+			})
+		}
+	}
+	l.terms = search.NewTrie(terms)
+	return l
+}
+
+func categoryAttrValue(cat categoryRepo.CategoryWithAttributes, code string) interface{} {
+	if a, ok := cat.Attributes[code]; ok {
+		return a["value"]
+	}
+	return nil
+}
+
+// suggestProducts completes product names (match_phrase_prefix) and SKUs (prefix) on the store's
+// Elasticsearch index. Text is the SKU when the query starts it, otherwise the name.
+func (s *SearchService) suggestProducts(ctx context.Context, db *gorm.DB, storeID uint16, query string, limit int) ([]*gqlmodels.ProductSuggestion, error) {
+	body := map[string]interface{}{
+		"size":    limit,
+		"_source": []string{"entity_id"},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []map[string]interface{}{
+					{"match_phrase_prefix": map[string]interface{}{"name": map[string]interface{}{"query": query}}},
+					{"prefix": map[string]interface{}{"sku": map[string]interface{}{"value": query, "case_insensitive": true}}},
+				},
+				"minimum_should_match": 1,
+			},
+		},
+	}
+	res, err := s.run(ctx, s.indexName(db, storeID), body)
+	if err != nil || len(res.ids) == 0 {
+		return []*gqlmodels.ProductSuggestion{}, err
+	}
+	ids := res.ids
+	flat, err := productRepo.GetProductRepository(db).FetchWithAllAttributesFlatByIDs(ids, storeID)
+	if err != nil {
+		return nil, err
+	}
+	products := make([]*gqlmodels.ProductSuggestion, 0, len(ids))
+	for _, id := range ids {
+		p, ok := flat[id]
+		if !ok {
+			continue
+		}
+		sku, _ := p["sku"].(string)
+		info := &gqlmodels.ProductSuggestion{SKU: sku, Text: sku}
+		if name, ok := p["name"].(string); ok && name != "" {
+			info.Name = &name
+			if !strings.HasPrefix(strings.ToLower(sku), strings.ToLower(strings.TrimSpace(query))) {
+				info.Text = name
+			}
+		}
+		if urlKey, ok := p["url_key"].(string); ok && urlKey != "" {
+			info.URLKey = &urlKey
+		}
+		products = append(products, info)
+	}
+	return products, nil
+}
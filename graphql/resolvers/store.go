@@ -0,0 +1,126 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	gqlmodels "magento.GO/graphql/models"
+	storeEntity "magento.GO/model/entity/store"
+	configRepo "magento.GO/model/repository/config"
+	storeRepo "magento.GO/model/repository/store"
+)
+
+func (r *QueryResolver) storeRepo() *storeRepo.StoreRepository {
+	return storeRepo.GetStoreRepository(r.db)
+}
+
+func (r *QueryResolver) configRepo() *configRepo.ConfigRepository {
+	return configRepo.GetConfigRepository(r.db)
+}
+
+// StoreConfig returns the current store view's configuration; store 0 resolves to the default store view.
+func (r *QueryResolver) StoreConfig(ctx context.Context) (*gqlmodels.StoreConfig, error) {
+	stores := r.storeRepo()
+	s, ok := stores.GetByID(stores.ResolveStoreID(r.storeID(ctx)))
+	if !ok {
+		return nil, errors.New("no store view is configured")
+	}
+	return r.storeConfig(s), nil
+}
+
+// AvailableStores returns the active store views of the current website, or of the current store
+// group when useCurrentGroup is set.
+func (r *QueryResolver) AvailableStores(ctx context.Context, args struct{ UseCurrentGroup *bool }) (*[]*gqlmodels.StoreConfig, error) {
+	result := []*gqlmodels.StoreConfig{}
+	stores := r.storeRepo()
+	current, ok := stores.GetByID(stores.ResolveStoreID(r.storeID(ctx)))
+	if !ok {
+		return &result, nil
+	}
+	var groupID *uint16
+	if args.UseCurrentGroup != nil && *args.UseCurrentGroup {
+		groupID = &current.GroupID
+	}
+	list, err := stores.WebsiteStores(current.WebsiteID, groupID)
+	if err != nil {
+		return &result, nil
+	}
+	for _, s := range list {
+		result = append(result, r.storeConfig(s))
+	}
+	return &result, nil
+}
+
+func (r *QueryResolver) storeConfig(s storeEntity.Store) *gqlmodels.StoreConfig {
+	cfg := r.configRepo()
+	get := func(path string) *string {
+		v := cfg.Get(path, s.StoreID)
+		return &v
+	}
+	url := func(path string) *string {
+		v := cfg.GetURL(path, s.StoreID)
+		return &v
+	}
+	getInt := func(path string) *int32 {
+		n, err := strconv.Atoi(cfg.Get(path, s.StoreID))
+		if err != nil {
+			return nil
+		}
+		v := int32(n)
+		return &v
+	}
+
+	name := s.Name
+	sortOrder := int32(s.SortOrder)
+	websiteID := int32(s.WebsiteID)
+	useStoreInURL := cfg.Get(configRepo.PathUseStoreInURL, s.StoreID) == "1"
+	sc := &gqlmodels.StoreConfig{
+		ID:                         int32(s.StoreID),
+		StoreCode:                  s.Code,
+		StoreName:                  &name,
+		StoreSortOrder:             &sortOrder,
+		WebsiteID:                  &websiteID,
+		Locale:                     get(configRepo.PathLocale),
+		Timezone:                   get(configRepo.PathTimezone),
+		WeightUnit:                 get(configRepo.PathWeightUnit),
+		BaseCurrencyCode:           get(configRepo.PathBaseCurrency),
+		DefaultDisplayCurrencyCode: get(configRepo.PathDefaultCurrency),
+		BaseURL:                    url(configRepo.PathUnsecureBaseURL),
+		BaseLinkURL:                url(configRepo.PathUnsecureBaseLinkURL),
+		BaseMediaURL:               url(configRepo.PathUnsecureBaseMediaURL),
+		SecureBaseURL:              url(configRepo.PathSecureBaseURL),
+		SecureBaseLinkURL:          url(configRepo.PathSecureBaseLinkURL),
+		SecureBaseMediaURL:         url(configRepo.PathSecureBaseMediaURL),
+		UseStoreInURL:              &useStoreInURL,
+		ProductURLSuffix:           get(configRepo.PathProductURLSuffix),
+		CategoryURLSuffix:          get(configRepo.PathCategoryURLSuffix),
+		TitleSeparator:             get(configRepo.PathTitleSeparator),
+		DefaultTitle:               get(configRepo.PathDefaultTitle),
+		DefaultDescription:         get(configRepo.PathDefaultDescription),
+		DefaultKeywords:            get(configRepo.PathDefaultKeywords),
+		CmsHomePage:                get(configRepo.PathCmsHomePage),
+		CatalogDefaultSortBy:       get(configRepo.PathDefaultSortBy),
+		GridPerPage:                getInt(configRepo.PathGridPerPage),
+		ListPerPage:                getInt(configRepo.PathListPerPage),
+	}
+	if g, ok := r.storeRepo().GetGroup(s.GroupID); ok {
+		code, groupName := g.Code, g.Name
+		isDefault := g.DefaultStoreID == s.StoreID
+		rootID := int32(g.RootCategoryID)
+		rootUID := uidEncode(g.RootCategoryID)
+		sc.StoreGroupCode = &code
+		sc.StoreGroupName = &groupName
+		sc.IsDefaultStore = &isDefault
+		sc.RootCategoryID = &rootID
+		sc.RootCategoryUID = &rootUID
+	}
+	if w, ok := r.storeRepo().GetWebsite(s.WebsiteID); ok {
+		code, websiteName := w.Code, w.Name
+		isDefaultGroup := w.DefaultGroupID == s.GroupID
+		sc.WebsiteCode = &code
+		sc.WebsiteName = &websiteName
+		sc.IsDefaultStoreGroup = &isDefaultGroup
+	}
+	return sc
+}
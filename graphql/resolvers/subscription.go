@@ -0,0 +1,111 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+
+	"magento.GO/core/events"
+	"magento.GO/graphql"
+	gqlmodels "magento.GO/graphql/models"
+	inventoryRepo "magento.GO/model/repository/inventory"
+	priceRepo "magento.GO/model/repository/price"
+)
+
+// maxSubscriptionSKUs is the largest number of SKUs one subscription may watch.
+const maxSubscriptionSKUs = 100
+
+// StockChanged sends the stock of a subscribed SKU each time it changes.
+func (r *SubscriptionResolver) StockChanged(ctx context.Context, args graphql.SubscriptionSKUsArgs) (<-chan *gqlmodels.StockChange, error) {
+	repo, err := inventoryRepo.GetInventoryRepository(r.db)
+	if err != nil {
+		return nil, err
+	}
+	return watchChanges(ctx, events.TopicStock, args.Skus, func(skus []string) (map[string]gqlmodels.StockChange, error) {
+		stock, err := repo.GetStockBySKUs(skus)
+		if err != nil {
+			return nil, err
+		}
+		changes := make(map[string]gqlmodels.StockChange, len(stock))
+		for sku, s := range stock {
+			inStock := s.InStock && (!s.Managed || s.Qty > 0)
+			status := "OUT_OF_STOCK"
+			if inStock {
+				status = "IN_STOCK"
+			}
+			changes[sku] = gqlmodels.StockChange{SKU: sku, Qty: s.Qty, IsInStock: inStock, StockStatus: status}
+		}
+		return changes, nil
+	})
+}
+
+// PriceChanged sends the lowest price of a subscribed SKU for the website and customer group, in
+// the display currency of the connection, each time it changes.
+func (r *SubscriptionResolver) PriceChanged(ctx context.Context, args graphql.SubscriptionSKUsArgs) (<-chan *gqlmodels.PriceChange, error) {
+	repo, err := priceRepo.GetPriceRepository(r.db)
+	if err != nil {
+		return nil, err
+	}
+	websiteID, groupID := r.websiteID(ctx), int(r.customerGroupID(ctx))
+	cur := r.currency(ctx)
+	return watchChanges(ctx, events.TopicPrice, args.Skus, func(skus []string) (map[string]gqlmodels.PriceChange, error) {
+		prices, err := repo.GetLowestPricesBySKUs(skus, websiteID, groupID)
+		if err != nil {
+			return nil, err
+		}
+		changes := make(map[string]gqlmodels.PriceChange, len(prices))
+		for sku, price := range prices {
+			changes[sku] = gqlmodels.PriceChange{SKU: sku, Price: cur.Convert(price), Currency: cur.Display}
+		}
+		return changes, nil
+	})
+}
+
+// watchChanges streams the values of skus published on topic. Published SKUs are reloaded with
+// load, and a value is sent only when it differs from the last one sent, or from the value when
+// the subscription started: importers and the change poller may publish SKUs that did not change.
+// The stream ends when ctx is done.
+func watchChanges[T comparable](ctx context.Context, topic string, skus []string, load func([]string) (map[string]T, error)) (<-chan *T, error) {
+	if len(skus) == 0 {
+		return nil, fmt.Errorf("skus must not be empty")
+	}
+	if len(skus) > maxSubscriptionSKUs {
+		return nil, fmt.Errorf("at most %d skus can be subscribed to, got %d", maxSubscriptionSKUs, len(skus))
+	}
+	// Subscribe before loading the initial values, so no change in between is missed.
+	sub := events.GetInstance().Subscribe(topic, skus)
+	last, err := load(skus)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+	ch := make(chan *T)
+	go func() {
+		defer close(ch)
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.C():
+			}
+			changed := sub.Take()
+			values, err := load(changed)
+			if err != nil {
+				continue // sent when the SKU is published again
+			}
+			for _, sku := range changed {
+				v, ok := values[sku]
+				if !ok || v == last[sku] {
+					continue
+				}
+				last[sku] = v
+				select {
+				case ch <- &v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
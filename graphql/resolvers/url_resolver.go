@@ -0,0 +1,90 @@
+package resolvers
+
+import (
+	"context"
+
+	gql "github.com/graph-gophers/graphql-go"
+
+	gqlmodels "magento.GO/graphql/models"
+	entity "magento.GO/model/entity"
+	urlRewriteRepo "magento.GO/model/repository/urlrewrite"
+)
+
+func (r *QueryResolver) urlRewriteRepo() *urlRewriteRepo.UrlRewriteRepository {
+	return urlRewriteRepo.GetUrlRewriteRepository(r.db)
+}
+
+// UrlResolver resolves a storefront URL to its entity via url_rewrite (Magento urlResolver).
+func (r *QueryResolver) UrlResolver(ctx context.Context, args struct{ URL string }) (*gqlmodels.EntityUrl, error) {
+	res, err := r.urlRewriteRepo().Resolve(args.URL, r.storeID(ctx))
+	if err != nil || res == nil {
+		return nil, nil
+	}
+	relativeURL := res.RelativeURL
+	out := &gqlmodels.EntityUrl{
+		CanonicalURL: &relativeURL,
+		RelativeURL:  &relativeURL,
+		RedirectCode: int32(res.RedirectCode),
+		Type:         urlRewriteEntityType(res.Rewrite.EntityType),
+	}
+	if out.Type != nil {
+		id := int32(res.Rewrite.EntityID)
+		uid := uidEncode(res.Rewrite.EntityID)
+		out.ID = &id
+		out.EntityUID = &uid
+	}
+	return out, nil
+}
+
+// Route resolves a storefront URL to the routable entity itself (Magento route). Products resolve
+// as their ProductInterface type and categories as CategoryTree; other rewrites resolve as RoutableUrl.
+func (r *QueryResolver) Route(ctx context.Context, args struct{ URL string }) (*gqlmodels.Routable, error) {
+	res, err := r.urlRewriteRepo().Resolve(args.URL, r.storeID(ctx))
+	if err != nil || res == nil {
+		return nil, nil
+	}
+	relativeURL := res.RelativeURL
+	routable := gqlmodels.RoutableUrl{
+		RelativeURL:  &relativeURL,
+		RedirectCode: int32(res.RedirectCode),
+		Type:         urlRewriteEntityType(res.Rewrite.EntityType),
+	}
+	out := &gqlmodels.Routable{RoutableUrl: routable}
+
+	switch res.Rewrite.EntityType {
+	case entity.UrlRewriteEntityProduct:
+		p, ok, err := r.loaders(ctx).Products(r.storeID(ctx)).Load(res.Rewrite.EntityID)
+		if err != nil || !ok || toUint(p["status"]) == productStatusDisabled {
+			return nil, nil
+		}
+		p = filterPriceForGroup(p, r.customerGroupID(ctx))
+		withConfigurable := gql.HasSelectedField(ctx, "variants") || gql.HasSelectedField(ctx, "configurable_options")
+		out.Product = r.toProductInterfaces(ctx, []map[string]interface{}{p}, "", withConfigurable)[0]
+		out.Product.SetRoutable(routable)
+	case entity.UrlRewriteEntityCategory:
+		c, ok, err := r.loaders(ctx).Categories(r.storeID(ctx)).Load(res.Rewrite.EntityID)
+		if err != nil || !ok {
+			return nil, nil
+		}
+		out.Category = categoryToCategoryTree(&c.Category, c.Attributes)
+		tagCategories(ctx, c.EntityID)
+		out.Category.RoutableUrl = routable
+	}
+	return out, nil
+}
+
+// urlRewriteEntityType maps url_rewrite.entity_type to UrlRewriteEntityTypeEnum; nil for custom rewrites.
+func urlRewriteEntityType(entityType string) *string {
+	var t string
+	switch entityType {
+	case entity.UrlRewriteEntityProduct:
+		t = "PRODUCT"
+	case entity.UrlRewriteEntityCategory:
+		t = "CATEGORY"
+	case entity.UrlRewriteEntityCmsPage:
+		t = "CMS_PAGE"
+	default:
+		return nil
+	}
+	return &t
+}
@@ -1,10 +1,16 @@
 package graphql
 
 import (
+	"fmt"
 	"strings"
 	"sync"
 
 	_ "embed"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+
+	gqlregistry "magento.GO/graphql/registry"
 )
 
 //go:embed schema.graphqls
@@ -22,8 +28,8 @@ func RegisterSchemaExtension(schema string) {
 	schemaExtensions = append(schemaExtensions, strings.TrimSpace(schema))
 }
 
-// Schema returns base schema + registered extensions.
-func Schema() string {
+// CoreSchema returns base schema + registered extensions: the fields the core resolvers serve.
+func CoreSchema() string {
 	schemaMu.Lock()
 	ext := schemaExtensions
 	schemaMu.Unlock()
@@ -33,6 +39,102 @@ func Schema() string {
 	return schemaBase + "\n\n" + strings.Join(ext, "\n\n")
 }
 
+// Schema returns the schema clients see: CoreSchema merged with the typed extensions of
+// gqlregistry.RegisterSchema. Panics on conflicts.
+func Schema() string {
+	merged, err := MergeSchemaExtensions(CoreSchema(), gqlregistry.SchemaExtensions())
+	if err != nil {
+		panic("graphql schema: " + err.Error())
+	}
+	return merged.SDL
+}
+
+// MergedSchema is a schema with typed extensions merged in.
+type MergedSchema struct {
+	SDL string
+	// Owners is the extension adding each root field, by operation kind ("query", "mutation") and
+	// field name. The other root fields are resolved by the core resolvers.
+	Owners map[string]map[string]string
+}
+
+// MergeSchemaExtensions adds exts to core, in order: their types, extensions of existing types and
+// fields on Query and Mutation. Each step is parsed, so the first extension that redefines a type,
+// adds a field a type already has, extends an unknown type or adds Subscription fields is reported.
+func MergeSchemaExtensions(core string, exts []gqlregistry.SchemaExtension) (*MergedSchema, error) {
+	prev, err := gql.ParseSchema(core, nil)
+	if err != nil {
+		return nil, err
+	}
+	merged := &MergedSchema{SDL: core, Owners: make(map[string]map[string]string)}
+	for _, ext := range exts {
+		var parts []string
+		if t := strings.TrimSpace(ext.Types); t != "" {
+			parts = append(parts, t)
+		}
+		if q := strings.TrimSpace(ext.Query); q != "" {
+			parts = append(parts, "extend type Query {\n"+q+"\n}")
+		}
+		if m := strings.TrimSpace(ext.Mutation); m != "" {
+			parts = append(parts, "extend type Mutation {\n"+m+"\n}")
+		}
+		if len(parts) == 0 {
+			return nil, fmt.Errorf("extension %q adds no types or fields", ext.Name)
+		}
+		sdl := merged.SDL + "\n\n" + strings.Join(parts, "\n\n")
+		next, err := gql.ParseSchema(sdl, nil)
+		if err != nil {
+			return nil, fmt.Errorf("extension %q: %v", ext.Name, err)
+		}
+		// graphql-go keeps the last definition of a type: one starting after the previous SDL is new.
+		lines := strings.Count(merged.SDL, "\n") + 1
+		for name, t := range next.AST().Types {
+			if _, ok := prev.AST().Types[name]; ok && typeLine(t) > lines {
+				return nil, fmt.Errorf("extension %q: type %s is already defined", ext.Name, name)
+			}
+		}
+		for kind, t := range next.AST().RootOperationTypes {
+			fields := t.(*ast.ObjectTypeDefinition).Fields
+			var before ast.FieldsDefinition
+			if p, ok := prev.AST().RootOperationTypes[kind].(*ast.ObjectTypeDefinition); ok {
+				before = p.Fields
+			}
+			for _, f := range fields {
+				if before.Get(f.Name) != nil {
+					continue
+				}
+				if kind == "subscription" {
+					return nil, fmt.Errorf("extension %q: Subscription fields cannot be added", ext.Name)
+				}
+				if merged.Owners[kind] == nil {
+					merged.Owners[kind] = make(map[string]string)
+				}
+				merged.Owners[kind][f.Name] = ext.Name
+			}
+		}
+		merged.SDL, prev = sdl, next
+	}
+	return merged, nil
+}
+
+// typeLine returns the line a type is defined on; 0 for built-in types.
+func typeLine(t ast.NamedType) int {
+	switch t := t.(type) {
+	case *ast.ObjectTypeDefinition:
+		return t.Loc.Line
+	case *ast.InterfaceTypeDefinition:
+		return t.Loc.Line
+	case *ast.Union:
+		return t.Loc.Line
+	case *ast.EnumTypeDefinition:
+		return t.Loc.Line
+	case *ast.InputObject:
+		return t.Loc.Line
+	case *ast.ScalarTypeDefinition:
+		return t.Loc.Line
+	}
+	return 0
+}
+
 // --- Schema arg types (used by resolvers for graphql-go method matching) ---
 
 type MagentoCategoryFilters struct {
@@ -43,13 +145,76 @@ type MagentoCategoryFilters struct {
 }
 
 type MagentoProductsArgs struct {
-	Filter *struct {
-		CategoryUID *struct {
-			In *[]*string
-			Eq *string
-		}
-	}
-	Sort        *struct{ Position *string }
+	Filter      ProductAttributeFilter
+	Sort        *ProductAttributeSortInput
 	PageSize    int32
 	CurrentPage int32
 }
+
+type ProductReviewRatingInput struct {
+	ID      string
+	ValueID string
+}
+
+type CreateProductReviewArgs struct {
+	Input struct {
+		Sku      string
+		Nickname string
+		Summary  string
+		Text     string
+		Ratings  []*ProductReviewRatingInput
+	}
+}
+
+type SubscriptionSKUsArgs struct {
+	Skus []string
+}
+
+type CartArgs struct {
+	CartID string
+}
+
+type CreateEmptyCartArgs struct {
+	Input *struct {
+		CartID *string
+	}
+}
+
+type EnteredOptionInput struct {
+	UID   string
+	Value string
+}
+
+type CartItemInput struct {
+	Sku             string
+	Quantity        float64
+	ParentSku       *string
+	SelectedOptions *[]string
+	EnteredOptions  *[]*EnteredOptionInput
+}
+
+type AddProductsToCartArgs struct {
+	CartID    string
+	CartItems []*CartItemInput
+}
+
+type CartItemUpdateInput struct {
+	CartItemID  *int32
+	CartItemUID *string
+	Quantity    *float64
+}
+
+type UpdateCartItemsArgs struct {
+	Input *struct {
+		CartID    string
+		CartItems []*CartItemUpdateInput
+	}
+}
+
+type RemoveItemFromCartArgs struct {
+	Input *struct {
+		CartID      string
+		CartItemID  *int32
+		CartItemUID *string
+	}
+}
@@ -112,8 +112,9 @@ func RegisterCategoryHTMLRoutes(e *echo.Echo, db *gorm.DB) {
 			return c.String(http.StatusBadRequest, "Invalid category ID")
 		}
 
+		storeID := api.StoreIDFromRequest(c)
 		start := time.Now()
-		cat, flat, err := repo.GetByIDWithAttributesAndFlat(uint(id), 0)
+		cat, flat, err := repo.GetByIDWithAttributesAndFlat(uint(id), storeID)
 		log.Printf("GetByIDWithAttributesAndFlat took %s", time.Since(start))
 		if err != nil || cat == nil {
 			return c.String(http.StatusNotFound, "Category not found")
@@ -143,7 +144,7 @@ func RegisterCategoryHTMLRoutes(e *echo.Echo, db *gorm.DB) {
 		var products []map[string]interface{}
 		if len(pagedProductIDs) > 0 {
 			start := time.Now()
-			flatProducts, err := prodRepo.FetchWithAllAttributesFlatByIDs(pagedProductIDs)
+			flatProducts, err := prodRepo.FetchWithAllAttributesFlatByIDs(pagedProductIDs, storeID)
 			log.Printf("FetchWithAllAttributesFlatByIDs took %s", time.Since(start))
 			if err == nil {
 				for _, id := range pagedProductIDs {
@@ -157,12 +158,12 @@ func RegisterCategoryHTMLRoutes(e *echo.Echo, db *gorm.DB) {
 		// Get category tree
 		tmpl := c.Echo().Renderer.(*Template)
 		start = time.Now()
-		categoryTree, err := repo.BuildCategoryTree(0, 0)
+		categoryTree, err := repo.BuildCategoryTree(storeID, 0)
 		log.Printf("BuildCategoryTree took %s", time.Since(start))
 		var categoryTreeHTML string
 		if err == nil {
 			start = time.Now()
-			categoryTreeHTML, err = RenderCategoryTreeCached(tmpl.Templates, categoryTree)
+			categoryTreeHTML, err = RenderCategoryTreeCached(tmpl.Templates, storeID, categoryTree)
 			log.Printf("RenderCategoryTreeCached took %s", time.Since(start))
 			if err != nil {
 				log.Println("Category tree render error:", err)
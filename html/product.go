@@ -20,18 +20,22 @@ import (
 	productRepo "magento.GO/model/repository/product"
 )
 
+// categoryTreeCache holds the rendered category tree of each store view for 30 minutes.
+type categoryTreeCache struct {
+	html string
+	at   time.Time
+}
+
 var (
-	categoryTreeHTMLCache string
-	categoryTreeCacheTime time.Time
+	categoryTreeHTMLCache = make(map[uint16]categoryTreeCache)
 	categoryTreeCacheLock sync.RWMutex
 )
 
-func RenderCategoryTreeCached(tmpl *template.Template, tree interface{}) (string, error) {
+func RenderCategoryTreeCached(tmpl *template.Template, storeID uint16, tree interface{}) (string, error) {
 	categoryTreeCacheLock.RLock()
-	if time.Since(categoryTreeCacheTime) < 30*time.Minute && categoryTreeHTMLCache != "" {
-		cached := categoryTreeHTMLCache
+	if cached, ok := categoryTreeHTMLCache[storeID]; ok && time.Since(cached.at) < 30*time.Minute && cached.html != "" {
 		categoryTreeCacheLock.RUnlock()
-		return cached, nil
+		return cached.html, nil
 	}
 	categoryTreeCacheLock.RUnlock()
 
@@ -43,11 +47,10 @@ func RenderCategoryTreeCached(tmpl *template.Template, tree interface{}) (string
 	}
 
 	categoryTreeCacheLock.Lock()
-	categoryTreeHTMLCache = buf.String()
-	categoryTreeCacheTime = time.Now()
+	categoryTreeHTMLCache[storeID] = categoryTreeCache{html: buf.String(), at: time.Now()}
 	categoryTreeCacheLock.Unlock()
 
-	return categoryTreeHTMLCache, nil
+	return buf.String(), nil
 }
 
 // Helper to build breadcrumbs from a category path string
@@ -154,7 +157,8 @@ func RegisterProductHTMLRoutes(e *echo.Echo, db *gorm.DB) {
 				ids = append(ids, uint(idUint))
 			}
 		}
-		flatProducts, err := repo.FetchWithAllAttributesFlatByIDs(ids)
+		storeID := api.StoreIDFromRequest(c)
+		flatProducts, err := repo.FetchWithAllAttributesFlatByIDs(ids, storeID)
 		if err != nil {
 			log.Println("Repo error:", err)
 			return c.String(http.StatusInternalServerError, "Error fetching products")
@@ -166,9 +170,9 @@ func RegisterProductHTMLRoutes(e *echo.Echo, db *gorm.DB) {
 				if idsVal, ok := prod["category_ids"]; ok {
 				
 					if lastCatID, ok := getLastCategoryID(idsVal); ok && lastCatID > 0 {
-						cat, _, err := catRepo.GetByIDWithAttributesAndFlat(lastCatID, 0)
+						cat, _, err := catRepo.GetByIDWithAttributesAndFlat(lastCatID, storeID)
 						if err == nil && cat != nil && cat.Path != "" {
-							breadcrumbs, _ := buildCategoryBreadcrumbs(catRepo, cat.Path, 0)
+							breadcrumbs, _ := buildCategoryBreadcrumbs(catRepo, cat.Path, storeID)
 							prod["Breadcrumbs"] = breadcrumbs
 							// Debug output
 							var bcIDs []uint
@@ -189,13 +193,13 @@ func RegisterProductHTMLRoutes(e *echo.Echo, db *gorm.DB) {
 				//log.Printf("Product %v: %v", id, prod)
 			}
 		}
-		categoryTree, err := catRepo.BuildCategoryTree(0, 0)
+		categoryTree, err := catRepo.BuildCategoryTree(storeID, 0)
 		if err != nil {
 			log.Println("Category tree error:", err)
 			categoryTree = nil
 		}
 		tmpl := c.Echo().Renderer.(*Template)
-		categoryTreeHTML, err := RenderCategoryTreeCached(tmpl.Templates, categoryTree)
+		categoryTreeHTML, err := RenderCategoryTreeCached(tmpl.Templates, storeID, categoryTree)
 		if err != nil {
 			log.Println("Category tree render error:", err)
 			categoryTreeHTML = ""
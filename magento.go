@@ -7,14 +7,12 @@ import (
 	"fmt"
 	"html/template"
 	"log"
-	"math/rand"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/common-nighthawk/go-figure"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"gorm.io/gorm"
@@ -25,6 +23,7 @@ import (
 	_ "magento.GO/api/product"
 	_ "magento.GO/api/realtime"
 	_ "magento.GO/api/sales"
+	_ "magento.GO/api/search"
 	_ "magento.GO/api/stock"
 	"magento.GO/config"
 	"magento.GO/core/auth"
@@ -99,6 +98,9 @@ func main() {
 	checkMagentoEdition(db)
 
 	e := echo.New()
+
+	// Resolve store codes and (with STORE_URL_MAPPING) the store of the Host and path prefix before routing
+	e.Pre(api.StoreMiddleware(db, api.StoreURLMappingFromEnv()))
 	
 	// Middleware to add cache control headers
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -162,7 +164,7 @@ func main() {
 	e.Renderer = t
 
 	for _, tmpl := range t.Templates.Templates() {
-		log.Println("Loaded template: %s", tmpl.Name())
+		log.Printf("Loaded template: %s", tmpl.Name())
 	}
 
 	apiGroup := e.Group("/api")
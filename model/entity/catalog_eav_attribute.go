@@ -0,0 +1,42 @@
+package entity
+
+// CatalogEavAttribute holds catalog-specific attribute settings (catalog_eav_attribute).
+// One row per product/category attribute, keyed by eav_attribute.attribute_id.
+type CatalogEavAttribute struct {
+	AttributeID          uint16  `gorm:"column:attribute_id;primaryKey"`
+	IsGlobal             uint16  `gorm:"column:is_global;type:smallint unsigned;not null;default:1"`
+	IsVisible            uint16  `gorm:"column:is_visible;type:smallint unsigned;not null;default:1"`
+	IsSearchable         uint16  `gorm:"column:is_searchable;type:smallint unsigned;not null;default:0"`
+	IsFilterable         uint16  `gorm:"column:is_filterable;type:smallint unsigned;not null;default:0"`
+	IsComparable         uint16  `gorm:"column:is_comparable;type:smallint unsigned;not null;default:0"`
+	IsVisibleOnFront     uint16  `gorm:"column:is_visible_on_front;type:smallint unsigned;not null;default:0"`
+	IsFilterableInSearch uint16  `gorm:"column:is_filterable_in_search;type:smallint unsigned;not null;default:0"`
+	UsedInProductListing uint16  `gorm:"column:used_in_product_listing;type:smallint unsigned;not null;default:0"`
+	UsedForSortBy        uint16  `gorm:"column:used_for_sort_by;type:smallint unsigned;not null;default:0"`
+	Position             int     `gorm:"column:position;not null;default:0"`
+	SearchWeight         float64 `gorm:"column:search_weight;not null;default:1"`
+}
+
+func (CatalogEavAttribute) TableName() string {
+	return "catalog_eav_attribute"
+}
+
+/* Usage Examples:
+
+1. Create:
+   attr := &CatalogEavAttribute{
+       AttributeID: 93,
+       IsFilterable: 1,
+   }
+   db.Create(attr)
+
+2. Read:
+   var attr CatalogEavAttribute
+   db.First(&attr, 93)
+
+3. Update:
+   db.Model(&attr).Update("IsFilterable", 2)
+
+4. Delete:
+   db.Delete(&attr)
+*/
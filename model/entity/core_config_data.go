@@ -0,0 +1,42 @@
+package entity
+
+// core_config_data scopes, from least to most specific.
+const (
+	ConfigScopeDefault  = "default"
+	ConfigScopeWebsites = "websites"
+	ConfigScopeStores   = "stores"
+)
+
+// CoreConfigData is one system configuration value (core_config_data) for a scope:
+// default (scope_id 0), a website or a store view.
+type CoreConfigData struct {
+	ConfigID uint    `gorm:"column:config_id;primaryKey;autoIncrement"`
+	Scope    string  `gorm:"column:scope;type:varchar(8);not null;default:default"`
+	ScopeID  uint    `gorm:"column:scope_id;not null;default:0"`
+	Path     string  `gorm:"column:path;type:varchar(255);not null;default:general"`
+	Value    *string `gorm:"column:value;type:text"`
+}
+
+func (CoreConfigData) TableName() string {
+	return "core_config_data"
+}
+
+/* Usage Examples:
+
+1. Create:
+   suffix := ".html"
+   c := &CoreConfigData{
+       Scope: ConfigScopeStores,
+       ScopeID: 1,
+       Path: "catalog/seo/product_url_suffix",
+       Value: &suffix,
+   }
+   db.Create(c)
+
+2. Read:
+   var rows []CoreConfigData
+   db.Where("path = ?", "web/unsecure/base_url").Find(&rows)
+
+3. Delete:
+   db.Delete(&c)
+*/
@@ -0,0 +1,39 @@
+package customer
+
+import "time"
+
+// Customer is a storefront customer account (customer_entity). Only the columns the storefront
+// reads are mapped.
+type Customer struct {
+	EntityID  uint      `gorm:"column:entity_id;primaryKey;autoIncrement"`
+	WebsiteID *uint16   `gorm:"column:website_id;type:smallint unsigned"`
+	Email     *string   `gorm:"column:email;type:varchar(255)"`
+	GroupID   uint      `gorm:"column:group_id;type:smallint unsigned;not null;default:0"`
+	StoreID   *uint16   `gorm:"column:store_id;type:smallint unsigned;default:0"`
+	Firstname *string   `gorm:"column:firstname;type:varchar(255)"`
+	Lastname  *string   `gorm:"column:lastname;type:varchar(255)"`
+	IsActive  uint16    `gorm:"column:is_active;type:smallint unsigned;not null;default:1"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName specifies the table name
+func (Customer) TableName() string {
+	return "customer_entity"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   email := "b2b@example.com"
+   c := &Customer{Email: &email, GroupID: 2}
+   db.Create(c)
+   ```
+
+2. Read:
+   ```go
+   var c Customer
+   db.Where("entity_id = ? AND is_active = 1", customerID).First(&c)
+   ```
+*/
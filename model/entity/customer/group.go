@@ -0,0 +1,13 @@
+package customer
+
+// Group is a customer group (customer_group). Prices can differ per group through tier prices.
+type Group struct {
+	CustomerGroupID   uint   `gorm:"column:customer_group_id;primaryKey;autoIncrement:false"`
+	CustomerGroupCode string `gorm:"column:customer_group_code;type:varchar(32);not null"`
+	TaxClassID        uint   `gorm:"column:tax_class_id;type:int unsigned;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (Group) TableName() string {
+	return "customer_group"
+}
@@ -0,0 +1,27 @@
+package entity
+
+// DirectoryCurrencyRate is the exchange rate from one currency to another (directory_currency_rate).
+// Magento stores rates from the base currency to each allowed display currency.
+type DirectoryCurrencyRate struct {
+	CurrencyFrom string  `gorm:"column:currency_from;type:varchar(3);primaryKey"`
+	CurrencyTo   string  `gorm:"column:currency_to;type:varchar(3);primaryKey"`
+	Rate         float64 `gorm:"column:rate;type:decimal(24,12);not null;default:0"`
+}
+
+func (DirectoryCurrencyRate) TableName() string {
+	return "directory_currency_rate"
+}
+
+/* Usage Examples:
+
+1. Create:
+   r := &DirectoryCurrencyRate{CurrencyFrom: "USD", CurrencyTo: "EUR", Rate: 0.92}
+   db.Create(r)
+
+2. Read:
+   var rates []DirectoryCurrencyRate
+   db.Where("currency_from = ?", "USD").Find(&rates)
+
+3. Delete:
+   db.Delete(&DirectoryCurrencyRate{}, "currency_from = ? AND currency_to = ?", "USD", "EUR")
+*/
@@ -0,0 +1,29 @@
+package entity
+
+// EavAttributeOption is one option of a select/multiselect attribute (eav_attribute_option).
+type EavAttributeOption struct {
+	OptionID    uint32 `gorm:"column:option_id;primaryKey;autoIncrement"`
+	AttributeID uint16 `gorm:"column:attribute_id;type:smallint unsigned;not null;default:0;index"`
+	SortOrder   uint16 `gorm:"column:sort_order;type:smallint unsigned;not null;default:0"`
+}
+
+func (EavAttributeOption) TableName() string {
+	return "eav_attribute_option"
+}
+
+/* Usage Examples:
+
+1. Create:
+   opt := &EavAttributeOption{
+       AttributeID: 93,
+       SortOrder: 1,
+   }
+   db.Create(opt)
+
+2. Read:
+   var opts []EavAttributeOption
+   db.Where("attribute_id = ?", 93).Order("sort_order").Find(&opts)
+
+3. Delete:
+   db.Delete(&opt)
+*/
@@ -0,0 +1,40 @@
+package entity
+
+// Swatch types stored in eav_attribute_option_swatch.type.
+const (
+	SwatchTypeText  = 0
+	SwatchTypeColor = 1
+	SwatchTypeImage = 2
+)
+
+// EavAttributeOptionSwatch is the per-store swatch of an attribute option (eav_attribute_option_swatch).
+type EavAttributeOptionSwatch struct {
+	SwatchID uint32  `gorm:"column:swatch_id;primaryKey;autoIncrement"`
+	OptionID uint32  `gorm:"column:option_id;not null;default:0;index"`
+	StoreID  uint16  `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+	Type     uint16  `gorm:"column:type;type:smallint unsigned;not null"`
+	Value    *string `gorm:"column:value;type:varchar(255)"`
+}
+
+func (EavAttributeOptionSwatch) TableName() string {
+	return "eav_attribute_option_swatch"
+}
+
+/* Usage Examples:
+
+1. Create:
+   hex := "#000000"
+   sw := &EavAttributeOptionSwatch{
+       OptionID: 49,
+       Type: SwatchTypeColor,
+       Value: &hex,
+   }
+   db.Create(sw)
+
+2. Read:
+   var sws []EavAttributeOptionSwatch
+   db.Where("option_id IN ? AND store_id IN ?", []uint32{49, 50}, []uint16{0, 1}).Find(&sws)
+
+3. Delete:
+   db.Delete(&sw)
+*/
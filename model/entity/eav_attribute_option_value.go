@@ -0,0 +1,35 @@
+package entity
+
+// EavAttributeOptionValue is the per-store label of an attribute option (eav_attribute_option_value).
+// store_id 0 holds the admin/default label.
+type EavAttributeOptionValue struct {
+	ValueID  uint32 `gorm:"column:value_id;primaryKey;autoIncrement"`
+	OptionID uint32 `gorm:"column:option_id;not null;default:0;index"`
+	StoreID  uint16 `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+	Value    string `gorm:"column:value;type:varchar(255)"`
+}
+
+func (EavAttributeOptionValue) TableName() string {
+	return "eav_attribute_option_value"
+}
+
+/* Usage Examples:
+
+1. Create:
+   val := &EavAttributeOptionValue{
+       OptionID: 49,
+       StoreID: 0,
+       Value: "Black",
+   }
+   db.Create(val)
+
+2. Read:
+   var vals []EavAttributeOptionValue
+   db.Where("option_id = ? AND store_id IN ?", 49, []uint16{0, 1}).Find(&vals)
+
+3. Update:
+   db.Model(&val).Update("Value", "Jet Black")
+
+4. Delete:
+   db.Delete(&val)
+*/
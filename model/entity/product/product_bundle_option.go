@@ -0,0 +1,40 @@
+package product
+
+// ProductBundleOption is an option group of a bundle product (catalog_product_bundle_option).
+// Type is select, radio, checkbox or multi.
+type ProductBundleOption struct {
+	OptionID uint   `gorm:"column:option_id;primaryKey;autoIncrement"`
+	ParentID uint   `gorm:"column:parent_id;type:int unsigned;not null;index"`
+	Required uint16 `gorm:"column:required;type:smallint unsigned;not null;default:0"`
+	Position uint   `gorm:"column:position;type:int unsigned;not null;default:0"`
+	Type     string `gorm:"column:type;type:varchar(255)"`
+}
+
+// TableName specifies the table name
+func (ProductBundleOption) TableName() string {
+	return "catalog_product_bundle_option"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   opt := &ProductBundleOption{
+       ParentID: 20,
+       Required: 1,
+       Type: "radio",
+   }
+   db.Create(opt)
+   ```
+
+2. Read:
+   ```go
+   var opts []ProductBundleOption
+   db.Where("parent_id = ?", 20).Order("position").Find(&opts)
+   ```
+
+3. Delete:
+   ```go
+   db.Delete(&opt)
+   ```
+*/
@@ -0,0 +1,35 @@
+package product
+
+// ProductBundleOptionValue is the per-store title of a bundle option (catalog_product_bundle_option_value).
+type ProductBundleOptionValue struct {
+	ValueID         uint   `gorm:"column:value_id;primaryKey;autoIncrement"`
+	OptionID        uint   `gorm:"column:option_id;type:int unsigned;not null;index"`
+	ParentProductID uint   `gorm:"column:parent_product_id;type:int unsigned;not null"`
+	StoreID         uint16 `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+	Title           string `gorm:"column:title;type:varchar(255)"`
+}
+
+// TableName specifies the table name
+func (ProductBundleOptionValue) TableName() string {
+	return "catalog_product_bundle_option_value"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   val := &ProductBundleOptionValue{
+       OptionID: 1,
+       ParentProductID: 20,
+       StoreID: 0,
+       Title: "Choose a size",
+   }
+   db.Create(val)
+   ```
+
+2. Read:
+   ```go
+   var vals []ProductBundleOptionValue
+   db.Where("option_id IN ? AND store_id IN ?", []uint{1, 2}, []uint16{0, 1}).Find(&vals)
+   ```
+*/
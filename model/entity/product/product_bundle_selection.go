@@ -0,0 +1,47 @@
+package product
+
+// ProductBundleSelection is a product selectable in a bundle option (catalog_product_bundle_selection).
+// SelectionPriceType is 0 (fixed) or 1 (percent); it only applies to fixed-price bundles.
+type ProductBundleSelection struct {
+	SelectionID           uint    `gorm:"column:selection_id;primaryKey;autoIncrement"`
+	OptionID              uint    `gorm:"column:option_id;type:int unsigned;not null;index"`
+	ParentProductID       uint    `gorm:"column:parent_product_id;type:int unsigned;not null"`
+	ProductID             uint    `gorm:"column:product_id;type:int unsigned;not null"`
+	Position              uint    `gorm:"column:position;type:int unsigned;not null;default:0"`
+	IsDefault             uint16  `gorm:"column:is_default;type:smallint unsigned;not null;default:0"`
+	SelectionPriceType    uint16  `gorm:"column:selection_price_type;type:smallint unsigned;not null;default:0"`
+	SelectionPriceValue   float64 `gorm:"column:selection_price_value;type:decimal(20,6);not null;default:0"`
+	SelectionQty          float64 `gorm:"column:selection_qty;type:decimal(12,4)"`
+	SelectionCanChangeQty int16   `gorm:"column:selection_can_change_qty;type:smallint;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (ProductBundleSelection) TableName() string {
+	return "catalog_product_bundle_selection"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   sel := &ProductBundleSelection{
+       OptionID: 1,
+       ParentProductID: 20,
+       ProductID: 21,
+       SelectionQty: 1,
+       IsDefault: 1,
+   }
+   db.Create(sel)
+   ```
+
+2. Read:
+   ```go
+   var sels []ProductBundleSelection
+   db.Where("parent_product_id = ?", 20).Order("option_id, position").Find(&sels)
+   ```
+
+3. Delete:
+   ```go
+   db.Delete(&sel)
+   ```
+*/
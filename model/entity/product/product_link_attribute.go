@@ -0,0 +1,54 @@
+package product
+
+// ProductLinkAttribute declares a per-link attribute such as position or qty (catalog_product_link_attribute).
+type ProductLinkAttribute struct {
+	ProductLinkAttributeID   uint16 `gorm:"column:product_link_attribute_id;primaryKey;autoIncrement"`
+	LinkTypeID               uint16 `gorm:"column:link_type_id;type:smallint unsigned;not null;default:0"`
+	ProductLinkAttributeCode string `gorm:"column:product_link_attribute_code;type:varchar(32)"`
+	DataType                 string `gorm:"column:data_type;type:varchar(32)"`
+}
+
+// TableName specifies the table name
+func (ProductLinkAttribute) TableName() string {
+	return "catalog_product_link_attribute"
+}
+
+// ProductLinkAttributeInt holds int link attribute values, e.g. position (catalog_product_link_attribute_int).
+type ProductLinkAttributeInt struct {
+	ValueID                uint   `gorm:"column:value_id;primaryKey;autoIncrement"`
+	ProductLinkAttributeID uint16 `gorm:"column:product_link_attribute_id;type:smallint unsigned"`
+	LinkID                 uint   `gorm:"column:link_id;type:int unsigned;not null;index"`
+	Value                  int    `gorm:"column:value;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (ProductLinkAttributeInt) TableName() string {
+	return "catalog_product_link_attribute_int"
+}
+
+// ProductLinkAttributeDecimal holds decimal link attribute values, e.g. grouped qty (catalog_product_link_attribute_decimal).
+type ProductLinkAttributeDecimal struct {
+	ValueID                uint    `gorm:"column:value_id;primaryKey;autoIncrement"`
+	ProductLinkAttributeID uint16  `gorm:"column:product_link_attribute_id;type:smallint unsigned"`
+	LinkID                 uint    `gorm:"column:link_id;type:int unsigned;not null;index"`
+	Value                  float64 `gorm:"column:value;type:decimal(20,6);not null;default:0"`
+}
+
+// TableName specifies the table name
+func (ProductLinkAttributeDecimal) TableName() string {
+	return "catalog_product_link_attribute_decimal"
+}
+
+/* Usage Examples:
+
+1. Create the position of a link:
+   ```go
+   db.Create(&ProductLinkAttributeInt{ProductLinkAttributeID: 5, LinkID: 7, Value: 2})
+   ```
+
+2. Read the qty of grouped links:
+   ```go
+   var qtys []ProductLinkAttributeDecimal
+   db.Where("link_id IN ? AND product_link_attribute_id = ?", linkIDs, 6).Find(&qtys)
+   ```
+*/
@@ -0,0 +1,65 @@
+package product
+
+// ProductOption is a custom option of a product such as an engraving field or a size drop-down (catalog_product_option).
+type ProductOption struct {
+	OptionID      uint    `gorm:"column:option_id;primaryKey;autoIncrement"`
+	ProductID     uint    `gorm:"column:product_id;type:int unsigned;not null;default:0;index"`
+	Type          string  `gorm:"column:type;type:varchar(50)"`
+	IsRequire     uint16  `gorm:"column:is_require;type:smallint;not null;default:1"`
+	SKU           *string `gorm:"column:sku;type:varchar(64)"`
+	MaxCharacters *uint   `gorm:"column:max_characters;type:int unsigned"`
+	FileExtension *string `gorm:"column:file_extension;type:varchar(50)"`
+	ImageSizeX    *uint16 `gorm:"column:image_size_x;type:smallint unsigned"`
+	ImageSizeY    *uint16 `gorm:"column:image_size_y;type:smallint unsigned"`
+	SortOrder     uint    `gorm:"column:sort_order;type:int unsigned;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (ProductOption) TableName() string {
+	return "catalog_product_option"
+}
+
+// ProductOptionTitle is the per-store title of a custom option (catalog_product_option_title).
+type ProductOptionTitle struct {
+	OptionTitleID uint   `gorm:"column:option_title_id;primaryKey;autoIncrement"`
+	OptionID      uint   `gorm:"column:option_id;type:int unsigned;not null;default:0;index"`
+	StoreID       uint16 `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+	Title         string `gorm:"column:title;type:varchar(255)"`
+}
+
+// TableName specifies the table name
+func (ProductOptionTitle) TableName() string {
+	return "catalog_product_option_title"
+}
+
+// ProductOptionPrice is the per-store price of a field, area, file or date option (catalog_product_option_price).
+// PriceType is "fixed" or "percent" (of the product price).
+type ProductOptionPrice struct {
+	OptionPriceID uint    `gorm:"column:option_price_id;primaryKey;autoIncrement"`
+	OptionID      uint    `gorm:"column:option_id;type:int unsigned;not null;default:0;index"`
+	StoreID       uint16  `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+	Price         float64 `gorm:"column:price;type:decimal(20,6);not null;default:0"`
+	PriceType     string  `gorm:"column:price_type;type:varchar(7);not null;default:fixed"`
+}
+
+// TableName specifies the table name
+func (ProductOptionPrice) TableName() string {
+	return "catalog_product_option_price"
+}
+
+/* Usage Examples:
+
+1. Create an engraving field with a fixed price:
+   ```go
+   opt := &ProductOption{ProductID: 10, Type: "field", IsRequire: 0, SortOrder: 1}
+   db.Create(opt)
+   db.Create(&ProductOptionTitle{OptionID: opt.OptionID, Title: "Engraving"})
+   db.Create(&ProductOptionPrice{OptionID: opt.OptionID, Price: 5, PriceType: "fixed"})
+   ```
+
+2. Read the options of products:
+   ```go
+   var opts []ProductOption
+   db.Where("product_id IN ?", productIDs).Order("product_id, sort_order, option_id").Find(&opts)
+   ```
+*/
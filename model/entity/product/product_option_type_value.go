@@ -0,0 +1,60 @@
+package product
+
+// ProductOptionTypeValue is a value of a drop_down, radio, checkbox or multiple custom option
+// (catalog_product_option_type_value).
+type ProductOptionTypeValue struct {
+	OptionTypeID uint    `gorm:"column:option_type_id;primaryKey;autoIncrement"`
+	OptionID     uint    `gorm:"column:option_id;type:int unsigned;not null;default:0;index"`
+	SKU          *string `gorm:"column:sku;type:varchar(64)"`
+	SortOrder    uint    `gorm:"column:sort_order;type:int unsigned;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (ProductOptionTypeValue) TableName() string {
+	return "catalog_product_option_type_value"
+}
+
+// ProductOptionTypeTitle is the per-store title of a custom option value (catalog_product_option_type_title).
+type ProductOptionTypeTitle struct {
+	OptionTypeTitleID uint   `gorm:"column:option_type_title_id;primaryKey;autoIncrement"`
+	OptionTypeID      uint   `gorm:"column:option_type_id;type:int unsigned;not null;default:0;index"`
+	StoreID           uint16 `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+	Title             string `gorm:"column:title;type:varchar(255)"`
+}
+
+// TableName specifies the table name
+func (ProductOptionTypeTitle) TableName() string {
+	return "catalog_product_option_type_title"
+}
+
+// ProductOptionTypePrice is the per-store price of a custom option value (catalog_product_option_type_price).
+// PriceType is "fixed" or "percent" (of the product price).
+type ProductOptionTypePrice struct {
+	OptionTypePriceID uint    `gorm:"column:option_type_price_id;primaryKey;autoIncrement"`
+	OptionTypeID      uint    `gorm:"column:option_type_id;type:int unsigned;not null;default:0;index"`
+	StoreID           uint16  `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+	Price             float64 `gorm:"column:price;type:decimal(20,6);not null;default:0"`
+	PriceType         string  `gorm:"column:price_type;type:varchar(7);not null;default:fixed"`
+}
+
+// TableName specifies the table name
+func (ProductOptionTypePrice) TableName() string {
+	return "catalog_product_option_type_price"
+}
+
+/* Usage Examples:
+
+1. Create a size drop-down value priced at 10% of the product:
+   ```go
+   val := &ProductOptionTypeValue{OptionID: opt.OptionID, SortOrder: 2}
+   db.Create(val)
+   db.Create(&ProductOptionTypeTitle{OptionTypeID: val.OptionTypeID, Title: "XL"})
+   db.Create(&ProductOptionTypePrice{OptionTypeID: val.OptionTypeID, Price: 10, PriceType: "percent"})
+   ```
+
+2. Read the values of options:
+   ```go
+   var vals []ProductOptionTypeValue
+   db.Where("option_id IN ?", optionIDs).Order("option_id, sort_order, option_type_id").Find(&vals)
+   ```
+*/
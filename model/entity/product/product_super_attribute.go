@@ -0,0 +1,38 @@
+package product
+
+// ProductSuperAttribute is an attribute a configurable product varies on (catalog_product_super_attribute).
+type ProductSuperAttribute struct {
+	ProductSuperAttributeID uint   `gorm:"column:product_super_attribute_id;primaryKey;autoIncrement"`
+	ProductID               uint   `gorm:"column:product_id;type:int unsigned;not null;default:0;index"`
+	AttributeID             uint16 `gorm:"column:attribute_id;type:smallint unsigned;not null;default:0"`
+	Position                uint16 `gorm:"column:position;type:smallint unsigned;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (ProductSuperAttribute) TableName() string {
+	return "catalog_product_super_attribute"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   attr := &ProductSuperAttribute{
+       ProductID: 10,
+       AttributeID: 93,
+       Position: 0,
+   }
+   db.Create(attr)
+   ```
+
+2. Read:
+   ```go
+   var attrs []ProductSuperAttribute
+   db.Where("product_id = ?", 10).Order("position").Find(&attrs)
+   ```
+
+3. Delete:
+   ```go
+   db.Delete(&attr)
+   ```
+*/
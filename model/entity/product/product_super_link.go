@@ -0,0 +1,36 @@
+package product
+
+// ProductSuperLink links a configurable parent to one of its simple children (catalog_product_super_link).
+type ProductSuperLink struct {
+	LinkID    uint `gorm:"column:link_id;primaryKey;autoIncrement"`
+	ProductID uint `gorm:"column:product_id;type:int unsigned;not null;default:0"`
+	ParentID  uint `gorm:"column:parent_id;type:int unsigned;not null;default:0;index"`
+}
+
+// TableName specifies the table name
+func (ProductSuperLink) TableName() string {
+	return "catalog_product_super_link"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   link := &ProductSuperLink{
+       ProductID: 12,
+       ParentID: 10,
+   }
+   db.Create(link)
+   ```
+
+2. Read children of a configurable:
+   ```go
+   var links []ProductSuperLink
+   db.Where("parent_id = ?", 10).Find(&links)
+   ```
+
+3. Delete:
+   ```go
+   db.Delete(&link)
+   ```
+*/
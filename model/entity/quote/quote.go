@@ -0,0 +1,63 @@
+package quote
+
+import "time"
+
+// Quote is a shopping cart (quote). Totals are kept in the base and the quote (display) currency.
+type Quote struct {
+	EntityID                 uint       `gorm:"column:entity_id;primaryKey;autoIncrement"`
+	StoreID                  uint16     `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+	CreatedAt                time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt                time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+	ConvertedAt              *time.Time `gorm:"column:converted_at"`
+	IsActive                 uint16     `gorm:"column:is_active;type:smallint unsigned;default:1"`
+	IsVirtual                uint16     `gorm:"column:is_virtual;type:smallint unsigned;default:0"`
+	IsMultiShipping          uint16     `gorm:"column:is_multi_shipping;type:smallint unsigned;default:0"`
+	ItemsCount               uint       `gorm:"column:items_count;type:int unsigned;default:0"`
+	ItemsQty                 float64    `gorm:"column:items_qty;type:decimal(12,4);default:0"`
+	OrigOrderID              uint       `gorm:"column:orig_order_id;type:int unsigned;default:0"`
+	StoreToBaseRate          float64    `gorm:"column:store_to_base_rate;type:decimal(12,4);default:0"`
+	StoreToQuoteRate         float64    `gorm:"column:store_to_quote_rate;type:decimal(12,4);default:0"`
+	BaseCurrencyCode         *string    `gorm:"column:base_currency_code;type:varchar(255)"`
+	StoreCurrencyCode        *string    `gorm:"column:store_currency_code;type:varchar(255)"`
+	QuoteCurrencyCode        *string    `gorm:"column:quote_currency_code;type:varchar(255)"`
+	GlobalCurrencyCode       *string    `gorm:"column:global_currency_code;type:varchar(255)"`
+	BaseToGlobalRate         float64    `gorm:"column:base_to_global_rate;type:decimal(12,4)"`
+	BaseToQuoteRate          float64    `gorm:"column:base_to_quote_rate;type:decimal(12,4)"`
+	GrandTotal               float64    `gorm:"column:grand_total;type:decimal(20,4);default:0"`
+	BaseGrandTotal           float64    `gorm:"column:base_grand_total;type:decimal(20,4);default:0"`
+	Subtotal                 float64    `gorm:"column:subtotal;type:decimal(20,4)"`
+	BaseSubtotal             float64    `gorm:"column:base_subtotal;type:decimal(20,4)"`
+	SubtotalWithDiscount     float64    `gorm:"column:subtotal_with_discount;type:decimal(20,4)"`
+	BaseSubtotalWithDiscount float64    `gorm:"column:base_subtotal_with_discount;type:decimal(20,4)"`
+	CheckoutMethod           *string    `gorm:"column:checkout_method;type:varchar(255)"`
+	CustomerID               *uint      `gorm:"column:customer_id;type:int unsigned"`
+	CustomerTaxClassID       *uint      `gorm:"column:customer_tax_class_id;type:int unsigned"`
+	CustomerGroupID          uint       `gorm:"column:customer_group_id;type:int unsigned;default:0"`
+	CustomerEmail            *string    `gorm:"column:customer_email;type:varchar(255)"`
+	CustomerIsGuest          uint16     `gorm:"column:customer_is_guest;type:smallint unsigned;default:0"`
+	RemoteIP                 *string    `gorm:"column:remote_ip;type:varchar(45)"`
+	ReservedOrderID          *string    `gorm:"column:reserved_order_id;type:varchar(64)"`
+	IsChanged                uint       `gorm:"column:is_changed;type:int unsigned"`
+	TriggerRecollect         uint16     `gorm:"column:trigger_recollect;type:smallint;not null;default:0"`
+	IsPersistent             uint16     `gorm:"column:is_persistent;type:smallint unsigned;default:0"`
+}
+
+// TableName specifies the table name
+func (Quote) TableName() string {
+	return "quote"
+}
+
+/* Usage Examples:
+
+1. Create a guest cart:
+   ```go
+   q := &Quote{StoreID: 1, IsActive: 1, CustomerIsGuest: 1}
+   db.Create(q)
+   ```
+
+2. Read an active cart:
+   ```go
+   var q Quote
+   db.Where("entity_id = ? AND is_active = 1", quoteID).First(&q)
+   ```
+*/
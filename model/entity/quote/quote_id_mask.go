@@ -0,0 +1,27 @@
+package quote
+
+// QuoteIDMask maps the masked cart ID given to guests to the quote entity_id (quote_id_mask).
+type QuoteIDMask struct {
+	EntityID uint   `gorm:"column:entity_id;primaryKey;autoIncrement"`
+	QuoteID  uint   `gorm:"column:quote_id;type:int unsigned;not null;index"`
+	MaskedID string `gorm:"column:masked_id;type:varchar(32);index"`
+}
+
+// TableName specifies the table name
+func (QuoteIDMask) TableName() string {
+	return "quote_id_mask"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   db.Create(&QuoteIDMask{QuoteID: q.EntityID, MaskedID: "3f0c1e..."})
+   ```
+
+2. Resolve a masked ID:
+   ```go
+   var mask QuoteIDMask
+   db.Where("masked_id = ?", maskedID).First(&mask)
+   ```
+*/
@@ -0,0 +1,57 @@
+package quote
+
+import "time"
+
+// QuoteItem is a cart line (quote_item). Price and RowTotal are in the quote currency, the Base*
+// columns in the base currency.
+type QuoteItem struct {
+	ItemID               uint      `gorm:"column:item_id;primaryKey;autoIncrement"`
+	QuoteID              uint      `gorm:"column:quote_id;type:int unsigned;not null;default:0;index"`
+	CreatedAt            time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt            time.Time `gorm:"column:updated_at;autoUpdateTime"`
+	ProductID            *uint     `gorm:"column:product_id;type:int unsigned"`
+	StoreID              *uint16   `gorm:"column:store_id;type:smallint unsigned"`
+	ParentItemID         *uint     `gorm:"column:parent_item_id;type:int unsigned"`
+	IsVirtual            *uint16   `gorm:"column:is_virtual;type:smallint unsigned"`
+	SKU                  *string   `gorm:"column:sku;type:varchar(255)"`
+	Name                 *string   `gorm:"column:name;type:varchar(255)"`
+	IsQtyDecimal         *uint16   `gorm:"column:is_qty_decimal;type:smallint unsigned"`
+	NoDiscount           uint16    `gorm:"column:no_discount;type:smallint unsigned;default:0"`
+	Weight               *float64  `gorm:"column:weight;type:decimal(12,4);default:0"`
+	Qty                  float64   `gorm:"column:qty;type:decimal(12,4);not null;default:0"`
+	Price                float64   `gorm:"column:price;type:decimal(20,4);not null;default:0"`
+	BasePrice            float64   `gorm:"column:base_price;type:decimal(20,4);not null;default:0"`
+	CustomPrice          *float64  `gorm:"column:custom_price;type:decimal(20,4)"`
+	DiscountPercent      *float64  `gorm:"column:discount_percent;type:decimal(12,4);default:0"`
+	DiscountAmount       *float64  `gorm:"column:discount_amount;type:decimal(20,4);default:0"`
+	BaseDiscountAmount   *float64  `gorm:"column:base_discount_amount;type:decimal(20,4);default:0"`
+	RowTotal             float64   `gorm:"column:row_total;type:decimal(20,4);not null;default:0"`
+	BaseRowTotal         float64   `gorm:"column:base_row_total;type:decimal(20,4);not null;default:0"`
+	RowTotalWithDiscount *float64  `gorm:"column:row_total_with_discount;type:decimal(20,4);default:0"`
+	RowWeight            *float64  `gorm:"column:row_weight;type:decimal(12,4);default:0"`
+	ProductType          *string   `gorm:"column:product_type;type:varchar(255)"`
+	PriceInclTax         *float64  `gorm:"column:price_incl_tax;type:decimal(20,4)"`
+	BasePriceInclTax     *float64  `gorm:"column:base_price_incl_tax;type:decimal(20,4)"`
+	RowTotalInclTax      *float64  `gorm:"column:row_total_incl_tax;type:decimal(20,4)"`
+	BaseRowTotalInclTax  *float64  `gorm:"column:base_row_total_incl_tax;type:decimal(20,4)"`
+}
+
+// TableName specifies the table name
+func (QuoteItem) TableName() string {
+	return "quote_item"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   item := &QuoteItem{QuoteID: q.EntityID, ProductID: &productID, Qty: 2, Price: 10, BasePrice: 10}
+   db.Create(item)
+   ```
+
+2. Read the items of a cart:
+   ```go
+   var items []QuoteItem
+   db.Where("quote_id = ? AND parent_item_id IS NULL", q.EntityID).Order("item_id").Find(&items)
+   ```
+*/
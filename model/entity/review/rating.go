@@ -0,0 +1,30 @@
+package review
+
+// Rating is a rating criterion such as Quality or Price (rating).
+type Rating struct {
+	RatingID   uint16 `gorm:"column:rating_id;primaryKey;autoIncrement"`
+	EntityID   uint16 `gorm:"column:entity_id;type:smallint unsigned;not null;default:0"`
+	RatingCode string `gorm:"column:rating_code;type:varchar(64);not null;uniqueIndex"`
+	Position   uint16 `gorm:"column:position;type:smallint unsigned;not null;default:0"`
+	IsActive   uint16 `gorm:"column:is_active;type:smallint;not null;default:1"`
+}
+
+// TableName specifies the table name
+func (Rating) TableName() string {
+	return "rating"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   r := &Rating{EntityID: EntityProduct, RatingCode: "Quality", IsActive: 1}
+   db.Create(r)
+   ```
+
+2. Read:
+   ```go
+   var ratings []Rating
+   db.Where("entity_id = ? AND is_active = 1", EntityProduct).Order("position").Find(&ratings)
+   ```
+*/
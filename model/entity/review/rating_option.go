@@ -0,0 +1,30 @@
+package review
+
+// RatingOption is one selectable value of a rating, usually 1 to 5 stars (rating_option).
+type RatingOption struct {
+	OptionID uint   `gorm:"column:option_id;primaryKey;autoIncrement"`
+	RatingID uint16 `gorm:"column:rating_id;type:smallint unsigned;not null;default:0;index"`
+	Code     string `gorm:"column:code;type:varchar(32);not null"`
+	Value    uint16 `gorm:"column:value;type:smallint unsigned;not null;default:0"`
+	Position uint16 `gorm:"column:position;type:smallint unsigned;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (RatingOption) TableName() string {
+	return "rating_option"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   o := &RatingOption{RatingID: 1, Code: "5", Value: 5, Position: 5}
+   db.Create(o)
+   ```
+
+2. Read:
+   ```go
+   var options []RatingOption
+   db.Where("rating_id = ?", 1).Order("position").Find(&options)
+   ```
+*/
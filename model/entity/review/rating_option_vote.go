@@ -0,0 +1,36 @@
+package review
+
+// RatingOptionVote is the option chosen for one rating of a review (rating_option_vote).
+// Percent is the option value as a share of the rating's scale.
+type RatingOptionVote struct {
+	VoteID        uint64  `gorm:"column:vote_id;primaryKey;autoIncrement"`
+	OptionID      uint    `gorm:"column:option_id;not null;default:0"`
+	RemoteIP      string  `gorm:"column:remote_ip;type:varchar(16)"`
+	RemoteIPLong  int64   `gorm:"column:remote_ip_long;not null;default:0"`
+	CustomerID    *uint   `gorm:"column:customer_id;type:int unsigned;default:0"`
+	EntityPkValue uint    `gorm:"column:entity_pk_value;not null;default:0"`
+	RatingID      uint16  `gorm:"column:rating_id;type:smallint unsigned;not null;default:0"`
+	ReviewID      *uint64 `gorm:"column:review_id;index"`
+	Percent       uint16  `gorm:"column:percent;type:smallint;not null;default:0"`
+	Value         uint16  `gorm:"column:value;type:smallint;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (RatingOptionVote) TableName() string {
+	return "rating_option_vote"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   v := &RatingOptionVote{OptionID: 5, RatingID: 1, EntityPkValue: 1, ReviewID: &reviewID, Percent: 100, Value: 5}
+   db.Create(v)
+   ```
+
+2. Read:
+   ```go
+   var votes []RatingOptionVote
+   db.Where("review_id IN ?", reviewIDs).Find(&votes)
+   ```
+*/
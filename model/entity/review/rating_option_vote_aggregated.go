@@ -0,0 +1,28 @@
+package review
+
+// RatingOptionVoteAggregated is the per-rating vote aggregate of an entity per store view
+// (rating_option_vote_aggregated). PercentApproved counts approved reviews only.
+type RatingOptionVoteAggregated struct {
+	PrimaryID       uint    `gorm:"column:primary_id;primaryKey;autoIncrement"`
+	RatingID        uint16  `gorm:"column:rating_id;type:smallint unsigned;not null;default:0"`
+	EntityPkValue   uint    `gorm:"column:entity_pk_value;not null;default:0;index"`
+	VoteCount       uint    `gorm:"column:vote_count;not null;default:0"`
+	VoteValueSum    uint    `gorm:"column:vote_value_sum;not null;default:0"`
+	Percent         uint16  `gorm:"column:percent;type:smallint;not null;default:0"`
+	PercentApproved *uint16 `gorm:"column:percent_approved;type:smallint;default:0"`
+	StoreID         uint16  `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (RatingOptionVoteAggregated) TableName() string {
+	return "rating_option_vote_aggregated"
+}
+
+/* Usage Examples:
+
+1. Read:
+   ```go
+   var aggregates []RatingOptionVoteAggregated
+   db.Where("entity_pk_value = ? AND store_id = ?", 1, 1).Find(&aggregates)
+   ```
+*/
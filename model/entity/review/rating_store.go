@@ -0,0 +1,20 @@
+package review
+
+// RatingStore enables a rating in a store view (rating_store).
+type RatingStore struct {
+	RatingID uint16 `gorm:"column:rating_id;primaryKey"`
+	StoreID  uint16 `gorm:"column:store_id;type:smallint unsigned;primaryKey"`
+}
+
+// TableName specifies the table name
+func (RatingStore) TableName() string {
+	return "rating_store"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   db.Create(&RatingStore{RatingID: 1, StoreID: 1})
+   ```
+*/
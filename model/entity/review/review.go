@@ -0,0 +1,47 @@
+package review
+
+import "time"
+
+// review.status_id values.
+const (
+	StatusApproved    uint16 = 1
+	StatusPending     uint16 = 2
+	StatusNotApproved uint16 = 3
+)
+
+// EntityProduct is the review_entity ID of product reviews (review.entity_id, review_entity_summary.entity_type).
+const EntityProduct uint16 = 1
+
+// Review is a customer review of an entity (review). EntityPkValue is the product entity_id.
+type Review struct {
+	ReviewID      uint64    `gorm:"column:review_id;primaryKey;autoIncrement"`
+	CreatedAt     time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+	EntityID      uint16    `gorm:"column:entity_id;type:smallint unsigned;not null;default:0"`
+	EntityPkValue uint      `gorm:"column:entity_pk_value;type:int unsigned;not null;default:0;index"`
+	StatusID      uint16    `gorm:"column:status_id;type:smallint unsigned;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (Review) TableName() string {
+	return "review"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   r := &Review{EntityID: EntityProduct, EntityPkValue: 1, StatusID: StatusPending}
+   db.Create(r)
+   ```
+
+2. Read:
+   ```go
+   var reviews []Review
+   db.Where("entity_pk_value = ? AND status_id = ?", 1, StatusApproved).Find(&reviews)
+   ```
+
+3. Update:
+   ```go
+   db.Model(&r).Update("StatusID", StatusApproved)
+   ```
+*/
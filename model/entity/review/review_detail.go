@@ -0,0 +1,32 @@
+package review
+
+// ReviewDetail holds the text of a review (review_detail).
+type ReviewDetail struct {
+	DetailID   uint64  `gorm:"column:detail_id;primaryKey;autoIncrement"`
+	ReviewID   uint64  `gorm:"column:review_id;not null;default:0;index"`
+	StoreID    *uint16 `gorm:"column:store_id;type:smallint unsigned;default:0"`
+	Title      string  `gorm:"column:title;type:varchar(255);not null"`
+	Detail     string  `gorm:"column:detail;type:text;not null"`
+	Nickname   string  `gorm:"column:nickname;type:varchar(128);not null"`
+	CustomerID *uint   `gorm:"column:customer_id;type:int unsigned"`
+}
+
+// TableName specifies the table name
+func (ReviewDetail) TableName() string {
+	return "review_detail"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   d := &ReviewDetail{ReviewID: r.ReviewID, Title: "Great", Detail: "Fits well", Nickname: "Jane"}
+   db.Create(d)
+   ```
+
+2. Read:
+   ```go
+   var d ReviewDetail
+   db.Where("review_id = ?", reviewID).First(&d)
+   ```
+*/
@@ -0,0 +1,26 @@
+package review
+
+// ReviewEntitySummary is the approved review count and average rating (percent) of an entity per
+// store view (review_entity_summary), maintained by Magento when reviews are moderated.
+type ReviewEntitySummary struct {
+	PrimaryID     uint64 `gorm:"column:primary_id;primaryKey;autoIncrement"`
+	EntityPkValue uint   `gorm:"column:entity_pk_value;not null;default:0;index"`
+	EntityType    uint16 `gorm:"column:entity_type;type:smallint;not null;default:0"`
+	ReviewsCount  uint16 `gorm:"column:reviews_count;type:smallint;not null;default:0"`
+	RatingSummary uint16 `gorm:"column:rating_summary;type:smallint;not null;default:0"`
+	StoreID       uint16 `gorm:"column:store_id;type:smallint unsigned;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (ReviewEntitySummary) TableName() string {
+	return "review_entity_summary"
+}
+
+/* Usage Examples:
+
+1. Read:
+   ```go
+   var s ReviewEntitySummary
+   db.Where("entity_pk_value = ? AND entity_type = ? AND store_id = ?", 1, EntityProduct, 1).First(&s)
+   ```
+*/
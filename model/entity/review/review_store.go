@@ -0,0 +1,26 @@
+package review
+
+// ReviewStore makes a review visible in a store view (review_store).
+type ReviewStore struct {
+	ReviewID uint64 `gorm:"column:review_id;primaryKey"`
+	StoreID  uint16 `gorm:"column:store_id;type:smallint unsigned;primaryKey"`
+}
+
+// TableName specifies the table name
+func (ReviewStore) TableName() string {
+	return "review_store"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   db.Create(&[]ReviewStore{{ReviewID: r.ReviewID, StoreID: 0}, {ReviewID: r.ReviewID, StoreID: 1}})
+   ```
+
+2. Read:
+   ```go
+   var stores []ReviewStore
+   db.Where("review_id = ?", reviewID).Find(&stores)
+   ```
+*/
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// SearchQuery is a storefront search term with how often it was searched (popularity) and how many
+// products it found (search_query). Terms with display_in_terms are shown as popular searches.
+type SearchQuery struct {
+	QueryID        uint      `gorm:"column:query_id;primaryKey;autoIncrement"`
+	QueryText      string    `gorm:"column:query_text;type:varchar(255);index:search_query_query_text_store_id_popularity"`
+	NumResults     uint      `gorm:"column:num_results;type:int unsigned;not null;default:0"`
+	Popularity     uint      `gorm:"column:popularity;type:int unsigned;not null;default:0"`
+	Redirect       *string   `gorm:"column:redirect;type:varchar(255)"`
+	StoreID        uint16    `gorm:"column:store_id;type:smallint unsigned;not null;default:0;index:search_query_query_text_store_id_popularity"`
+	DisplayInTerms uint16    `gorm:"column:display_in_terms;type:smallint;not null;default:1"`
+	IsActive       uint16    `gorm:"column:is_active;type:smallint;default:1"`
+	IsProcessed    uint16    `gorm:"column:is_processed;type:smallint;default:0"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;type:timestamp;not null;default:CURRENT_TIMESTAMP;autoUpdateTime"`
+}
+
+func (SearchQuery) TableName() string {
+	return "search_query"
+}
+
+/* Usage Examples:
+
+1. Create:
+   q := &SearchQuery{QueryText: "duffle bag", NumResults: 3, Popularity: 42, StoreID: 1}
+   db.Create(q)
+
+2. Popular terms of a store:
+   var terms []SearchQuery
+   db.Where("store_id = ? AND is_active = 1 AND display_in_terms = 1 AND num_results > 0", 1).
+       Order("popularity DESC").Limit(10).Find(&terms)
+*/
@@ -0,0 +1,32 @@
+package store
+
+// Store is a store view (store). Store 0 is the admin store.
+type Store struct {
+	StoreID   uint16 `gorm:"column:store_id;primaryKey;autoIncrement"`
+	Code      string `gorm:"column:code;type:varchar(32);uniqueIndex"`
+	WebsiteID uint16 `gorm:"column:website_id;type:smallint unsigned;not null;default:0"`
+	GroupID   uint16 `gorm:"column:group_id;type:smallint unsigned;not null;default:0"`
+	Name      string `gorm:"column:name;type:varchar(255);not null"`
+	SortOrder uint16 `gorm:"column:sort_order;type:smallint unsigned;not null;default:0"`
+	IsActive  uint16 `gorm:"column:is_active;type:smallint unsigned;not null;default:0"`
+}
+
+// TableName specifies the table name
+func (Store) TableName() string {
+	return "store"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   s := &Store{Code: "de", WebsiteID: 1, GroupID: 1, Name: "German", IsActive: 1}
+   db.Create(s)
+   ```
+
+2. Read:
+   ```go
+   var s Store
+   db.Where("code = ?", "default").First(&s)
+   ```
+*/
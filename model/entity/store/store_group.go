@@ -0,0 +1,31 @@
+package store
+
+// StoreGroup is a store (store_group): a root category shared by its store views.
+type StoreGroup struct {
+	GroupID        uint16 `gorm:"column:group_id;primaryKey;autoIncrement"`
+	WebsiteID      uint16 `gorm:"column:website_id;type:smallint unsigned;not null;default:0"`
+	Name           string `gorm:"column:name;type:varchar(255);not null"`
+	RootCategoryID uint   `gorm:"column:root_category_id;type:int unsigned;not null;default:0"`
+	DefaultStoreID uint16 `gorm:"column:default_store_id;type:smallint unsigned;not null;default:0"`
+	Code           string `gorm:"column:code;type:varchar(32);uniqueIndex"`
+}
+
+// TableName specifies the table name
+func (StoreGroup) TableName() string {
+	return "store_group"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   g := &StoreGroup{WebsiteID: 1, Name: "Main Website Store", RootCategoryID: 2, DefaultStoreID: 1, Code: "main_website_store"}
+   db.Create(g)
+   ```
+
+2. Read:
+   ```go
+   var groups []StoreGroup
+   db.Where("website_id = ?", 1).Find(&groups)
+   ```
+*/
@@ -0,0 +1,31 @@
+package store
+
+// StoreWebsite is a website (store_website). Website 0 is the admin website.
+type StoreWebsite struct {
+	WebsiteID      uint16 `gorm:"column:website_id;primaryKey;autoIncrement"`
+	Code           string `gorm:"column:code;type:varchar(32);uniqueIndex"`
+	Name           string `gorm:"column:name;type:varchar(64)"`
+	SortOrder      uint16 `gorm:"column:sort_order;type:smallint unsigned;not null;default:0"`
+	DefaultGroupID uint16 `gorm:"column:default_group_id;type:smallint unsigned;not null;default:0"`
+	IsDefault      uint16 `gorm:"column:is_default;type:smallint unsigned;default:0"`
+}
+
+// TableName specifies the table name
+func (StoreWebsite) TableName() string {
+	return "store_website"
+}
+
+/* Usage Examples:
+
+1. Create:
+   ```go
+   w := &StoreWebsite{Code: "base", Name: "Main Website", DefaultGroupID: 1, IsDefault: 1}
+   db.Create(w)
+   ```
+
+2. Read:
+   ```go
+   var w StoreWebsite
+   db.Where("is_default = 1").First(&w)
+   ```
+*/
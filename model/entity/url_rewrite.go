@@ -0,0 +1,48 @@
+package entity
+
+// url_rewrite.entity_type values written by Magento.
+const (
+	UrlRewriteEntityProduct  = "product"
+	UrlRewriteEntityCategory = "category"
+	UrlRewriteEntityCmsPage  = "cms-page"
+	UrlRewriteEntityCustom   = "custom"
+)
+
+// UrlRewrite maps a storefront request path to an entity or a redirect target (url_rewrite).
+// RedirectType is 0 for internal rewrites, 301 or 302 for redirects.
+type UrlRewrite struct {
+	UrlRewriteID    uint    `gorm:"column:url_rewrite_id;primaryKey;autoIncrement"`
+	EntityType      string  `gorm:"column:entity_type;type:varchar(32);not null"`
+	EntityID        uint    `gorm:"column:entity_id;type:int unsigned;not null"`
+	RequestPath     string  `gorm:"column:request_path;type:varchar(255);index:idx_url_rewrite_request_path_store"`
+	TargetPath      string  `gorm:"column:target_path;type:varchar(255)"`
+	RedirectType    uint16  `gorm:"column:redirect_type;type:smallint unsigned;not null;default:0"`
+	StoreID         uint16  `gorm:"column:store_id;type:smallint unsigned;not null;index:idx_url_rewrite_request_path_store"`
+	Description     *string `gorm:"column:description;type:varchar(255)"`
+	IsAutogenerated uint16  `gorm:"column:is_autogenerated;type:smallint unsigned;not null;default:0"`
+	Metadata        *string `gorm:"column:metadata;type:varchar(255)"`
+}
+
+func (UrlRewrite) TableName() string {
+	return "url_rewrite"
+}
+
+/* Usage Examples:
+
+1. Create:
+   rw := &UrlRewrite{
+       EntityType: UrlRewriteEntityProduct,
+       EntityID: 1,
+       RequestPath: "some-shirt.html",
+       TargetPath: "catalog/product/view/id/1",
+       StoreID: 1,
+   }
+   db.Create(rw)
+
+2. Read:
+   var rw UrlRewrite
+   db.Where("request_path = ? AND store_id = ?", "some-shirt.html", 1).First(&rw)
+
+3. Delete:
+   db.Delete(&rw)
+*/
@@ -0,0 +1,255 @@
+// Attribute Repository for Magento catalog EAV attribute metadata
+//
+// Joins eav_attribute with catalog_eav_attribute so callers get the storefront
+// flags (is_filterable, is_searchable, used_for_sort_by) next to the attribute code.
+// Metadata is loaded once per repository and kept in memory; call InvalidateCache
+// after attribute changes.
+
+package attribute
+
+import (
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+
+	entity "magento.GO/model/entity"
+	"magento.GO/model/repository"
+)
+
+// ProductEntityTypeID is the eav_entity_type ID of catalog_product.
+const ProductEntityTypeID = 4
+
+var attributeRepos repository.PerDB[*AttributeRepository]
+
+// GetAttributeRepository returns the AttributeRepository of the given DB.
+func GetAttributeRepository(db *gorm.DB) *AttributeRepository {
+	return attributeRepos.Get(db, NewAttributeRepository)
+}
+
+// ProductAttribute is a product attribute with its catalog storefront settings.
+type ProductAttribute struct {
+	AttributeID   uint16  `json:"attribute_id"`
+	Code          string  `json:"attribute_code"`
+	Label         string  `json:"label"`
+	FrontendInput string  `json:"frontend_input"`
+	BackendType   string  `json:"backend_type"`
+	IsFilterable  bool    `json:"is_filterable"`
+	IsSearchable  bool    `json:"is_searchable"`
+	UsedForSortBy bool    `json:"used_for_sort_by"`
+	Position      int     `json:"position"`
+	SearchWeight  float64 `json:"search_weight"`
+}
+
+type AttributeRepository struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	byCode   map[string]ProductAttribute
+	options  map[uint16]map[uint16][]AttributeOption // store_id -> attribute_id -> options
+	swatches map[uint16]map[uint32]OptionSwatch      // store_id -> option_id -> swatch
+}
+
+// AttributeOption is a select/multiselect option with its store label.
+// DefaultLabel is the admin (store 0) label; Label falls back to it.
+type AttributeOption struct {
+	OptionID     uint32 `json:"option_id"`
+	Label        string `json:"label"`
+	DefaultLabel string `json:"default_label"`
+	SortOrder    uint16 `json:"sort_order"`
+}
+
+// OptionSwatch is the visual swatch of an option; Type is entity.SwatchTypeText, Color or Image.
+type OptionSwatch struct {
+	Type  uint16 `json:"type"`
+	Value string `json:"value"`
+}
+
+func NewAttributeRepository(db *gorm.DB) *AttributeRepository {
+	return &AttributeRepository{db: db}
+}
+
+// ProductAttributes returns all product attributes keyed by attribute_code.
+func (r *AttributeRepository) ProductAttributes() (map[string]ProductAttribute, error) {
+	r.mu.RLock()
+	cached := r.byCode
+	r.mu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	var rows []struct {
+		AttributeID   uint16   `gorm:"column:attribute_id"`
+		AttributeCode string   `gorm:"column:attribute_code"`
+		FrontendLabel *string  `gorm:"column:frontend_label"`
+		FrontendInput *string  `gorm:"column:frontend_input"`
+		BackendType   string   `gorm:"column:backend_type"`
+		IsFilterable  *uint16  `gorm:"column:is_filterable"`
+		IsSearchable  *uint16  `gorm:"column:is_searchable"`
+		UsedForSortBy *uint16  `gorm:"column:used_for_sort_by"`
+		Position      *int     `gorm:"column:position"`
+		SearchWeight  *float64 `gorm:"column:search_weight"`
+	}
+	err := r.db.Table("eav_attribute AS ea").
+		Select("ea.attribute_id, ea.attribute_code, ea.frontend_label, ea.frontend_input, ea.backend_type, "+
+			"cea.is_filterable, cea.is_searchable, cea.used_for_sort_by, cea.position, cea.search_weight").
+		Joins("LEFT JOIN catalog_eav_attribute cea ON cea.attribute_id = ea.attribute_id").
+		Where("ea.entity_type_id = ?", ProductEntityTypeID).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]ProductAttribute, len(rows))
+	for _, row := range rows {
+		a := ProductAttribute{
+			AttributeID:  row.AttributeID,
+			Code:         row.AttributeCode,
+			BackendType:  row.BackendType,
+			SearchWeight: 1,
+		}
+		if row.FrontendLabel != nil {
+			a.Label = *row.FrontendLabel
+		}
+		if row.FrontendInput != nil {
+			a.FrontendInput = *row.FrontendInput
+		}
+		if row.IsFilterable != nil {
+			a.IsFilterable = *row.IsFilterable > 0
+		}
+		if row.IsSearchable != nil {
+			a.IsSearchable = *row.IsSearchable > 0
+		}
+		if row.UsedForSortBy != nil {
+			a.UsedForSortBy = *row.UsedForSortBy > 0
+		}
+		if row.Position != nil {
+			a.Position = *row.Position
+		}
+		if row.SearchWeight != nil && *row.SearchWeight > 0 {
+			a.SearchWeight = *row.SearchWeight
+		}
+		m[a.Code] = a
+	}
+
+	r.mu.Lock()
+	r.byCode = m
+	r.mu.Unlock()
+	return m, nil
+}
+
+// FilterableAttributes returns attributes with is_filterable > 0, ordered by position then code.
+func (r *AttributeRepository) FilterableAttributes() ([]ProductAttribute, error) {
+	all, err := r.ProductAttributes()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ProductAttribute, 0)
+	for _, a := range all {
+		if a.IsFilterable {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Position != result[j].Position {
+			return result[i].Position < result[j].Position
+		}
+		return result[i].Code < result[j].Code
+	})
+	return result, nil
+}
+
+// ProductAttributeOptions returns product attribute options keyed by attribute_id, ordered by
+// sort_order. Labels come from eav_attribute_option_value for the store, falling back to store 0.
+func (r *AttributeRepository) ProductAttributeOptions(storeID uint16) (map[uint16][]AttributeOption, error) {
+	r.mu.RLock()
+	cached, ok := r.options[storeID]
+	r.mu.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	var rows []struct {
+		OptionID     uint32  `gorm:"column:option_id"`
+		AttributeID  uint16  `gorm:"column:attribute_id"`
+		SortOrder    uint16  `gorm:"column:sort_order"`
+		DefaultLabel *string `gorm:"column:default_label"`
+		StoreLabel   *string `gorm:"column:store_label"`
+	}
+	err := r.db.Table("eav_attribute_option AS o").
+		Select("o.option_id, o.attribute_id, o.sort_order, dv.value AS default_label, sv.value AS store_label").
+		Joins("JOIN eav_attribute ea ON ea.attribute_id = o.attribute_id AND ea.entity_type_id = ?", ProductEntityTypeID).
+		Joins("LEFT JOIN eav_attribute_option_value dv ON dv.option_id = o.option_id AND dv.store_id = 0").
+		Joins("LEFT JOIN eav_attribute_option_value sv ON sv.option_id = o.option_id AND sv.store_id = ?", storeID).
+		Order("o.attribute_id, o.sort_order, o.option_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[uint16][]AttributeOption)
+	for _, row := range rows {
+		opt := AttributeOption{OptionID: row.OptionID, SortOrder: row.SortOrder}
+		if row.DefaultLabel != nil {
+			opt.DefaultLabel = *row.DefaultLabel
+		}
+		opt.Label = opt.DefaultLabel
+		if row.StoreLabel != nil && *row.StoreLabel != "" {
+			opt.Label = *row.StoreLabel
+		}
+		m[row.AttributeID] = append(m[row.AttributeID], opt)
+	}
+
+	r.mu.Lock()
+	if r.options == nil {
+		r.options = make(map[uint16]map[uint16][]AttributeOption)
+	}
+	r.options[storeID] = m
+	r.mu.Unlock()
+	return m, nil
+}
+
+// OptionSwatches returns option swatches keyed by option_id from eav_attribute_option_swatch,
+// preferring the store row over store 0.
+func (r *AttributeRepository) OptionSwatches(storeID uint16) (map[uint32]OptionSwatch, error) {
+	r.mu.RLock()
+	cached, ok := r.swatches[storeID]
+	r.mu.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	var rows []entity.EavAttributeOptionSwatch
+	err := r.db.Where("store_id IN ?", []uint16{0, storeID}).
+		Order("store_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[uint32]OptionSwatch, len(rows))
+	for _, row := range rows {
+		sw := OptionSwatch{Type: row.Type}
+		if row.Value != nil {
+			sw.Value = *row.Value
+		}
+		// store 0 rows come first; a store row overrides them
+		m[row.OptionID] = sw
+	}
+
+	r.mu.Lock()
+	if r.swatches == nil {
+		r.swatches = make(map[uint16]map[uint32]OptionSwatch)
+	}
+	r.swatches[storeID] = m
+	r.mu.Unlock()
+	return m, nil
+}
+
+// InvalidateCache drops cached attribute metadata, options and swatches; the next call reloads from the database.
+func (r *AttributeRepository) InvalidateCache() {
+	r.mu.Lock()
+	r.byCode = nil
+	r.options = nil
+	r.swatches = nil
+	r.mu.Unlock()
+}
@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 	categoryEntity "magento.GO/model/entity/category"
 	entity "magento.GO/model/entity"
+	"magento.GO/model/repository"
 )
 
 var (
@@ -14,17 +15,12 @@ var (
 	treeCache     map[uint16][]*CategoryTreeNode
 	treeCacheLock sync.RWMutex
 
-	// Singleton for CategoryRepository
-	categoryRepoInstance *CategoryRepository
-	categoryRepoOnce sync.Once
+	categoryRepos repository.PerDB[*CategoryRepository]
 )
 
-// GetCategoryRepository returns the singleton instance of CategoryRepository
+// GetCategoryRepository returns the CategoryRepository of the given DB.
 func GetCategoryRepository(db *gorm.DB) *CategoryRepository {
-	categoryRepoOnce.Do(func() {
-		categoryRepoInstance = NewCategoryRepository(db)
-	})
-	return categoryRepoInstance
+	return categoryRepos.Get(db, NewCategoryRepository)
 }
 
 // CategoryRepository provides access to category data with in-memory caching for performance.
@@ -75,9 +71,9 @@ func (r *CategoryRepository) FetchAllWithAttributesMap(storeID uint16) (map[uint
 	var categories []categoryEntity.Category
 	err := r.db.
 		Preload("Products").
-		Preload("Ints", "store_id = ?", storeID).
-		Preload("Varchars", "store_id = ?", storeID).
-		Preload("Texts", "store_id = ?", storeID).
+		Preload("Ints", storeScope(storeID)).
+		Preload("Varchars", storeScope(storeID)).
+		Preload("Texts", storeScope(storeID)).
 		Find(&categories).Error
 	if err != nil {
 		return nil, err
@@ -102,6 +98,14 @@ func (r *CategoryRepository) FetchAllWithAttributesMap(storeID uint16) (map[uint
 	return cache, nil
 }
 
+// storeScope preloads the EAV values of a store view and of the default scope (store_id 0), ordered
+// by store_id: FlattenCategoryAttributesWithLabels keeps the store view value where both exist.
+func storeScope(storeID uint16) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("store_id IN ?", []uint16{0, storeID}).Order("store_id")
+	}
+}
+
 // InvalidateCache clears the in-memory category cache for all stores.
 // The next call to FetchAllWithAttributes or FetchAllWithAttributesMap will reload from the database.
 func (r *CategoryRepository) InvalidateCache() {
@@ -305,9 +309,9 @@ func (r *CategoryRepository) GetByIDsWithAttributes(ids []uint, storeID uint16)
 	var cats []categoryEntity.Category
 	err := r.db.
 		Preload("Products").
-		Preload("Ints", "store_id = ?", storeID).
-		Preload("Varchars", "store_id = ?", storeID).
-		Preload("Texts", "store_id = ?", storeID).
+		Preload("Ints", storeScope(storeID)).
+		Preload("Varchars", storeScope(storeID)).
+		Preload("Texts", storeScope(storeID)).
 		Where("entity_id IN ?", ids).
 		Find(&cats).Error
 	if err != nil {
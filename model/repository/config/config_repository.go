@@ -0,0 +1,231 @@
+// Config Repository for Magento system configuration (core_config_data)
+//
+// Values resolve with Magento's scope fallback: store view, then its website, then default.
+// Paths without a row fall back to the config.xml defaults in Defaults. The table is loaded
+// once per repository and kept in memory; call InvalidateCache after config changes.
+
+package config
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+
+	entity "magento.GO/model/entity"
+	"magento.GO/model/repository"
+	storeRepo "magento.GO/model/repository/store"
+)
+
+// Common core_config_data paths.
+const (
+	PathLocale               = "general/locale/code"
+	PathTimezone             = "general/locale/timezone"
+	PathWeightUnit           = "general/locale/weight_unit"
+	PathBaseCurrency         = "currency/options/base"
+	PathDefaultCurrency      = "currency/options/default"
+	PathAllowedCurrencies    = "currency/options/allow"
+	PathUnsecureBaseURL      = "web/unsecure/base_url"
+	PathUnsecureBaseLinkURL  = "web/unsecure/base_link_url"
+	PathUnsecureBaseMediaURL = "web/unsecure/base_media_url"
+	PathSecureBaseURL        = "web/secure/base_url"
+	PathSecureBaseLinkURL    = "web/secure/base_link_url"
+	PathSecureBaseMediaURL   = "web/secure/base_media_url"
+	PathUseStoreInURL        = "web/url/use_store"
+	PathCmsHomePage          = "web/default/cms_home_page"
+	PathProductURLSuffix     = "catalog/seo/product_url_suffix"
+	PathCategoryURLSuffix    = "catalog/seo/category_url_suffix"
+	PathTitleSeparator       = "catalog/seo/title_separator"
+	PathDefaultSortBy        = "catalog/frontend/default_sort_by"
+	PathGridPerPage          = "catalog/frontend/grid_per_page"
+	PathListPerPage          = "catalog/frontend/list_per_page"
+	PathDefaultTitle         = "design/head/default_title"
+	PathDefaultDescription   = "design/head/default_description"
+	PathDefaultKeywords      = "design/head/default_keywords"
+	PathReviewActive         = "catalog/review/active"
+	PathReviewAllowGuest     = "catalog/review/allow_guest"
+	PathSearchEngine         = "catalog/search/engine"
+)
+
+// Defaults are Magento's config.xml values for paths that have no core_config_data row.
+var Defaults = map[string]string{
+	PathLocale:               "en_US",
+	PathTimezone:             "America/Los_Angeles",
+	PathWeightUnit:           "lbs",
+	PathBaseCurrency:         "USD",
+	PathDefaultCurrency:      "USD",
+	PathAllowedCurrencies:    "USD",
+	PathUnsecureBaseLinkURL:  "{{unsecure_base_url}}",
+	PathUnsecureBaseMediaURL: "{{unsecure_base_url}}media/",
+	PathSecureBaseURL:        "{{unsecure_base_url}}",
+	PathSecureBaseLinkURL:    "{{secure_base_url}}",
+	PathSecureBaseMediaURL:   "{{secure_base_url}}media/",
+	PathUseStoreInURL:        "0",
+	PathCmsHomePage:          "home",
+	PathProductURLSuffix:     ".html",
+	PathCategoryURLSuffix:    ".html",
+	PathTitleSeparator:       "-",
+	PathDefaultSortBy:        "position",
+	PathGridPerPage:          "12",
+	PathListPerPage:          "10",
+	PathDefaultTitle:         "Magento Commerce",
+	PathReviewActive:         "1",
+	PathReviewAllowGuest:     "1",
+}
+
+var configRepos repository.PerDB[*ConfigRepository]
+
+// GetConfigRepository returns the ConfigRepository of the given DB.
+func GetConfigRepository(db *gorm.DB) *ConfigRepository {
+	return configRepos.Get(db, NewConfigRepository)
+}
+
+type scopeKey struct {
+	scope   string
+	scopeID uint
+}
+
+type ConfigRepository struct {
+	db     *gorm.DB
+	stores *storeRepo.StoreRepository
+
+	mu     sync.RWMutex
+	values map[scopeKey]map[string]*string // scope -> path -> value (nil for NULL)
+}
+
+func NewConfigRepository(db *gorm.DB) *ConfigRepository {
+	return &ConfigRepository{db: db, stores: storeRepo.GetStoreRepository(db)}
+}
+
+func (r *ConfigRepository) load() (map[scopeKey]map[string]*string, error) {
+	r.mu.RLock()
+	values := r.values
+	r.mu.RUnlock()
+	if values != nil {
+		return values, nil
+	}
+	var rows []entity.CoreConfigData
+	if err := r.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	values = make(map[scopeKey]map[string]*string)
+	for _, row := range rows {
+		k := scopeKey{row.Scope, row.ScopeID}
+		if values[k] == nil {
+			values[k] = make(map[string]*string)
+		}
+		values[k][row.Path] = row.Value
+	}
+	r.mu.Lock()
+	r.values = values
+	r.mu.Unlock()
+	return values, nil
+}
+
+// InvalidateCache drops the loaded configuration.
+func (r *ConfigRepository) InvalidateCache() {
+	r.mu.Lock()
+	r.values = nil
+	r.mu.Unlock()
+}
+
+// Lookup returns the value of path for a store view, falling back store → website → default.
+// ok is false when no scope has a row; a NULL value counts as a row and yields "".
+func (r *ConfigRepository) Lookup(path string, storeID uint16) (string, bool) {
+	values, err := r.load()
+	if err != nil {
+		return "", false
+	}
+	scopes := []scopeKey{{entity.ConfigScopeDefault, 0}}
+	if storeID != storeRepo.AdminStoreID {
+		if s, ok := r.stores.GetByID(storeID); ok {
+			scopes = []scopeKey{
+				{entity.ConfigScopeStores, uint(s.StoreID)},
+				{entity.ConfigScopeWebsites, uint(s.WebsiteID)},
+				{entity.ConfigScopeDefault, 0},
+			}
+		}
+	}
+	for _, k := range scopes {
+		if v, ok := values[k][path]; ok {
+			if v == nil {
+				return "", true
+			}
+			return *v, true
+		}
+	}
+	return "", false
+}
+
+// Get returns the value of path for a store view, or the config.xml default from Defaults.
+func (r *ConfigRepository) Get(path string, storeID uint16) string {
+	if v, ok := r.Lookup(path, storeID); ok {
+		return v
+	}
+	return Defaults[path]
+}
+
+// GetWebsite returns the value of path for a website, falling back to default.
+func (r *ConfigRepository) GetWebsite(path string, websiteID uint16) string {
+	values, err := r.load()
+	if err == nil {
+		for _, k := range []scopeKey{{entity.ConfigScopeWebsites, uint(websiteID)}, {entity.ConfigScopeDefault, 0}} {
+			if v, ok := values[k][path]; ok {
+				if v == nil {
+					return ""
+				}
+				return *v
+			}
+		}
+	}
+	return Defaults[path]
+}
+
+// GetURL returns a web/* URL for a store view with the {{unsecure_base_url}} and
+// {{secure_base_url}} placeholders expanded.
+func (r *ConfigRepository) GetURL(path string, storeID uint16) string {
+	v := r.Get(path, storeID)
+	if !strings.Contains(v, "{{") {
+		return v
+	}
+	unsecure := r.Get(PathUnsecureBaseURL, storeID)
+	secure := strings.ReplaceAll(r.Get(PathSecureBaseURL, storeID), "{{unsecure_base_url}}", unsecure)
+	v = strings.ReplaceAll(v, "{{unsecure_base_url}}", unsecure)
+	return strings.ReplaceAll(v, "{{secure_base_url}}", secure)
+}
+
+// StoreForURL returns the active store view whose web/unsecure/base_url or web/secure/base_url
+// (with the store code appended when web/url/use_store is set) matches a request's host and path.
+// The longest matching base path wins; among stores sharing it, the default store view. prefix is
+// the matched base path without its trailing slash ("" for a base URL at the root).
+func (r *ConfigRepository) StoreForURL(host, path string) (storeID uint16, prefix string, ok bool) {
+	stores, err := r.stores.Stores()
+	if err != nil {
+		return 0, "", false
+	}
+	def, _ := r.stores.DefaultStore()
+	best := -1
+	for _, s := range stores {
+		if s.StoreID == storeRepo.AdminStoreID || s.IsActive != 1 {
+			continue
+		}
+		for _, base := range []string{r.GetURL(PathUnsecureBaseURL, s.StoreID), r.GetURL(PathSecureBaseURL, s.StoreID)} {
+			u, err := url.Parse(base)
+			if err != nil || u.Host == "" || !strings.EqualFold(u.Host, host) {
+				continue
+			}
+			basePath := strings.TrimSuffix(u.Path, "/")
+			if r.Get(PathUseStoreInURL, s.StoreID) == "1" {
+				basePath += "/" + s.Code
+			}
+			if path != basePath && !strings.HasPrefix(path, basePath+"/") {
+				continue
+			}
+			if len(basePath) > best || (len(basePath) == best && s.StoreID == def.StoreID) {
+				best, storeID, prefix = len(basePath), s.StoreID, basePath
+			}
+		}
+	}
+	return storeID, prefix, best >= 0
+}
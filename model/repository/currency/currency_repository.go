@@ -0,0 +1,247 @@
+// Currency Repository for base/display currencies and exchange rates (directory_currency_rate)
+//
+// Prices are stored in the website's base currency (currency/options/base). A store view can
+// show them in another allowed currency (currency/options/allow) when a rate from the base
+// currency exists; currency/options/default is the display currency when none is requested.
+// Rates are loaded once per repository and kept in memory; call InvalidateCache after changes.
+
+package currency
+
+import (
+	"math"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+
+	entity "magento.GO/model/entity"
+	"magento.GO/model/repository"
+	configRepo "magento.GO/model/repository/config"
+	storeRepo "magento.GO/model/repository/store"
+)
+
+// HeaderContentCurrency is the request header a client sets to pick the display currency.
+const HeaderContentCurrency = "Content-Currency"
+
+// PriceFields are the flat product keys that hold prices in the base currency.
+var PriceFields = []string{"price", "special_price", "final_price", "min_price", "max_price", "minimal_price", "tier_price", "msrp", "cost"}
+
+var symbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "CNY": "¥", "CHF": "CHF", "CAD": "CA$",
+	"AUD": "A$", "NZD": "NZ$", "SEK": "kr", "NOK": "kr", "DKK": "kr", "PLN": "zł", "CZK": "Kč",
+	"HUF": "Ft", "INR": "₹", "BRL": "R$", "MXN": "MX$", "RUB": "₽", "UAH": "₴", "TRY": "₺",
+	"KRW": "₩", "ZAR": "R",
+}
+
+// Symbol returns the display symbol of a currency code, or the code itself when unknown.
+func Symbol(code string) string {
+	if s, ok := symbols[code]; ok {
+		return s
+	}
+	return code
+}
+
+var currencyRepos repository.PerDB[*CurrencyRepository]
+
+// GetCurrencyRepository returns the CurrencyRepository of the given DB.
+func GetCurrencyRepository(db *gorm.DB) *CurrencyRepository {
+	return currencyRepos.Get(db, NewCurrencyRepository)
+}
+
+type CurrencyRepository struct {
+	db     *gorm.DB
+	config *configRepo.ConfigRepository
+	stores *storeRepo.StoreRepository
+
+	mu    sync.RWMutex
+	rates map[string]map[string]float64 // from -> to -> rate
+}
+
+func NewCurrencyRepository(db *gorm.DB) *CurrencyRepository {
+	return &CurrencyRepository{
+		db:     db,
+		config: configRepo.GetConfigRepository(db),
+		stores: storeRepo.GetStoreRepository(db),
+	}
+}
+
+func (r *CurrencyRepository) load() map[string]map[string]float64 {
+	r.mu.RLock()
+	rates := r.rates
+	r.mu.RUnlock()
+	if rates != nil {
+		return rates
+	}
+	var rows []entity.DirectoryCurrencyRate
+	rates = make(map[string]map[string]float64)
+	if err := r.db.Find(&rows).Error; err != nil {
+		// Missing table: no conversions; not cached so a later migration is picked up.
+		return rates
+	}
+	for _, row := range rows {
+		if row.Rate <= 0 {
+			continue
+		}
+		if rates[row.CurrencyFrom] == nil {
+			rates[row.CurrencyFrom] = make(map[string]float64)
+		}
+		rates[row.CurrencyFrom][row.CurrencyTo] = row.Rate
+	}
+	r.mu.Lock()
+	r.rates = rates
+	r.mu.Unlock()
+	return rates
+}
+
+// InvalidateCache drops the loaded exchange rates.
+func (r *CurrencyRepository) InvalidateCache() {
+	r.mu.Lock()
+	r.rates = nil
+	r.mu.Unlock()
+}
+
+// Rate returns the exchange rate from one currency to another, using the inverse rate when only
+// that is stored. A currency converts to itself at 1.
+func (r *CurrencyRepository) Rate(from, to string) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	rates := r.load()
+	if rate, ok := rates[from][to]; ok {
+		return rate, true
+	}
+	if rate, ok := rates[to][from]; ok {
+		return 1 / rate, true
+	}
+	return 0, false
+}
+
+// BaseCurrency returns the base currency code of a store view.
+func (r *CurrencyRepository) BaseCurrency(storeID uint16) string {
+	return r.config.Get(configRepo.PathBaseCurrency, r.stores.ResolveStoreID(storeID))
+}
+
+// DefaultDisplayCurrency returns the display currency of a store view when none is requested.
+// It falls back to the base currency when the configured one has no rate.
+func (r *CurrencyRepository) DefaultDisplayCurrency(storeID uint16) string {
+	return r.Converter(storeID, "").Display
+}
+
+// AvailableCurrencies returns the allowed currencies of a store view that have a rate from the base
+// currency, the base currency included.
+func (r *CurrencyRepository) AvailableCurrencies(storeID uint16) []string {
+	storeID = r.stores.ResolveStoreID(storeID)
+	base := r.BaseCurrency(storeID)
+	var codes []string
+	for _, code := range strings.Split(r.config.Get(configRepo.PathAllowedCurrencies, storeID), ",") {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+		if _, ok := r.Rate(base, code); ok {
+			codes = append(codes, code)
+		}
+	}
+	for _, code := range codes {
+		if code == base {
+			return codes
+		}
+	}
+	return append([]string{base}, codes...)
+}
+
+// Converter returns the price converter of a store view for the requested display currency.
+// An unknown, disallowed or unrated request falls back to the default display currency, then to the base.
+func (r *CurrencyRepository) Converter(storeID uint16, requested string) Converter {
+	storeID = r.stores.ResolveStoreID(storeID)
+	base := r.BaseCurrency(storeID)
+	c := Converter{Base: base, Display: base, Rate: 1}
+	available := r.AvailableCurrencies(storeID)
+	for _, code := range []string{strings.ToUpper(strings.TrimSpace(requested)), r.config.Get(configRepo.PathDefaultCurrency, storeID)} {
+		if code == "" || !contains(available, code) {
+			continue
+		}
+		if rate, ok := r.Rate(base, code); ok {
+			c.Display, c.Rate = code, rate
+			return c
+		}
+	}
+	return c
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Converter converts base currency prices into a display currency.
+type Converter struct {
+	Base    string
+	Display string
+	Rate    float64
+}
+
+// Convert converts a base price to the display currency, rounded to cents.
+func (c Converter) Convert(v float64) float64 {
+	if c.Rate == 1 || c.Rate == 0 {
+		return v
+	}
+	return math.Round(v*c.Rate*100) / 100
+}
+
+// ConvertFlat returns a flat product with its PriceFields, index_prices, bundle_price_range and
+// resolved custom option prices in the display currency. The input map is shared (product cache) and is never modified.
+func (c Converter) ConvertFlat(p map[string]interface{}) map[string]interface{} {
+	if c.Rate == 1 || c.Rate == 0 {
+		return p
+	}
+	out := c.convertMap(p, PriceFields)
+	if ips, ok := p["index_prices"].([]map[string]interface{}); ok {
+		converted := make([]map[string]interface{}, len(ips))
+		for i, ip := range ips {
+			converted[i] = c.convertMap(ip, PriceFields)
+		}
+		out["index_prices"] = converted
+	}
+	if bpr, ok := p["bundle_price_range"].(map[string]interface{}); ok {
+		out["bundle_price_range"] = c.convertMap(bpr, []string{"minimum_price", "maximum_price"})
+	}
+	if options, ok := p["options"].([]map[string]interface{}); ok {
+		converted := make([]map[string]interface{}, len(options))
+		for i, o := range options {
+			converted[i] = c.convertMap(o, []string{"price"})
+			if values, ok := o["values"].([]map[string]interface{}); ok {
+				convertedValues := make([]map[string]interface{}, len(values))
+				for j, v := range values {
+					convertedValues[j] = c.convertMap(v, []string{"price"})
+				}
+				converted[i]["values"] = convertedValues
+			}
+		}
+		out["options"] = converted
+	}
+	return out
+}
+
+func (c Converter) convertMap(m map[string]interface{}, fields []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	for _, k := range fields {
+		switch v := m[k].(type) {
+		case float64:
+			out[k] = c.Convert(v)
+		case *float64:
+			if v != nil {
+				converted := c.Convert(*v)
+				out[k] = &converted
+			}
+		}
+	}
+	return out
+}
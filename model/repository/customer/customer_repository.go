@@ -0,0 +1,48 @@
+package customer
+
+import (
+	"gorm.io/gorm"
+
+	customerEntity "magento.GO/model/entity/customer"
+	"magento.GO/model/repository"
+)
+
+var customerRepos repository.PerDB[*CustomerRepository]
+
+// GetCustomerRepository returns the CustomerRepository of the given DB.
+func GetCustomerRepository(db *gorm.DB) *CustomerRepository {
+	return customerRepos.Get(db, NewCustomerRepository)
+}
+
+type CustomerRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerRepository(db *gorm.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// GetActiveByID returns an active customer by entity ID.
+func (r *CustomerRepository) GetActiveByID(id uint) (*customerEntity.Customer, error) {
+	var c customerEntity.Customer
+	if err := r.db.Where("entity_id = ? AND is_active = 1", id).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetGroupID returns the customer_group_id of an active customer.
+func (r *CustomerRepository) GetGroupID(id uint) (uint, error) {
+	c, err := r.GetActiveByID(id)
+	if err != nil {
+		return 0, err
+	}
+	return c.GroupID, nil
+}
+
+// GetGroupIDs returns the IDs of all customer groups, NOT LOGGED IN (0) included.
+func (r *CustomerRepository) GetGroupIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&customerEntity.Group{}).Order("customer_group_id").Pluck("customer_group_id", &ids).Error
+	return ids, err
+}
@@ -6,8 +6,16 @@ import (
 	"gorm.io/gorm"
 
 	inventoryEntity "magento.GO/model/entity/inventory"
+	"magento.GO/model/repository"
 )
 
+var inventoryRepos repository.PerDB[*InventoryRepository]
+
+// GetInventoryRepository returns the InventoryRepository of the given DB.
+func GetInventoryRepository(db *gorm.DB) (*InventoryRepository, error) {
+	return inventoryRepos.TryGet(db, NewInventoryRepository)
+}
+
 type InventoryRepository struct {
 	db    *gorm.DB
 	sqlDB *sql.DB
@@ -83,3 +91,80 @@ func (r *InventoryRepository) BatchGetQuantities(skus []string, sourceCode strin
 	}
 	return result, nil
 }
+
+// Stock is what can be sold of a SKU, as read by GetStockBySKUs.
+type Stock struct {
+	Qty     float64
+	InStock bool
+	Managed bool // when false any qty can be sold while in stock
+}
+
+// IsSalable reports whether qty can be sold from s.
+func (s Stock) IsSalable(qty float64) bool {
+	return s.InStock && (!s.Managed || s.Qty >= qty)
+}
+
+// IsSalable reports whether qty of a SKU can be sold. MSI source items (inventory_source_item) decide
+// when the SKU has any: the in-stock sources must hold qty in total. Otherwise the legacy stock item
+// (cataloginventory_stock_item) must be in stock and, when stock is managed, hold qty. Items using
+// the config setting count as managed (Magento's default).
+func (r *InventoryRepository) IsSalable(sku string, qty float64) bool {
+	stock, err := r.GetStockBySKUs([]string{sku})
+	if err != nil {
+		return false
+	}
+	s, ok := stock[sku]
+	return ok && s.IsSalable(qty)
+}
+
+// GetStockBySKUs returns the stock of several SKUs with one query per stock table, by the rules of
+// IsSalable. SKUs without a source item or stock item are left out.
+func (r *InventoryRepository) GetStockBySKUs(skus []string) (map[string]Stock, error) {
+	result := make(map[string]Stock, len(skus))
+	if len(skus) == 0 {
+		return result, nil
+	}
+	// Without MSI tables every SKU falls back to the legacy stock item.
+	var items []inventoryEntity.InventorySourceItem
+	r.db.Where("sku IN ?", skus).Find(&items)
+	for _, item := range items {
+		s := result[item.SKU]
+		s.InStock, s.Managed = true, true
+		if item.Status == 1 {
+			s.Qty += item.Quantity
+		}
+		result[item.SKU] = s
+	}
+	var legacy []string
+	for _, sku := range skus {
+		if _, ok := result[sku]; !ok {
+			legacy = append(legacy, sku)
+		}
+	}
+	if len(legacy) == 0 {
+		return result, nil
+	}
+	rows, err := r.db.Raw(`SELECT e.sku, s.qty, s.is_in_stock, s.manage_stock, s.use_config_manage_stock FROM cataloginventory_stock_item s
+		JOIN catalog_product_entity e ON e.entity_id = s.product_id WHERE e.sku IN ?`, legacy).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sku string
+		var stockQty sql.NullFloat64
+		var inStock, manageStock, useConfigManageStock int
+		if err := rows.Scan(&sku, &stockQty, &inStock, &manageStock, &useConfigManageStock); err != nil {
+			return nil, err
+		}
+		if _, seen := result[sku]; seen {
+			continue
+		}
+		result[sku] = Stock{
+			Qty:     stockQty.Float64,
+			InStock: inStock != 0,
+			Managed: manageStock == 1 || useConfigManageStock == 1,
+		}
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,46 @@
+// Package repository holds what the repository packages share.
+package repository
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// PerDB keeps one repository (or other value caching rows) per *gorm.DB. The Get<Name>Repository
+// functions share a repository and its caches per database rather than per process, so tests that
+// each open their own database never read another test's rows. The zero value is ready to use.
+type PerDB[T any] struct {
+	mu    sync.RWMutex
+	repos map[*gorm.DB]T
+}
+
+// Get returns the repository of db, creating it with create on first use.
+func (p *PerDB[T]) Get(db *gorm.DB, create func(*gorm.DB) T) T {
+	r, _ := p.TryGet(db, func(db *gorm.DB) (T, error) { return create(db), nil })
+	return r
+}
+
+// TryGet is Get for a create that can fail. A failed create is not kept, so the next call retries.
+func (p *PerDB[T]) TryGet(db *gorm.DB, create func(*gorm.DB) (T, error)) (T, error) {
+	p.mu.RLock()
+	r, ok := p.repos[db]
+	p.mu.RUnlock()
+	if ok {
+		return r, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r, ok := p.repos[db]; ok {
+		return r, nil
+	}
+	r, err := create(db)
+	if err != nil {
+		return r, err
+	}
+	if p.repos == nil {
+		p.repos = make(map[*gorm.DB]T)
+	}
+	p.repos[db] = r
+	return r, nil
+}
@@ -2,11 +2,22 @@ package price
 
 import (
 	"database/sql"
+	"strings"
 	"sync"
+	"time"
 
 	"gorm.io/gorm"
+
+	"magento.GO/model/repository"
 )
 
+var priceRepos repository.PerDB[*PriceRepository]
+
+// GetPriceRepository returns the PriceRepository of the given DB.
+func GetPriceRepository(db *gorm.DB) (*PriceRepository, error) {
+	return priceRepos.TryGet(db, NewPriceRepository)
+}
+
 type PriceRepository struct {
 	db           *gorm.DB
 	sqlDB        *sql.DB
@@ -39,77 +50,143 @@ func (r *PriceRepository) detectSchema() {
 	})
 }
 
-// GetLowestPriceBySKU returns the lowest price considering base, special, and tier prices
-// Uses raw SQL with LEAST() for optimal performance
-func (r *PriceRepository) GetLowestPriceBySKU(sku string, customerGroupID int) (float64, bool) {
+// GetLowestPriceBySKU returns the lowest price of a website and customer group considering the base
+// price, the special price within its special_from_date and special_to_date, and the qty 1 tier price.
+// Uses one raw SQL query; the lowest of the returned values is picked in Go (portable across MySQL and SQLite)
+func (r *PriceRepository) GetLowestPriceBySKU(sku string, websiteID uint16, customerGroupID int) (float64, bool) {
+	prices, err := r.GetLowestPricesBySKUs([]string{sku}, websiteID, customerGroupID)
+	if err != nil {
+		return 0, false
+	}
+	price, ok := prices[sku]
+	return price, ok
+}
+
+// GetLowestPricesBySKUs returns the lowest price of each SKU for a website and customer group in one
+// query, as GetLowestPriceBySKU. SKUs without any price are left out.
+func (r *PriceRepository) GetLowestPricesBySKUs(skus []string, websiteID uint16, customerGroupID int) (map[string]float64, error) {
+	prices, err := r.GetLowestPricesBySKUsForGroups(skus, websiteID, []int{customerGroupID})
+	if err != nil {
+		return nil, err
+	}
+	return prices[customerGroupID], nil
+}
+
+// GetLowestPricesBySKUsForGroups returns the lowest price of each SKU for a website per customer
+// group, as GetLowestPricesBySKUs, from the same single query.
+func (r *PriceRepository) GetLowestPricesBySKUsForGroups(skus []string, websiteID uint16, customerGroupIDs []int) (map[int]map[string]float64, error) {
+	lowest := make(map[int]map[string]float64, len(customerGroupIDs))
+	for _, group := range customerGroupIDs {
+		lowest[group] = make(map[string]float64, len(skus))
+	}
+	if len(skus) == 0 {
+		return lowest, nil
+	}
 	r.detectSchema()
+	linkCol := "entity_id"
 	if r.isEnterprise {
-		return r.getLowestPriceEE(sku, customerGroupID)
+		linkCol = "row_id"
 	}
-	return r.getLowestPriceCE(sku, customerGroupID)
-}
 
-func (r *PriceRepository) getLowestPriceEE(sku string, customerGroupID int) (float64, bool) {
-	const query = `
-		SELECT LEAST(
-			COALESCE(base.value, 999999999),
-			COALESCE(special.value, 999999999),
-			COALESCE(tier.value, 999999999)
-		) AS lowest_price
+	query := `
+		SELECT cpe.sku, base.value, special.value, special_from.value, special_to.value,
+			tier.customer_group_id, tier.qty, tier.value, tier.all_groups, tier.website_id, tier.percentage_value
 		FROM catalog_product_entity cpe
 		LEFT JOIN catalog_product_entity_decimal base 
-			ON base.row_id = cpe.row_id 
-			AND base.attribute_id = (SELECT attribute_id FROM eav_attribute WHERE attribute_code = 'price' AND entity_type_id = 4)
+			ON base.` + linkCol + ` = cpe.` + linkCol + ` 
+			AND base.attribute_id = ` + attributeIDQuery("price") + `
 			AND base.store_id = 0
 		LEFT JOIN catalog_product_entity_decimal special 
-			ON special.row_id = cpe.row_id 
-			AND special.attribute_id = (SELECT attribute_id FROM eav_attribute WHERE attribute_code = 'special_price' AND entity_type_id = 4)
+			ON special.` + linkCol + ` = cpe.` + linkCol + ` 
+			AND special.attribute_id = ` + attributeIDQuery("special_price") + `
 			AND special.store_id = 0
+		LEFT JOIN catalog_product_entity_datetime special_from 
+			ON special_from.` + linkCol + ` = cpe.` + linkCol + ` 
+			AND special_from.attribute_id = ` + attributeIDQuery("special_from_date") + `
+			AND special_from.store_id = 0
+		LEFT JOIN catalog_product_entity_datetime special_to 
+			ON special_to.` + linkCol + ` = cpe.` + linkCol + ` 
+			AND special_to.attribute_id = ` + attributeIDQuery("special_to_date") + `
+			AND special_to.store_id = 0
 		LEFT JOIN catalog_product_entity_tier_price tier 
-			ON tier.row_id = cpe.row_id 
-			AND (tier.customer_group_id = ? OR tier.all_groups = 1)
+			ON tier.` + linkCol + ` = cpe.` + linkCol + ` 
 			AND tier.qty = 1
-		WHERE cpe.sku = ?
-		LIMIT 1
+		WHERE cpe.sku IN (` + placeholders(len(skus)) + `)
 	`
-	return r.execPriceQuery(query, customerGroupID, sku)
+	args := make([]interface{}, len(skus))
+	for i, sku := range skus {
+		args[i] = sku
+	}
+	rows, err := r.sqlDB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	today := time.Now().Format("2006-01-02")
+	for rows.Next() {
+		var sku string
+		var base, special, tierQty, tierValue, percentage sql.NullFloat64
+		var specialFrom, specialTo sql.NullString
+		var tierGroup, tierAllGroups, tierWebsite sql.NullInt64
+		if err := rows.Scan(&sku, &base, &special, &specialFrom, &specialTo,
+			&tierGroup, &tierQty, &tierValue, &tierAllGroups, &tierWebsite, &percentage); err != nil {
+			return nil, err
+		}
+		candidates := []sql.NullFloat64{base}
+		if special.Valid && withinDates(today, specialFrom, specialTo) {
+			candidates = append(candidates, special)
+		}
+		var tp *TierPriceResult
+		// a percentage tier needs the base price it is a discount off
+		if tierQty.Valid && (!percentage.Valid || percentage.Float64 <= 0 || base.Valid) {
+			tp = &TierPriceResult{
+				CustomerGroupID: uint16(tierGroup.Int64),
+				Qty:             tierQty.Float64,
+				Value:           tierValue.Float64,
+				AllGroups:       uint8(tierAllGroups.Int64),
+				WebsiteID:       uint16(tierWebsite.Int64),
+			}
+			if percentage.Valid {
+				tp.PercentageValue = &percentage.Float64
+			}
+		}
+		for group, prices := range lowest {
+			for _, v := range candidates {
+				if price, found := prices[sku]; v.Valid && (!found || v.Float64 < price) {
+					prices[sku] = v.Float64
+				}
+			}
+			if tp != nil && tp.AppliesTo(websiteID, uint(group)) {
+				if price, found := prices[sku]; !found || tp.Price(base.Float64) < price {
+					prices[sku] = tp.Price(base.Float64)
+				}
+			}
+		}
+	}
+	return lowest, rows.Err()
 }
 
-func (r *PriceRepository) getLowestPriceCE(sku string, customerGroupID int) (float64, bool) {
-	const query = `
-		SELECT LEAST(
-			COALESCE(base.value, 999999999),
-			COALESCE(special.value, 999999999),
-			COALESCE(tier.value, 999999999)
-		) AS lowest_price
-		FROM catalog_product_entity cpe
-		LEFT JOIN catalog_product_entity_decimal base 
-			ON base.entity_id = cpe.entity_id 
-			AND base.attribute_id = (SELECT attribute_id FROM eav_attribute WHERE attribute_code = 'price' AND entity_type_id = 4)
-			AND base.store_id = 0
-		LEFT JOIN catalog_product_entity_decimal special 
-			ON special.entity_id = cpe.entity_id 
-			AND special.attribute_id = (SELECT attribute_id FROM eav_attribute WHERE attribute_code = 'special_price' AND entity_type_id = 4)
-			AND special.store_id = 0
-		LEFT JOIN catalog_product_entity_tier_price tier 
-			ON tier.entity_id = cpe.entity_id 
-			AND (tier.customer_group_id = ? OR tier.all_groups = 1)
-			AND tier.qty = 1
-		WHERE cpe.sku = ?
-		LIMIT 1
-	`
-	return r.execPriceQuery(query, customerGroupID, sku)
+// attributeIDQuery is a subquery for the attribute_id of a product attribute.
+func attributeIDQuery(code string) string {
+	return "(SELECT attribute_id FROM eav_attribute WHERE attribute_code = '" + code + "' AND entity_type_id = 4)"
 }
 
-func (r *PriceRepository) execPriceQuery(query string, customerGroupID int, sku string) (float64, bool) {
-	var price sql.NullFloat64
-	if err := r.sqlDB.QueryRow(query, customerGroupID, sku).Scan(&price); err != nil || !price.Valid {
-		return 0, false
+// withinDates reports whether today (YYYY-MM-DD) is within the from and to dates, both inclusive;
+// either may be NULL.
+func withinDates(today string, from, to sql.NullString) bool {
+	if from.Valid && len(from.String) >= 10 && today < from.String[:10] {
+		return false
 	}
-	if price.Float64 >= 999999999 {
-		return 0, false
+	if to.Valid && len(to.String) >= 10 && today > to.String[:10] {
+		return false
 	}
-	return price.Float64, true
+	return true
+}
+
+// placeholders returns n comma-separated "?" for an IN list.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
 }
 
 // GetBasePriceBySKU returns only the base price
@@ -145,36 +222,95 @@ func (r *PriceRepository) GetTierPricesBySKU(sku string) ([]TierPriceResult, err
 	}
 
 	query := `
-		SELECT t.customer_group_id, t.qty, t.value, t.all_groups, t.website_id
+		SELECT cpe.entity_id, t.customer_group_id, t.qty, t.value, t.all_groups, t.website_id, t.percentage_value
 		FROM catalog_product_entity cpe
 		JOIN catalog_product_entity_tier_price t ON t.` + linkCol + ` = cpe.` + linkCol + `
 		WHERE cpe.sku = ?
 		ORDER BY t.qty ASC
 	`
-	rows, err := r.sqlDB.Query(query, sku)
+	tiers, err := r.queryTierPrices(query, sku)
+	if err != nil {
+		return nil, err
+	}
+	var results []TierPriceResult
+	for _, ts := range tiers {
+		results = append(results, ts...)
+	}
+	return results, nil
+}
+
+// GetTierPricesByProductIDs returns all tier prices of the products by entity_id, in one query.
+// Products without tier prices are left out.
+func (r *PriceRepository) GetTierPricesByProductIDs(ids []uint) (map[uint][]TierPriceResult, error) {
+	if len(ids) == 0 {
+		return map[uint][]TierPriceResult{}, nil
+	}
+	r.detectSchema()
+	linkCol := "entity_id"
+	if r.isEnterprise {
+		linkCol = "row_id"
+	}
+
+	query := `
+		SELECT cpe.entity_id, t.customer_group_id, t.qty, t.value, t.all_groups, t.website_id, t.percentage_value
+		FROM catalog_product_entity cpe
+		JOIN catalog_product_entity_tier_price t ON t.` + linkCol + ` = cpe.` + linkCol + `
+		WHERE cpe.entity_id IN (` + placeholders(len(ids)) + `)
+		ORDER BY t.qty ASC
+	`
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return r.queryTierPrices(query, args...)
+}
+
+func (r *PriceRepository) queryTierPrices(query string, args ...interface{}) (map[uint][]TierPriceResult, error) {
+	rows, err := r.sqlDB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var results []TierPriceResult
+	results := make(map[uint][]TierPriceResult)
 	for rows.Next() {
+		var entityID uint
 		var tp TierPriceResult
-		if err := rows.Scan(&tp.CustomerGroupID, &tp.Qty, &tp.Value, &tp.AllGroups, &tp.WebsiteID); err != nil {
+		var percentage sql.NullFloat64
+		if err := rows.Scan(&entityID, &tp.CustomerGroupID, &tp.Qty, &tp.Value, &tp.AllGroups, &tp.WebsiteID, &percentage); err != nil {
 			continue
 		}
-		results = append(results, tp)
+		if percentage.Valid {
+			tp.PercentageValue = &percentage.Float64
+		}
+		results[entityID] = append(results[entityID], tp)
 	}
-	return results, nil
+	return results, rows.Err()
 }
 
 // TierPriceResult holds tier price query result
 type TierPriceResult struct {
-	CustomerGroupID uint16  `json:"customer_group_id"`
-	Qty             float64 `json:"qty"`
-	Value           float64 `json:"value"`
-	AllGroups       uint8   `json:"all_groups"`
-	WebsiteID       uint16  `json:"website_id"`
+	CustomerGroupID uint16   `json:"customer_group_id"`
+	Qty             float64  `json:"qty"`
+	Value           float64  `json:"value"`
+	AllGroups       uint8    `json:"all_groups"`
+	WebsiteID       uint16   `json:"website_id"`
+	PercentageValue *float64 `json:"percentage_value,omitempty"`
+}
+
+// AppliesTo reports whether the tier price is for the website (website 0 is all websites) and the
+// customer group (or all groups).
+func (t TierPriceResult) AppliesTo(websiteID uint16, customerGroupID uint) bool {
+	return (t.WebsiteID == 0 || t.WebsiteID == websiteID) && (t.AllGroups == 1 || uint(t.CustomerGroupID) == customerGroupID)
+}
+
+// Price returns the tier's unit price for a product of the given price: a percentage_value tier
+// is a discount off that price, other tiers are a fixed value.
+func (t TierPriceResult) Price(price float64) float64 {
+	if t.PercentageValue != nil && *t.PercentageValue > 0 {
+		return price * (1 - *t.PercentageValue/100)
+	}
+	return t.Value
 }
 
 // IsEnterprise returns whether EE schema is detected
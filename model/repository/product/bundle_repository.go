@@ -0,0 +1,247 @@
+package product
+
+import (
+	"math"
+
+	productEntity "magento.GO/model/entity/product"
+)
+
+// Bundle price_type attribute values.
+const (
+	BundlePriceDynamic = 0
+	BundlePriceFixed   = 1
+)
+
+// Bundle selection_price_type values (fixed-price bundles only).
+const (
+	SelectionPriceFixed   = 0
+	SelectionPricePercent = 1
+)
+
+// attachBundleOptions sets "bundle_options" (options with nested "selections") on bundle products in
+// the flat map. The price range depends on the customer group and website; see BundlePriceRanges.
+func (r *ProductRepository) attachBundleOptions(flat map[uint]map[string]interface{}, bundleIDs []uint, storeID uint16) error {
+	if len(bundleIDs) == 0 {
+		return nil
+	}
+	var options []productEntity.ProductBundleOption
+	if err := r.db.Where("parent_id IN ?", bundleIDs).Order("parent_id, position, option_id").Find(&options).Error; err != nil {
+		return err
+	}
+	optionIDs := make([]uint, len(options))
+	for i, o := range options {
+		optionIDs[i] = o.OptionID
+	}
+
+	titles := make(map[uint]string)
+	var selections []struct {
+		productEntity.ProductBundleSelection
+		SKU string `gorm:"column:sku"`
+	}
+	if len(optionIDs) > 0 {
+		var values []productEntity.ProductBundleOptionValue
+		if err := r.db.Where("option_id IN ? AND store_id IN ?", optionIDs, []uint16{0, storeID}).Order("store_id").Find(&values).Error; err != nil {
+			return err
+		}
+		for _, v := range values {
+			if v.Title != "" || titles[v.OptionID] == "" {
+				titles[v.OptionID] = v.Title
+			}
+		}
+		err := r.db.Table("catalog_product_bundle_selection AS s").
+			Select("s.*, e.sku").
+			Joins("JOIN catalog_product_entity e ON e.entity_id = s.product_id").
+			Where("s.option_id IN ?", optionIDs).
+			Order("s.option_id, s.position, s.selection_id").
+			Scan(&selections).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	byOption := make(map[uint][]map[string]interface{}, len(options))
+	for _, s := range selections {
+		qty := s.SelectionQty
+		if qty <= 0 {
+			qty = 1
+		}
+		byOption[s.OptionID] = append(byOption[s.OptionID], map[string]interface{}{
+			"selection_id":   s.SelectionID,
+			"product_id":     s.ProductID,
+			"sku":            s.SKU,
+			"position":       s.Position,
+			"is_default":     s.IsDefault == 1,
+			"price_type":     s.SelectionPriceType,
+			"price_value":    s.SelectionPriceValue,
+			"qty":            qty,
+			"can_change_qty": s.SelectionCanChangeQty == 1,
+		})
+	}
+
+	byParent := make(map[uint][]map[string]interface{}, len(bundleIDs))
+	for _, o := range options {
+		sels := byOption[o.OptionID]
+		if sels == nil {
+			sels = []map[string]interface{}{}
+		}
+		byParent[o.ParentID] = append(byParent[o.ParentID], map[string]interface{}{
+			"option_id":  o.OptionID,
+			"title":      titles[o.OptionID],
+			"required":   o.Required == 1,
+			"type":       o.Type,
+			"position":   o.Position,
+			"selections": sels,
+		})
+	}
+
+	for _, id := range bundleIDs {
+		p, ok := flat[id]
+		if !ok {
+			continue
+		}
+		opts := byParent[id]
+		if opts == nil {
+			opts = []map[string]interface{}{}
+		}
+		p["bundle_options"] = opts
+	}
+	return nil
+}
+
+// BundlePriceRanges returns the "bundle_price_range" ({minimum_price, maximum_price}) of each bundle
+// among the flat products, by entity_id, for a customer group on a website. Dynamic selections are
+// priced from the group's catalog_product_index_price rows of the website; websiteID 0 (admin)
+// takes the lowest row of any website.
+func (r *ProductRepository) BundlePriceRanges(products []map[string]interface{}, websiteID uint16, customerGroupID uint) (map[uint]map[string]interface{}, error) {
+	ranges := make(map[uint]map[string]interface{})
+	var selectionIDs []uint
+	for _, p := range products {
+		options, _ := p["bundle_options"].([]map[string]interface{})
+		for _, o := range options {
+			sels, _ := o["selections"].([]map[string]interface{})
+			for _, s := range sels {
+				selectionIDs = append(selectionIDs, uint(flatFloat(s["product_id"])))
+			}
+		}
+	}
+	prices, err := r.finalPrices(selectionIDs, websiteID, customerGroupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range products {
+		if _, ok := p["bundle_options"]; !ok {
+			continue
+		}
+		minPrice, maxPrice := BundlePriceRange(p, func(productID uint) (float64, bool) {
+			price, ok := prices[productID]
+			return price, ok
+		})
+		ranges[uint(flatFloat(p["entity_id"]))] = map[string]interface{}{"minimum_price": minPrice, "maximum_price": maxPrice}
+	}
+	return ranges, nil
+}
+
+// finalPrices returns the index final price per product for a customer group on a website
+// (the lowest of any website for websiteID 0).
+func (r *ProductRepository) finalPrices(ids []uint, websiteID uint16, customerGroupID uint) (map[uint]float64, error) {
+	prices := make(map[uint]float64, len(ids))
+	if len(ids) == 0 {
+		return prices, nil
+	}
+	q := r.db.Where("entity_id IN ? AND customer_group_id = ?", ids, customerGroupID)
+	if websiteID != 0 {
+		q = q.Where("website_id = ?", websiteID)
+	}
+	var rows []productEntity.ProductIndexPrice
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if cur, ok := prices[row.EntityID]; !ok || row.FinalPrice < cur {
+			prices[row.EntityID] = row.FinalPrice
+		}
+	}
+	return prices, nil
+}
+
+// BundlePriceRange computes a bundle's minimum and maximum price from its flat "bundle_options".
+// Dynamic bundles price each selection by selectionPrice(product_id); fixed bundles use the parent
+// price plus each selection's fixed or percent price. The minimum takes the cheapest selection of every
+// required option (or the cheapest option when none is required); the maximum takes the dearest selection
+// of single-choice options and all selections of checkbox/multi options.
+func BundlePriceRange(p map[string]interface{}, selectionPrice func(productID uint) (float64, bool)) (minPrice, maxPrice float64) {
+	fixed := int(flatFloat(p["price_type"])) == BundlePriceFixed
+	base := 0.0
+	if fixed {
+		base = flatFloat(p["price"])
+	}
+	options, _ := p["bundle_options"].([]map[string]interface{})
+
+	requiredMin := 0.0
+	hasRequired := false
+	cheapestOption := math.Inf(1)
+	for _, o := range options {
+		sels, _ := o["selections"].([]map[string]interface{})
+		optMin, optMax, optSum := math.Inf(1), 0.0, 0.0
+		priced := false
+		for _, s := range sels {
+			var price float64
+			if fixed {
+				price = flatFloat(s["price_value"])
+				if int(flatFloat(s["price_type"])) == SelectionPricePercent {
+					price = base * price / 100
+				}
+			} else {
+				var ok bool
+				if price, ok = selectionPrice(uint(flatFloat(s["product_id"]))); !ok {
+					continue
+				}
+			}
+			price *= flatFloat(s["qty"])
+			priced = true
+			optMin = math.Min(optMin, price)
+			optMax = math.Max(optMax, price)
+			optSum += price
+		}
+		if !priced {
+			continue
+		}
+		if t, _ := o["type"].(string); t == "checkbox" || t == "multi" {
+			maxPrice += optSum
+		} else {
+			maxPrice += optMax
+		}
+		cheapestOption = math.Min(cheapestOption, optMin)
+		if required, _ := o["required"].(bool); required {
+			hasRequired = true
+			requiredMin += optMin
+		}
+	}
+	switch {
+	case hasRequired:
+		minPrice = requiredMin
+	case !math.IsInf(cheapestOption, 1):
+		minPrice = cheapestOption
+	}
+	return base + minPrice, base + maxPrice
+}
+
+func flatFloat(v interface{}) float64 {
+	switch val := v.(type) {
+	case float64:
+		return val
+	case float32:
+		return float64(val)
+	case int:
+		return float64(val)
+	case int16:
+		return float64(val)
+	case uint:
+		return float64(val)
+	case uint16:
+		return float64(val)
+	case int64:
+		return float64(val)
+	}
+	return 0
+}
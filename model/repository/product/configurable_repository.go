@@ -0,0 +1,45 @@
+package product
+
+import (
+	productEntity "magento.GO/model/entity/product"
+)
+
+// FetchConfigurableChildIDs returns child product IDs per configurable parent from catalog_product_super_link,
+// ordered by link_id. Parents without children are absent from the map.
+func (r *ProductRepository) FetchConfigurableChildIDs(parentIDs []uint) (map[uint][]uint, error) {
+	result := make(map[uint][]uint)
+	if len(parentIDs) == 0 {
+		return result, nil
+	}
+	var links []productEntity.ProductSuperLink
+	err := r.db.Where("parent_id IN ?", parentIDs).
+		Order("parent_id, link_id").
+		Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range links {
+		result[l.ParentID] = append(result[l.ParentID], l.ProductID)
+	}
+	return result, nil
+}
+
+// FetchConfigurableAttributes returns the super attributes per configurable parent from
+// catalog_product_super_attribute, ordered by position.
+func (r *ProductRepository) FetchConfigurableAttributes(parentIDs []uint) (map[uint][]productEntity.ProductSuperAttribute, error) {
+	result := make(map[uint][]productEntity.ProductSuperAttribute)
+	if len(parentIDs) == 0 {
+		return result, nil
+	}
+	var attrs []productEntity.ProductSuperAttribute
+	err := r.db.Where("product_id IN ?", parentIDs).
+		Order("product_id, position, product_super_attribute_id").
+		Find(&attrs).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attrs {
+		result[a.ProductID] = append(result[a.ProductID], a)
+	}
+	return result, nil
+}
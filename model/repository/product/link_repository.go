@@ -0,0 +1,88 @@
+package product
+
+// Magento catalog_product_link_type IDs.
+const (
+	LinkTypeRelated   uint16 = 1
+	LinkTypeGrouped   uint16 = 3
+	LinkTypeUpsell    uint16 = 4
+	LinkTypeCrosssell uint16 = 5
+)
+
+// LinkedProduct is one catalog_product_link row with its position and qty link attributes.
+type LinkedProduct struct {
+	LinkID          uint    `json:"link_id"`
+	ProductID       uint    `json:"product_id"`
+	LinkedProductID uint    `json:"linked_product_id"`
+	SKU             string  `json:"sku"`
+	Position        int     `json:"position"`
+	Qty             float64 `json:"qty"`
+}
+
+// FetchProductLinks returns links of the given type per source product, ordered by the position
+// link attribute (catalog_product_link_attribute_int), then link_id.
+func (r *ProductRepository) FetchProductLinks(productIDs []uint, linkTypeID uint16) (map[uint][]LinkedProduct, error) {
+	result := make(map[uint][]LinkedProduct)
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		LinkID          uint     `gorm:"column:link_id"`
+		ProductID       uint     `gorm:"column:product_id"`
+		LinkedProductID uint     `gorm:"column:linked_product_id"`
+		SKU             string   `gorm:"column:sku"`
+		Position        *int     `gorm:"column:position"`
+		Qty             *float64 `gorm:"column:qty"`
+	}
+	err := r.db.Table("catalog_product_link AS l").
+		Select("l.link_id, l.product_id, l.linked_product_id, e.sku, pos.value AS position, qty.value AS qty").
+		Joins("JOIN catalog_product_entity e ON e.entity_id = l.linked_product_id").
+		Joins("LEFT JOIN catalog_product_link_attribute pa ON pa.link_type_id = l.link_type_id AND pa.product_link_attribute_code = 'position'").
+		Joins("LEFT JOIN catalog_product_link_attribute_int pos ON pos.link_id = l.link_id AND pos.product_link_attribute_id = pa.product_link_attribute_id").
+		Joins("LEFT JOIN catalog_product_link_attribute qa ON qa.link_type_id = l.link_type_id AND qa.product_link_attribute_code = 'qty'").
+		Joins("LEFT JOIN catalog_product_link_attribute_decimal qty ON qty.link_id = l.link_id AND qty.product_link_attribute_id = qa.product_link_attribute_id").
+		Where("l.product_id IN ? AND l.link_type_id = ?", productIDs, linkTypeID).
+		Order("l.product_id, pos.value, l.link_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		lp := LinkedProduct{LinkID: row.LinkID, ProductID: row.ProductID, LinkedProductID: row.LinkedProductID, SKU: row.SKU}
+		if row.Position != nil {
+			lp.Position = *row.Position
+		}
+		if row.Qty != nil {
+			lp.Qty = *row.Qty
+		}
+		result[row.ProductID] = append(result[row.ProductID], lp)
+	}
+	return result, nil
+}
+
+// attachGroupedItems sets "grouped_items" on grouped products in the flat map.
+func (r *ProductRepository) attachGroupedItems(flat map[uint]map[string]interface{}, groupedIDs []uint) error {
+	if len(groupedIDs) == 0 {
+		return nil
+	}
+	links, err := r.FetchProductLinks(groupedIDs, LinkTypeGrouped)
+	if err != nil {
+		return err
+	}
+	for _, id := range groupedIDs {
+		p, ok := flat[id]
+		if !ok {
+			continue
+		}
+		items := make([]map[string]interface{}, 0, len(links[id]))
+		for _, l := range links[id] {
+			items = append(items, map[string]interface{}{
+				"product_id": l.LinkedProductID,
+				"sku":        l.SKU,
+				"position":   l.Position,
+				"qty":        l.Qty,
+			})
+		}
+		p["grouped_items"] = items
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package product
+
+import (
+	"math"
+
+	productEntity "magento.GO/model/entity/product"
+)
+
+// Custom option price_type values.
+const (
+	OptionPriceFixed   = "fixed"
+	OptionPricePercent = "percent"
+)
+
+// IsSelectOptionType reports whether a custom option type has values (catalog_product_option_type_value)
+// rather than a price of its own.
+func IsSelectOptionType(optionType string) bool {
+	switch optionType {
+	case "drop_down", "radio", "checkbox", "multiple":
+		return true
+	}
+	return false
+}
+
+// ResolveOptionPrice returns the amount a custom option adds to a product: the fixed price, or the
+// percent of basePrice rounded to cents.
+func ResolveOptionPrice(value float64, priceType string, basePrice float64) float64 {
+	if priceType == OptionPricePercent {
+		return math.Round(basePrice*value) / 100
+	}
+	return value
+}
+
+// attachCustomOptions sets "options" (custom options with nested "values") on the products in the
+// flat map that have options. Titles and prices of the store view override the admin (store 0) ones;
+// each option and value carries its raw "price_value", its "price_type" and the resolved "price".
+func (r *ProductRepository) attachCustomOptions(flat map[uint]map[string]interface{}, productIDs []uint, storeID uint16) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	var options []productEntity.ProductOption
+	if err := r.db.Where("product_id IN ?", productIDs).Order("product_id, sort_order, option_id").Find(&options).Error; err != nil {
+		return err
+	}
+	optionIDs := make([]uint, len(options))
+	for i, o := range options {
+		optionIDs[i] = o.OptionID
+	}
+	stores := []uint16{0, storeID}
+
+	titles := make(map[uint]string)
+	prices := make(map[uint]productEntity.ProductOptionPrice)
+	var values []productEntity.ProductOptionTypeValue
+	valueTitles := make(map[uint]string)
+	valuePrices := make(map[uint]productEntity.ProductOptionTypePrice)
+	if len(optionIDs) > 0 {
+		var titleRows []productEntity.ProductOptionTitle
+		if err := r.db.Where("option_id IN ? AND store_id IN ?", optionIDs, stores).Order("store_id").Find(&titleRows).Error; err != nil {
+			return err
+		}
+		for _, t := range titleRows {
+			if t.Title != "" || titles[t.OptionID] == "" {
+				titles[t.OptionID] = t.Title
+			}
+		}
+		var priceRows []productEntity.ProductOptionPrice
+		if err := r.db.Where("option_id IN ? AND store_id IN ?", optionIDs, stores).Order("store_id").Find(&priceRows).Error; err != nil {
+			return err
+		}
+		for _, p := range priceRows {
+			prices[p.OptionID] = p
+		}
+		if err := r.db.Where("option_id IN ?", optionIDs).Order("option_id, sort_order, option_type_id").Find(&values).Error; err != nil {
+			return err
+		}
+	}
+	if len(values) > 0 {
+		valueIDs := make([]uint, len(values))
+		for i, v := range values {
+			valueIDs[i] = v.OptionTypeID
+		}
+		var titleRows []productEntity.ProductOptionTypeTitle
+		if err := r.db.Where("option_type_id IN ? AND store_id IN ?", valueIDs, stores).Order("store_id").Find(&titleRows).Error; err != nil {
+			return err
+		}
+		for _, t := range titleRows {
+			if t.Title != "" || valueTitles[t.OptionTypeID] == "" {
+				valueTitles[t.OptionTypeID] = t.Title
+			}
+		}
+		var priceRows []productEntity.ProductOptionTypePrice
+		if err := r.db.Where("option_type_id IN ? AND store_id IN ?", valueIDs, stores).Order("store_id").Find(&priceRows).Error; err != nil {
+			return err
+		}
+		for _, p := range priceRows {
+			valuePrices[p.OptionTypeID] = p
+		}
+	}
+
+	byOption := make(map[uint][]productEntity.ProductOptionTypeValue, len(options))
+	for _, v := range values {
+		byOption[v.OptionID] = append(byOption[v.OptionID], v)
+	}
+	byProduct := make(map[uint][]map[string]interface{}, len(productIDs))
+	for _, o := range options {
+		p, ok := flat[o.ProductID]
+		if !ok {
+			continue
+		}
+		base := optionBasePrice(p)
+		vals := make([]map[string]interface{}, 0, len(byOption[o.OptionID]))
+		for _, v := range byOption[o.OptionID] {
+			price := valuePrices[v.OptionTypeID]
+			vals = append(vals, map[string]interface{}{
+				"option_type_id": v.OptionTypeID,
+				"title":          valueTitles[v.OptionTypeID],
+				"sku":            stringValue(v.SKU),
+				"sort_order":     v.SortOrder,
+				"price_value":    price.Price,
+				"price_type":     priceTypeOrFixed(price.PriceType),
+				"price":          ResolveOptionPrice(price.Price, price.PriceType, base),
+			})
+		}
+		option := map[string]interface{}{
+			"option_id":  o.OptionID,
+			"title":      titles[o.OptionID],
+			"type":       o.Type,
+			"required":   o.IsRequire == 1,
+			"sort_order": o.SortOrder,
+			"sku":        stringValue(o.SKU),
+			"values":     vals,
+		}
+		if !IsSelectOptionType(o.Type) {
+			price := prices[o.OptionID]
+			option["price_value"] = price.Price
+			option["price_type"] = priceTypeOrFixed(price.PriceType)
+			option["price"] = ResolveOptionPrice(price.Price, price.PriceType, base)
+		}
+		if o.MaxCharacters != nil {
+			option["max_characters"] = *o.MaxCharacters
+		}
+		if o.FileExtension != nil {
+			option["file_extension"] = *o.FileExtension
+		}
+		byProduct[o.ProductID] = append(byProduct[o.ProductID], option)
+	}
+	for _, id := range productIDs {
+		p, ok := flat[id]
+		if !ok {
+			continue
+		}
+		opts := byProduct[id]
+		if opts == nil {
+			opts = []map[string]interface{}{}
+		}
+		p["options"] = opts
+	}
+	return nil
+}
+
+// optionBasePrice is the price percent options are taken of: the price attribute, or the NOT LOGGED
+// IN index price when the product has none.
+func optionBasePrice(p map[string]interface{}) float64 {
+	if price := flatFloat(p["price"]); price > 0 {
+		return price
+	}
+	ips, _ := p["index_prices"].([]map[string]interface{})
+	for _, ip := range ips {
+		if flatFloat(ip["customer_group_id"]) == 0 {
+			return flatFloat(ip["price"])
+		}
+	}
+	return 0
+}
+
+func priceTypeOrFixed(priceType string) string {
+	if priceType == "" {
+		return OptionPriceFixed
+	}
+	return priceType
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
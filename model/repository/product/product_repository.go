@@ -17,8 +17,9 @@ import (
 )
 
 var (
-	attributeCodeMap map[uint16]string
-	attributeCodeMapOnce sync.Once
+	// Attribute code maps per DB (loaded once per gorm.DB instance)
+	attributeCodeMaps  = make(map[*gorm.DB]map[uint16]string)
+	attributeCodeMapMu sync.Mutex
 	flatProductsCache = make(map[uint16]map[uint]map[string]interface{})
 	flatProductsCacheOnce  sync.Once
 	flatProductsCacheLock  sync.RWMutex
@@ -49,10 +50,14 @@ func GetProductRepository(db *gorm.DB) *ProductRepository {
 }
 
 func getGlobalAttributeCodeMap(db *gorm.DB) map[uint16]string {
-	attributeCodeMapOnce.Do(func() {
-		attributeCodeMap, _ = LoadAttributeCodeMap(db)
-	})
-	return attributeCodeMap
+	attributeCodeMapMu.Lock()
+	defer attributeCodeMapMu.Unlock()
+	if m, ok := attributeCodeMaps[db]; ok {
+		return m
+	}
+	m, _ := LoadAttributeCodeMap(db)
+	attributeCodeMaps[db] = m
+	return m
 }
 
 type ProductRepository struct {
@@ -110,6 +115,32 @@ func (r *ProductRepository) FindByID(id uint) (*productEntity.Product, error) {
 	return &product, nil
 }
 
+// FindBySKU returns the catalog_product_entity row of a SKU, without attributes.
+func (r *ProductRepository) FindBySKU(sku string) (*productEntity.Product, error) {
+	var product productEntity.Product
+	if err := r.db.Where("sku = ?", sku).First(&product).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// FindBySKUs returns the catalog_product_entity rows of several SKUs, keyed by SKU, without
+// attributes. Unknown SKUs are left out.
+func (r *ProductRepository) FindBySKUs(skus []string) (map[string]productEntity.Product, error) {
+	result := make(map[string]productEntity.Product, len(skus))
+	if len(skus) == 0 {
+		return result, nil
+	}
+	var products []productEntity.Product
+	if err := r.db.Where("sku IN ?", skus).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range products {
+		result[p.SKU] = p
+	}
+	return result, nil
+}
+
 func (r *ProductRepository) Create(product *productEntity.Product) error {
 	return r.db.Create(product).Error
 }
@@ -157,15 +188,23 @@ func (r *ProductRepository) FetchWithAllAttributes(storeID ...uint16) ([]product
 		Preload("MediaGallery").
 		Preload("StockItem").
 		Preload("ProductIndexPrices").
-		Preload("Varchars", "store_id = ?", sid).
-		Preload("Ints", "store_id = ?", sid).
-		Preload("Decimals", "store_id = ?", sid).
-		Preload("Texts", "store_id = ?", sid).
-		Preload("Datetimes", "store_id = ?", sid).
+		Preload("Varchars", storeScope(sid)).
+		Preload("Ints", storeScope(sid)).
+		Preload("Decimals", storeScope(sid)).
+		Preload("Texts", storeScope(sid)).
+		Preload("Datetimes", storeScope(sid)).
 		Find(&products).Error
 	return products, err
 }
 
+// storeScope preloads the EAV values of a store view and of the default scope (store_id 0). Rows are
+// ordered by store_id, so when attributes are flattened a store view value overrides the default.
+func storeScope(storeID uint16) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("store_id IN ?", []uint16{0, storeID}).Order("store_id")
+	}
+}
+
 const batchSize = 1000 // MySQL placeholder limit is 65535; batching avoids "too many placeholders" error
 
 // fetchFlatProducts loads products with all EAV attributes. Uses batched fetching
@@ -197,11 +236,11 @@ func (r *ProductRepository) fetchFlatProductsBatch(ids []uint, storeID uint16) (
 		Preload("MediaGallery").
 		Preload("StockItem").
 		Preload("ProductIndexPrices").
-		Preload("Varchars", "store_id = ?", storeID).
-		Preload("Ints", "store_id = ?", storeID).
-		Preload("Decimals", "store_id = ?", storeID).
-		Preload("Texts", "store_id = ?", storeID).
-		Preload("Datetimes", "store_id = ?", storeID)
+		Preload("Varchars", storeScope(storeID)).
+		Preload("Ints", storeScope(storeID)).
+		Preload("Decimals", storeScope(storeID)).
+		Preload("Texts", storeScope(storeID)).
+		Preload("Datetimes", storeScope(storeID))
 
 	if len(ids) > 0 {
 		db = db.Where("entity_id IN ?", ids)
@@ -213,9 +252,28 @@ func (r *ProductRepository) fetchFlatProductsBatch(ids []uint, storeID uint16) (
 
 	attrMap := getGlobalAttributeCodeMap(r.db)
 	flatProducts := make(map[uint]map[string]interface{}, len(products))
+	var bundleIDs, groupedIDs, optionProductIDs []uint
 	for i := range products {
 		id := products[i].EntityID
 		flatProducts[id] = FlattenProductAttributesWithCodes(&products[i], attrMap)
+		if products[i].HasOptions == 1 {
+			optionProductIDs = append(optionProductIDs, id)
+		}
+		switch products[i].TypeID {
+		case "bundle":
+			bundleIDs = append(bundleIDs, id)
+		case "grouped":
+			groupedIDs = append(groupedIDs, id)
+		}
+	}
+	if err := r.attachBundleOptions(flatProducts, bundleIDs, storeID); err != nil {
+		return nil, err
+	}
+	if err := r.attachGroupedItems(flatProducts, groupedIDs); err != nil {
+		return nil, err
+	}
+	if err := r.attachCustomOptions(flatProducts, optionProductIDs, storeID); err != nil {
+		return nil, err
 	}
 	return flatProducts, nil
 }
@@ -358,6 +416,39 @@ func (r *ProductRepository) FetchWithAllAttributesFlatByIDs(ids []uint, storeID
 	return result, nil
 }
 
+// RefreshFlatProducts reloads ids of a store from the database and replaces them in the flat
+// cache; ids that no longer exist are dropped from it. Returns the reloaded products.
+func (r *ProductRepository) RefreshFlatProducts(ids []uint, storeID uint16) (map[uint]map[string]interface{}, error) {
+	if len(ids) == 0 {
+		return map[uint]map[string]interface{}{}, nil
+	}
+	fetched, err := r.fetchFlatProducts(ids, storeID)
+	if err != nil || cacheDisabled() {
+		return fetched, err
+	}
+
+	flatProductsCacheLock.Lock()
+	defer flatProductsCacheLock.Unlock()
+	cached, ok := flatProductsCache[storeID]
+	if !ok {
+		return fetched, nil
+	}
+	// Callers may still read the cached map: replace it with an updated copy.
+	updated := make(map[uint]map[string]interface{}, len(cached)+len(fetched))
+	for id, p := range cached {
+		updated[id] = p
+	}
+	for _, id := range ids {
+		if p, found := fetched[id]; found {
+			updated[id] = p
+		} else {
+			delete(updated, id)
+		}
+	}
+	flatProductsCache[storeID] = updated
+	return fetched, nil
+}
+
 func attrKey(attrMap map[uint16]string, attrID uint16) string {
 	if k := attrMap[attrID]; k != "" {
 		return k
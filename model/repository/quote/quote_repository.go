@@ -0,0 +1,345 @@
+// Quote Repository for guest carts (quote, quote_item, quote_id_mask)
+//
+// Guests address a cart by its masked ID, as in Magento, so carts created here can be read and
+// checked out by Magento and vice versa. Item prices are kept in the base currency and in the quote
+// currency (base price times base_to_quote_rate); cart totals are recollected on every change.
+
+package quote
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+
+	"gorm.io/gorm"
+
+	quoteEntity "magento.GO/model/entity/quote"
+	"magento.GO/model/repository"
+)
+
+var (
+	// ErrCartNotFound is returned for an unknown masked ID or an inactive (ordered) cart.
+	ErrCartNotFound = errors.New("cart not found")
+	// ErrCartItemNotFound is returned for an item ID that is not in the cart.
+	ErrCartItemNotFound = errors.New("cart item not found")
+	// ErrCartNotOwned is returned for a customer's cart requested by a guest or another customer, and
+	// for a guest cart requested by a customer.
+	ErrCartNotOwned = errors.New("cart belongs to another user")
+	// ErrMaskedIDTaken is returned when a client-chosen cart ID is already used.
+	ErrMaskedIDTaken = errors.New("cart ID is already in use")
+)
+
+var quoteRepos repository.PerDB[*QuoteRepository]
+
+// GetQuoteRepository returns the QuoteRepository of the given DB.
+func GetQuoteRepository(db *gorm.DB) *QuoteRepository {
+	return quoteRepos.Get(db, NewQuoteRepository)
+}
+
+type QuoteRepository struct {
+	db *gorm.DB
+}
+
+func NewQuoteRepository(db *gorm.DB) *QuoteRepository {
+	return &QuoteRepository{db: db}
+}
+
+// Currency holds the currencies of a new cart: prices are stored in Base and shown in Quote.
+type Currency struct {
+	Base  string
+	Quote string
+	Rate  float64
+}
+
+// NewItem is a product added to a cart at its base currency price.
+type NewItem struct {
+	ProductID   uint
+	SKU         string
+	Name        string
+	ProductType string
+	IsVirtual   bool
+	Weight      float64
+	Qty         float64
+	BasePrice   float64
+}
+
+// CreateCart creates an active cart for a store view and returns its masked ID. The cart belongs to
+// customerID and is priced for customerGroupID, or is a guest cart when customerID is 0. A maskedID
+// chosen by the client is used when given; otherwise a random 32-character ID is created.
+func (r *QuoteRepository) CreateCart(storeID uint16, customerID, customerGroupID uint, cur Currency, maskedID string) (string, error) {
+	if maskedID == "" {
+		var err error
+		if maskedID, err = newMaskedID(); err != nil {
+			return "", err
+		}
+	}
+	rate := cur.Rate
+	if rate == 0 {
+		rate = 1
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&quoteEntity.QuoteIDMask{}).Where("masked_id = ?", maskedID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrMaskedIDTaken
+		}
+		q := quoteEntity.Quote{
+			StoreID:            storeID,
+			IsActive:           1,
+			CustomerIsGuest:    1,
+			BaseCurrencyCode:   &cur.Base,
+			StoreCurrencyCode:  &cur.Base,
+			QuoteCurrencyCode:  &cur.Quote,
+			GlobalCurrencyCode: &cur.Base,
+			BaseToGlobalRate:   1,
+			BaseToQuoteRate:    rate,
+			StoreToBaseRate:    1,
+			StoreToQuoteRate:   rate,
+		}
+		if customerID != 0 {
+			q.CustomerID = &customerID
+			q.CustomerGroupID = customerGroupID
+			q.CustomerIsGuest = 0
+		}
+		if err := tx.Create(&q).Error; err != nil {
+			return err
+		}
+		return tx.Create(&quoteEntity.QuoteIDMask{QuoteID: q.EntityID, MaskedID: maskedID}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return maskedID, nil
+}
+
+func newMaskedID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// FindByMaskedID returns the active cart with the given masked ID for the requesting customer (0 for
+// guests). A cart with a customer_id belongs to that customer only, and a cart without one to guests
+// only; other requesters get ErrCartNotOwned. customer_is_guest is not checked, as Magento sets it
+// only at checkout.
+func (r *QuoteRepository) FindByMaskedID(maskedID string, customerID uint) (*quoteEntity.Quote, error) {
+	var mask quoteEntity.QuoteIDMask
+	if err := r.db.Where("masked_id = ?", maskedID).First(&mask).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCartNotFound
+		}
+		return nil, err
+	}
+	var q quoteEntity.Quote
+	if err := r.db.Where("entity_id = ? AND is_active = 1", mask.QuoteID).First(&q).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCartNotFound
+		}
+		return nil, err
+	}
+	owner := uint(0)
+	if q.CustomerID != nil {
+		owner = *q.CustomerID
+	}
+	if owner != customerID {
+		return nil, ErrCartNotOwned
+	}
+	return &q, nil
+}
+
+// Items returns the top-level items of a cart in the order they were added.
+func (r *QuoteRepository) Items(quoteID uint) ([]quoteEntity.QuoteItem, error) {
+	var items []quoteEntity.QuoteItem
+	err := r.db.Where("quote_id = ? AND parent_item_id IS NULL", quoteID).Order("item_id").Find(&items).Error
+	return items, err
+}
+
+// FindItem returns the item of a cart with the given ID.
+func (r *QuoteRepository) FindItem(quoteID, itemID uint) (*quoteEntity.QuoteItem, error) {
+	var item quoteEntity.QuoteItem
+	if err := r.db.Where("quote_id = ? AND item_id = ?", quoteID, itemID).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCartItemNotFound
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindItemBySKU returns the top-level item of a cart for a SKU, or nil when the cart has none.
+func (r *QuoteRepository) FindItemBySKU(quoteID uint, sku string) (*quoteEntity.QuoteItem, error) {
+	var items []quoteEntity.QuoteItem
+	if err := r.db.Where("quote_id = ? AND sku = ? AND parent_item_id IS NULL", quoteID, sku).Limit(1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return &items[0], nil
+}
+
+// AddItem adds a product to a cart. A product already in the cart gets its qty raised and its price
+// refreshed instead of a second line.
+func (r *QuoteRepository) AddItem(q *quoteEntity.Quote, in NewItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []quoteEntity.QuoteItem
+		if err := tx.Where("quote_id = ? AND sku = ? AND parent_item_id IS NULL", q.EntityID, in.SKU).Limit(1).Find(&existing).Error; err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			item := existing[0]
+			item.BasePrice = in.BasePrice
+			setItemQty(&item, item.Qty+in.Qty, q.BaseToQuoteRate)
+			if err := tx.Save(&item).Error; err != nil {
+				return err
+			}
+			return collectTotals(tx, q)
+		}
+		storeID := q.StoreID
+		isVirtual := boolToFlag(in.IsVirtual)
+		item := quoteEntity.QuoteItem{
+			QuoteID:     q.EntityID,
+			ProductID:   &in.ProductID,
+			StoreID:     &storeID,
+			IsVirtual:   &isVirtual,
+			SKU:         &in.SKU,
+			Name:        &in.Name,
+			ProductType: &in.ProductType,
+			Weight:      &in.Weight,
+			BasePrice:   in.BasePrice,
+		}
+		setItemQty(&item, in.Qty, q.BaseToQuoteRate)
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return collectTotals(tx, q)
+	})
+}
+
+// UpdateItemQty sets the qty of a cart item; a qty of zero removes it.
+func (r *QuoteRepository) UpdateItemQty(q *quoteEntity.Quote, itemID uint, qty float64) error {
+	if qty <= 0 {
+		return r.RemoveItem(q, itemID)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var item quoteEntity.QuoteItem
+		if err := tx.Where("quote_id = ? AND item_id = ?", q.EntityID, itemID).First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCartItemNotFound
+			}
+			return err
+		}
+		setItemQty(&item, qty, q.BaseToQuoteRate)
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		return collectTotals(tx, q)
+	})
+}
+
+// RemoveItem deletes an item and its child items from a cart.
+func (r *QuoteRepository) RemoveItem(q *quoteEntity.Quote, itemID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("quote_id = ? AND item_id = ?", q.EntityID, itemID).Delete(&quoteEntity.QuoteItem{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrCartItemNotFound
+		}
+		if err := tx.Where("quote_id = ? AND parent_item_id = ?", q.EntityID, itemID).Delete(&quoteEntity.QuoteItem{}).Error; err != nil {
+			return err
+		}
+		return collectTotals(tx, q)
+	})
+}
+
+// setItemQty sets the qty of an item and derives its quote currency price and row totals.
+func setItemQty(item *quoteEntity.QuoteItem, qty, rate float64) {
+	if rate == 0 {
+		rate = 1
+	}
+	item.Qty = qty
+	item.Price = roundPrice(item.BasePrice * rate)
+	item.BaseRowTotal = roundPrice(item.BasePrice * qty)
+	item.RowTotal = roundPrice(item.Price * qty)
+	rowTotal, baseRowTotal := item.RowTotal, item.BaseRowTotal
+	price, basePrice := item.Price, item.BasePrice
+	item.RowTotalWithDiscount = &rowTotal
+	item.PriceInclTax, item.BasePriceInclTax = &price, &basePrice
+	item.RowTotalInclTax, item.BaseRowTotalInclTax = &rowTotal, &baseRowTotal
+	if item.Weight != nil {
+		rowWeight := *item.Weight * qty
+		item.RowWeight = &rowWeight
+	}
+}
+
+// collectTotals recomputes the item count, qty, subtotals and grand totals of a cart from its items.
+// Tax, shipping and discounts are left to Magento's checkout. Only these columns are written, so
+// changes Magento made to the quote meanwhile (addresses, coupon, checkout) are kept, and a cart
+// ordered meanwhile is not reactivated: it gives ErrCartNotFound and the item change is rolled back.
+func collectTotals(tx *gorm.DB, q *quoteEntity.Quote) error {
+	var items []quoteEntity.QuoteItem
+	if err := tx.Where("quote_id = ? AND parent_item_id IS NULL", q.EntityID).Find(&items).Error; err != nil {
+		return err
+	}
+	q.ItemsCount, q.ItemsQty = uint(len(items)), 0
+	q.Subtotal, q.BaseSubtotal = 0, 0
+	virtual := len(items) > 0
+	for _, item := range items {
+		q.ItemsQty += item.Qty
+		q.Subtotal += item.RowTotal
+		q.BaseSubtotal += item.BaseRowTotal
+		if item.IsVirtual == nil || *item.IsVirtual == 0 {
+			virtual = false
+		}
+	}
+	q.Subtotal, q.BaseSubtotal = roundPrice(q.Subtotal), roundPrice(q.BaseSubtotal)
+	q.SubtotalWithDiscount, q.BaseSubtotalWithDiscount = q.Subtotal, q.BaseSubtotal
+	q.GrandTotal, q.BaseGrandTotal = q.Subtotal, q.BaseSubtotal
+	q.IsVirtual = boolToFlag(virtual)
+	q.IsChanged = 1
+	res := tx.Model(q).Where("is_active = 1").Updates(map[string]interface{}{
+		"items_count":                 q.ItemsCount,
+		"items_qty":                   q.ItemsQty,
+		"subtotal":                    q.Subtotal,
+		"base_subtotal":               q.BaseSubtotal,
+		"subtotal_with_discount":      q.SubtotalWithDiscount,
+		"base_subtotal_with_discount": q.BaseSubtotalWithDiscount,
+		"grand_total":                 q.GrandTotal,
+		"base_grand_total":            q.BaseGrandTotal,
+		"is_virtual":                  q.IsVirtual,
+		"is_changed":                  q.IsChanged,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+	// MySQL counts only changed rows, so an unchanged active cart also affects none.
+	var active int64
+	if err := tx.Model(&quoteEntity.Quote{}).Where("entity_id = ? AND is_active = 1", q.EntityID).Count(&active).Error; err != nil {
+		return err
+	}
+	if active == 0 {
+		return ErrCartNotFound
+	}
+	return nil
+}
+
+func roundPrice(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func boolToFlag(b bool) uint16 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,366 @@
+// Review Repository for Magento product reviews and ratings
+//
+// Reads approved reviews (review, review_detail, review_store), their votes (rating_option_vote)
+// and the moderated aggregates (review_entity_summary, rating_option_vote_aggregated). New reviews
+// are written as pending, so they stay hidden until approved in the Magento admin.
+
+package review
+
+import (
+	"encoding/binary"
+	"errors"
+	"net/netip"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	reviewEntity "magento.GO/model/entity/review"
+	"magento.GO/model/repository"
+)
+
+// ErrInvalidRating is returned when a vote names an inactive rating or an option of another rating.
+var ErrInvalidRating = errors.New("invalid rating or rating option")
+
+var reviewRepos repository.PerDB[*ReviewRepository]
+
+// GetReviewRepository returns the ReviewRepository of the given DB.
+func GetReviewRepository(db *gorm.DB) *ReviewRepository {
+	return reviewRepos.Get(db, NewReviewRepository)
+}
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Summary is the approved review count and average rating (0-100) of a product in a store view.
+type Summary struct {
+	ReviewCount   int
+	RatingSummary float64
+}
+
+// Vote is the option a review chose for one rating.
+type Vote struct {
+	RatingID   uint16
+	RatingCode string
+	Value      uint16
+	Percent    uint16
+}
+
+// ProductReview is a review with its text and votes.
+type ProductReview struct {
+	ReviewID  uint64
+	ProductID uint
+	StatusID  uint16
+	CreatedAt time.Time
+	Nickname  string
+	Title     string
+	Detail    string
+	Votes     []Vote
+}
+
+// AverageRating is the mean vote percent of the review, 0 without votes.
+func (p ProductReview) AverageRating() float64 {
+	if len(p.Votes) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range p.Votes {
+		sum += float64(v.Percent)
+	}
+	return sum / float64(len(p.Votes))
+}
+
+// RatingWithOptions is an active rating and its options ordered by position.
+type RatingWithOptions struct {
+	reviewEntity.Rating
+	Options []reviewEntity.RatingOption
+}
+
+// Summaries returns the review summary of each product in a store view. The count and rating come
+// from review_entity_summary; products without a rating there fall back to the average approved
+// percent in rating_option_vote_aggregated. Products without reviews are omitted.
+func (r *ReviewRepository) Summaries(productIDs []uint, storeID uint16) (map[uint]Summary, error) {
+	result := make(map[uint]Summary, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+	var rows []reviewEntity.ReviewEntitySummary
+	err := r.db.Where("entity_type = ? AND store_id = ? AND entity_pk_value IN ?", reviewEntity.EntityProduct, storeID, productIDs).
+		Find(&rows).Error
+	if err != nil {
+		return result, err
+	}
+	for _, row := range rows {
+		result[row.EntityPkValue] = Summary{ReviewCount: int(row.ReviewsCount), RatingSummary: float64(row.RatingSummary)}
+	}
+
+	var missing []uint
+	for _, id := range productIDs {
+		if s, ok := result[id]; !ok || s.RatingSummary == 0 {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+	var aggregates []struct {
+		EntityPkValue uint
+		Percent       float64
+	}
+	err = r.db.Model(&reviewEntity.RatingOptionVoteAggregated{}).
+		Select("entity_pk_value, AVG(percent_approved) AS percent").
+		Where("store_id = ? AND entity_pk_value IN ? AND percent_approved > 0", storeID, missing).
+		Group("entity_pk_value").
+		Scan(&aggregates).Error
+	if err != nil {
+		return result, nil
+	}
+	for _, a := range aggregates {
+		s := result[a.EntityPkValue]
+		s.RatingSummary = a.Percent
+		result[a.EntityPkValue] = s
+	}
+	return result, nil
+}
+
+// ProductReviews returns a page (1-based) of a product's approved reviews visible in a store view,
+// newest first, and the total count.
+func (r *ReviewRepository) ProductReviews(productID uint, storeID uint16, pageSize, currentPage int) ([]ProductReview, int64, error) {
+	q := r.db.Table("review AS r").
+		Joins("JOIN review_detail AS d ON d.review_id = r.review_id").
+		Joins("JOIN review_store AS rs ON rs.review_id = r.review_id AND rs.store_id = ?", storeID).
+		Where("r.entity_id = ? AND r.entity_pk_value = ? AND r.status_id = ?", reviewEntity.EntityProduct, productID, reviewEntity.StatusApproved)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if currentPage < 1 {
+		currentPage = 1
+	}
+	var rows []struct {
+		ReviewID  uint64
+		CreatedAt time.Time
+		Nickname  string
+		Title     string
+		Detail    string
+	}
+	err := q.Select("r.review_id, r.created_at, d.nickname, d.title, d.detail").
+		Order("r.created_at DESC, r.review_id DESC").
+		Limit(pageSize).Offset((currentPage - 1) * pageSize).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, total, err
+	}
+	reviews := make([]ProductReview, len(rows))
+	ids := make([]uint64, len(rows))
+	for i, row := range rows {
+		reviews[i] = ProductReview{
+			ReviewID:  row.ReviewID,
+			ProductID: productID,
+			StatusID:  reviewEntity.StatusApproved,
+			CreatedAt: row.CreatedAt,
+			Nickname:  row.Nickname,
+			Title:     row.Title,
+			Detail:    row.Detail,
+		}
+		ids[i] = row.ReviewID
+	}
+	votes, err := r.votes(ids)
+	if err != nil {
+		return reviews, total, nil
+	}
+	for i := range reviews {
+		reviews[i].Votes = votes[reviews[i].ReviewID]
+	}
+	return reviews, total, nil
+}
+
+// votes returns the votes of the given reviews, ordered by rating position.
+func (r *ReviewRepository) votes(reviewIDs []uint64) (map[uint64][]Vote, error) {
+	result := make(map[uint64][]Vote)
+	if len(reviewIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		ReviewID   uint64
+		RatingID   uint16
+		RatingCode string
+		Value      uint16
+		Percent    uint16
+	}
+	err := r.db.Table("rating_option_vote AS v").
+		Select("v.review_id, v.rating_id, rt.rating_code, v.value, v.percent").
+		Joins("JOIN rating AS rt ON rt.rating_id = v.rating_id").
+		Where("v.review_id IN ?", reviewIDs).
+		Order("rt.position, v.rating_id").
+		Scan(&rows).Error
+	if err != nil {
+		return result, err
+	}
+	for _, row := range rows {
+		result[row.ReviewID] = append(result[row.ReviewID], Vote{RatingID: row.RatingID, RatingCode: row.RatingCode, Value: row.Value, Percent: row.Percent})
+	}
+	return result, nil
+}
+
+// ActiveRatings returns the active product ratings enabled for a store view (rating_store), with
+// their options.
+func (r *ReviewRepository) ActiveRatings(storeID uint16) ([]RatingWithOptions, error) {
+	var ratings []reviewEntity.Rating
+	err := r.db.Where("entity_id = ? AND is_active = 1", reviewEntity.EntityProduct).
+		Where("rating_id IN (?)", r.db.Model(&reviewEntity.RatingStore{}).Select("rating_id").Where("store_id = ?", storeID)).
+		Order("position, rating_id").
+		Find(&ratings).Error
+	if err != nil || len(ratings) == 0 {
+		return nil, err
+	}
+	ids := make([]uint16, len(ratings))
+	for i, rt := range ratings {
+		ids[i] = rt.RatingID
+	}
+	var options []reviewEntity.RatingOption
+	if err := r.db.Where("rating_id IN ?", ids).Order("position, option_id").Find(&options).Error; err != nil {
+		return nil, err
+	}
+	byRating := make(map[uint16][]reviewEntity.RatingOption, len(ratings))
+	for _, o := range options {
+		byRating[o.RatingID] = append(byRating[o.RatingID], o)
+	}
+	result := make([]RatingWithOptions, len(ratings))
+	for i, rt := range ratings {
+		result[i] = RatingWithOptions{Rating: rt, Options: byRating[rt.RatingID]}
+	}
+	return result, nil
+}
+
+// NewReview is a review submitted from the storefront. Votes maps rating_id to the chosen option_id.
+// RemoteIP is the client IP recorded with the votes.
+type NewReview struct {
+	ProductID  uint
+	StoreID    uint16
+	CustomerID *uint
+	Nickname   string
+	Title      string
+	Detail     string
+	Votes      map[uint16]uint
+	RemoteIP   string
+}
+
+// CreatePending writes a pending review with its votes, visible in the admin store and in StoreID
+// once approved. Votes must name ratings active in the store and options of those ratings.
+func (r *ReviewRepository) CreatePending(in NewReview) (*ProductReview, error) {
+	ratings, err := r.ActiveRatings(in.StoreID)
+	if err != nil {
+		return nil, err
+	}
+	type choice struct {
+		rating reviewEntity.Rating
+		option reviewEntity.RatingOption
+		scale  int
+	}
+	var choices []choice
+	for ratingID, optionID := range in.Votes {
+		var found *choice
+		for _, rt := range ratings {
+			if rt.RatingID != ratingID {
+				continue
+			}
+			for _, o := range rt.Options {
+				if o.OptionID == optionID {
+					found = &choice{rating: rt.Rating, option: o, scale: len(rt.Options)}
+				}
+			}
+		}
+		if found == nil {
+			return nil, ErrInvalidRating
+		}
+		choices = append(choices, *found)
+	}
+	sort.Slice(choices, func(i, j int) bool {
+		if choices[i].rating.Position != choices[j].rating.Position {
+			return choices[i].rating.Position < choices[j].rating.Position
+		}
+		return choices[i].rating.RatingID < choices[j].rating.RatingID
+	})
+
+	review := reviewEntity.Review{
+		EntityID:      reviewEntity.EntityProduct,
+		EntityPkValue: in.ProductID,
+		StatusID:      reviewEntity.StatusPending,
+	}
+	result := &ProductReview{ProductID: in.ProductID, StatusID: reviewEntity.StatusPending, Nickname: in.Nickname, Title: in.Title, Detail: in.Detail}
+	remoteIP, remoteIPLong := voteRemoteIP(in.RemoteIP)
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		storeID := in.StoreID
+		detail := reviewEntity.ReviewDetail{
+			ReviewID:   review.ReviewID,
+			StoreID:    &storeID,
+			Title:      in.Title,
+			Detail:     in.Detail,
+			Nickname:   in.Nickname,
+			CustomerID: in.CustomerID,
+		}
+		if err := tx.Create(&detail).Error; err != nil {
+			return err
+		}
+		stores := []reviewEntity.ReviewStore{{ReviewID: review.ReviewID, StoreID: 0}}
+		if in.StoreID != 0 {
+			stores = append(stores, reviewEntity.ReviewStore{ReviewID: review.ReviewID, StoreID: in.StoreID})
+		}
+		if err := tx.Create(&stores).Error; err != nil {
+			return err
+		}
+		for _, c := range choices {
+			percent := uint16(0)
+			if c.scale > 0 {
+				percent = uint16(int(c.option.Value) * 100 / c.scale)
+			}
+			vote := reviewEntity.RatingOptionVote{
+				OptionID:      c.option.OptionID,
+				RemoteIP:      remoteIP,
+				RemoteIPLong:  remoteIPLong,
+				CustomerID:    in.CustomerID,
+				EntityPkValue: in.ProductID,
+				RatingID:      c.rating.RatingID,
+				ReviewID:      &review.ReviewID,
+				Percent:       percent,
+				Value:         c.option.Value,
+			}
+			if err := tx.Create(&vote).Error; err != nil {
+				return err
+			}
+			result.Votes = append(result.Votes, Vote{RatingID: c.rating.RatingID, RatingCode: c.rating.RatingCode, Value: c.option.Value, Percent: percent})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.ReviewID = review.ReviewID
+	result.CreatedAt = review.CreatedAt
+	return result, nil
+}
+
+// voteRemoteIP returns remote_ip and remote_ip_long of a vote: an IPv4 address and its number,
+// as Magento's ip2long. remote_ip is varchar(16), so longer (IPv6) addresses are not recorded.
+func voteRemoteIP(ip string) (string, int64) {
+	if len(ip) > 16 {
+		return "", 0
+	}
+	if addr, err := netip.ParseAddr(ip); err == nil && addr.Is4() {
+		b := addr.As4()
+		return ip, int64(binary.BigEndian.Uint32(b[:]))
+	}
+	return ip, 0
+}
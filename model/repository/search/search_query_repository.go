@@ -0,0 +1,43 @@
+// Search Query Repository for Magento's search terms (search_query)
+//
+// Magento records every storefront search with its popularity and result count. Terms are read
+// from the database on each call; callers that need them per keystroke keep their own copy.
+
+package search
+
+import (
+	"gorm.io/gorm"
+
+	entity "magento.GO/model/entity"
+	"magento.GO/model/repository"
+)
+
+// MaxPopularTerms is the most terms PopularTerms returns per store, by popularity.
+const MaxPopularTerms = 5000
+
+var searchQueryRepos repository.PerDB[*SearchQueryRepository]
+
+// GetSearchQueryRepository returns the SearchQueryRepository of the given DB.
+func GetSearchQueryRepository(db *gorm.DB) *SearchQueryRepository {
+	return searchQueryRepos.Get(db, NewSearchQueryRepository)
+}
+
+type SearchQueryRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchQueryRepository(db *gorm.DB) *SearchQueryRepository {
+	return &SearchQueryRepository{db: db}
+}
+
+// PopularTerms returns the active terms of a store that are shown in search terms and found
+// products, most popular first.
+func (r *SearchQueryRepository) PopularTerms(storeID uint16) ([]entity.SearchQuery, error) {
+	var terms []entity.SearchQuery
+	err := r.db.
+		Where("store_id = ? AND is_active = 1 AND display_in_terms = 1 AND num_results > 0", storeID).
+		Order("popularity DESC, query_text").
+		Limit(MaxPopularTerms).
+		Find(&terms).Error
+	return terms, err
+}
@@ -0,0 +1,252 @@
+// Store Repository for Magento websites, store groups and store views
+//
+// The store, store_group and store_website tables are small and rarely change, so they are
+// loaded once per repository and kept in memory; call InvalidateCache after changes.
+
+package store
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"gorm.io/gorm"
+
+	storeEntity "magento.GO/model/entity/store"
+	"magento.GO/model/repository"
+)
+
+// AdminStoreID is the admin store view (store_id 0).
+const AdminStoreID uint16 = 0
+
+var storeRepos repository.PerDB[*StoreRepository]
+
+// GetStoreRepository returns the StoreRepository of the given DB.
+func GetStoreRepository(db *gorm.DB) *StoreRepository {
+	return storeRepos.Get(db, NewStoreRepository)
+}
+
+type StoreRepository struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	loaded   bool
+	stores   []storeEntity.Store // ordered by sort_order, store_id
+	byID     map[uint16]storeEntity.Store
+	byCode   map[string]storeEntity.Store
+	groups   map[uint16]storeEntity.StoreGroup
+	websites map[uint16]storeEntity.StoreWebsite
+}
+
+func NewStoreRepository(db *gorm.DB) *StoreRepository {
+	return &StoreRepository{db: db}
+}
+
+// load reads store_website, store_group and store once.
+func (r *StoreRepository) load() error {
+	r.mu.RLock()
+	loaded := r.loaded
+	r.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	var websites []storeEntity.StoreWebsite
+	if err := r.db.Find(&websites).Error; err != nil {
+		return err
+	}
+	var groups []storeEntity.StoreGroup
+	if err := r.db.Find(&groups).Error; err != nil {
+		return err
+	}
+	var stores []storeEntity.Store
+	if err := r.db.Find(&stores).Error; err != nil {
+		return err
+	}
+	sort.SliceStable(stores, func(i, j int) bool {
+		if stores[i].SortOrder != stores[j].SortOrder {
+			return stores[i].SortOrder < stores[j].SortOrder
+		}
+		return stores[i].StoreID < stores[j].StoreID
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stores = stores
+	r.byID = make(map[uint16]storeEntity.Store, len(stores))
+	r.byCode = make(map[string]storeEntity.Store, len(stores))
+	for _, s := range stores {
+		r.byID[s.StoreID] = s
+		r.byCode[s.Code] = s
+	}
+	r.groups = make(map[uint16]storeEntity.StoreGroup, len(groups))
+	for _, g := range groups {
+		r.groups[g.GroupID] = g
+	}
+	r.websites = make(map[uint16]storeEntity.StoreWebsite, len(websites))
+	for _, w := range websites {
+		r.websites[w.WebsiteID] = w
+	}
+	r.loaded = true
+	return nil
+}
+
+// InvalidateCache drops the loaded stores, groups and websites.
+func (r *StoreRepository) InvalidateCache() {
+	r.mu.Lock()
+	r.loaded = false
+	r.stores, r.byID, r.byCode, r.groups, r.websites = nil, nil, nil, nil, nil
+	r.mu.Unlock()
+}
+
+// Stores returns all store views, including the admin store, ordered by sort_order.
+func (r *StoreRepository) Stores() ([]storeEntity.Store, error) {
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.stores, nil
+}
+
+// GetByID returns a store view by store_id.
+func (r *StoreRepository) GetByID(id uint16) (storeEntity.Store, bool) {
+	if err := r.load(); err != nil {
+		return storeEntity.Store{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.byID[id]
+	return s, ok
+}
+
+// GetByCode returns a store view by its code.
+func (r *StoreRepository) GetByCode(code string) (storeEntity.Store, bool) {
+	if err := r.load(); err != nil {
+		return storeEntity.Store{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.byCode[code]
+	return s, ok
+}
+
+// GetGroup returns a store group by group_id.
+func (r *StoreRepository) GetGroup(id uint16) (storeEntity.StoreGroup, bool) {
+	if err := r.load(); err != nil {
+		return storeEntity.StoreGroup{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.groups[id]
+	return g, ok
+}
+
+// GetWebsite returns a website by website_id.
+func (r *StoreRepository) GetWebsite(id uint16) (storeEntity.StoreWebsite, bool) {
+	if err := r.load(); err != nil {
+		return storeEntity.StoreWebsite{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.websites[id]
+	return w, ok
+}
+
+// WebsiteIDs returns the storefront websites (all but the admin website 0), ascending.
+func (r *StoreRepository) WebsiteIDs() ([]uint16, error) {
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]uint16, 0, len(r.websites))
+	for id := range r.websites {
+		if id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// DefaultStore returns the storefront default store view: the default store of the default group
+// of the default website (store_website.is_default).
+func (r *StoreRepository) DefaultStore() (storeEntity.Store, bool) {
+	if err := r.load(); err != nil {
+		return storeEntity.Store{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, w := range r.websites {
+		if w.IsDefault != 1 {
+			continue
+		}
+		if g, ok := r.groups[w.DefaultGroupID]; ok {
+			s, ok := r.byID[g.DefaultStoreID]
+			return s, ok
+		}
+	}
+	for _, s := range r.stores {
+		if s.StoreID != AdminStoreID && s.IsActive == 1 {
+			return s, true
+		}
+	}
+	return storeEntity.Store{}, false
+}
+
+// ParseStore resolves the store a client asks for (Store header, __Store): a numeric store_id as is,
+// or the code of an active store view. ok is false for unknown or inactive codes.
+func (r *StoreRepository) ParseStore(v string) (uint16, bool) {
+	if id, err := strconv.ParseUint(v, 10, 16); err == nil {
+		return uint16(id), true
+	}
+	s, ok := r.GetByCode(v)
+	if !ok || s.IsActive != 1 {
+		return 0, false
+	}
+	return s.StoreID, true
+}
+
+// ResolveStoreID maps a request store ID to a storefront store view: the admin store (0) and
+// unknown IDs resolve to the default store view.
+func (r *StoreRepository) ResolveStoreID(id uint16) uint16 {
+	if id != AdminStoreID {
+		if _, ok := r.GetByID(id); ok {
+			return id
+		}
+	}
+	if s, ok := r.DefaultStore(); ok {
+		return s.StoreID
+	}
+	return id
+}
+
+// WebsiteID returns the website of a request store ID, resolved as ResolveStoreID; 0 when no store
+// view is configured.
+func (r *StoreRepository) WebsiteID(storeID uint16) uint16 {
+	s, ok := r.GetByID(r.ResolveStoreID(storeID))
+	if !ok {
+		return 0
+	}
+	return s.WebsiteID
+}
+
+// WebsiteStores returns the active store views of a website, optionally limited to one group.
+func (r *StoreRepository) WebsiteStores(websiteID uint16, groupID *uint16) ([]storeEntity.Store, error) {
+	stores, err := r.Stores()
+	if err != nil {
+		return nil, err
+	}
+	var out []storeEntity.Store
+	for _, s := range stores {
+		if s.StoreID == AdminStoreID || s.IsActive != 1 || s.WebsiteID != websiteID {
+			continue
+		}
+		if groupID != nil && s.GroupID != *groupID {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
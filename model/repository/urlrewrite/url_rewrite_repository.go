@@ -0,0 +1,127 @@
+// URL Rewrite Repository for Magento storefront URLs
+//
+// Resolves request paths (e.g. "men/tops/some-shirt.html") against url_rewrite per store,
+// following 301/302 redirects to the entity they end at.
+
+package urlrewrite
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+
+	entity "magento.GO/model/entity"
+	"magento.GO/model/repository"
+	storeRepo "magento.GO/model/repository/store"
+)
+
+// maxRedirectHops bounds how many redirects Resolve follows, so rewrite loops terminate.
+const maxRedirectHops = 5
+
+var urlRewriteRepos repository.PerDB[*UrlRewriteRepository]
+
+// GetUrlRewriteRepository returns the UrlRewriteRepository of the given DB.
+func GetUrlRewriteRepository(db *gorm.DB) *UrlRewriteRepository {
+	return urlRewriteRepos.Get(db, NewUrlRewriteRepository)
+}
+
+type UrlRewriteRepository struct {
+	db *gorm.DB
+}
+
+func NewUrlRewriteRepository(db *gorm.DB) *UrlRewriteRepository {
+	return &UrlRewriteRepository{db: db}
+}
+
+// ResolvedUrl is the outcome of resolving a storefront URL.
+type ResolvedUrl struct {
+	// Rewrite is the row the URL ends at: the entity's own rewrite after following redirects,
+	// or the last redirect when its target has no rewrite (e.g. an external or custom URL).
+	Rewrite entity.UrlRewrite
+	// RelativeURL is the path the client should use: the request path of Rewrite, or the redirect
+	// target when the chain ends at a redirect.
+	RelativeURL string
+	// RedirectCode is the first redirect's code (301/302), or 0 when the URL was not redirected.
+	RedirectCode int
+}
+
+// NormalizePath turns a URL or path into a url_rewrite request_path: scheme, host, query and
+// fragment are dropped, as is the leading slash.
+func NormalizePath(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if u, err := url.Parse(raw); err == nil {
+		raw = u.Path
+	}
+	return strings.TrimLeft(raw, "/")
+}
+
+// FindByRequestPath returns the rewrite for path in the store, trying the path with and without a
+// trailing slash. The admin store (store_id 0 has no rewrites) and unknown stores look in the
+// default store view. It returns nil when no rewrite matches.
+func (r *UrlRewriteRepository) FindByRequestPath(path string, storeID uint16) (*entity.UrlRewrite, error) {
+	storeID = storeRepo.GetStoreRepository(r.db).ResolveStoreID(storeID)
+	candidates := []string{path}
+	if strings.HasSuffix(path, "/") {
+		candidates = append(candidates, strings.TrimRight(path, "/"))
+	} else {
+		candidates = append(candidates, path+"/")
+	}
+	var rows []entity.UrlRewrite
+	if err := r.db.Where("request_path IN ? AND store_id = ?", candidates, storeID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		for i := range rows {
+			if rows[i].RequestPath == candidate {
+				return &rows[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Resolve looks up rawURL in the store and follows 301/302 rewrites. It returns nil when the URL
+// has no rewrite.
+func (r *UrlRewriteRepository) Resolve(rawURL string, storeID uint16) (*ResolvedUrl, error) {
+	path := NormalizePath(rawURL)
+	if path == "" {
+		return nil, nil
+	}
+	rw, err := r.FindByRequestPath(path, storeID)
+	if err != nil || rw == nil {
+		return nil, err
+	}
+	res := &ResolvedUrl{Rewrite: *rw, RelativeURL: rw.RequestPath}
+	seen := map[string]bool{rw.RequestPath: true}
+	for hops := 0; isRedirect(rw.RedirectType) && hops < maxRedirectHops; hops++ {
+		if res.RedirectCode == 0 {
+			res.RedirectCode = int(rw.RedirectType)
+		}
+		res.RelativeURL = rw.TargetPath
+		if u, err := url.Parse(rw.TargetPath); err == nil && u.Host != "" {
+			break // external target
+		}
+		target := NormalizePath(rw.TargetPath)
+		if seen[target] {
+			return nil, errors.New("url_rewrite redirect loop at " + target)
+		}
+		seen[target] = true
+		next, err := r.FindByRequestPath(target, storeID)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			break
+		}
+		rw = next
+		res.Rewrite = *next
+		res.RelativeURL = next.RequestPath
+	}
+	return res, nil
+}
+
+func isRedirect(redirectType uint16) bool {
+	return redirectType == 301 || redirectType == 302
+}
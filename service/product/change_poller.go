@@ -0,0 +1,128 @@
+package product
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"magento.GO/core/events"
+	customerRepo "magento.GO/model/repository/customer"
+	inventoryRepo "magento.GO/model/repository/inventory"
+	priceRepo "magento.GO/model/repository/price"
+	storeRepo "magento.GO/model/repository/store"
+)
+
+// ChangePoller publishes stock and price changes made outside this process (Magento admin, imports
+// run from the CLI or another instance). Each poll reloads only the SKUs someone subscribed to on
+// the hub and publishes those whose stock, or price in any website and customer group, differs from
+// the previous poll.
+type ChangePoller struct {
+	db       *gorm.DB
+	hub      *events.Hub
+	interval time.Duration
+
+	once   sync.Once
+	mu     sync.Mutex
+	stock  map[string]inventoryRepo.Stock
+	prices map[priceScope]map[string]float64
+}
+
+// priceScope is the website and customer group of a polled price.
+type priceScope struct {
+	websiteID uint16
+	groupID   int
+}
+
+// NewChangePoller creates a poller of db publishing on hub every interval (0 disables Start).
+func NewChangePoller(db *gorm.DB, hub *events.Hub, interval time.Duration) *ChangePoller {
+	return &ChangePoller{db: db, hub: hub, interval: interval}
+}
+
+// Start polls in the background every interval. Only the first call starts the poller.
+func (p *ChangePoller) Start() {
+	if p.interval <= 0 {
+		return
+	}
+	p.once.Do(func() {
+		go func() {
+			ticker := time.NewTicker(p.interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				p.Poll()
+			}
+		}()
+	})
+}
+
+// Poll compares the watched SKUs with the previous poll and publishes the changed ones. SKUs seen
+// for the first time are only recorded.
+func (p *ChangePoller) Poll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if skus := p.hub.Watched(events.TopicStock); len(skus) > 0 {
+		if repo, err := inventoryRepo.GetInventoryRepository(p.db); err == nil {
+			if stock, err := repo.GetStockBySKUs(skus); err == nil {
+				p.hub.Publish(events.TopicStock, changedSKUs(p.stock, stock)...)
+				p.stock = stock
+			}
+		}
+	}
+	if skus := p.hub.Watched(events.TopicPrice); len(skus) > 0 {
+		if repo, err := priceRepo.GetPriceRepository(p.db); err == nil {
+			groups := p.customerGroupIDs()
+			prices := make(map[priceScope]map[string]float64)
+			var changed []string
+			for _, websiteID := range p.websiteIDs() {
+				websitePrices, err := repo.GetLowestPricesBySKUsForGroups(skus, websiteID, groups)
+				if err != nil {
+					return
+				}
+				for group, groupPrices := range websitePrices {
+					scope := priceScope{websiteID: websiteID, groupID: group}
+					changed = append(changed, changedSKUs(p.prices[scope], groupPrices)...)
+					prices[scope] = groupPrices
+				}
+			}
+			p.hub.Publish(events.TopicPrice, changed...)
+			p.prices = prices
+		}
+	}
+}
+
+// websiteIDs returns the websites whose prices are polled: all of them, or only the default store
+// view's when the websites cannot be read.
+func (p *ChangePoller) websiteIDs() []uint16 {
+	stores := storeRepo.GetStoreRepository(p.db)
+	if ids, err := stores.WebsiteIDs(); err == nil && len(ids) > 0 {
+		return ids
+	}
+	return []uint16{stores.WebsiteID(storeRepo.AdminStoreID)}
+}
+
+// customerGroupIDs returns the customer groups whose prices are polled: all of them, or only NOT
+// LOGGED IN when the groups cannot be read.
+func (p *ChangePoller) customerGroupIDs() []int {
+	groups := []int{0}
+	ids, err := customerRepo.GetCustomerRepository(p.db).GetGroupIDs()
+	if err != nil {
+		return groups
+	}
+	for _, id := range ids {
+		if id != 0 {
+			groups = append(groups, int(id))
+		}
+	}
+	return groups
+}
+
+// changedSKUs returns the SKUs of current whose value differs from a recorded previous one.
+func changedSKUs[T comparable](previous, current map[string]T) []string {
+	var changed []string
+	for sku, v := range current {
+		if old, ok := previous[sku]; ok && old != v {
+			changed = append(changed, sku)
+		}
+	}
+	return changed
+}
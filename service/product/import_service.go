@@ -11,6 +11,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"magento.GO/core/events"
 	entity "magento.GO/model/entity"
 	productEntity "magento.GO/model/entity/product"
 )
@@ -41,6 +42,9 @@ type attrMeta struct {
 	BackendType string
 }
 
+// priceChangeColumns are the columns that change the price of a SKU (see PriceRepository.GetLowestPriceBySKU).
+var priceChangeColumns = []string{"price", "special_price", "tier_price"}
+
 var staticFields = map[string]bool{
 	"sku": true, "type_id": true, "attribute_set_id": true,
 }
@@ -172,6 +176,16 @@ func ImportProducts(db *gorm.DB, r io.Reader, opts ImportOptions) (*ImportResult
 	}
 	result.DBTime = time.Since(startDB)
 
+	// Notify price/stock subscribers (GraphQL subscriptions) and the search index of the imported SKUs
+	events.Publish(events.TopicProduct, importedSKUs(skus, skuToID)...)
+	events.Publish(events.TopicStock, stockData.skus...)
+	for _, col := range priceChangeColumns {
+		if _, ok := colIndex[col]; ok {
+			events.Publish(events.TopicPrice, importedSKUs(skus, skuToID)...)
+			break
+		}
+	}
+
 	// Merge counts
 	for k, v := range eavData.counts() {
 		result.EAVCounts[k] = v
@@ -187,6 +201,17 @@ func ImportProducts(db *gorm.DB, r io.Reader, opts ImportOptions) (*ImportResult
 	return result, nil
 }
 
+// importedSKUs returns the SKUs of skus that exist after the import.
+func importedSKUs(skus []string, skuToID map[string]uint) []string {
+	out := make([]string, 0, len(skus))
+	for _, sku := range skus {
+		if _, ok := skuToID[sku]; ok {
+			out = append(out, sku)
+		}
+	}
+	return out
+}
+
 // lookupSKUs batch-queries existing SKUs and returns sku->linkID map.
 // linkID is entity_id for CE, row_id for EE (determined at compile time).
 func lookupSKUs(db *gorm.DB, skus []string, batchSize int) map[string]uint {
@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
+	"magento.GO/core/events"
 	productEntity "magento.GO/model/entity/product"
 )
 
@@ -19,6 +20,7 @@ var stockColumns = map[string]bool{
 // stockData holds collected stock rows ready to flush.
 type stockData struct {
 	rows     []productEntity.StockItem
+	skus     []string // SKU of each row, for change events
 	warnings []string
 }
 
@@ -108,6 +110,7 @@ func collectStock(rows [][]string, colIndex map[string]int, skuToID map[string]u
 
 		if populated {
 			d.rows = append(d.rows, item)
+			d.skus = append(d.skus, sku)
 		}
 	}
 	return d
@@ -179,6 +182,7 @@ func ImportStockJSON(db *gorm.DB, items []StockItemInput, batchSize int) (*Stock
 
 	// Build stock item rows
 	rows := make([]productEntity.StockItem, 0, len(items))
+	imported := make([]string, 0, len(items))
 	for _, it := range items {
 		if it.SKU == "" {
 			result.Skipped++
@@ -217,6 +221,7 @@ func ImportStockJSON(db *gorm.DB, items []StockItemInput, batchSize int) (*Stock
 			item.MaxSaleQty = *it.MaxSaleQty
 		}
 		rows = append(rows, item)
+		imported = append(imported, it.SKU)
 	}
 
 	if len(rows) > 0 {
@@ -227,6 +232,7 @@ func ImportStockJSON(db *gorm.DB, items []StockItemInput, batchSize int) (*Stock
 		if err := db.Clauses(upsert).CreateInBatches(rows, batchSize).Error; err != nil {
 			return nil, fmt.Errorf("stock upsert: %w", err)
 		}
+		events.Publish(events.TopicStock, imported...)
 	}
 
 	result.Imported = len(rows)
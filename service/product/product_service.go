@@ -1,6 +1,7 @@
 package product
 
 import (
+	"magento.GO/core/events"
 	productEntity "magento.GO/model/entity/product"
 	productRepository "magento.GO/model/repository/product"
 )
@@ -37,7 +38,11 @@ func (s *ProductService) CreateProduct(input *ProductInput) error {
 		HasOptions:      input.HasOptions,
 		RequiredOptions: input.RequiredOptions,
 	}
-	return s.repo.Create(prod)
+	if err := s.repo.Create(prod); err != nil {
+		return err
+	}
+	events.Publish(events.TopicProduct, prod.SKU)
+	return nil
 }
 
 func (s *ProductService) UpdateProduct(id uint, input *ProductInput) error {
@@ -45,14 +50,27 @@ func (s *ProductService) UpdateProduct(id uint, input *ProductInput) error {
 	if err != nil {
 		return err
 	}
+	oldSKU := prod.SKU
 	prod.AttributeSetID = input.AttributeSetID
 	prod.TypeID = input.TypeID
 	prod.SKU = input.SKU
 	prod.HasOptions = input.HasOptions
 	prod.RequiredOptions = input.RequiredOptions
-	return s.repo.Update(prod)
+	if err := s.repo.Update(prod); err != nil {
+		return err
+	}
+	events.Publish(events.TopicProduct, oldSKU, prod.SKU)
+	return nil
 }
 
 func (s *ProductService) DeleteProduct(id uint) error {
-	return s.repo.Delete(id)
+	prod, err := s.repo.FindByID(id)
+	if err != nil {
+		return s.repo.Delete(id)
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	events.Publish(events.TopicProduct, prod.SKU)
+	return nil
 } 
\ No newline at end of file
@@ -0,0 +1,266 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	graphqlApi "magento.GO/api/graphql"
+	entity "magento.GO/model/entity"
+	customerEntity "magento.GO/model/entity/customer"
+	priceEntity "magento.GO/model/entity/price"
+	productEntity "magento.GO/model/entity/product"
+	quoteEntity "magento.GO/model/entity/quote"
+)
+
+// seedCartProducts creates TEE-1 (simple, price 20 with an all-groups tier price of 18, 5 in
+// stock) and EBOOK-1 (virtual, price 9.5, 100 in stock).
+func seedCartProducts(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	seedDefaultStore(t, db)
+	if err := db.AutoMigrate(&quoteEntity.Quote{}, &quoteEntity.QuoteItem{}, &quoteEntity.QuoteIDMask{}, &priceEntity.TierPrice{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&entity.EavAttribute{AttributeID: 77, EntityTypeID: 4, AttributeCode: "price", BackendType: "decimal"})
+	for _, p := range []struct {
+		sku, typeID string
+		price, qty  float64
+	}{{"TEE-1", "simple", 20, 5}, {"EBOOK-1", "virtual", 9.5, 100}} {
+		product := productEntity.Product{AttributeSetID: 4, TypeID: p.typeID, SKU: p.sku}
+		db.Create(&product)
+		db.Create(&productEntity.ProductDecimal{AttributeID: 77, EntityID: product.EntityID, Value: p.price})
+		db.Create(&productEntity.ProductIndexPrice{EntityID: product.EntityID, WebsiteID: 1, Price: p.price, FinalPrice: p.price})
+		db.Create(&productEntity.StockItem{ProductID: product.EntityID, StockID: 1, Qty: p.qty, IsInStock: 1})
+		if p.sku == "TEE-1" {
+			db.Create(&priceEntity.TierPrice{EntityID: product.EntityID, AllGroups: 1, Qty: 1, Value: 18, WebsiteID: 0})
+		}
+	}
+}
+
+func cartItemsBySKU(cart map[string]interface{}) map[string]map[string]interface{} {
+	items := make(map[string]map[string]interface{})
+	for _, it := range cart["items"].([]interface{}) {
+		item := it.(map[string]interface{})
+		items[item["product"].(map[string]interface{})["sku"].(string)] = item
+	}
+	return items
+}
+
+func cartSubtotal(cart map[string]interface{}) float64 {
+	prices := cart["prices"].(map[string]interface{})
+	return prices["subtotal_excluding_tax"].(map[string]interface{})["value"].(float64)
+}
+
+const cartFields = `id total_quantity is_virtual
+	items { __typename uid quantity product { sku } prices { price { value } row_total { value } } }
+	prices { subtotal_excluding_tax { value currency } grand_total { value } }`
+
+func TestGraphQL_GuestCart(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedCartProducts(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	cartID, _ := execGraphQL(t, e, `mutation { createEmptyCart }`)["createEmptyCart"].(string)
+	if len(cartID) != 32 {
+		t.Fatalf("createEmptyCart = %q, want a 32-character masked ID", cartID)
+	}
+
+	data := execGraphQL(t, e, `mutation { addProductsToCart(cartId: "`+cartID+`", cartItems: [
+		{ sku: "TEE-1", quantity: 2 },
+		{ sku: "EBOOK-1", quantity: 1 },
+		{ sku: "NOPE", quantity: 1 },
+		{ sku: "TEE-1", quantity: 10 }
+	]) { cart { `+cartFields+` } user_errors { code message } } }`)
+	out := data["addProductsToCart"].(map[string]interface{})
+	userErrors := out["user_errors"].([]interface{})
+	if len(userErrors) != 2 ||
+		userErrors[0].(map[string]interface{})["code"] != "PRODUCT_NOT_FOUND" ||
+		userErrors[1].(map[string]interface{})["code"] != "INSUFFICIENT_STOCK" {
+		t.Errorf("user_errors = %v, want PRODUCT_NOT_FOUND for NOPE and INSUFFICIENT_STOCK for 12 of TEE-1", userErrors)
+	}
+	cart := out["cart"].(map[string]interface{})
+	items := cartItemsBySKU(cart)
+	tee, ebook := items["TEE-1"], items["EBOOK-1"]
+	if len(items) != 2 || tee == nil || ebook == nil {
+		t.Fatalf("cart items = %v, want TEE-1 and EBOOK-1", cart["items"])
+	}
+	if tee["__typename"] != "SimpleCartItem" || ebook["__typename"] != "VirtualCartItem" {
+		t.Errorf("item types = %v / %v, want SimpleCartItem / VirtualCartItem", tee["__typename"], ebook["__typename"])
+	}
+	if price := tee["prices"].(map[string]interface{})["price"].(map[string]interface{})["value"]; price != 18.0 {
+		t.Errorf("TEE-1 price = %v, want the tier price 18", price)
+	}
+	if cart["total_quantity"] != 3.0 || cart["is_virtual"] != false || cartSubtotal(cart) != 45.5 {
+		t.Errorf("cart = %v, want 3 items with subtotal 2*18 + 9.5 = 45.5", cart)
+	}
+
+	data = execGraphQL(t, e, `mutation { updateCartItems(input: { cart_id: "`+cartID+`", cart_items: [
+		{ cart_item_uid: "`+tee["uid"].(string)+`", quantity: 3 }
+	] }) { cart { `+cartFields+` } } }`)
+	cart = data["updateCartItems"].(map[string]interface{})["cart"].(map[string]interface{})
+	if cart["total_quantity"] != 4.0 || cartSubtotal(cart) != 63.5 {
+		t.Errorf("updated cart = %v, want 4 items with subtotal 3*18 + 9.5 = 63.5", cart)
+	}
+	if errs := graphQLErrors(t, e, `mutation { updateCartItems(input: { cart_id: "`+cartID+`", cart_items: [
+		{ cart_item_uid: "`+tee["uid"].(string)+`", quantity: 6 }
+	] }) { cart { id } } }`); len(errs) == 0 || !strings.Contains(errs[0], "not available") {
+		t.Errorf("update beyond stock errors = %v, want a qty error", errs)
+	}
+
+	data = execGraphQL(t, e, `mutation { removeItemFromCart(input: { cart_id: "`+cartID+`", cart_item_uid: "`+ebook["uid"].(string)+`" }) { cart { `+cartFields+` } } }`)
+	cart = data["removeItemFromCart"].(map[string]interface{})["cart"].(map[string]interface{})
+	if len(cart["items"].([]interface{})) != 1 || cartSubtotal(cart) != 54.0 {
+		t.Errorf("cart after remove = %v, want TEE-1 only with subtotal 54", cart)
+	}
+
+	cart = execGraphQL(t, e, `{ cart(cart_id: "`+cartID+`") { `+cartFields+` } }`)["cart"].(map[string]interface{})
+	if cart["id"] != cartID || cart["total_quantity"] != 3.0 || cartSubtotal(cart) != 54.0 {
+		t.Errorf("cart query = %v, want the stored cart", cart)
+	}
+
+	var mask quoteEntity.QuoteIDMask
+	if err := db.Where("masked_id = ?", cartID).First(&mask).Error; err != nil {
+		t.Fatalf("quote_id_mask: %v", err)
+	}
+	var q quoteEntity.Quote
+	db.First(&q, mask.QuoteID)
+	if q.ItemsCount != 1 || q.ItemsQty != 3 || q.BaseGrandTotal != 54 || q.IsActive != 1 {
+		t.Errorf("quote = %+v, want 1 item, qty 3 and grand total 54", q)
+	}
+	var rows int64
+	db.Model(&quoteEntity.QuoteItem{}).Where("quote_id = ?", q.EntityID).Count(&rows)
+	if rows != 1 {
+		t.Errorf("quote_item rows = %d, want 1", rows)
+	}
+}
+
+func TestGraphQL_GuestCart_Errors(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedCartProducts(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	if errs := graphQLErrors(t, e, `{ cart(cart_id: "missing") { id } }`); len(errs) == 0 || !strings.Contains(errs[0], "could not find a cart") {
+		t.Errorf("unknown cart errors = %v, want cart not found", errs)
+	}
+	chosen := strings.Repeat("a", 32)
+	if id := execGraphQL(t, e, `mutation { createEmptyCart(input: { cart_id: "`+chosen+`" }) }`)["createEmptyCart"]; id != chosen {
+		t.Errorf("createEmptyCart with cart_id = %v, want %s", id, chosen)
+	}
+	if errs := graphQLErrors(t, e, `mutation { createEmptyCart(input: { cart_id: "`+chosen+`" }) }`); len(errs) == 0 {
+		t.Error("createEmptyCart with a taken cart_id succeeded, want an error")
+	}
+	if errs := graphQLErrors(t, e, `mutation { removeItemFromCart(input: { cart_id: "`+chosen+`", cart_item_id: 999 }) { cart { id } } }`); len(errs) == 0 {
+		t.Error("removing an unknown item succeeded, want an error")
+	}
+}
+
+func TestGraphQL_CustomerCartOwnership(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedCartProducts(t, db)
+	if err := db.AutoMigrate(&entity.OauthToken{}, &customerEntity.Customer{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	owner, other := customerEntity.Customer{GroupID: 1, IsActive: 1}, customerEntity.Customer{GroupID: 1, IsActive: 1}
+	db.Create(&owner)
+	db.Create(&other)
+	db.Create(&[]entity.OauthToken{
+		{CustomerID: &owner.EntityID, Type: "access", Token: "owner-token", Secret: "s"},
+		{CustomerID: &other.EntityID, Type: "access", Token: "other-token", Secret: "s"},
+	})
+	q := quoteEntity.Quote{StoreID: 1, IsActive: 1, CustomerID: &owner.EntityID}
+	db.Create(&q)
+	cartID := strings.Repeat("c", 32)
+	db.Create(&quoteEntity.QuoteIDMask{QuoteID: q.EntityID, MaskedID: cartID})
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	cartOperations := func(cartID string) map[string]string {
+		return map[string]string{
+			"cart":               `{ cart(cart_id: "` + cartID + `") { id } }`,
+			"addProductsToCart":  `mutation { addProductsToCart(cartId: "` + cartID + `", cartItems: [{ sku: "TEE-1", quantity: 1 }]) { cart { id } } }`,
+			"updateCartItems":    `mutation { updateCartItems(input: { cart_id: "` + cartID + `", cart_items: [{ cart_item_id: 1, quantity: 2 }] }) { cart { id } } }`,
+			"removeItemFromCart": `mutation { removeItemFromCart(input: { cart_id: "` + cartID + `", cart_item_id: 1 }) { cart { id } } }`,
+		}
+	}
+	expectRefused := func(cartID string, requesters map[string]map[string]string) {
+		t.Helper()
+		for name, query := range cartOperations(cartID) {
+			for requester, headers := range requesters {
+				errs := graphQLErrorsWithHeaders(t, e, query, headers)
+				if len(errs) == 0 || !strings.Contains(errs[0], "cannot perform operations on cart") {
+					t.Errorf("%s as %s: errors = %v, want the cart refused", name, requester, errs)
+				}
+			}
+		}
+	}
+	operations := cartOperations(cartID)
+	expectRefused(cartID, map[string]map[string]string{
+		"guest":          nil,
+		"other customer": {"Authorization": "Bearer other-token"},
+	})
+
+	owned := map[string]string{"Authorization": "Bearer owner-token"}
+	data := execGraphQLWithHeaders(t, e, operations["addProductsToCart"], owned)
+	if cart := data["addProductsToCart"].(map[string]interface{})["cart"].(map[string]interface{}); cart["id"] != cartID {
+		t.Errorf("owner addProductsToCart cart = %v", cart)
+	}
+	if data := execGraphQLWithHeaders(t, e, operations["cart"], owned); data["cart"].(map[string]interface{})["id"] != cartID {
+		t.Errorf("owner cart = %v", data["cart"])
+	}
+
+	// A guest cart is refused to customers, so they cannot fill it at NOT LOGGED IN prices.
+	guestCartID, _ := execGraphQL(t, e, `mutation { createEmptyCart }`)["createEmptyCart"].(string)
+	expectRefused(guestCartID, map[string]map[string]string{"customer": owned})
+	if data := execGraphQL(t, e, cartOperations(guestCartID)["cart"]); data["cart"].(map[string]interface{})["id"] != guestCartID {
+		t.Errorf("guest cart = %v", data["cart"])
+	}
+
+	// A customer's createEmptyCart creates a cart they own, priced for their group.
+	customerCartID, _ := execGraphQLWithHeaders(t, e, `mutation { createEmptyCart }`, owned)["createEmptyCart"].(string)
+	data = execGraphQLWithHeaders(t, e, cartOperations(customerCartID)["addProductsToCart"], owned)
+	if cart := data["addProductsToCart"].(map[string]interface{})["cart"].(map[string]interface{}); cart["id"] != customerCartID {
+		t.Errorf("customer addProductsToCart cart = %v", cart)
+	}
+	var created quoteEntity.Quote
+	db.Joins("JOIN quote_id_mask ON quote_id_mask.quote_id = quote.entity_id").Where("quote_id_mask.masked_id = ?", customerCartID).First(&created)
+	if created.CustomerID == nil || *created.CustomerID != owner.EntityID || created.CustomerGroupID != 1 || created.CustomerIsGuest != 0 {
+		t.Errorf("customer cart customer_id/group/is_guest = %v/%d/%d, want %d/1/0", created.CustomerID, created.CustomerGroupID, created.CustomerIsGuest, owner.EntityID)
+	}
+	expectRefused(customerCartID, map[string]map[string]string{"guest": nil})
+}
+
+// graphQLErrorsWithHeaders posts query with the given request headers and returns the error
+// messages of the response.
+func graphQLErrorsWithHeaders(t *testing.T, e *echo.Echo, query string, headers map[string]string) []string {
+	t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"query": query})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	var resp struct {
+		Errors []struct{ Message string }
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var messages []string
+	for _, e := range resp.Errors {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
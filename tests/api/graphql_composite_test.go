@@ -0,0 +1,211 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	graphqlApi "magento.GO/api/graphql"
+	productApi "magento.GO/api/product"
+	productEntity "magento.GO/model/entity/product"
+)
+
+// seedComposite creates BUNDLE-1, a dynamic bundle with a required radio option (10 or 20) and an
+// optional checkbox option (5), and GROUPED-1 with two children linked at positions 2 and 1.
+func seedComposite(t *testing.T, db *gorm.DB) (bundleID, groupedID uint) {
+	t.Helper()
+	if err := db.AutoMigrate(
+		&productEntity.ProductBundleOption{},
+		&productEntity.ProductBundleOptionValue{},
+		&productEntity.ProductBundleSelection{},
+		&productEntity.ProductLink{},
+		&productEntity.ProductLinkAttribute{},
+		&productEntity.ProductLinkAttributeInt{},
+		&productEntity.ProductLinkAttributeDecimal{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	child := func(sku string, price float64) uint {
+		p := productEntity.Product{AttributeSetID: 4, TypeID: "simple", SKU: sku}
+		db.Create(&p)
+		db.Create(&productEntity.ProductIndexPrice{EntityID: p.EntityID, WebsiteID: 1, Price: price, FinalPrice: price})
+		return p.EntityID
+	}
+
+	bundle := productEntity.Product{AttributeSetID: 4, TypeID: "bundle", SKU: "BUNDLE-1"}
+	db.Create(&bundle)
+	small, large, extra := child("B-SMALL", 10), child("B-LARGE", 20), child("B-EXTRA", 5)
+	size := productEntity.ProductBundleOption{ParentID: bundle.EntityID, Required: 1, Position: 1, Type: "radio"}
+	addon := productEntity.ProductBundleOption{ParentID: bundle.EntityID, Required: 0, Position: 2, Type: "checkbox"}
+	db.Create(&size)
+	db.Create(&addon)
+	db.Create(&[]productEntity.ProductBundleOptionValue{
+		{OptionID: size.OptionID, ParentProductID: bundle.EntityID, Title: "Size"},
+		{OptionID: addon.OptionID, ParentProductID: bundle.EntityID, Title: "Extras"},
+	})
+	db.Create(&[]productEntity.ProductBundleSelection{
+		{OptionID: size.OptionID, ParentProductID: bundle.EntityID, ProductID: small, Position: 1, IsDefault: 1, SelectionQty: 1},
+		{OptionID: size.OptionID, ParentProductID: bundle.EntityID, ProductID: large, Position: 2, SelectionQty: 1},
+		{OptionID: addon.OptionID, ParentProductID: bundle.EntityID, ProductID: extra, Position: 1, SelectionQty: 1},
+	})
+
+	grouped := productEntity.Product{AttributeSetID: 4, TypeID: "grouped", SKU: "GROUPED-1"}
+	db.Create(&grouped)
+	first, second := child("G-FIRST", 7), child("G-SECOND", 3)
+	position := productEntity.ProductLinkAttribute{LinkTypeID: 3, ProductLinkAttributeCode: "position", DataType: "int"}
+	qty := productEntity.ProductLinkAttribute{LinkTypeID: 3, ProductLinkAttributeCode: "qty", DataType: "decimal"}
+	db.Create(&position)
+	db.Create(&qty)
+	for i, id := range []uint{first, second} {
+		link := productEntity.ProductLink{ProductID: grouped.EntityID, LinkedProductID: id, LinkTypeID: 3}
+		db.Create(&link)
+		db.Create(&productEntity.ProductLinkAttributeInt{ProductLinkAttributeID: position.ProductLinkAttributeID, LinkID: link.LinkID, Value: 2 - i})
+		db.Create(&productEntity.ProductLinkAttributeDecimal{ProductLinkAttributeID: qty.ProductLinkAttributeID, LinkID: link.LinkID, Value: 1})
+	}
+	return bundle.EntityID, grouped.EntityID
+}
+
+func priceRangeValues(p map[string]interface{}) (minPrice, maxPrice float64) {
+	pr := p["price_range"].(map[string]interface{})
+	value := func(key string) float64 {
+		return pr[key].(map[string]interface{})["final_price"].(map[string]interface{})["value"].(float64)
+	}
+	return value("minimum_price"), value("maximum_price")
+}
+
+func TestGraphQL_MagentoProducts_BundleAndGrouped(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedComposite(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data := execGraphQL(t, e, `{ magentoProducts(filter: { sku: { in: ["BUNDLE-1", "GROUPED-1"] } }, sort: { name: ASC }) { typed_items {
+		__typename sku
+		price_range { minimum_price { final_price { value } } maximum_price { final_price { value } } }
+		... on BundleProduct { dynamic_price items { title required type options { label price price_type is_default product { sku } } } }
+		... on GroupedProduct { items { position qty product { sku } } }
+	} } }`)
+	items := data["magentoProducts"].(map[string]interface{})["typed_items"].([]interface{})
+	bySKU := map[string]map[string]interface{}{}
+	for _, it := range items {
+		p := it.(map[string]interface{})
+		bySKU[p["sku"].(string)] = p
+	}
+
+	bundle := bySKU["BUNDLE-1"]
+	if bundle == nil || bundle["__typename"] != "BundleProduct" || bundle["dynamic_price"] != true {
+		t.Fatalf("bundle = %v, want dynamic BundleProduct", bundle)
+	}
+	if minPrice, maxPrice := priceRangeValues(bundle); minPrice != 10 || maxPrice != 25 {
+		t.Errorf("bundle price range = %v-%v, want 10-25", minPrice, maxPrice)
+	}
+	bundleItems := bundle["items"].([]interface{})
+	if len(bundleItems) != 2 {
+		t.Fatalf("bundle items = %d, want 2", len(bundleItems))
+	}
+	sizeItem := bundleItems[0].(map[string]interface{})
+	if sizeItem["title"] != "Size" || sizeItem["required"] != true || sizeItem["type"] != "radio" {
+		t.Errorf("items[0] = %v", sizeItem)
+	}
+	sizeOptions := sizeItem["options"].([]interface{})
+	if len(sizeOptions) != 2 {
+		t.Fatalf("items[0].options = %d, want 2", len(sizeOptions))
+	}
+	small := sizeOptions[0].(map[string]interface{})
+	if small["product"].(map[string]interface{})["sku"] != "B-SMALL" || small["price"].(float64) != 10 ||
+		small["price_type"] != "DYNAMIC" || small["is_default"] != true {
+		t.Errorf("items[0].options[0] = %v", small)
+	}
+
+	grouped := bySKU["GROUPED-1"]
+	if grouped == nil || grouped["__typename"] != "GroupedProduct" {
+		t.Fatalf("grouped = %v, want GroupedProduct", grouped)
+	}
+	if minPrice, maxPrice := priceRangeValues(grouped); minPrice != 3 || maxPrice != 7 {
+		t.Errorf("grouped price range = %v-%v, want 3-7", minPrice, maxPrice)
+	}
+	groupedItems := grouped["items"].([]interface{})
+	if len(groupedItems) != 2 {
+		t.Fatalf("grouped items = %d, want 2", len(groupedItems))
+	}
+	firstItem := groupedItems[0].(map[string]interface{})
+	if firstItem["product"].(map[string]interface{})["sku"] != "G-SECOND" || firstItem["position"].(float64) != 1 || firstItem["qty"].(float64) != 1 {
+		t.Errorf("grouped items[0] = %v, want G-SECOND at position 1", firstItem)
+	}
+}
+
+func TestGraphQL_CompositeChildren_OneQueryPerLevel(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedComposite(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	// each flat product fetch reads catalog_product_entity_varchar once
+	var flatFetches int
+	db.Callback().Query().After("gorm:query").Register("test:count_flat_fetches", func(tx *gorm.DB) {
+		if tx.Statement.Table == "catalog_product_entity_varchar" {
+			flatFetches++
+		}
+	})
+	data := execGraphQL(t, e, `{ magentoProducts(filter: { sku: { in: ["BUNDLE-1", "GROUPED-1"] } }) { typed_items {
+		sku
+		... on BundleProduct { items { options { product { sku } } } }
+		... on GroupedProduct { items { product { sku } } }
+	} } }`)
+	if items := data["magentoProducts"].(map[string]interface{})["typed_items"].([]interface{}); len(items) != 2 {
+		t.Fatalf("items = %v, want BUNDLE-1 and GROUPED-1", items)
+	}
+	if flatFetches != 2 {
+		t.Errorf("flat product fetches = %d, want 2 (the page, then the children of both products)", flatFetches)
+	}
+}
+
+func TestProductAPI_FlatByIDs_BundleAndGrouped(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	bundleID, groupedID := seedComposite(t, db)
+	productApi.RegisterProductRoutes(e.Group("/api"), db)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/products/flat/%d,%d", bundleID, groupedID), nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Products []map[string]interface{} `json:"products"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Products) != 2 {
+		t.Fatalf("products = %d, want 2", len(resp.Products))
+	}
+	bundle, grouped := resp.Products[0], resp.Products[1]
+
+	options, _ := bundle["bundle_options"].([]interface{})
+	if len(options) != 2 {
+		t.Fatalf("bundle_options = %v, want 2 options", bundle["bundle_options"])
+	}
+	selections := options[0].(map[string]interface{})["selections"].([]interface{})
+	if len(selections) != 2 || selections[0].(map[string]interface{})["sku"] != "B-SMALL" {
+		t.Errorf("bundle_options[0].selections = %v", selections)
+	}
+	priceRange := bundle["bundle_price_range"].(map[string]interface{})
+	if priceRange["minimum_price"].(float64) != 10 || priceRange["maximum_price"].(float64) != 25 {
+		t.Errorf("bundle_price_range = %v, want 10-25", priceRange)
+	}
+
+	groupedItems, _ := grouped["grouped_items"].([]interface{})
+	if len(groupedItems) != 2 || groupedItems[0].(map[string]interface{})["sku"] != "G-SECOND" {
+		t.Errorf("grouped_items = %v, want G-SECOND first", grouped["grouped_items"])
+	}
+}
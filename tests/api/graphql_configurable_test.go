@@ -0,0 +1,128 @@
+package apitest
+
+import (
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	graphqlApi "magento.GO/api/graphql"
+	entity "magento.GO/model/entity"
+	productEntity "magento.GO/model/entity/product"
+)
+
+// seedConfigurable creates CONF-1 (color: Black/Blue) with an enabled child per color
+// and a disabled Blue child.
+func seedConfigurable(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if err := db.AutoMigrate(
+		&entity.CatalogEavAttribute{},
+		&entity.EavAttributeOption{},
+		&entity.EavAttributeOptionValue{},
+		&entity.EavAttributeOptionSwatch{},
+		&productEntity.ProductSuperLink{},
+		&productEntity.ProductSuperAttribute{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	selectInput := "select"
+	colorLabel := "Color"
+	db.Create(&[]entity.EavAttribute{
+		{AttributeID: 93, EntityTypeID: 4, AttributeCode: "color", BackendType: "int", FrontendInput: &selectInput, FrontendLabel: &colorLabel},
+		{AttributeID: 97, EntityTypeID: 4, AttributeCode: "status", BackendType: "int"},
+	})
+	db.Create(&[]entity.EavAttributeOption{{OptionID: 49, AttributeID: 93, SortOrder: 1}, {OptionID: 50, AttributeID: 93, SortOrder: 2}})
+	db.Create(&[]entity.EavAttributeOptionValue{{OptionID: 49, Value: "Black"}, {OptionID: 50, Value: "Blue"}})
+	black := "#000000"
+	db.Create(&entity.EavAttributeOptionSwatch{OptionID: 49, Type: entity.SwatchTypeColor, Value: &black})
+
+	parent := productEntity.Product{AttributeSetID: 4, TypeID: "configurable", SKU: "CONF-1"}
+	db.Create(&parent)
+	db.Create(&productEntity.ProductSuperAttribute{ProductID: parent.EntityID, AttributeID: 93})
+	children := []struct {
+		sku    string
+		color  int
+		status int
+		price  float64
+	}{{"CONF-1-BLK", 49, 1, 30}, {"CONF-1-BLU", 50, 1, 35}, {"CONF-1-OFF", 50, 2, 99}}
+	for _, c := range children {
+		child := productEntity.Product{AttributeSetID: 4, TypeID: "simple", SKU: c.sku}
+		db.Create(&child)
+		db.Create(&[]productEntity.ProductInt{
+			{AttributeID: 93, EntityID: child.EntityID, Value: c.color},
+			{AttributeID: 97, EntityID: child.EntityID, Value: c.status},
+		})
+		db.Create(&productEntity.ProductIndexPrice{EntityID: child.EntityID, WebsiteID: 1, Price: c.price, FinalPrice: c.price})
+		db.Create(&productEntity.ProductSuperLink{ProductID: child.EntityID, ParentID: parent.EntityID})
+	}
+}
+
+func TestGraphQL_MagentoProducts_ConfigurableProduct(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedConfigurable(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data := execGraphQL(t, e, `{ magentoProducts(filter: { sku: { eq: "CONF-1" } }) { typed_items {
+		__typename sku
+		price_range { maximum_price { final_price { value } } }
+		... on ConfigurableProduct {
+			configurable_options { attribute_code label values { label value_index swatch_data { value } } }
+			variants { attributes { code label value_index } product { sku } }
+		}
+	} } }`)
+	items := data["magentoProducts"].(map[string]interface{})["typed_items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("items = %d, want 1", len(items))
+	}
+	p := items[0].(map[string]interface{})
+	if p["__typename"] != "ConfigurableProduct" {
+		t.Errorf("__typename = %v, want ConfigurableProduct", p["__typename"])
+	}
+	price := p["price_range"].(map[string]interface{})["maximum_price"].(map[string]interface{})["final_price"].(map[string]interface{})["value"]
+	if price.(float64) != 35 {
+		t.Errorf("parent max price = %v, want 35 (from variants)", price)
+	}
+
+	variants := p["variants"].([]interface{})
+	if len(variants) != 2 {
+		t.Fatalf("variants = %d, want 2 (disabled child skipped)", len(variants))
+	}
+	v0 := variants[0].(map[string]interface{})
+	if v0["product"].(map[string]interface{})["sku"] != "CONF-1-BLK" {
+		t.Errorf("variants[0].product = %v, want CONF-1-BLK", v0["product"])
+	}
+	attr := v0["attributes"].([]interface{})[0].(map[string]interface{})
+	if attr["code"] != "color" || attr["label"] != "Black" || attr["value_index"].(float64) != 49 {
+		t.Errorf("variants[0].attributes[0] = %v", attr)
+	}
+
+	opts := p["configurable_options"].([]interface{})
+	if len(opts) != 1 {
+		t.Fatalf("configurable_options = %d, want 1", len(opts))
+	}
+	opt := opts[0].(map[string]interface{})
+	if opt["attribute_code"] != "color" || opt["label"] != "Color" {
+		t.Errorf("configurable_options[0] = %v", opt)
+	}
+	values := opt["values"].([]interface{})
+	if len(values) != 2 {
+		t.Fatalf("values = %d, want 2", len(values))
+	}
+	first := values[0].(map[string]interface{})
+	if first["label"] != "Black" || first["swatch_data"].(map[string]interface{})["value"] != "#000000" {
+		t.Errorf("values[0] = %v, want Black with #000000 swatch", first)
+	}
+	if values[1].(map[string]interface{})["swatch_data"] != nil {
+		t.Errorf("values[1].swatch_data = %v, want null", values[1])
+	}
+
+	// items keeps its MagentoProduct type for existing clients.
+	data = execGraphQL(t, e, `{ magentoProducts(filter: { sku: { eq: "CONF-1" } }) { items { __typename sku } } }`)
+	items = data["magentoProducts"].(map[string]interface{})["items"].([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["__typename"] != "MagentoProduct" ||
+		items[0].(map[string]interface{})["sku"] != "CONF-1" {
+		t.Errorf("items = %v, want CONF-1 as MagentoProduct", items)
+	}
+}
@@ -0,0 +1,116 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	graphqlApi "magento.GO/api/graphql"
+	productApi "magento.GO/api/product"
+	entity "magento.GO/model/entity"
+	productEntity "magento.GO/model/entity/product"
+)
+
+// seedCurrencies configures store 1 with base USD, allowed USD/EUR/GBP and a USD→EUR rate only,
+// and creates CUR-1 priced 20. The database is pinned to one connection, as each :memory:
+// connection is its own database.
+func seedCurrencies(t *testing.T, db *gorm.DB) uint {
+	t.Helper()
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	seedDefaultStore(t, db)
+	if err := db.AutoMigrate(&entity.DirectoryCurrencyRate{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&[]entity.CoreConfigData{
+		{Scope: "default", Path: "currency/options/base", Value: strVal("USD")},
+		{Scope: "default", Path: "currency/options/default", Value: strVal("USD")},
+		{Scope: "default", Path: "currency/options/allow", Value: strVal("USD,EUR,GBP")},
+	})
+	db.Create(&entity.DirectoryCurrencyRate{CurrencyFrom: "USD", CurrencyTo: "EUR", Rate: 0.9})
+
+	p := productEntity.Product{AttributeSetID: 4, TypeID: "simple", SKU: "CUR-1"}
+	db.Create(&p)
+	db.Create(&productEntity.ProductIndexPrice{EntityID: p.EntityID, WebsiteID: 1, Price: 20, FinalPrice: 20})
+	return p.EntityID
+}
+
+func TestGraphQL_CurrencyAndDisplayCurrency(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedCurrencies(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	query := `{
+		currency { base_currency_code base_currency_symbol default_display_currency_code available_currency_codes exchange_rates { currency_to rate } }
+		magentoProducts(filter: { sku: { eq: "CUR-1" } }) { items { price_range { minimum_price { final_price { value currency } regular_price { currency } } } } }
+	}`
+	finalPrice := func(data map[string]interface{}) map[string]interface{} {
+		items := data["magentoProducts"].(map[string]interface{})["items"].([]interface{})
+		if len(items) != 1 {
+			t.Fatalf("items = %v, want CUR-1", items)
+		}
+		pr := items[0].(map[string]interface{})["price_range"].(map[string]interface{})
+		return pr["minimum_price"].(map[string]interface{})["final_price"].(map[string]interface{})
+	}
+
+	data := execGraphQL(t, e, query)
+	cur := data["currency"].(map[string]interface{})
+	if cur["base_currency_code"] != "USD" || cur["base_currency_symbol"] != "$" || cur["default_display_currency_code"] != "USD" {
+		t.Errorf("currency = %v", cur)
+	}
+	if codes := cur["available_currency_codes"].([]interface{}); len(codes) != 2 || codes[0] != "USD" || codes[1] != "EUR" {
+		t.Errorf("available_currency_codes = %v, want [USD EUR] (GBP has no rate)", codes)
+	}
+	rates := cur["exchange_rates"].([]interface{})
+	if len(rates) != 2 || rates[1].(map[string]interface{})["currency_to"] != "EUR" || rates[1].(map[string]interface{})["rate"].(float64) != 0.9 {
+		t.Errorf("exchange_rates = %v", rates)
+	}
+	if fp := finalPrice(data); fp["value"].(float64) != 20 || fp["currency"] != "USD" {
+		t.Errorf("base final_price = %v, want 20 USD", fp)
+	}
+
+	data = execGraphQLWithHeaders(t, e, query, map[string]string{"Content-Currency": "EUR"})
+	if fp := finalPrice(data); fp["value"].(float64) != 18 || fp["currency"] != "EUR" {
+		t.Errorf("EUR final_price = %v, want 18 EUR", fp)
+	}
+	data = execGraphQLWithHeaders(t, e, query, map[string]string{"Content-Currency": "GBP"})
+	if fp := finalPrice(data); fp["value"].(float64) != 20 || fp["currency"] != "USD" {
+		t.Errorf("GBP final_price = %v, want the USD fallback (no rate)", fp)
+	}
+}
+
+func TestProductAPI_FlatByIDs_DisplayCurrency(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	id := seedCurrencies(t, db)
+	productApi.RegisterProductRoutes(e.Group("/api"), db)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/products/flat/%d?currency=EUR", id), nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Products []map[string]interface{} `json:"products"`
+		Currency string                   `json:"currency"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Currency != "EUR" || len(resp.Products) != 1 {
+		t.Fatalf("response = %s", rec.Body.String())
+	}
+	indexPrices := resp.Products[0]["index_prices"].([]interface{})
+	if price := indexPrices[0].(map[string]interface{})["final_price"].(float64); price != 18 {
+		t.Errorf("index_prices[0].final_price = %v, want 18", price)
+	}
+}
@@ -0,0 +1,142 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	graphqlApi "magento.GO/api/graphql"
+	productApi "magento.GO/api/product"
+	productEntity "magento.GO/model/entity/product"
+)
+
+// seedCustomOptions creates MUG-1 (price 40) with an engraving field (fixed 5, store 1 title
+// "Gravur"), a size drop-down with S (fixed 0) and XL (10 percent), and a delivery date_time.
+func seedCustomOptions(t *testing.T, db *gorm.DB) uint {
+	t.Helper()
+	if err := db.AutoMigrate(
+		&productEntity.ProductOption{},
+		&productEntity.ProductOptionTitle{},
+		&productEntity.ProductOptionPrice{},
+		&productEntity.ProductOptionTypeValue{},
+		&productEntity.ProductOptionTypeTitle{},
+		&productEntity.ProductOptionTypePrice{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	mug := productEntity.Product{AttributeSetID: 4, TypeID: "simple", SKU: "MUG-1", HasOptions: 1, RequiredOptions: 1}
+	db.Create(&mug)
+	db.Create(&productEntity.ProductIndexPrice{EntityID: mug.EntityID, WebsiteID: 1, Price: 40, FinalPrice: 40})
+
+	maxChars := uint(20)
+	engraving := productEntity.ProductOption{ProductID: mug.EntityID, Type: "field", MaxCharacters: &maxChars, SortOrder: 2}
+	size := productEntity.ProductOption{ProductID: mug.EntityID, Type: "drop_down", IsRequire: 1, SortOrder: 1}
+	delivery := productEntity.ProductOption{ProductID: mug.EntityID, Type: "date_time", SortOrder: 3}
+	db.Create(&engraving)
+	db.Create(&size)
+	db.Create(&delivery)
+	db.Create(&[]productEntity.ProductOptionTitle{
+		{OptionID: engraving.OptionID, StoreID: 0, Title: "Engraving"},
+		{OptionID: engraving.OptionID, StoreID: 1, Title: "Gravur"},
+		{OptionID: size.OptionID, StoreID: 0, Title: "Size"},
+		{OptionID: delivery.OptionID, StoreID: 0, Title: "Delivery"},
+	})
+	db.Create(&[]productEntity.ProductOptionPrice{
+		{OptionID: engraving.OptionID, Price: 5, PriceType: "fixed"},
+		{OptionID: delivery.OptionID, Price: 0, PriceType: "fixed"},
+	})
+	for i, v := range []struct {
+		title, priceType string
+		price            float64
+	}{{"S", "fixed", 0}, {"XL", "percent", 10}} {
+		value := productEntity.ProductOptionTypeValue{OptionID: size.OptionID, SortOrder: uint(i + 1)}
+		db.Create(&value)
+		db.Create(&productEntity.ProductOptionTypeTitle{OptionTypeID: value.OptionTypeID, Title: v.title})
+		db.Create(&productEntity.ProductOptionTypePrice{OptionTypeID: value.OptionTypeID, Price: v.price, PriceType: v.priceType})
+	}
+	return mug.EntityID
+}
+
+func TestGraphQL_MagentoProducts_CustomOptions(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedCustomOptions(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data := execGraphQL(t, e, `{ magentoProducts(filter: { sku: { eq: "MUG-1" } }) { items { options {
+		__typename title required sort_order uid
+		... on CustomizableFieldOption { product_sku field_value: value { price price_type max_characters } }
+		... on CustomizableDropDownOption { values: value { title price price_type uid } }
+		... on CustomizableDateOption { date_value: value { type price } }
+	} } } }`)
+	items := data["magentoProducts"].(map[string]interface{})["items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("items = %v, want MUG-1", items)
+	}
+	options := items[0].(map[string]interface{})["options"].([]interface{})
+	if len(options) != 3 {
+		t.Fatalf("options = %v, want 3", options)
+	}
+	size, engraving, delivery := options[0].(map[string]interface{}), options[1].(map[string]interface{}), options[2].(map[string]interface{})
+
+	if size["__typename"] != "CustomizableDropDownOption" || size["title"] != "Size" || size["required"] != true {
+		t.Errorf("options[0] = %v, want the required Size drop-down", size)
+	}
+	values := size["values"].([]interface{})
+	xl := values[1].(map[string]interface{})
+	if len(values) != 2 || xl["title"] != "XL" || xl["price"] != 4.0 || xl["price_type"] != "PERCENT" {
+		t.Errorf("size values = %v, want XL at 10%% of 40 = 4", values)
+	}
+	if engraving["__typename"] != "CustomizableFieldOption" || engraving["title"] != "Engraving" || engraving["product_sku"] != "MUG-1" {
+		t.Errorf("options[1] = %v, want the Engraving field", engraving)
+	}
+	if v := engraving["field_value"].(map[string]interface{}); v["price"] != 5.0 || v["price_type"] != "FIXED" || v["max_characters"] != 20.0 {
+		t.Errorf("engraving value = %v, want fixed 5 with 20 characters", v)
+	}
+	if delivery["__typename"] != "CustomizableDateOption" || delivery["date_value"].(map[string]interface{})["type"] != "DATE_TIME" {
+		t.Errorf("options[2] = %v, want a DATE_TIME option", delivery)
+	}
+}
+
+func TestProductAPI_FlatByIDs_CustomOptions(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	mugID := seedCustomOptions(t, db)
+	productApi.RegisterProductRoutes(e.Group("/api"), db)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/products/flat/%d", mugID), nil)
+	req.Header.Set("Store", "1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Products []map[string]interface{} `json:"products"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Products) != 1 {
+		t.Fatalf("products = %d, want 1", len(resp.Products))
+	}
+	options, _ := resp.Products[0]["options"].([]interface{})
+	if len(options) != 3 {
+		t.Fatalf("options = %v, want 3", resp.Products[0]["options"])
+	}
+	engraving := options[1].(map[string]interface{})
+	if engraving["title"] != "Gravur" || engraving["price"] != 5.0 || engraving["price_type"] != "fixed" {
+		t.Errorf("engraving = %v, want the store 1 title and fixed price 5", engraving)
+	}
+	xl := options[0].(map[string]interface{})["values"].([]interface{})[1].(map[string]interface{})
+	if xl["price"] != 4.0 || xl["price_value"] != 10.0 || xl["price_type"] != "percent" {
+		t.Errorf("XL value = %v, want 10 percent resolved to 4", xl)
+	}
+}
@@ -0,0 +1,159 @@
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	graphqlApi "magento.GO/api/graphql"
+	gqlmodels "magento.GO/graphql/models"
+	gqlregistry "magento.GO/graphql/registry"
+)
+
+// loyaltyResolver is a typed schema extension, registered like a custom package would.
+type loyaltyResolver struct {
+	points int32
+}
+
+type loyaltyAccount struct {
+	Points  int32
+	Tier    string
+	Balance *gqlmodels.Money
+	Stars   int32 // resolves the field the stars extension adds to LoyaltyAccount
+}
+
+func (r *loyaltyResolver) LoyaltyAccount(args struct{ Email string }) *loyaltyAccount {
+	return &loyaltyAccount{Points: r.points, Tier: "GOLD", Balance: &gqlmodels.Money{Currency: "USD", Value: float64(r.points) / 100}, Stars: r.points / 100}
+}
+
+func (r *loyaltyResolver) AddLoyaltyPoints(ctx context.Context, args struct{ Points int32 }) *loyaltyAccount {
+	r.points += args.Points
+	return r.LoyaltyAccount(struct{ Email string }{})
+}
+
+func init() {
+	gqlregistry.RegisterSchema(gqlregistry.SchemaExtension{
+		Name: "loyalty",
+		Types: `
+enum LoyaltyTier { SILVER GOLD }
+
+"A customer's loyalty points."
+type LoyaltyAccount {
+  points: Int!
+  tier: LoyaltyTier!
+  balance: Money!
+}`,
+		Query:    `loyaltyAccount(email: String!): LoyaltyAccount`,
+		Mutation: `addLoyaltyPoints(points: Int!): LoyaltyAccount!`,
+		Resolver: func(db interface{}) interface{} {
+			return &loyaltyResolver{points: 250}
+		},
+	})
+	// Extends types of the loyalty extension and, through Types, Query.
+	gqlregistry.RegisterSchema(gqlregistry.SchemaExtension{
+		Name: "loyaltyStars",
+		Types: `
+extend enum LoyaltyTier { PLATINUM }
+
+extend type LoyaltyAccount { stars: Int! }
+
+extend type Query { topLoyaltyTier: LoyaltyTier! }`,
+		Resolver: func(db interface{}) interface{} {
+			return &loyaltyStarsResolver{}
+		},
+	})
+}
+
+type loyaltyStarsResolver struct{}
+
+func (r *loyaltyStarsResolver) TopLoyaltyTier() string { return "PLATINUM" }
+
+func postGraphQL(t *testing.T, e *echo.Echo, body map[string]interface{}) (data map[string]json.RawMessage, errs []string, raw string) {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	var resp struct {
+		Data   map[string]json.RawMessage
+		Errors []struct{ Message string }
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	for _, e := range resp.Errors {
+		errs = append(errs, e.Message)
+	}
+	return resp.Data, errs, rec.Body.String()
+}
+
+func TestGraphQL_SchemaExtension_TypedFields(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	seedCartProducts(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	// Extension and core fields in one operation, with variables and fragments of both.
+	query := `query Mixed($sku: String, $email: String!) {
+  first: products(filter: { sku: { eq: $sku } }) { total_count }
+  account: loyaltyAccount(email: $email) { ...Account }
+  customPing { pong }
+  ...Both
+}
+fragment Account on LoyaltyAccount { points tier stars balance { currency value } }
+fragment Both on Query { topLoyaltyTier last: products(filter: { sku: { eq: $sku } }) { total_count } }`
+	data, errs, raw := postGraphQL(t, e, map[string]interface{}{
+		"query":     query,
+		"variables": map[string]interface{}{"sku": "TEE-1", "email": "a@example.com"},
+	})
+	if len(errs) > 0 {
+		t.Fatalf("errors: %v", errs)
+	}
+	if got := string(data["account"]); got != `{"points":250,"tier":"GOLD","stars":2,"balance":{"currency":"USD","value":2.5}}` {
+		t.Errorf("account = %s", got)
+	}
+	if got := string(data["customPing"]); got != `{"pong":"ok"}` {
+		t.Errorf("customPing = %s", got)
+	}
+	if got := string(data["topLoyaltyTier"]); got != `"PLATINUM"` {
+		t.Errorf("topLoyaltyTier = %s", got)
+	}
+	if string(data["first"]) != `{"total_count":1}` || string(data["last"]) != `{"total_count":1}` {
+		t.Errorf("products = %s", raw)
+	}
+	// Fields keep the order of the selection.
+	if strings.Index(raw, `"first"`) > strings.Index(raw, `"account"`) || strings.Index(raw, `"customPing"`) > strings.Index(raw, `"last"`) {
+		t.Errorf("field order: %s", raw)
+	}
+
+	data, errs, _ = postGraphQL(t, e, map[string]interface{}{"query": `mutation { addLoyaltyPoints(points: 50) { points } }`})
+	if len(errs) > 0 || string(data["addLoyaltyPoints"]) != `{"points":300}` {
+		t.Errorf("addLoyaltyPoints = %s %v, want 300 points", data["addLoyaltyPoints"], errs)
+	}
+
+	// Extension types are validated and introspected with the core schema.
+	_, errs, _ = postGraphQL(t, e, map[string]interface{}{"query": `{ loyaltyAccount(email: "a") { rank } }`})
+	if len(errs) != 1 || !strings.Contains(errs[0], `"rank"`) {
+		t.Errorf("unknown field errors = %v", errs)
+	}
+	data, errs, _ = postGraphQL(t, e, map[string]interface{}{"query": `{ __type(name: "LoyaltyAccount") { fields { name } } q: __type(name: "Query") { fields { name } } }`})
+	if len(errs) > 0 || !strings.Contains(string(data["__type"]), `"tier"`) || !strings.Contains(string(data["q"]), `"loyaltyAccount"`) {
+		t.Errorf("introspection = %s %v", data, errs)
+	}
+
+	// A mutation mixing extension and core fields runs serially, in order.
+	data, errs, _ = postGraphQL(t, e, map[string]interface{}{"query": `mutation { a: addLoyaltyPoints(points: 100) { points } createEmptyCart b: addLoyaltyPoints(points: 100) { points stars } }`})
+	if len(errs) > 0 || string(data["a"]) != `{"points":400}` || string(data["b"]) != `{"points":500,"stars":5}` || len(data["createEmptyCart"]) < 3 {
+		t.Errorf("mixed mutation = %s %v", data, errs)
+	}
+}
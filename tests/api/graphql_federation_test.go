@@ -0,0 +1,63 @@
+package apitest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	graphqlApi "magento.GO/api/graphql"
+)
+
+func TestGraphQL_Federation_ServiceAndEntities(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	t.Setenv("GRAPHQL_FEDERATION", "true")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedCartProducts(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data, errs, _ := postGraphQL(t, e, map[string]interface{}{"query": `{ _service { sdl } }`})
+	if len(errs) > 0 {
+		t.Fatalf("_service errors: %v", errs)
+	}
+	sdl := string(data["_service"])
+	for _, want := range []string{`@link(url: \"https://specs.apollo.dev/federation/v2.3\"`, `type Product @key(fields: \"sku\")`, `type Category @key(fields: \"entity_id\")`} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("sdl does not contain %s", want)
+		}
+	}
+	if strings.Contains(sdl, "_entities(") || strings.Contains(sdl, "_Any") {
+		t.Errorf("sdl contains the federation fields")
+	}
+
+	data, errs, raw := postGraphQL(t, e, map[string]interface{}{
+		"query": `query($r: [_Any!]!) { _entities(representations: $r) { __typename ... on Product { sku price } ... on Category { entity_id name } } }`,
+		"variables": map[string]interface{}{"r": []interface{}{
+			map[string]interface{}{"__typename": "Product", "sku": "EBOOK-1"},
+			map[string]interface{}{"__typename": "Product", "sku": "MISSING"},
+			map[string]interface{}{"__typename": "Product", "entity_id": "1"},
+		}},
+	})
+	if len(errs) > 0 {
+		t.Fatalf("_entities errors: %v", errs)
+	}
+	want := `[{"__typename":"Product","sku":"EBOOK-1","price":9.5},null,{"__typename":"Product","sku":"TEE-1","price":20}]`
+	if got := string(data["_entities"]); got != want {
+		t.Errorf("_entities = %s, want %s (%s)", got, want, raw)
+	}
+
+	_, errs, _ = postGraphQL(t, e, map[string]interface{}{"query": `{ _entities(representations: [{__typename: "Review", id: "1"}]) { __typename } }`})
+	if len(errs) != 1 || !strings.Contains(errs[0], `unknown entity type "Review"`) {
+		t.Errorf("unknown type errors = %v", errs)
+	}
+}
+
+func TestGraphQL_Federation_DisabledByDefault(t *testing.T) {
+	e := echo.New()
+	graphqlApi.RegisterGraphQLRoutes(e, graphqlProductTestDB(t))
+	_, errs, _ := postGraphQL(t, e, map[string]interface{}{"query": `{ _service { sdl } }`})
+	if len(errs) == 0 {
+		t.Fatal("_service resolved without GRAPHQL_FEDERATION")
+	}
+}
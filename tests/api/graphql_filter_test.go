@@ -0,0 +1,130 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	graphqlApi "magento.GO/api/graphql"
+	productEntity "magento.GO/model/entity/product"
+)
+
+// seedFilterProducts creates simple products with guest index prices.
+func seedFilterProducts(t *testing.T, db *gorm.DB, prices map[string]float64) {
+	t.Helper()
+	for sku, price := range prices {
+		p := productEntity.Product{AttributeSetID: 4, TypeID: "simple", SKU: sku}
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+		ip := productEntity.ProductIndexPrice{EntityID: p.EntityID, CustomerGroupID: 0, WebsiteID: 1, Price: price, FinalPrice: price}
+		if err := db.Create(&ip).Error; err != nil {
+			t.Fatalf("create index price: %v", err)
+		}
+	}
+}
+
+func execGraphQL(t *testing.T, e *echo.Echo, query string) map[string]interface{} {
+	t.Helper()
+	return execGraphQLWithHeaders(t, e, query, nil)
+}
+
+func execGraphQLWithHeaders(t *testing.T, e *echo.Echo, query string, headers map[string]string) map[string]interface{} {
+	t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"query": query})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("graphql status = %d, want 200", rec.Code)
+	}
+	var resp struct {
+		Data   map[string]interface{}
+		Errors []struct{ Message string }
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("graphql errors: %v", resp.Errors)
+	}
+	return resp.Data
+}
+
+func itemSKUs(list map[string]interface{}) []string {
+	var skus []string
+	for _, it := range list["items"].([]interface{}) {
+		skus = append(skus, it.(map[string]interface{})["sku"].(string))
+	}
+	return skus
+}
+
+func TestGraphQL_MagentoProducts_FilterAndSort(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+	seedFilterProducts(t, db, map[string]float64{"F-CHEAP": 5, "F-MID": 25, "F-HIGH": 45, "F-LUX": 500})
+
+	data := execGraphQL(t, e, `{ magentoProducts(filter: { price: { from: "10", to: "100" } }, sort: { price: DESC }) { total_count items { sku } } }`)
+	list := data["magentoProducts"].(map[string]interface{})
+	if int(list["total_count"].(float64)) != 2 {
+		t.Errorf("total_count = %v, want 2", list["total_count"])
+	}
+	if got := itemSKUs(list); len(got) != 2 || got[0] != "F-HIGH" || got[1] != "F-MID" {
+		t.Errorf("skus = %v, want [F-HIGH F-MID]", got)
+	}
+
+	data = execGraphQL(t, e, `{ magentoProducts(filter: { sku: { in: ["F-LUX", "F-CHEAP"] } }, sort: { price: ASC }) { items { sku } } }`)
+	if got := itemSKUs(data["magentoProducts"].(map[string]interface{})); len(got) != 2 || got[0] != "F-CHEAP" || got[1] != "F-LUX" {
+		t.Errorf("sku in = %v, want [F-CHEAP F-LUX]", got)
+	}
+
+	data = execGraphQL(t, e, `{ products(filter: { sku: { eq: "F-MID" } }) { total_count items { sku } } }`)
+	if got := itemSKUs(data["products"].(map[string]interface{})); len(got) != 1 || got[0] != "F-MID" {
+		t.Errorf("products sku eq = %v, want [F-MID]", got)
+	}
+}
+
+func TestGraphQL_MagentoProducts_PriceAggregation(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+	seedFilterProducts(t, db, map[string]float64{"A-1": 5, "A-2": 25, "A-3": 27, "A-4": 45})
+
+	data := execGraphQL(t, e, `{ magentoProducts(pageSize: 1) { items { sku } aggregations { attribute_code label count options { label value count } } } }`)
+	aggs := data["magentoProducts"].(map[string]interface{})["aggregations"].([]interface{})
+	var price map[string]interface{}
+	for _, a := range aggs {
+		if am := a.(map[string]interface{}); am["attribute_code"] == "price" {
+			price = am
+		}
+	}
+	if price == nil {
+		t.Fatalf("price aggregation missing: %v", aggs)
+	}
+	want := []struct {
+		value string
+		count float64
+	}{{"0_10", 1}, {"20_30", 2}, {"40_50", 1}}
+	opts := price["options"].([]interface{})
+	if len(opts) != len(want) {
+		t.Fatalf("price options = %v, want %d buckets", opts, len(want))
+	}
+	for i, w := range want {
+		o := opts[i].(map[string]interface{})
+		if o["value"] != w.value || o["count"].(float64) != w.count {
+			t.Errorf("bucket %d = %v, want %s (%v)", i, o, w.value, w.count)
+		}
+	}
+}
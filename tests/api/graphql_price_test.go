@@ -0,0 +1,121 @@
+package apitest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	graphqlApi "magento.GO/api/graphql"
+	entity "magento.GO/model/entity"
+	priceEntity "magento.GO/model/entity/price"
+	productEntity "magento.GO/model/entity/product"
+	priceRepo "magento.GO/model/repository/price"
+)
+
+func TestGraphQL_TierAndSpecialPrices(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	seedCartProducts(t, db)
+	var tee, ebook productEntity.Product
+	db.First(&tee, "sku = ?", "TEE-1")
+	db.First(&ebook, "sku = ?", "EBOOK-1")
+
+	db.Create(&[]entity.EavAttribute{
+		{AttributeID: 78, EntityTypeID: 4, AttributeCode: "special_price", BackendType: "decimal"},
+		{AttributeID: 79, EntityTypeID: 4, AttributeCode: "special_from_date", BackendType: "datetime"},
+		{AttributeID: 80, EntityTypeID: 4, AttributeCode: "special_to_date", BackendType: "datetime"},
+	})
+	today := time.Now()
+	// EBOOK-1 is on special today; the special of TEE-1 ended three days ago.
+	db.Create(&[]productEntity.ProductDecimal{
+		{AttributeID: 78, EntityID: ebook.EntityID, Value: 7.6},
+		{AttributeID: 78, EntityID: tee.EntityID, Value: 15},
+	})
+	db.Create(&[]productEntity.ProductDatetime{
+		{AttributeID: 79, EntityID: ebook.EntityID, Value: today.AddDate(0, 0, -1)},
+		{AttributeID: 80, EntityID: ebook.EntityID, Value: today},
+		{AttributeID: 80, EntityID: tee.EntityID, Value: today.AddDate(0, 0, -3)},
+	})
+
+	db.Create(&[]priceEntity.TierPrice{
+		{EntityID: tee.EntityID, AllGroups: 1, Qty: 3, Value: 19, WebsiteID: 0},
+		{EntityID: tee.EntityID, AllGroups: 1, Qty: 5, Value: 17, WebsiteID: 0},
+		{EntityID: tee.EntityID, AllGroups: 1, Qty: 5, Value: 16, WebsiteID: 1},
+		{EntityID: tee.EntityID, CustomerGroupID: 0, Qty: 10, PercentageValue: 25, WebsiteID: 1},
+		{EntityID: tee.EntityID, CustomerGroupID: 2, Qty: 10, Value: 12, WebsiteID: 0},
+		{EntityID: tee.EntityID, AllGroups: 1, Qty: 20, Value: 10, WebsiteID: 2},
+		{EntityID: tee.EntityID, AllGroups: 1, Qty: 1, Value: 11, WebsiteID: 2},
+		{EntityID: ebook.EntityID, CustomerGroupID: 1, Qty: 1, PercentageValue: 50, WebsiteID: 1},
+	})
+	// all_groups defaults to 1, so the zero value is not inserted.
+	db.Model(&priceEntity.TierPrice{}).Where("qty = ? OR customer_group_id = ?", 10, 1).Update("all_groups", 0)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	fields := `items { special_price
+		price_range { minimum_price { regular_price { value } final_price { value } discount { amount_off percent_off } } }
+		price_tiers { quantity final_price { value } discount { amount_off percent_off } } }`
+	data, errs, raw := postGraphQL(t, e, map[string]interface{}{"query": `{
+		tee: magentoProducts(filter: { sku: { eq: "TEE-1" } }) { ` + fields + ` }
+		ebook: magentoProducts(filter: { sku: { eq: "EBOOK-1" } }) { ` + fields + ` }
+	}`})
+	if len(errs) > 0 {
+		t.Fatalf("errors: %v", errs)
+	}
+
+	// Qty 1 (all websites) and qty 5 and 10 of website 1 for guests; qty 3 is not cheaper than qty 1.
+	wantTee := `{"items":[{"special_price":null,` +
+		`"price_range":{"minimum_price":{"regular_price":{"value":20},"final_price":{"value":20},"discount":null}},` +
+		`"price_tiers":[{"quantity":1,"final_price":{"value":18},"discount":{"amount_off":2,"percent_off":10}},` +
+		`{"quantity":5,"final_price":{"value":16},"discount":{"amount_off":4,"percent_off":20}},` +
+		`{"quantity":10,"final_price":{"value":15},"discount":{"amount_off":5,"percent_off":25}}]}]}`
+	if got := string(data["tee"]); got != wantTee {
+		t.Errorf("TEE-1 = %s, want %s", got, wantTee)
+	}
+	ebookJSON := string(data["ebook"])
+	for _, want := range []string{`"special_price":7.6`, `"final_price":{"value":7.6}`, `"percent_off":20`, `"price_tiers":[]`} {
+		if !strings.Contains(ebookJSON, want) {
+			t.Errorf("EBOOK-1 = %s, want %s (%s)", ebookJSON, want, raw)
+		}
+	}
+
+	// The cart prices a guest's items like the products query: TEE-1 at its qty 1 tier price, not
+	// the expired special; EBOOK-1 at its special price.
+	cartID, _ := execGraphQL(t, e, `mutation { createEmptyCart }`)["createEmptyCart"].(string)
+	cart := execGraphQL(t, e, `mutation { addProductsToCart(cartId: "`+cartID+`", cartItems: [
+		{ sku: "TEE-1", quantity: 1 },
+		{ sku: "EBOOK-1", quantity: 1 }
+	]) { cart { `+cartFields+` } } }`)
+	items := cartItemsBySKU(cart["addProductsToCart"].(map[string]interface{})["cart"].(map[string]interface{}))
+	for sku, want := range map[string]float64{"TEE-1": 18, "EBOOK-1": 7.6} {
+		if item := items[sku]; item == nil || item["prices"].(map[string]interface{})["price"].(map[string]interface{})["value"] != want {
+			t.Errorf("cart %s = %v, want price %v", sku, item, want)
+		}
+	}
+
+	// Tier prices of other websites and customer groups do not apply; percentage tiers are a
+	// discount off the price.
+	repo, err := priceRepo.GetPriceRepository(db)
+	if err != nil {
+		t.Fatalf("price repository: %v", err)
+	}
+	for _, tt := range []struct {
+		websiteID uint16
+		groupID   int
+		want      map[string]float64
+	}{
+		{1, 0, map[string]float64{"TEE-1": 18, "EBOOK-1": 7.6}},
+		{1, 1, map[string]float64{"TEE-1": 18, "EBOOK-1": 4.75}},
+		{2, 0, map[string]float64{"TEE-1": 11, "EBOOK-1": 7.6}},
+	} {
+		got, err := repo.GetLowestPricesBySKUs([]string{"TEE-1", "EBOOK-1"}, tt.websiteID, tt.groupID)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lowest prices of website %d, group %d = %v (%v), want %v", tt.websiteID, tt.groupID, got, err, tt.want)
+		}
+	}
+}
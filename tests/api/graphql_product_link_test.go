@@ -0,0 +1,90 @@
+package apitest
+
+import (
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	graphqlApi "magento.GO/api/graphql"
+	entity "magento.GO/model/entity"
+	productEntity "magento.GO/model/entity/product"
+)
+
+func TestGraphQL_RelatedUpsellCrosssellProducts(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	if err := db.AutoMigrate(&productEntity.ProductLink{}, &productEntity.ProductLinkAttribute{}, &productEntity.ProductLinkAttributeInt{}, &productEntity.ProductLinkAttributeDecimal{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&entity.EavAttribute{AttributeID: 97, EntityTypeID: 4, AttributeCode: "status", BackendType: "int"})
+	product := func(sku string, status int, inStock uint16) uint {
+		p := productEntity.Product{AttributeSetID: 4, TypeID: "simple", SKU: sku}
+		db.Create(&p)
+		db.Create(&productEntity.ProductInt{AttributeID: 97, EntityID: p.EntityID, Value: status})
+		db.Create(&productEntity.StockItem{ProductID: p.EntityID, StockID: 1, Qty: 10, IsInStock: inStock})
+		db.Create(&productEntity.ProductIndexPrice{EntityID: p.EntityID, WebsiteID: 1, Price: 10, FinalPrice: 10})
+		return p.EntityID
+	}
+	main, other := product("MAIN", 1, 1), product("OTHER", 1, 1)
+	relA, relB := product("REL-A", 1, 1), product("REL-B", 1, 1)
+	disabled, outOfStock := product("REL-OFF", 2, 1), product("REL-OOS", 1, 0)
+	upsell, crosssell := product("UP-1", 1, 1), product("CROSS-1", 1, 1)
+
+	position := productEntity.ProductLinkAttribute{LinkTypeID: 1, ProductLinkAttributeCode: "position", DataType: "int"}
+	db.Create(&position)
+	link := func(from, to uint, linkType uint16, pos int) {
+		l := productEntity.ProductLink{ProductID: from, LinkedProductID: to, LinkTypeID: linkType}
+		db.Create(&l)
+		if linkType == 1 {
+			db.Create(&productEntity.ProductLinkAttributeInt{ProductLinkAttributeID: position.ProductLinkAttributeID, LinkID: l.LinkID, Value: pos})
+		}
+	}
+	link(main, relA, 1, 3)
+	link(main, disabled, 1, 1)
+	link(main, relB, 1, 2)
+	link(main, outOfStock, 1, 0)
+	link(main, upsell, 4, 0)
+	link(main, crosssell, 5, 0)
+	link(other, relA, 1, 0)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data := execGraphQL(t, e, `{ magentoProducts(filter: { sku: { in: ["MAIN", "OTHER"] } }, sort: { name: ASC }) { items {
+		sku
+		related_products { sku stock_status }
+		upsell_products { sku }
+		crosssell_products { sku }
+	} } }`)
+	items := data["magentoProducts"].(map[string]interface{})["items"].([]interface{})
+	skus := func(v interface{}) []string {
+		var out []string
+		for _, p := range v.([]interface{}) {
+			out = append(out, p.(map[string]interface{})["sku"].(string))
+		}
+		return out
+	}
+	bySKU := map[string]map[string]interface{}{}
+	for _, it := range items {
+		p := it.(map[string]interface{})
+		bySKU[p["sku"].(string)] = p
+	}
+	got := bySKU["MAIN"]
+	if got == nil {
+		t.Fatalf("MAIN missing from %v", items)
+	}
+	if rel := skus(got["related_products"]); len(rel) != 2 || rel[0] != "REL-B" || rel[1] != "REL-A" {
+		t.Errorf("related_products = %v, want [REL-B REL-A] (by position, disabled and out-of-stock left out)", rel)
+	}
+	if up := skus(got["upsell_products"]); len(up) != 1 || up[0] != "UP-1" {
+		t.Errorf("upsell_products = %v, want [UP-1]", up)
+	}
+	if cross := skus(got["crosssell_products"]); len(cross) != 1 || cross[0] != "CROSS-1" {
+		t.Errorf("crosssell_products = %v, want [CROSS-1]", cross)
+	}
+	if rel := skus(bySKU["OTHER"]["related_products"]); len(rel) != 1 || rel[0] != "REL-A" {
+		t.Errorf("OTHER related_products = %v, want [REL-A]", rel)
+	}
+	if up := bySKU["OTHER"]["upsell_products"].([]interface{}); len(up) != 0 {
+		t.Errorf("OTHER upsell_products = %v, want empty", up)
+	}
+}
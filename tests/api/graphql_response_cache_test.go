@@ -0,0 +1,203 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	graphqlApi "magento.GO/api/graphql"
+	"magento.GO/core/events"
+	"magento.GO/graphql"
+	"magento.GO/model/entity"
+	categoryEntity "magento.GO/model/entity/category"
+	productEntity "magento.GO/model/entity/product"
+	categoryRepo "magento.GO/model/repository/category"
+	productService "magento.GO/service/product"
+)
+
+func serveGraphQLRaw(e *echo.Echo, method, query string, headers map[string]string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if method == http.MethodGet {
+		req = httptest.NewRequest(http.MethodGet, "/graphql?"+url.Values{"query": {query}}.Encode(), nil)
+	} else {
+		body, _ := json.Marshal(map[string]interface{}{"query": query})
+		req = httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGraphQL_ResponseCache(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	t.Setenv("GRAPHQL_RESPONSE_CACHE_TTL", "60")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedCartProducts(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+	graphql.InvalidateCacheTags(graphql.ProductListTag) // entries of earlier runs in this process
+
+	var tee productEntity.Product
+	db.Where("sku = ?", "TEE-1").First(&tee)
+	query := `{ magentoProducts(pageSize: 10) { items { sku } } }`
+
+	rec := serveGraphQLRaw(e, http.MethodPost, query, nil)
+	tags := strings.Split(rec.Header().Get("X-Magento-Tags"), ",")
+	if rec.Header().Get("X-Magento-Cache-Debug") != "MISS" || len(tags) != 3 || tags[0] != "cat_p" || !strings.Contains(rec.Header().Get("X-Magento-Tags"), graphql.ProductTag(tee.EntityID)) {
+		t.Fatalf("first response: cache %q, tags %v; want MISS and cat_p plus both products", rec.Header().Get("X-Magento-Cache-Debug"), tags)
+	}
+	cacheID := rec.Header().Get("X-Magento-Cache-Id")
+	if len(cacheID) != 64 {
+		t.Errorf("X-Magento-Cache-Id = %q, want a sha256", cacheID)
+	}
+	if vary := rec.Header().Get("Vary"); !strings.Contains(vary, "Store") || !strings.Contains(vary, "Content-Currency") || !strings.Contains(vary, "Authorization") {
+		t.Errorf("Vary = %q, want Store, Content-Currency and Authorization", vary)
+	}
+	first := rec.Body.String()
+
+	db.Model(&productEntity.Product{}).Where("entity_id = ?", tee.EntityID).Update("sku", "TEE-2")
+	rec = serveGraphQLRaw(e, http.MethodPost, query, nil)
+	if rec.Header().Get("X-Magento-Cache-Debug") != "HIT" || rec.Body.String() != first || rec.Header().Get("X-Magento-Cache-Id") != cacheID {
+		t.Errorf("second response: cache %q, body %s; want the cached body", rec.Header().Get("X-Magento-Cache-Debug"), rec.Body.String())
+	}
+
+	// Another store view is another variant.
+	rec = serveGraphQLRaw(e, http.MethodPost, query, map[string]string{"Store": "1"})
+	if rec.Header().Get("X-Magento-Cache-Debug") != "MISS" || rec.Header().Get("X-Magento-Cache-Id") == cacheID {
+		t.Errorf("store 1: cache %q, id %q; want MISS and another cache ID", rec.Header().Get("X-Magento-Cache-Debug"), rec.Header().Get("X-Magento-Cache-Id"))
+	}
+
+	graphql.InvalidateCacheTags(graphql.ProductTag(tee.EntityID))
+	rec = serveGraphQLRaw(e, http.MethodPost, query, nil)
+	if rec.Header().Get("X-Magento-Cache-Debug") != "MISS" || !strings.Contains(rec.Body.String(), "TEE-2") {
+		t.Errorf("after invalidation: cache %q, body %s; want a fresh response", rec.Header().Get("X-Magento-Cache-Debug"), rec.Body.String())
+	}
+
+	rec = serveGraphQLRaw(e, http.MethodGet, query, nil)
+	if rec.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Errorf("GET Cache-Control = %q, want public, max-age=60", rec.Header().Get("Cache-Control"))
+	}
+}
+
+func TestGraphQL_ResponseCache_PurgedOnStockImport(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	t.Setenv("GRAPHQL_RESPONSE_CACHE_TTL", "60")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	seedCartProducts(t, db)
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_unq ON cataloginventory_stock_item (product_id, stock_id)")
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+	graphql.InvalidateCacheTags(graphql.ProductListTag) // entries of earlier runs in this process
+
+	query := `{ product: magentoProducts(filter: { sku: { eq: "TEE-1" } }) { items { sku stock_status } } }`
+	if rec := serveGraphQLRaw(e, http.MethodPost, query, nil); rec.Header().Get("X-Magento-Cache-Debug") != "MISS" {
+		t.Fatalf("first response: cache %q, want MISS", rec.Header().Get("X-Magento-Cache-Debug"))
+	}
+	if rec := serveGraphQLRaw(e, http.MethodPost, query, nil); rec.Header().Get("X-Magento-Cache-Debug") != "HIT" {
+		t.Fatalf("second response: cache %q, want HIT", rec.Header().Get("X-Magento-Cache-Debug"))
+	}
+
+	outOfStock, zero := uint16(0), 0.0
+	if _, err := productService.ImportStockJSON(db, []productService.StockItemInput{{SKU: "TEE-1", Qty: &zero, IsInStock: &outOfStock}}, 100); err != nil {
+		t.Fatalf("import stock: %v", err)
+	}
+	// the import publishes TEE-1 on the stock topic; the purge runs in the background
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		rec := serveGraphQLRaw(e, http.MethodPost, query, nil)
+		if rec.Header().Get("X-Magento-Cache-Debug") == "MISS" {
+			if !strings.Contains(rec.Body.String(), "OUT_OF_STOCK") {
+				t.Errorf("after the import: body %s, want TEE-1 out of stock", rec.Body.String())
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("cached response was not purged after the stock import")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGraphQL_ResponseCache_PurgedOnCategoryChange(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	t.Setenv("GRAPHQL_RESPONSE_CACHE_TTL", "60")
+	categoryRepo.InvalidateCategoryAttributeMetaCache()
+	t.Cleanup(categoryRepo.InvalidateCategoryAttributeMetaCache)
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	if err := db.AutoMigrate(&categoryEntity.Category{}, &categoryEntity.CategoryVarchar{},
+		&categoryEntity.CategoryInt{}, &categoryEntity.CategoryText{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&entity.EavAttribute{AttributeID: 41, EntityTypeID: 3, AttributeCode: "name", BackendType: "varchar"})
+	db.Create(&categoryEntity.Category{EntityID: 4, ParentID: 2, Path: "1/2/4", Level: 2})
+	db.Create(&categoryEntity.CategoryVarchar{AttributeID: 41, EntityID: 4, Value: "Bags"})
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+	graphql.InvalidateCacheTags(graphql.CategoryListTag) // entries of earlier runs in this process
+
+	for _, query := range []string{`{ category(id: "4") { name } }`, `{ categories { name } }`} {
+		if rec := serveGraphQLRaw(e, http.MethodPost, query, nil); rec.Header().Get("X-Magento-Cache-Debug") != "MISS" {
+			t.Fatalf("%s first response: cache %q, want MISS", query, rec.Header().Get("X-Magento-Cache-Debug"))
+		}
+		if rec := serveGraphQLRaw(e, http.MethodPost, query, nil); rec.Header().Get("X-Magento-Cache-Debug") != "HIT" {
+			t.Fatalf("%s second response: cache %q, want HIT", query, rec.Header().Get("X-Magento-Cache-Debug"))
+		}
+	}
+
+	db.Model(&categoryEntity.CategoryVarchar{}).Where("entity_id = ?", 4).Update("value", "Backpacks")
+	events.Publish(events.TopicCategory, "4")
+	// the purge runs in the background
+	for _, query := range []string{`{ category(id: "4") { name } }`, `{ categories { name } }`} {
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			rec := serveGraphQLRaw(e, http.MethodPost, query, nil)
+			if rec.Header().Get("X-Magento-Cache-Debug") == "MISS" {
+				if !strings.Contains(rec.Body.String(), "Backpacks") {
+					t.Errorf("%s after the change: body %s, want the new name", query, rec.Body.String())
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: cached response was not purged after the category change", query)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestGraphQL_ResponseCache_SkipsCartsAndMutations(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	t.Setenv("GRAPHQL_RESPONSE_CACHE_TTL", "60")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedCartProducts(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	for _, query := range []string{
+		`mutation { createEmptyCart }`,
+		`{ magentoProducts { total_count } cart(cart_id: "missing") { id } }`,
+		`{ magentoProducts { total_count } ...Cart } fragment Cart on Query { cart(cart_id: "missing") { id } }`,
+	} {
+		for i := 0; i < 2; i++ {
+			rec := serveGraphQLRaw(e, http.MethodPost, query, nil)
+			if rec.Header().Get("X-Magento-Cache-Debug") != "" || rec.Header().Get("X-Magento-Tags") != "" || rec.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("%s: headers %v, want an uncached no-store response", query, rec.Header())
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	graphqlApi "magento.GO/api/graphql"
+	entity "magento.GO/model/entity"
+	productEntity "magento.GO/model/entity/product"
+	reviewEntity "magento.GO/model/entity/review"
+	configRepo "magento.GO/model/repository/config"
+)
+
+// seedReviews creates REVIEWED-1 with a Quality rating (options 1-5) enabled in store 1, two
+// approved reviews in store 1, one pending review and one approved review visible only in store 2.
+func seedReviews(t *testing.T, db *gorm.DB) (productID uint, qualityOptions []reviewEntity.RatingOption) {
+	t.Helper()
+	seedDefaultStore(t, db)
+	if err := db.AutoMigrate(
+		&reviewEntity.Review{}, &reviewEntity.ReviewDetail{}, &reviewEntity.ReviewStore{}, &reviewEntity.ReviewEntitySummary{},
+		&reviewEntity.Rating{}, &reviewEntity.RatingStore{}, &reviewEntity.RatingOption{},
+		&reviewEntity.RatingOptionVote{}, &reviewEntity.RatingOptionVoteAggregated{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	p := productEntity.Product{AttributeSetID: 4, TypeID: "simple", SKU: "REVIEWED-1"}
+	db.Create(&p)
+	db.Create(&productEntity.ProductIndexPrice{EntityID: p.EntityID, WebsiteID: 1, Price: 10, FinalPrice: 10})
+
+	quality := reviewEntity.Rating{EntityID: reviewEntity.EntityProduct, RatingCode: "Quality", IsActive: 1}
+	db.Create(&quality)
+	db.Create(&reviewEntity.RatingStore{RatingID: quality.RatingID, StoreID: 1})
+	for v := uint16(1); v <= 5; v++ {
+		o := reviewEntity.RatingOption{RatingID: quality.RatingID, Code: string(rune('0' + v)), Value: v, Position: v}
+		db.Create(&o)
+		qualityOptions = append(qualityOptions, o)
+	}
+
+	review := func(status uint16, store uint16, nickname string, createdAt time.Time, stars int) {
+		rv := reviewEntity.Review{EntityID: reviewEntity.EntityProduct, EntityPkValue: p.EntityID, StatusID: status, CreatedAt: createdAt}
+		db.Create(&rv)
+		db.Create(&reviewEntity.ReviewDetail{ReviewID: rv.ReviewID, Title: nickname + " says", Detail: "Text by " + nickname, Nickname: nickname})
+		db.Create(&[]reviewEntity.ReviewStore{{ReviewID: rv.ReviewID, StoreID: 0}, {ReviewID: rv.ReviewID, StoreID: store}})
+		o := qualityOptions[stars-1]
+		db.Create(&reviewEntity.RatingOptionVote{OptionID: o.OptionID, EntityPkValue: p.EntityID, RatingID: quality.RatingID,
+			ReviewID: &rv.ReviewID, Percent: o.Value * 20, Value: o.Value})
+	}
+	now := time.Now()
+	review(reviewEntity.StatusApproved, 1, "Ann", now.Add(-2*time.Hour), 5)
+	review(reviewEntity.StatusApproved, 1, "Bob", now.Add(-time.Hour), 3)
+	review(reviewEntity.StatusPending, 1, "Pending", now, 1)
+	review(reviewEntity.StatusApproved, 2, "Other store", now, 1)
+	db.Create(&reviewEntity.ReviewEntitySummary{EntityPkValue: p.EntityID, EntityType: reviewEntity.EntityProduct, ReviewsCount: 2, RatingSummary: 80, StoreID: 1})
+	return p.EntityID, qualityOptions
+}
+
+func TestGraphQL_ProductReviews(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	seedReviews(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data := execGraphQL(t, e, `{ magentoProducts(filter: { sku: { eq: "REVIEWED-1" } }) { items {
+		rating_summary review_count
+		reviews(pageSize: 1, currentPage: 2) {
+			items { nickname summary text average_rating ratings_breakdown { name value } }
+			page_info { current_page page_size total_pages }
+		}
+	} } }`)
+	items := data["magentoProducts"].(map[string]interface{})["items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("items = %v", items)
+	}
+	p := items[0].(map[string]interface{})
+	if p["rating_summary"].(float64) != 80 || p["review_count"].(float64) != 2 {
+		t.Errorf("rating_summary/review_count = %v/%v, want 80/2", p["rating_summary"], p["review_count"])
+	}
+	reviews := p["reviews"].(map[string]interface{})
+	pageInfo := reviews["page_info"].(map[string]interface{})
+	if pageInfo["total_pages"].(float64) != 2 || pageInfo["current_page"].(float64) != 2 || pageInfo["page_size"].(float64) != 1 {
+		t.Errorf("page_info = %v", pageInfo)
+	}
+	reviewItems := reviews["items"].([]interface{})
+	if len(reviewItems) != 1 {
+		t.Fatalf("reviews page 2 = %v, want one review", reviewItems)
+	}
+	ann := reviewItems[0].(map[string]interface{})
+	breakdown := ann["ratings_breakdown"].([]interface{})
+	if ann["nickname"] != "Ann" || ann["summary"] != "Ann says" || ann["text"] != "Text by Ann" || ann["average_rating"].(float64) != 100 ||
+		len(breakdown) != 1 || breakdown[0].(map[string]interface{})["name"] != "Quality" || breakdown[0].(map[string]interface{})["value"] != "5" {
+		t.Errorf("page 2 review = %v, want Ann (older of the two approved store 1 reviews)", ann)
+	}
+}
+
+func TestGraphQL_CreateProductReview(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	productID, _ := seedReviews(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	meta := execGraphQL(t, e, `{ productReviewRatingsMetadata { items { id name values { value_id value } } } }`)
+	ratings := meta["productReviewRatingsMetadata"].(map[string]interface{})["items"].([]interface{})
+	if len(ratings) != 1 {
+		t.Fatalf("ratings metadata = %v", ratings)
+	}
+	quality := ratings[0].(map[string]interface{})
+	values := quality["values"].([]interface{})
+	if quality["name"] != "Quality" || len(values) != 5 {
+		t.Fatalf("Quality metadata = %v", quality)
+	}
+	four := values[3].(map[string]interface{})
+
+	data := execGraphQL(t, e, `mutation { createProductReview(input: {
+		sku: "REVIEWED-1", nickname: "Cy", summary: "Solid", text: "Does the job",
+		ratings: [{ id: "`+quality["id"].(string)+`", value_id: "`+four["value_id"].(string)+`" }]
+	}) { review { nickname summary text average_rating ratings_breakdown { name value } } } }`)
+	review := data["createProductReview"].(map[string]interface{})["review"].(map[string]interface{})
+	if review["nickname"] != "Cy" || review["summary"] != "Solid" || review["average_rating"].(float64) != 80 {
+		t.Errorf("created review = %v", review)
+	}
+
+	var saved reviewEntity.Review
+	if err := db.Where("entity_pk_value = ?", productID).Order("review_id DESC").First(&saved).Error; err != nil {
+		t.Fatalf("load review: %v", err)
+	}
+	if saved.StatusID != reviewEntity.StatusPending || saved.EntityID != reviewEntity.EntityProduct {
+		t.Errorf("saved review = %+v, want a pending product review", saved)
+	}
+	var stores, votes int64
+	db.Model(&reviewEntity.ReviewStore{}).Where("review_id = ?", saved.ReviewID).Count(&stores)
+	db.Model(&reviewEntity.RatingOptionVote{}).Where("review_id = ? AND value = 4 AND percent = 80", saved.ReviewID).Count(&votes)
+	if stores != 2 || votes != 1 {
+		t.Errorf("review_store rows = %d, votes = %d; want 2 and 1", stores, votes)
+	}
+	var vote reviewEntity.RatingOptionVote
+	db.Where("review_id = ?", saved.ReviewID).First(&vote)
+	if vote.RemoteIP != "192.0.2.1" || vote.RemoteIPLong != 3221225985 {
+		t.Errorf("vote remote_ip = %q (%d), want the client's 192.0.2.1", vote.RemoteIP, vote.RemoteIPLong)
+	}
+
+	data = execGraphQL(t, e, `{ magentoProducts(filter: { sku: { eq: "REVIEWED-1" } }) { items { reviews { items { nickname } } } } }`)
+	listed := data["magentoProducts"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["reviews"].(map[string]interface{})["items"].([]interface{})
+	if len(listed) != 2 {
+		t.Errorf("listed reviews = %v, want the pending review hidden", listed)
+	}
+
+	// A rating ID past the smallint range must not wrap around to Quality.
+	qualityID, _ := base64.StdEncoding.DecodeString(quality["id"].(string))
+	n, _ := strconv.Atoi(string(qualityID))
+	wrapped := base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(n + 65536)))
+	if errs := graphQLErrors(t, e, `mutation { createProductReview(input: { sku: "REVIEWED-1", nickname: "W", summary: "S", text: "T",
+		ratings: [{ id: "`+wrapped+`", value_id: "`+four["value_id"].(string)+`" }] }) { review { nickname } } }`); len(errs) == 0 || !strings.Contains(errs[0], "invalid rating") {
+		t.Errorf("review with rating ID %d: errors = %v, want invalid rating", n+65536, errs)
+	}
+
+	db.Create(&entity.CoreConfigData{Scope: "default", Path: "catalog/review/allow_guest", Value: strVal("0")})
+	configRepo.GetConfigRepository(db).InvalidateCache()
+	if errs := graphQLErrors(t, e, `mutation { createProductReview(input: { sku: "REVIEWED-1", nickname: "G", summary: "S", text: "T", ratings: [] }) { review { nickname } } }`); len(errs) == 0 {
+		t.Error("guest review with allow_guest=0 succeeded, want an error")
+	}
+}
+
+func TestGraphQL_ReviewSummariesLoadedOnDemand(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	seedReviews(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	var summaryQueries int
+	db.Callback().Query().After("gorm:query").Register("test:count_review_summaries", func(tx *gorm.DB) {
+		if tx.Statement.Table == "review_entity_summary" {
+			summaryQueries++
+		}
+	})
+	execGraphQL(t, e, `{ magentoProducts(filter: { sku: { eq: "REVIEWED-1" } }) { items { sku } } }`)
+	if summaryQueries != 0 {
+		t.Errorf("review summary queries without review fields = %d, want 0", summaryQueries)
+	}
+	data := execGraphQL(t, e, `{ magentoProducts(filter: { sku: { eq: "REVIEWED-1" } }) { items { rating_summary review_count } } }`)
+	if summaryQueries != 1 {
+		t.Errorf("review summary queries = %d, want 1", summaryQueries)
+	}
+	p := data["magentoProducts"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})
+	if p["rating_summary"].(float64) != 80 || p["review_count"].(float64) != 2 {
+		t.Errorf("rating_summary/review_count = %v/%v, want 80/2", p["rating_summary"], p["review_count"])
+	}
+}
+
+func graphQLErrors(t *testing.T, e *echo.Echo, query string) []string {
+	t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"query": query})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	var resp struct {
+		Errors []struct{ Message string }
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var messages []string
+	for _, e := range resp.Errors {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
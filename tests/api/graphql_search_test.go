@@ -0,0 +1,305 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	graphqlApi "magento.GO/api/graphql"
+	productApi "magento.GO/api/product"
+	"magento.GO/core/events"
+	"magento.GO/graphql/resolvers"
+	entity "magento.GO/model/entity"
+	categoryEntity "magento.GO/model/entity/category"
+	productEntity "magento.GO/model/entity/product"
+)
+
+// seedSearchProducts creates bags in category 3 next to a hidden and a disabled bag.
+func seedSearchProducts(t *testing.T, db *gorm.DB) map[string]uint {
+	t.Helper()
+	// The Product many2many created catalog_category_product without position
+	if err := db.Migrator().DropTable(&categoryEntity.CategoryProduct{}); err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	if err := db.AutoMigrate(&categoryEntity.CategoryProduct{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&[]entity.EavAttribute{
+		{AttributeID: 73, EntityTypeID: 4, AttributeCode: "name", BackendType: "varchar"},
+		{AttributeID: 75, EntityTypeID: 4, AttributeCode: "description", BackendType: "text"},
+		{AttributeID: 97, EntityTypeID: 4, AttributeCode: "status", BackendType: "int"},
+		{AttributeID: 99, EntityTypeID: 4, AttributeCode: "visibility", BackendType: "int"},
+	})
+	db.Create(&categoryEntity.Category{EntityID: 3, ParentID: 2, Path: "1/2/3", Level: 2})
+	ids := make(map[string]uint)
+	for _, p := range []struct {
+		sku, name, description string
+		status, visibility     int
+	}{
+		{"24-MB01", "Joust Duffle Bag", "<p>A sporty bag for the gym.</p>", 1, 4},
+		{"24-MB04", "Strive Shoulder Pack", "Carry all your bags.", 1, 4},
+		{"24-MB05", "Hidden Bag", "", 1, 1},
+		{"24-MB06", "Disabled Bag", "", 2, 4},
+	} {
+		product := productEntity.Product{AttributeSetID: 4, TypeID: "simple", SKU: p.sku}
+		db.Create(&product)
+		ids[p.sku] = product.EntityID
+		db.Create(&productEntity.ProductVarchar{AttributeID: 73, EntityID: product.EntityID, Value: p.name})
+		db.Create(&productEntity.ProductText{AttributeID: 75, EntityID: product.EntityID, Value: p.description})
+		db.Create(&[]productEntity.ProductInt{
+			{AttributeID: 97, EntityID: product.EntityID, Value: p.status},
+			{AttributeID: 99, EntityID: product.EntityID, Value: p.visibility},
+		})
+	}
+	db.Exec("INSERT INTO catalog_category_product (category_id, product_id) VALUES (3, ?)", ids["24-MB01"])
+	return ids
+}
+
+func searchSKUs(t *testing.T, e *echo.Echo, args string) ([]string, map[string]interface{}) {
+	t.Helper()
+	data := execGraphQL(t, e, `{ search(`+args+`) { items { sku } total_count page_info { current_page total_pages } } }`)
+	result := data["search"].(map[string]interface{})
+	skus := []string{}
+	for _, it := range result["items"].([]interface{}) {
+		skus = append(skus, it.(map[string]interface{})["sku"].(string))
+	}
+	return skus, result
+}
+
+func TestGraphQL_Search_MemoryEngine(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	t.Setenv("SEARCH_ENGINE", "memory")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	ids := seedSearchProducts(t, db)
+	productApi.RegisterProductRoutes(e.Group("/api"), db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	cases := []struct {
+		args  string
+		want  []string
+		total float64
+	}{
+		{`query: "bags"`, []string{"24-MB01", "24-MB04"}, 2},
+		{`query: "duff"`, []string{"24-MB01"}, 1},
+		{`query: "24-MB04"`, []string{"24-MB04"}, 1},
+		{`query: "bag", categoryId: "3"`, []string{"24-MB01"}, 1},
+		{`query: "bag", pageSize: 1, currentPage: 2`, []string{"24-MB04"}, 2},
+		{`query: "sofa"`, []string{}, 0},
+	}
+	for _, c := range cases {
+		skus, result := searchSKUs(t, e, c.args)
+		if !reflect.DeepEqual(skus, c.want) || result["total_count"].(float64) != c.total {
+			t.Errorf("search(%s) = %v (%v), want %v (%v)", c.args, skus, result["total_count"], c.want, c.total)
+		}
+	}
+	if _, result := searchSKUs(t, e, `query: "bag", pageSize: 1, currentPage: 2`); fmt.Sprint(result["page_info"]) != "map[current_page:2 total_pages:2]" {
+		t.Errorf("page_info = %v", result["page_info"])
+	}
+
+	// Changes published on the hub are reindexed before the next search.
+	db.Model(&productEntity.ProductVarchar{}).Where("entity_id = ? AND attribute_id = 73", ids["24-MB04"]).Update("value", "Strive Shoulder Sofa")
+	events.Publish(events.TopicProduct, "24-MB04")
+	if skus, _ := searchSKUs(t, e, `query: "sofa"`); !reflect.DeepEqual(skus, []string{"24-MB04"}) {
+		t.Errorf("search(sofa) after rename = %v, want [24-MB04]", skus)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/products/%d", ids["24-MB01"]), nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if skus, result := searchSKUs(t, e, `query: "duffle"`); len(skus) != 0 || result["total_count"].(float64) != 0 {
+		t.Errorf("search(duffle) after delete = %v (%v), want none", skus, result["total_count"])
+	}
+}
+
+func TestGraphQL_Search_WithoutElasticsearch(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedSearchProducts(t, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	// Without SEARCH_ENGINE and ELASTICSEARCH_HOST the in-memory index is used.
+	t.Setenv("SEARCH_ENGINE", "")
+	t.Setenv("ELASTICSEARCH_HOST", "")
+	resolvers.ResetSearchService()
+	t.Cleanup(resolvers.ResetSearchService)
+	if engine := resolvers.SearchEngineFromEnv(); engine != resolvers.SearchEngineMemory {
+		t.Errorf("SearchEngineFromEnv() = %q without ELASTICSEARCH_HOST, want memory", engine)
+	}
+	if skus, _ := searchSKUs(t, e, `query: "bags"`); !reflect.DeepEqual(skus, []string{"24-MB01", "24-MB04"}) {
+		t.Errorf("search(bags) without Elasticsearch = %v", skus)
+	}
+
+	// A configured cluster that cannot be reached falls back to the in-memory index.
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	t.Setenv("ELASTICSEARCH_HOST", down.URL)
+	t.Setenv("SEARCH_ENGINE", "elasticsearch")
+	resolvers.ResetSearchService()
+	if skus, _ := searchSKUs(t, e, `query: "bags"`); !reflect.DeepEqual(skus, []string{"24-MB01", "24-MB04"}) {
+		t.Errorf("search(bags) with Elasticsearch down = %v", skus)
+	}
+}
+
+func TestGraphQL_Search_MemoryEngine_FilterSortAggregations(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	t.Setenv("SEARCH_ENGINE", "memory")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	ids := seedSearchProducts(t, db)
+	db.Create(&[]productEntity.ProductIndexPrice{
+		{EntityID: ids["24-MB01"], CustomerGroupID: 0, WebsiteID: 1, Price: 45, FinalPrice: 45},
+		{EntityID: ids["24-MB04"], CustomerGroupID: 0, WebsiteID: 1, Price: 32, FinalPrice: 32},
+	})
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	cases := []struct {
+		args  string
+		want  []string
+		total float64
+	}{
+		{`query: "bag"`, []string{"24-MB01", "24-MB04"}, 2},
+		{`query: "bag", sort: { price: ASC }`, []string{"24-MB04", "24-MB01"}, 2},
+		{`query: "bag", sort: { name: DESC }`, []string{"24-MB04", "24-MB01"}, 2},
+		{`query: "bag", filter: { price: { from: "40" } }`, []string{"24-MB01"}, 1},
+		{`query: "bag", filter: { category_id: { eq: "3" } }`, []string{"24-MB01"}, 1},
+		{`query: "bag", filter: { sku: { in: ["24-MB04", "24-MB05"] } }`, []string{"24-MB04"}, 1},
+		{`query: "bag", sort: { price: DESC }, pageSize: 1, currentPage: 2`, []string{"24-MB04"}, 2},
+		{`query: "bag", categoryId: "99"`, []string{}, 0},
+	}
+	for _, c := range cases {
+		skus, result := searchSKUs(t, e, c.args)
+		if !reflect.DeepEqual(skus, c.want) || result["total_count"].(float64) != c.total {
+			t.Errorf("search(%s) = %v (%v), want %v (%v)", c.args, skus, result["total_count"], c.want, c.total)
+		}
+	}
+
+	data := execGraphQL(t, e, `{ search(query: "bag", pageSize: 1) { aggregations { attribute_code options { value count } } } }`)
+	aggs := data["search"].(map[string]interface{})["aggregations"]
+	if got := fmt.Sprint(aggs); got != "[map[attribute_code:price options:[map[count:1 value:30_40] map[count:1 value:40_50]]]]" {
+		t.Errorf("aggregations = %s", got)
+	}
+}
+
+// esStandIn answers every search with response and records the path and body of the requests.
+type esStandIn struct {
+	paths  []string
+	bodies []map[string]interface{}
+}
+
+func (s *esStandIn) serve(t *testing.T, response string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.paths = append(s.paths, r.URL.Path)
+		s.bodies = append(s.bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("ELASTICSEARCH_HOST", srv.URL)
+	t.Setenv("SEARCH_ENGINE", "elasticsearch")
+	resolvers.ResetSearchService()
+	t.Cleanup(resolvers.ResetSearchService)
+	return srv
+}
+
+func TestGraphQL_Search_Elasticsearch(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := storeURLTestDB(t)
+	ids := seedSearchProducts(t, db)
+	if err := db.AutoMigrate(&entity.CatalogEavAttribute{}, &entity.EavAttributeOption{}, &entity.EavAttributeOptionValue{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	selectInput := "select"
+	colorLabel := "Color"
+	db.Create(&entity.EavAttribute{AttributeID: 93, EntityTypeID: 4, AttributeCode: "color", BackendType: "int", FrontendInput: &selectInput, FrontendLabel: &colorLabel})
+	db.Create(&entity.CatalogEavAttribute{AttributeID: 93, IsFilterable: 1})
+	db.Create(&[]entity.EavAttributeOption{{OptionID: 49, AttributeID: 93, SortOrder: 1}, {OptionID: 50, AttributeID: 93, SortOrder: 2}})
+	db.Create(&[]entity.EavAttributeOptionValue{{OptionID: 49, Value: "Black"}, {OptionID: 50, Value: "Blue"}})
+	db.Create(&[]entity.CoreConfigData{
+		{Scope: "default", Path: "catalog/search/engine", Value: strVal("opensearch")},
+		{Scope: "stores", ScopeID: 2, Path: "catalog/search/opensearch_index_prefix", Value: strVal("shop_fr")},
+	})
+	es := &esStandIn{}
+	es.serve(t, fmt.Sprintf(`{
+		"hits": {"total": {"value": 12}, "hits": [{"_source": {"entity_id": %d}}, {"_source": {"entity_id": %d}}]},
+		"aggregations": {
+			"price_bucket": {"buckets": [{"key": 30.0, "doc_count": 4}, {"key": 40.0, "doc_count": 0}, {"key": 120.0, "doc_count": 8}]},
+			"category_bucket": {"buckets": [{"key": 3, "doc_count": 12}]},
+			"color_bucket": {"buckets": [{"key": 50, "doc_count": 3}, {"key": 49, "doc_count": 9}]}
+		}
+	}`, ids["24-MB04"], ids["24-MB01"]))
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data := execGraphQL(t, e, `{ search(query: "bag", pageSize: 2, currentPage: 2, categoryId: "3",
+		filter: { price: { from: "30", to: "200" }, color: { in: ["49", "50"] } }, sort: { price: DESC }) {
+		items { sku } total_count aggregations { attribute_code options { label value count } }
+	} }`)
+	result := data["search"].(map[string]interface{})
+	skus := []string{}
+	for _, it := range result["items"].([]interface{}) {
+		skus = append(skus, it.(map[string]interface{})["sku"].(string))
+	}
+	if !reflect.DeepEqual(skus, []string{"24-MB04", "24-MB01"}) || result["total_count"].(float64) != 12 {
+		t.Errorf("search = %v (%v), want hits in response order (12)", skus, result["total_count"])
+	}
+	want := "[map[attribute_code:price options:[map[count:4 label:0-100 value:0_100] map[count:8 label:100-200 value:100_200]]] " +
+		"map[attribute_code:color options:[map[count:9 label:Black value:49] map[count:3 label:Blue value:50]]]]"
+	if got := fmt.Sprint(result["aggregations"]); got != want {
+		t.Errorf("aggregations = %s", got)
+	}
+
+	if len(es.paths) != 1 || es.paths[0] != "/magento2_catalog_product_1/_search" {
+		t.Fatalf("paths = %v, want the default store's index", es.paths)
+	}
+	body := es.bodies[0]
+	if body["from"].(float64) != 2 || body["size"].(float64) != 2 {
+		t.Errorf("from/size = %v/%v, want 2/2", body["from"], body["size"])
+	}
+	query := body["query"].(map[string]interface{})["bool"].(map[string]interface{})
+	if got := fmt.Sprint(query["filter"]); got != "[map[terms:map[category_ids:[3]]] map[terms:map[color:[49 50]]] map[range:map[price_0_1:map[gte:30 lte:200]]]]" {
+		t.Errorf("filter = %s", got)
+	}
+	if got := fmt.Sprint(query["must"]); got != "[map[multi_match:map[fields:[name^3 sku^2 description short_description] query:bag]]]" {
+		t.Errorf("must = %s", got)
+	}
+	if got := fmt.Sprint(body["sort"]); got != "[map[price_0_1:map[order:desc]] map[entity_id:map[order:asc]]]" {
+		t.Errorf("sort = %s", got)
+	}
+	aggs := body["aggs"].(map[string]interface{})
+	if got := fmt.Sprint(aggs["price_bucket"]); got != "map[histogram:map[field:price_0_1 interval:10 min_doc_count:1]]" {
+		t.Errorf("price_bucket = %s", got)
+	}
+	if got := fmt.Sprint(aggs["color_bucket"]); got != "map[terms:map[field:color size:500]]" {
+		t.Errorf("color_bucket = %s", got)
+	}
+	if _, ok := aggs["category_bucket"]; !ok {
+		t.Errorf("aggs = %v, want category_bucket", aggs)
+	}
+
+	// Without aggregations selected none are requested; the store's index prefix applies.
+	execGraphQLWithHeaders(t, e, `{ search(query: "bag", sort: { name: ASC, relevance: DESC }) { total_count } }`, map[string]string{"Store": "fr"})
+	if len(es.paths) != 2 || es.paths[1] != "/shop_fr_catalog_product_2/_search" {
+		t.Fatalf("paths = %v, want the fr store's index", es.paths)
+	}
+	if _, ok := es.bodies[1]["aggs"]; ok {
+		t.Errorf("aggs requested without aggregations selected")
+	}
+	if got := fmt.Sprint(es.bodies[1]["sort"]); got != "[map[_score:map[order:desc]] map[name.sort_name:map[order:asc]] map[entity_id:map[order:asc]]]" {
+		t.Errorf("sort = %s", got)
+	}
+}
@@ -0,0 +1,87 @@
+package apitest
+
+import (
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	graphqlApi "magento.GO/api/graphql"
+	entity "magento.GO/model/entity"
+	storeEntity "magento.GO/model/entity/store"
+)
+
+func strVal(s string) *string { return &s }
+
+// seedDefaultStore creates website "base" with store group "main" and its default store view 1.
+func seedDefaultStore(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if err := db.AutoMigrate(&storeEntity.Store{}, &storeEntity.StoreGroup{}, &storeEntity.StoreWebsite{}, &entity.CoreConfigData{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&storeEntity.StoreWebsite{WebsiteID: 1, Code: "base", Name: "Main Website", DefaultGroupID: 1, IsDefault: 1})
+	db.Create(&storeEntity.StoreGroup{GroupID: 1, WebsiteID: 1, Name: "Main Store", RootCategoryID: 2, DefaultStoreID: 1, Code: "main"})
+	db.Create(&storeEntity.Store{StoreID: 1, Code: "default", WebsiteID: 1, GroupID: 1, Name: "Default Store View", IsActive: 1})
+}
+
+func TestGraphQL_StoreConfigAndAvailableStores(t *testing.T) {
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	if err := db.AutoMigrate(&storeEntity.Store{}, &storeEntity.StoreGroup{}, &storeEntity.StoreWebsite{}, &entity.CoreConfigData{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&storeEntity.StoreWebsite{WebsiteID: 1, Code: "base", Name: "Main Website", DefaultGroupID: 1, IsDefault: 1})
+	db.Create(&[]storeEntity.StoreGroup{
+		{GroupID: 1, WebsiteID: 1, Name: "Main Store", RootCategoryID: 2, DefaultStoreID: 1, Code: "main"},
+		{GroupID: 2, WebsiteID: 1, Name: "Outlet", RootCategoryID: 9, DefaultStoreID: 3, Code: "outlet"},
+	})
+	db.Create(&[]storeEntity.Store{
+		{StoreID: 1, Code: "default", WebsiteID: 1, GroupID: 1, Name: "Default Store View", IsActive: 1},
+		{StoreID: 2, Code: "fr", WebsiteID: 1, GroupID: 1, Name: "French", SortOrder: 1, IsActive: 1},
+		{StoreID: 3, Code: "outlet", WebsiteID: 1, GroupID: 2, Name: "Outlet", SortOrder: 2, IsActive: 1},
+	})
+	db.Create(&[]entity.CoreConfigData{
+		{Scope: "default", Path: "web/unsecure/base_url", Value: strVal("http://shop.test/")},
+		{Scope: "stores", ScopeID: 2, Path: "general/locale/code", Value: strVal("fr_FR")},
+		{Scope: "default", Path: "currency/options/base", Value: strVal("EUR")},
+	})
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	query := `{
+		storeConfig { id store_code store_name locale base_currency_code base_url base_media_url secure_base_url
+			product_url_suffix store_group_code website_code is_default_store root_category_uid grid_per_page }
+		availableStores { store_code }
+		group: availableStores(useCurrentGroup: true) { store_code }
+	}`
+	data := execGraphQL(t, e, query)
+	sc := data["storeConfig"].(map[string]interface{})
+	if sc["store_code"] != "default" || sc["locale"] != "en_US" || sc["base_currency_code"] != "EUR" ||
+		sc["base_url"] != "http://shop.test/" || sc["base_media_url"] != "http://shop.test/media/" ||
+		sc["secure_base_url"] != "http://shop.test/" || sc["product_url_suffix"] != ".html" ||
+		sc["store_group_code"] != "main" || sc["website_code"] != "base" || sc["is_default_store"] != true ||
+		sc["root_category_uid"] != "Mg==" || sc["grid_per_page"].(float64) != 12 {
+		t.Errorf("storeConfig (store 0) = %v", sc)
+	}
+	if got := storeCodes(data["availableStores"]); len(got) != 3 || got[0] != "default" || got[1] != "fr" || got[2] != "outlet" {
+		t.Errorf("availableStores = %v", got)
+	}
+	if got := storeCodes(data["group"]); len(got) != 2 {
+		t.Errorf("availableStores(useCurrentGroup) = %v, want default and fr", got)
+	}
+
+	data = execGraphQLWithHeaders(t, e, query, map[string]string{"Store": "2"})
+	sc = data["storeConfig"].(map[string]interface{})
+	if sc["store_code"] != "fr" || sc["locale"] != "fr_FR" || sc["is_default_store"] != false {
+		t.Errorf("storeConfig (store 2) = %v", sc)
+	}
+}
+
+func storeCodes(v interface{}) []string {
+	var codes []string
+	for _, s := range v.([]interface{}) {
+		codes = append(codes, s.(map[string]interface{})["store_code"].(string))
+	}
+	return codes
+}
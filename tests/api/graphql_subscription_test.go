@@ -0,0 +1,204 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+
+	graphqlApi "magento.GO/api/graphql"
+	"magento.GO/core/events"
+	customerEntity "magento.GO/model/entity/customer"
+	priceEntity "magento.GO/model/entity/price"
+	productEntity "magento.GO/model/entity/product"
+	storeEntity "magento.GO/model/entity/store"
+	productService "magento.GO/service/product"
+)
+
+type wsMessage struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// data returns the data of a next message by field.
+func (m wsMessage) data() map[string]map[string]interface{} {
+	var payload struct {
+		Data map[string]map[string]interface{} `json:"data"`
+	}
+	json.Unmarshal(m.Payload, &payload)
+	return payload.Data
+}
+
+func wsExchange(t *testing.T, ws *websocket.Conn, send string, wantType string) wsMessage {
+	t.Helper()
+	if send != "" {
+		if err := websocket.Message.Send(ws, send); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg wsMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if msg.Type != wantType {
+		t.Fatalf("got %+v, want %s", msg, wantType)
+	}
+	return msg
+}
+
+func TestGraphQL_Subscriptions_StockAndPriceChanges(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	t.Setenv("GRAPHQL_SUBSCRIPTION_POLL_INTERVAL", "0")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	seedCartProducts(t, db)
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_unq ON cataloginventory_stock_item (product_id, stock_id)")
+	// Subscriptions query from their own goroutines; a second connection would open an empty :memory: DB.
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.SetMaxOpenConns(1)
+	}
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/graphql", "graphql-transport-ws", srv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+	wsExchange(t, ws, `{"type":"connection_init"}`, "connection_ack")
+	for _, sub := range []string{
+		`{"id":"stock","type":"subscribe","payload":{"query":"subscription { stockChanged(skus: [\"TEE-1\"]) { sku qty is_in_stock stock_status } }"}}`,
+		`{"id":"price","type":"subscribe","payload":{"query":"subscription { priceChanged(skus: [\"TEE-1\"]) { sku price currency } }"}}`,
+	} {
+		if err := websocket.Message.Send(ws, sub); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	wsExchange(t, ws, `{"type":"ping"}`, "pong") // both subscriptions are running
+
+	qty := 7.0
+	if _, err := productService.ImportStockJSON(db, []productService.StockItemInput{{SKU: "TEE-1", Qty: &qty}}, 0); err != nil {
+		t.Fatalf("ImportStockJSON: %v", err)
+	}
+	msg := wsExchange(t, ws, "", "next")
+	if change := msg.data()["stockChanged"]; msg.ID != "stock" || change["sku"] != "TEE-1" || change["qty"] != 7.0 || change["stock_status"] != "IN_STOCK" {
+		t.Fatalf("after import: %+v, want TEE-1 with qty 7", msg)
+	}
+	// Importing the same stock again sends nothing; the next event is the poller's.
+	if _, err := productService.ImportStockJSON(db, []productService.StockItemInput{{SKU: "TEE-1", Qty: &qty}}, 0); err != nil {
+		t.Fatalf("ImportStockJSON: %v", err)
+	}
+
+	// Changes made directly in the database are found by the change poller.
+	poller := productService.NewChangePoller(db, events.GetInstance(), 0)
+	poller.Poll()
+	var tee productEntity.Product
+	db.Where("sku = ?", "TEE-1").First(&tee)
+	db.Model(&productEntity.StockItem{}).Where("product_id = ?", tee.EntityID).Updates(map[string]interface{}{"qty": 0, "is_in_stock": 0})
+	db.Model(&productEntity.ProductDecimal{}).Where("entity_id = ? AND attribute_id = ?", tee.EntityID, 77).Update("value", 15)
+	poller.Poll()
+
+	got := map[string]map[string]interface{}{}
+	for i := 0; i < 2; i++ {
+		msg := wsExchange(t, ws, "", "next")
+		for field, change := range msg.data() {
+			got[field] = change
+		}
+	}
+	if stock := got["stockChanged"]; stock == nil || stock["qty"] != 0.0 || stock["is_in_stock"] != false || stock["stock_status"] != "OUT_OF_STOCK" {
+		t.Errorf("stockChanged = %v, want TEE-1 out of stock", stock)
+	}
+	if price := got["priceChanged"]; price == nil || price["price"] != 15.0 || price["currency"] != "USD" {
+		t.Errorf("priceChanged = %v, want TEE-1 at 15 USD", price)
+	}
+
+	if err := websocket.Message.Send(ws, `{"id":"stock","type":"complete"}`); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	wsExchange(t, ws, `{"type":"ping"}`, "pong")
+}
+
+func TestChangePoller_PricesOfEveryCustomerGroup(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	db := graphqlProductTestDB(t)
+	seedCartProducts(t, db)
+	if err := db.AutoMigrate(&customerEntity.Group{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&[]customerEntity.Group{{CustomerGroupID: 0, CustomerGroupCode: "NOT LOGGED IN"}, {CustomerGroupID: 1, CustomerGroupCode: "General"}})
+	hub := events.NewHub()
+	sub := hub.Subscribe(events.TopicPrice, []string{"TEE-1", "EBOOK-1"})
+	defer sub.Close()
+	poller := productService.NewChangePoller(db, hub, 0)
+	poller.Poll()
+
+	// A tier price of the General group changes only that group's price, and is still published.
+	var tee productEntity.Product
+	db.Where("sku = ?", "TEE-1").First(&tee)
+	general := priceEntity.TierPrice{EntityID: tee.EntityID, CustomerGroupID: 1, Qty: 1, Value: 12, WebsiteID: 0}
+	db.Create(&general)
+	db.Model(&general).Update("all_groups", 0) // a zero AllGroups is replaced by the column default on create
+	poller.Poll()
+	if skus := sub.Take(); !reflect.DeepEqual(skus, []string{"TEE-1"}) {
+		t.Errorf("published after a General tier price = %v, want [TEE-1]", skus)
+	}
+	poller.Poll()
+	if skus := sub.Take(); len(skus) != 0 {
+		t.Errorf("published without changes = %v, want none", skus)
+	}
+}
+
+func TestChangePoller_PricesOfEveryWebsite(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	seedCartProducts(t, db)
+	seedDefaultStore(t, db)
+	db.Create(&storeEntity.StoreWebsite{WebsiteID: 2, Code: "outlet", Name: "Outlet Website", DefaultGroupID: 2})
+	db.Create(&storeEntity.StoreGroup{GroupID: 2, WebsiteID: 2, Name: "Outlet", RootCategoryID: 2, DefaultStoreID: 2, Code: "outlet"})
+	db.Create(&storeEntity.Store{StoreID: 2, Code: "outlet", WebsiteID: 2, GroupID: 2, Name: "Outlet", IsActive: 1})
+	hub := events.NewHub()
+	sub := hub.Subscribe(events.TopicPrice, []string{"TEE-1", "EBOOK-1"})
+	defer sub.Close()
+	poller := productService.NewChangePoller(db, hub, 0)
+	poller.Poll()
+
+	// A tier price of the second website changes only that website's price, and is still published.
+	var tee productEntity.Product
+	db.Where("sku = ?", "TEE-1").First(&tee)
+	db.Create(&priceEntity.TierPrice{EntityID: tee.EntityID, CustomerGroupID: 0, AllGroups: 1, Qty: 1, Value: 11, WebsiteID: 2})
+	poller.Poll()
+	if skus := sub.Take(); !reflect.DeepEqual(skus, []string{"TEE-1"}) {
+		t.Errorf("published after an outlet tier price = %v, want [TEE-1]", skus)
+	}
+	poller.Poll()
+	if skus := sub.Take(); len(skus) != 0 {
+		t.Errorf("published without changes = %v, want none", skus)
+	}
+}
+
+func TestGraphQL_Subscriptions_RejectEmptySKUs(t *testing.T) {
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/graphql", "graphql-transport-ws", srv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+	wsExchange(t, ws, `{"type":"connection_init"}`, "connection_ack")
+	raw, _ := json.Marshal(map[string]interface{}{"id": "1", "type": "subscribe", "payload": map[string]string{"query": `subscription { stockChanged(skus: []) { sku } }`}})
+	wsExchange(t, ws, string(raw), "error")
+}
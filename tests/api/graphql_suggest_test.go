@@ -0,0 +1,133 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	graphqlApi "magento.GO/api/graphql"
+	productApi "magento.GO/api/product"
+	searchApi "magento.GO/api/search"
+	"magento.GO/core/events"
+	entity "magento.GO/model/entity"
+	categoryEntity "magento.GO/model/entity/category"
+	productEntity "magento.GO/model/entity/product"
+	categoryRepo "magento.GO/model/repository/category"
+)
+
+func suggestionTexts(list interface{}, field string) []string {
+	texts := []string{}
+	for _, it := range list.([]interface{}) {
+		texts = append(texts, it.(map[string]interface{})[field].(string))
+	}
+	return texts
+}
+
+func TestGraphQL_SearchSuggestions_MemoryEngine(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	t.Setenv("SEARCH_ENGINE", "memory")
+	categoryRepo.InvalidateCategoryAttributeMetaCache()
+	t.Cleanup(categoryRepo.InvalidateCategoryAttributeMetaCache)
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	if err := db.AutoMigrate(&categoryEntity.Category{}, &categoryEntity.CategoryVarchar{},
+		&categoryEntity.CategoryInt{}, &categoryEntity.CategoryText{}, &entity.SearchQuery{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ids := seedSearchProducts(t, db)
+	db.Create(&[]entity.EavAttribute{
+		{AttributeID: 41, EntityTypeID: 3, AttributeCode: "name", BackendType: "varchar"},
+		{AttributeID: 42, EntityTypeID: 3, AttributeCode: "is_active", BackendType: "int"},
+	})
+	db.Create(&categoryEntity.Category{EntityID: 4, ParentID: 2, Path: "1/2/4", Level: 2})
+	db.Create(&[]categoryEntity.CategoryVarchar{
+		{AttributeID: 41, EntityID: 3, Value: "Duffle Bags"},
+		{AttributeID: 41, EntityID: 4, Value: "Dufflecoats"},
+	})
+	db.Create(&[]categoryEntity.CategoryInt{
+		{AttributeID: 42, EntityID: 3, Value: 1},
+		{AttributeID: 42, EntityID: 4, Value: 0},
+	})
+	db.Create(&[]entity.SearchQuery{
+		{QueryText: "duffle", NumResults: 3, Popularity: 10, IsActive: 1, DisplayInTerms: 1},
+		{QueryText: "duffle bag", NumResults: 1, Popularity: 25, IsActive: 1, DisplayInTerms: 1},
+		{QueryText: "duffel", NumResults: 0, Popularity: 40, IsActive: 1, DisplayInTerms: 1},
+		{QueryText: "dufflecoat", NumResults: 2, Popularity: 50, IsActive: 1, DisplayInTerms: 1},
+	})
+	db.Model(&entity.SearchQuery{}).Where("query_text = ?", "dufflecoat").Update("is_active", 0)
+	api := e.Group("/api")
+	productApi.RegisterProductRoutes(api, db)
+	searchApi.RegisterSearchRoutes(api, db)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	suggest := func(query string) map[string]interface{} {
+		t.Helper()
+		data := execGraphQL(t, e, `{ searchSuggestions(query: "`+query+`") {
+			products { text sku }
+			categories { id name }
+			terms { query_text num_results }
+		} }`)
+		return data["searchSuggestions"].(map[string]interface{})
+	}
+
+	got := suggest("duf")
+	if texts := suggestionTexts(got["products"], "text"); !reflect.DeepEqual(texts, []string{"Joust Duffle Bag"}) {
+		t.Errorf("products(duf) = %v, want [Joust Duffle Bag]", texts)
+	}
+	if names := suggestionTexts(got["categories"], "name"); !reflect.DeepEqual(names, []string{"Duffle Bags"}) {
+		t.Errorf("categories(duf) = %v, want [Duffle Bags]", names)
+	}
+	if terms := suggestionTexts(got["terms"], "query_text"); !reflect.DeepEqual(terms, []string{"duffle bag", "duffle"}) {
+		t.Errorf("terms(duf) = %v, want [duffle bag duffle]", terms)
+	}
+	if skus := suggestionTexts(suggest("24-mb0")["products"], "sku"); !reflect.DeepEqual(skus, []string{"24-MB01", "24-MB04"}) {
+		t.Errorf("products(24-mb0) = %v, want [24-MB01 24-MB04]", skus)
+	}
+	if got := suggest("  "); len(got["products"].([]interface{})) != 0 || len(got["terms"].([]interface{})) != 0 {
+		t.Errorf("blank query = %v, want no suggestions", got)
+	}
+
+	// Published changes update the product trie: a renamed product is suggested by its new name
+	// only, a disabled one no longer, and the other products stay.
+	db.Model(&productEntity.ProductVarchar{}).Where("entity_id = ? AND attribute_id = 73", ids["24-MB04"]).Update("value", "Duffel Shoulder Pack")
+	events.Publish(events.TopicProduct, "24-MB04")
+	if texts := suggestionTexts(suggest("duffel")["products"], "text"); !reflect.DeepEqual(texts, []string{"Duffel Shoulder Pack"}) {
+		t.Errorf("products(duffel) after rename = %v", texts)
+	}
+	if texts := suggestionTexts(suggest("strive")["products"], "text"); len(texts) != 0 {
+		t.Errorf("products(strive) after rename = %v, want none", texts)
+	}
+	db.Model(&productEntity.ProductInt{}).Where("entity_id = ? AND attribute_id = 97", ids["24-MB04"]).Update("value", 2)
+	events.Publish(events.TopicProduct, "24-MB04")
+	if skus := suggestionTexts(suggest("24-mb0")["products"], "sku"); !reflect.DeepEqual(skus, []string{"24-MB01"}) {
+		t.Errorf("products(24-mb0) after disabling 24-MB04 = %v, want [24-MB01]", skus)
+	}
+	db.Model(&productEntity.ProductInt{}).Where("entity_id = ? AND attribute_id = 97", ids["24-MB04"]).Update("value", 1)
+	events.Publish(events.TopicProduct, "24-MB04")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search/suggest?q=joust&limit=1", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("suggest status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Products []struct {
+			Text string `json:"text"`
+			SKU  string `json:"sku"`
+		} `json:"products"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || len(body.Products) != 1 || body.Products[0].SKU != "24-MB01" {
+		t.Errorf("GET /api/search/suggest = %s (%v)", rec.Body.String(), err)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search/suggest", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("suggest without q status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,98 @@
+package apitest
+
+import (
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	graphqlApi "magento.GO/api/graphql"
+	entity "magento.GO/model/entity"
+	productEntity "magento.GO/model/entity/product"
+	storeEntity "magento.GO/model/entity/store"
+)
+
+func TestGraphQL_UrlResolverAndRoute(t *testing.T) {
+	t.Setenv("PRODUCT_FLAT_CACHE", "off")
+	e := echo.New()
+	db := graphqlProductTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	seedDefaultStore(t, db)
+	if err := db.AutoMigrate(&entity.UrlRewrite{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	shirt := productEntity.Product{AttributeSetID: 4, TypeID: "simple", SKU: "SHIRT-1"}
+	db.Create(&shirt)
+	db.Create(&productEntity.ProductIndexPrice{EntityID: shirt.EntityID, WebsiteID: 1, Price: 25, FinalPrice: 25})
+	db.Create(&[]entity.UrlRewrite{
+		{EntityType: "product", EntityID: shirt.EntityID, RequestPath: "men/tops/some-shirt.html", TargetPath: "catalog/product/view/id/1", StoreID: 1},
+		{EntityType: "product", EntityID: shirt.EntityID, RequestPath: "old-shirt.html", TargetPath: "older-shirt.html", RedirectType: 301, StoreID: 1},
+		{EntityType: "product", EntityID: shirt.EntityID, RequestPath: "older-shirt.html", TargetPath: "men/tops/some-shirt.html", RedirectType: 302, StoreID: 1},
+		{EntityType: "category", EntityID: 5, RequestPath: "men/tops.html", TargetPath: "catalog/category/view/id/5", StoreID: 2},
+		{EntityType: "custom", RequestPath: "sale", TargetPath: "https://example.com/sale", RedirectType: 302, StoreID: 1},
+	})
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data := execGraphQL(t, e, `{
+		direct: urlResolver(url: "/men/tops/some-shirt.html") { id entity_uid relative_url redirectCode type }
+		redirected: urlResolver(url: "https://shop.example.com/old-shirt.html?utm=x") { id relative_url redirectCode type }
+		otherStore: urlResolver(url: "men/tops.html") { id }
+		custom: urlResolver(url: "sale") { id relative_url redirectCode type }
+		route(url: "old-shirt.html") { __typename relative_url redirect_code type ... on ProductInterface { sku } }
+	}`)
+
+	direct := data["direct"].(map[string]interface{})
+	if direct["type"] != "PRODUCT" || int(direct["id"].(float64)) != int(shirt.EntityID) ||
+		direct["relative_url"] != "men/tops/some-shirt.html" || direct["redirectCode"].(float64) != 0 {
+		t.Errorf("direct = %v", direct)
+	}
+	redirected := data["redirected"].(map[string]interface{})
+	if redirected["relative_url"] != "men/tops/some-shirt.html" || redirected["redirectCode"].(float64) != 301 {
+		t.Errorf("redirected = %v, want the final path with the first redirect code", redirected)
+	}
+	if data["otherStore"] != nil {
+		t.Errorf("otherStore = %v, want null (rewrite belongs to store 2)", data["otherStore"])
+	}
+	custom := data["custom"].(map[string]interface{})
+	if custom["type"] != nil || custom["id"] != nil || custom["relative_url"] != "https://example.com/sale" || custom["redirectCode"].(float64) != 302 {
+		t.Errorf("custom = %v", custom)
+	}
+
+	route := data["route"].(map[string]interface{})
+	if route["__typename"] != "MagentoProduct" || route["sku"] != "SHIRT-1" || route["type"] != "PRODUCT" ||
+		route["relative_url"] != "men/tops/some-shirt.html" || route["redirect_code"].(float64) != 301 {
+		t.Errorf("route = %v", route)
+	}
+}
+
+func TestGraphQL_UrlResolverDefaultStoreView(t *testing.T) {
+	e := echo.New()
+	db := storeURLTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	if err := db.AutoMigrate(&entity.UrlRewrite{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	// the main store's default view is the French store view
+	db.Model(&storeEntity.StoreGroup{}).Where("group_id = ?", 1).Update("default_store_id", 2)
+	db.Create(&[]entity.UrlRewrite{
+		{EntityType: "category", EntityID: 5, RequestPath: "homme.html", TargetPath: "catalog/category/view/id/5", StoreID: 2},
+		{EntityType: "category", EntityID: 6, RequestPath: "men.html", TargetPath: "catalog/category/view/id/6", StoreID: 1},
+	})
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data := execGraphQL(t, e, `{
+		fr: urlResolver(url: "homme.html") { id type }
+		en: urlResolver(url: "men.html") { id }
+	}`)
+	if fr, ok := data["fr"].(map[string]interface{}); !ok || int(fr["id"].(float64)) != 5 || fr["type"] != "CATEGORY" {
+		t.Errorf("fr = %v, want category 5 from the default store view", data["fr"])
+	}
+	if data["en"] != nil {
+		t.Errorf("en = %v, want null (rewrite belongs to store 1)", data["en"])
+	}
+	data = execGraphQLWithHeaders(t, e, `{ urlResolver(url: "men.html") { id } }`, map[string]string{"Store": "default"})
+	if data["urlResolver"] == nil {
+		t.Error("urlResolver (Store: default) = null, want category 6")
+	}
+}
@@ -0,0 +1,83 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"magento.GO/api"
+	graphqlApi "magento.GO/api/graphql"
+	entity "magento.GO/model/entity"
+	storeEntity "magento.GO/model/entity/store"
+)
+
+// storeURLTestDB adds store view "fr" (ID 2) with base URL http://shop.test/fr/ to the default store.
+func storeURLTestDB(t *testing.T) *gorm.DB {
+	db := graphqlProductTestDB(t)
+	seedDefaultStore(t, db)
+	db.Create(&storeEntity.Store{StoreID: 2, Code: "fr", WebsiteID: 1, GroupID: 1, Name: "French", SortOrder: 1, IsActive: 1})
+	db.Create(&[]entity.CoreConfigData{
+		{Scope: "default", Path: "web/unsecure/base_url", Value: strVal("http://shop.test/")},
+		{Scope: "stores", ScopeID: 2, Path: "web/unsecure/base_url", Value: strVal("http://shop.test/fr/")},
+	})
+	return db
+}
+
+func TestGraphQL_StoreCodeHeader(t *testing.T) {
+	e := echo.New()
+	db := storeURLTestDB(t)
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+
+	data := execGraphQLWithHeaders(t, e, `{ storeConfig { store_code } }`, map[string]string{"Store": "fr"})
+	if sc := data["storeConfig"].(map[string]interface{}); sc["store_code"] != "fr" {
+		t.Errorf("storeConfig (Store: fr) = %v", sc)
+	}
+}
+
+func TestStoreMiddleware_CodesAndURLMapping(t *testing.T) {
+	e := echo.New()
+	db := storeURLTestDB(t)
+	e.Pre(api.StoreMiddleware(db, true))
+	graphqlApi.RegisterGraphQLRoutes(e, db)
+	e.GET("/api/store", func(c echo.Context) error {
+		return c.String(http.StatusOK, strconv.Itoa(int(api.StoreIDFromRequest(c))))
+	})
+
+	cases := []struct {
+		target string
+		store  string
+		want   string
+	}{
+		{"http://shop.test/api/store", "", "1"},
+		{"http://shop.test/fr/api/store", "", "2"},
+		{"http://shop.test/api/store?__Store=fr", "", "2"},
+		{"http://shop.test/api/store", "fr", "2"},
+		{"http://other.test/api/store", "", "0"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.target, nil)
+		if c.store != "" {
+			req.Header.Set("Store", c.store)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Body.String() != c.want {
+			t.Errorf("GET %s (Store %q) = %d %s, want %s", c.target, c.store, rec.Code, rec.Body.String(), c.want)
+		}
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"query": `{ storeConfig { store_code } }`})
+	req := httptest.NewRequest(http.MethodPost, "http://shop.test/fr/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"store_code":"fr"`)) {
+		t.Errorf("POST /fr/graphql = %d %s, want store fr", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,137 @@
+package graphqltest
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"magento.GO/graphql/dataloader"
+)
+
+// recordingFetch returns squares of positive keys and records the batches it is called with.
+type recordingFetch struct {
+	mu      sync.Mutex
+	batches [][]int
+	err     error
+}
+
+func (f *recordingFetch) fetch(keys []int) (map[int]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	batch := append([]int(nil), keys...)
+	sort.Ints(batch)
+	f.batches = append(f.batches, batch)
+	if f.err != nil {
+		return nil, f.err
+	}
+	values := make(map[int]int, len(keys))
+	for _, k := range keys {
+		if k > 0 {
+			values[k] = k * k
+		}
+	}
+	return values, nil
+}
+
+func (f *recordingFetch) calls() [][]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.batches
+}
+
+func TestLoader_BatchesAndDeduplicatesConcurrentLoads(t *testing.T) {
+	f := &recordingFetch{}
+	l := dataloader.NewLoader(f.fetch, 20*time.Millisecond, 0)
+
+	keys := []int{3, 1, 3, 2, -1, 1}
+	values := make([]int, len(keys))
+	found := make([]bool, len(keys))
+	var wg sync.WaitGroup
+	for i, k := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, ok, err := l.Load(k)
+			if err != nil {
+				t.Errorf("Load(%d) error: %v", k, err)
+			}
+			values[i], found[i] = v, ok
+		}()
+	}
+	wg.Wait()
+
+	if calls := f.calls(); len(calls) != 1 || len(calls[0]) != 4 {
+		t.Fatalf("batches = %v, want one batch of the 4 distinct keys", calls)
+	}
+	for i, k := range keys {
+		if k > 0 && (!found[i] || values[i] != k*k) {
+			t.Errorf("Load(%d) = %d, %v; want %d, true", k, values[i], found[i], k*k)
+		}
+		if k < 0 && found[i] {
+			t.Errorf("Load(%d) found a value, want none", k)
+		}
+	}
+
+	// Cached keys are not fetched again; new ones go into a new batch.
+	got, err := l.LoadMany([]int{1, 2, 4})
+	if err != nil || len(got) != 3 || got[4] != 16 {
+		t.Errorf("LoadMany = %v, %v", got, err)
+	}
+	if calls := f.calls(); len(calls) != 2 || len(calls[1]) != 1 || calls[1][0] != 4 {
+		t.Errorf("batches = %v, want a second batch with only key 4", calls)
+	}
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	f := &recordingFetch{}
+	l := dataloader.NewLoader(f.fetch, time.Millisecond, 2)
+
+	got, err := l.LoadMany([]int{1, 2, 3, 4, 5})
+	if err != nil || len(got) != 5 {
+		t.Fatalf("LoadMany = %v, %v", got, err)
+	}
+	if calls := f.calls(); len(calls) != 3 {
+		t.Errorf("batches = %v, want 3 of at most 2 keys", calls)
+	}
+}
+
+func TestLoader_LoadManyDoesNotWait(t *testing.T) {
+	f := &recordingFetch{}
+	l := dataloader.NewLoader(f.fetch, time.Hour, 0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if got, err := l.LoadMany([]int{1, 2}); err != nil || len(got) != 2 {
+			t.Errorf("LoadMany = %v, %v", got, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("LoadMany waited for the batch window")
+	}
+	if calls := f.calls(); len(calls) != 1 || len(calls[0]) != 2 {
+		t.Errorf("batches = %v, want one batch of both keys", calls)
+	}
+}
+
+func TestLoader_ErrorsAreNotCached(t *testing.T) {
+	f := &recordingFetch{err: errors.New("db down")}
+	l := dataloader.NewLoader(f.fetch, time.Millisecond, 0)
+
+	if _, _, err := l.Load(1); err == nil {
+		t.Fatal("Load error = nil, want the fetch error")
+	}
+	f.mu.Lock()
+	f.err = nil
+	f.mu.Unlock()
+	if v, ok, err := l.Load(1); err != nil || !ok || v != 1 {
+		t.Errorf("Load after error = %d, %v, %v; want 1, true, nil", v, ok, err)
+	}
+	if calls := f.calls(); len(calls) != 2 {
+		t.Errorf("batches = %v, want the failed key fetched again", calls)
+	}
+}
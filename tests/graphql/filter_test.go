@@ -0,0 +1,104 @@
+package graphqltest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gql "github.com/graph-gophers/graphql-go"
+
+	"magento.GO/graphql"
+	gqlmodels "magento.GO/graphql/models"
+)
+
+func TestProductAttributeFilter_Unmarshal(t *testing.T) {
+	var f graphql.ProductAttributeFilter
+	err := f.UnmarshalGraphQL(map[string]interface{}{
+		"price":   map[string]interface{}{"from": "10", "to": "50"},
+		"sku":     map[string]interface{}{"in": []interface{}{"A", "B"}},
+		"name":    map[string]interface{}{"match": "shirt", "match_type": "FULL"},
+		"url_key": map[string]interface{}{"eq": "blue-shirt"},
+		"color":   map[string]interface{}{"eq": float64(49)},
+	})
+	if err != nil {
+		t.Fatalf("UnmarshalGraphQL: %v", err)
+	}
+	if c, ok := f.Get("price"); !ok || *c.From != "10" || *c.To != "50" {
+		t.Errorf("price = %+v", c)
+	}
+	if c, _ := f.Get("sku"); len(c.In) != 2 || c.In[1] != "B" {
+		t.Errorf("sku.in = %v", c.In)
+	}
+	if c, _ := f.Get("name"); c.Match == nil || *c.Match != "shirt" || c.MatchType != "FULL" {
+		t.Errorf("name = %+v", c)
+	}
+	if c, _ := f.Get("color"); len(c.Values()) != 1 || c.Values()[0] != "49" {
+		t.Errorf("color values = %v", c.Values())
+	}
+	if strings.Join(f.Codes(), ",") != "color,name,price,sku,url_key" {
+		t.Errorf("Codes() = %v", f.Codes())
+	}
+}
+
+func TestProductFilterExtension(t *testing.T) {
+	ext := graphql.ProductFilterExtension(map[string]string{
+		"color":    graphql.FilterEqualType,
+		"price":    graphql.FilterRangeType, // already in base schema
+		"bad-code": graphql.FilterEqualType, // not a valid GraphQL name
+	})
+	if !strings.Contains(ext, "color: FilterEqualTypeInput") {
+		t.Errorf("extension missing color: %s", ext)
+	}
+	if strings.Contains(ext, "price") || strings.Contains(ext, "bad-code") {
+		t.Errorf("extension should skip base and invalid fields: %s", ext)
+	}
+	if graphql.ProductFilterExtension(nil) != "" {
+		t.Error("empty field map should produce no extension")
+	}
+}
+
+// filterCaptureResolver records the filter that reaches MagentoProducts.
+type filterCaptureResolver struct {
+	MockQueryResolver
+	got *graphql.MagentoProductsArgs
+}
+
+func (m *filterCaptureResolver) MagentoProducts(ctx context.Context, args graphql.MagentoProductsArgs) (*gqlmodels.Products, error) {
+	*m.got = args
+	return &gqlmodels.Products{TypedItems: []*gqlmodels.ProductInterface{}}, nil
+}
+
+type filterCaptureRoot struct{ q *filterCaptureResolver }
+
+func (r *filterCaptureRoot) Query() *filterCaptureResolver { return r.q }
+
+func (r *filterCaptureRoot) Mutation() *MockMutationResolver { return &MockMutationResolver{} }
+
+func (r *filterCaptureRoot) Subscription() *MockSubscriptionResolver {
+	return &MockSubscriptionResolver{}
+}
+
+func TestProductAttributeFilter_DynamicAttributeReachesResolver(t *testing.T) {
+	var got graphql.MagentoProductsArgs
+	sdl := graphql.CoreSchema() + "\n\n" + graphql.ProductFilterExtension(map[string]string{"color": graphql.FilterEqualType})
+	schema, err := gql.ParseSchema(sdl, &filterCaptureRoot{q: &filterCaptureResolver{got: &got}}, gql.UseFieldResolvers())
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+
+	resp := schema.Exec(context.Background(), `{
+		magentoProducts(filter: { color: { in: ["49", "50"] }, price: { from: "5" } }, sort: { price: DESC }) { total_count }
+	}`, "", nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %v", resp.Errors)
+	}
+	if c, ok := got.Filter.Get("color"); !ok || len(c.In) != 2 {
+		t.Errorf("color filter = %+v, ok=%v", c, ok)
+	}
+	if c, ok := got.Filter.Get("price"); !ok || c.From == nil || *c.From != "5" {
+		t.Errorf("price filter = %+v", c)
+	}
+	if got.Sort == nil || got.Sort.Price == nil || *got.Sort.Price != "DESC" {
+		t.Errorf("sort = %+v", got.Sort)
+	}
+}
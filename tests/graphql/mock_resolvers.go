@@ -4,6 +4,7 @@ import (
 	"context"
 
 	gql "github.com/graph-gophers/graphql-go"
+	graphqlApi "magento.GO/api/graphql"
 	"magento.GO/graphql"
 	gqlmodels "magento.GO/graphql/models"
 )
@@ -15,13 +16,23 @@ func (m *MockRootResolver) Query() *MockQueryResolver {
 	return &MockQueryResolver{}
 }
 
+func (m *MockRootResolver) Mutation() *MockMutationResolver {
+	return &MockMutationResolver{}
+}
+
+func (m *MockRootResolver) Subscription() *MockSubscriptionResolver {
+	return &MockSubscriptionResolver{}
+}
+
 type MockQueryResolver struct{}
 
 type mockProductsArgs struct {
 	PageSize    int32
 	CurrentPage int32
-	Skus       *[]string
-	CategoryID *string
+	Skus        *[]string
+	CategoryID  *string
+	Filter      graphql.ProductAttributeFilter
+	Sort        *graphql.ProductAttributeSortInput
 }
 
 func (m *MockQueryResolver) Products(ctx context.Context, args mockProductsArgs) (*gqlmodels.ProductSearchResult, error) {
@@ -34,6 +45,18 @@ func (m *MockQueryResolver) Products(ctx context.Context, args mockProductsArgs)
 	}, nil
 }
 
+func (m *MockQueryResolver) SearchSuggestions(ctx context.Context, args struct {
+	Query    string
+	PageSize int32
+}) (*gqlmodels.SearchSuggestions, error) {
+	name := "Mock Search Result"
+	return &gqlmodels.SearchSuggestions{
+		Products:   []*gqlmodels.ProductSuggestion{{Text: name, SKU: "SEARCH-1", Name: &name}},
+		Categories: []*gqlmodels.CategorySuggestion{},
+		Terms:      []*gqlmodels.SearchTermSuggestion{{QueryText: "mock", NumResults: 1, Popularity: 3}},
+	}, nil
+}
+
 type mockProductArgs struct {
 	Sku    *string
 	URLKey *string
@@ -72,6 +95,8 @@ type mockSearchArgs struct {
 	PageSize    int32
 	CurrentPage int32
 	CategoryID  *string
+	Filter      graphql.ProductAttributeFilter
+	Sort        *graphql.ProductAttributeSortInput
 }
 
 func (m *MockQueryResolver) Search(ctx context.Context, args mockSearchArgs) (*gqlmodels.ProductSearchResult, error) {
@@ -97,19 +122,64 @@ func (m *MockQueryResolver) MagentoProducts(ctx context.Context, args graphql.Ma
 	name := "Mock Product"
 	urlKey := "mock-product"
 	return &gqlmodels.Products{
-		Items: []*gqlmodels.MagentoProduct{{
+		TypedItems: []*gqlmodels.ProductInterface{{MagentoProduct: gqlmodels.MagentoProduct{
 			ID: int32(1), UID: "MQ==", Name: &name, SKU: "MOCK",
 			PriceRange: gqlmodels.PriceRange{MaximumPrice: gqlmodels.ProductPrice{
 				FinalPrice:   gqlmodels.Money{Currency: "USD", Value: 99.99},
 				RegularPrice: gqlmodels.Money{Currency: "USD", Value: 99.99},
 			}},
-			StockStatus: "IN_STOCK", RatingSummary: 0, URLKey: &urlKey,
-		}},
+			StockStatus: "IN_STOCK", URLKey: &urlKey,
+		}}},
 		PageInfo:   gqlmodels.SearchResultPageInfo{TotalPages: int32(1)},
 		TotalCount: int32(1),
 	}, nil
 }
 
+type mockURLArgs struct{ URL string }
+
+func (m *MockQueryResolver) UrlResolver(ctx context.Context, args mockURLArgs) (*gqlmodels.EntityUrl, error) {
+	if args.URL != "/mock.html" {
+		return nil, nil
+	}
+	id, uid, rel, typ := int32(1), "MQ==", "mock.html", "PRODUCT"
+	return &gqlmodels.EntityUrl{ID: &id, EntityUID: &uid, RelativeURL: &rel, Type: &typ}, nil
+}
+
+func (m *MockQueryResolver) Route(ctx context.Context, args mockURLArgs) (*gqlmodels.Routable, error) {
+	if args.URL != "/mock.html" {
+		return nil, nil
+	}
+	rel, typ := "mock.html", "PRODUCT"
+	routable := gqlmodels.RoutableUrl{RelativeURL: &rel, Type: &typ}
+	product := &gqlmodels.ProductInterface{MagentoProduct: gqlmodels.MagentoProduct{ID: 1, UID: "MQ==", SKU: "MOCK", StockStatus: "IN_STOCK"}}
+	product.SetRoutable(routable)
+	return &gqlmodels.Routable{RoutableUrl: routable, Product: product}, nil
+}
+
+func (m *MockQueryResolver) StoreConfig(ctx context.Context) (*gqlmodels.StoreConfig, error) {
+	locale := "en_US"
+	return &gqlmodels.StoreConfig{ID: 1, StoreCode: "default", Locale: &locale}, nil
+}
+
+func (m *MockQueryResolver) AvailableStores(ctx context.Context, args struct{ UseCurrentGroup *bool }) (*[]*gqlmodels.StoreConfig, error) {
+	stores := []*gqlmodels.StoreConfig{{ID: 1, StoreCode: "default"}}
+	return &stores, nil
+}
+
+func (m *MockQueryResolver) ProductReviewRatingsMetadata(ctx context.Context) *gqlmodels.ProductReviewRatingsMetadata {
+	return &gqlmodels.ProductReviewRatingsMetadata{Items: []*gqlmodels.ProductReviewRatingMetadata{}}
+}
+
+func (m *MockQueryResolver) Currency(ctx context.Context) *gqlmodels.Currency {
+	code := "USD"
+	return &gqlmodels.Currency{BaseCurrencyCode: &code, DefaultDisplayCurrencyCode: &code}
+}
+
+func (m *MockQueryResolver) Cart(ctx context.Context, args graphql.CartArgs) (*gqlmodels.Cart, error) {
+	items := []*gqlmodels.CartItem{}
+	return &gqlmodels.Cart{ID: args.CartID, Items: &items, Prices: &gqlmodels.CartPrices{}}, nil
+}
+
 type mockExtensionArgs struct {
 	Name string
 	Args *string
@@ -120,9 +190,72 @@ func (m *MockQueryResolver) Extension(ctx context.Context, args mockExtensionArg
 	return &s, nil
 }
 
-// NewMockSchema creates a schema with mock resolvers for tests.
+type MockMutationResolver struct{}
+
+func (m *MockMutationResolver) CreateProductReview(ctx context.Context, args graphql.CreateProductReviewArgs) (*gqlmodels.CreateProductReviewOutput, error) {
+	return &gqlmodels.CreateProductReviewOutput{Review: &gqlmodels.ProductReview{
+		Nickname: args.Input.Nickname, Summary: args.Input.Summary, Text: args.Input.Text,
+		RatingsBreakdown: []*gqlmodels.ProductReviewRating{},
+	}}, nil
+}
+
+func (m *MockMutationResolver) CreateEmptyCart(ctx context.Context, args graphql.CreateEmptyCartArgs) (*string, error) {
+	id := "mock-cart"
+	return &id, nil
+}
+
+func (m *MockMutationResolver) AddProductsToCart(ctx context.Context, args graphql.AddProductsToCartArgs) (*gqlmodels.AddProductsToCartOutput, error) {
+	items := []*gqlmodels.CartItem{}
+	return &gqlmodels.AddProductsToCartOutput{Cart: &gqlmodels.Cart{ID: args.CartID, Items: &items}, UserErrors: []*gqlmodels.CartUserInputError{}}, nil
+}
+
+func (m *MockMutationResolver) UpdateCartItems(ctx context.Context, args graphql.UpdateCartItemsArgs) (*gqlmodels.UpdateCartItemsOutput, error) {
+	items := []*gqlmodels.CartItem{}
+	return &gqlmodels.UpdateCartItemsOutput{Cart: &gqlmodels.Cart{ID: args.Input.CartID, Items: &items}}, nil
+}
+
+func (m *MockMutationResolver) RemoveItemFromCart(ctx context.Context, args graphql.RemoveItemFromCartArgs) (*gqlmodels.RemoveItemFromCartOutput, error) {
+	items := []*gqlmodels.CartItem{}
+	return &gqlmodels.RemoveItemFromCartOutput{Cart: &gqlmodels.Cart{ID: args.Input.CartID, Items: &items}}, nil
+}
+
+// MockSubscriptionResolver sends one event per requested SKU, then ends the subscription.
+type MockSubscriptionResolver struct{}
+
+func (m *MockSubscriptionResolver) StockChanged(ctx context.Context, args graphql.SubscriptionSKUsArgs) (<-chan *gqlmodels.StockChange, error) {
+	ch := make(chan *gqlmodels.StockChange)
+	go func() {
+		defer close(ch)
+		for _, sku := range args.Skus {
+			select {
+			case ch <- &gqlmodels.StockChange{SKU: sku, Qty: 1, IsInStock: true, StockStatus: "IN_STOCK"}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (m *MockSubscriptionResolver) PriceChanged(ctx context.Context, args graphql.SubscriptionSKUsArgs) (<-chan *gqlmodels.PriceChange, error) {
+	ch := make(chan *gqlmodels.PriceChange)
+	go func() {
+		defer close(ch)
+		for _, sku := range args.Skus {
+			select {
+			case ch <- &gqlmodels.PriceChange{SKU: sku, Price: 10, Currency: "USD"}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// NewMockSchema creates a schema with mock resolvers for tests, with the query limits of the environment.
 func NewMockSchema() *gql.Schema {
-	schema, err := gql.ParseSchema(graphql.Schema(), &MockRootResolver{}, gql.UseFieldResolvers())
+	opts := append([]gql.SchemaOpt{gql.UseFieldResolvers()}, graphqlApi.QueryLimitsFromEnv().SchemaOptions()...)
+	schema, err := gql.ParseSchema(graphql.CoreSchema(), &MockRootResolver{}, opts...)
 	if err != nil {
 		panic("mock schema: " + err.Error())
 	}
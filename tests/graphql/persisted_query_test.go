@@ -0,0 +1,147 @@
+package graphqltest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	graphqlApi "magento.GO/api/graphql"
+	"magento.GO/core/cache"
+)
+
+type apqResponse struct {
+	Data   map[string]interface{}
+	Errors []struct {
+		Message    string
+		Extensions map[string]interface{}
+	}
+}
+
+func postGraphQL(t *testing.T, e *echo.Echo, body map[string]interface{}) (int, apqResponse) {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	return serveGraphQL(t, e, req)
+}
+
+func getGraphQL(t *testing.T, e *echo.Echo, params url.Values) (int, apqResponse) {
+	t.Helper()
+	return serveGraphQL(t, e, httptest.NewRequest(http.MethodGet, "/graphql?"+params.Encode(), nil))
+}
+
+func serveGraphQL(t *testing.T, e *echo.Echo, req *http.Request) (int, apqResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	var resp apqResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return rec.Code, resp
+}
+
+func persistedQueryExtension(query string) map[string]interface{} {
+	sum := sha256.Sum256([]byte(query))
+	return map[string]interface{}{"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hex.EncodeToString(sum[:])}}
+}
+
+// forgetPersistedQuery drops query from the in-memory cache when the test ends, so the test
+// finds it unregistered when run again (-count).
+func forgetPersistedQuery(t *testing.T, query string) {
+	sum := sha256.Sum256([]byte(query))
+	t.Cleanup(func() { cache.GetInstance().Delete("graphql:apq:" + hex.EncodeToString(sum[:])) })
+}
+
+func TestGraphQL_PersistedQuery_RegisterOnRetry(t *testing.T) {
+	e := echo.New()
+	graphqlApi.RegisterGraphQLRoutesWithSchema(e, NewMockSchema())
+	query := `query apqRegister { products { items { sku } total_count } }`
+	forgetPersistedQuery(t, query)
+	ext := persistedQueryExtension(query)
+
+	_, resp := postGraphQL(t, e, map[string]interface{}{"extensions": ext})
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "PersistedQueryNotFound" ||
+		resp.Errors[0].Extensions["code"] != "PERSISTED_QUERY_NOT_FOUND" {
+		t.Fatalf("hash-only request before registration = %+v, want PersistedQueryNotFound", resp.Errors)
+	}
+
+	_, resp = postGraphQL(t, e, map[string]interface{}{"query": query, "extensions": ext})
+	if len(resp.Errors) > 0 || resp.Data["products"] == nil {
+		t.Fatalf("retry with query = %+v, want data", resp)
+	}
+
+	_, resp = postGraphQL(t, e, map[string]interface{}{"extensions": ext})
+	if len(resp.Errors) > 0 || resp.Data["products"].(map[string]interface{})["total_count"] != 1.0 {
+		t.Errorf("hash-only request after registration = %+v, want the registered query's data", resp)
+	}
+}
+
+func TestGraphQL_PersistedQuery_HashMismatch(t *testing.T) {
+	e := echo.New()
+	graphqlApi.RegisterGraphQLRoutesWithSchema(e, NewMockSchema())
+	ext := persistedQueryExtension(`{ categories { entity_id } }`)
+
+	_, resp := postGraphQL(t, e, map[string]interface{}{"query": `{ products { total_count } }`, "extensions": ext})
+	if len(resp.Errors) != 1 || resp.Data != nil {
+		t.Errorf("query with a foreign hash = %+v, want an error and no data", resp)
+	}
+}
+
+func TestGraphQL_PersistedQuery_GET(t *testing.T) {
+	e := echo.New()
+	graphqlApi.RegisterGraphQLRoutesWithSchema(e, NewMockSchema())
+	query := `query apqGet($pageSize: Int) { products(pageSize: $pageSize) { items { sku } } }`
+	forgetPersistedQuery(t, query)
+	ext, _ := json.Marshal(persistedQueryExtension(query))
+
+	if _, resp := getGraphQL(t, e, url.Values{"extensions": {string(ext)}}); len(resp.Errors) != 1 || resp.Errors[0].Message != "PersistedQueryNotFound" {
+		t.Fatalf("GET before registration = %+v, want PersistedQueryNotFound", resp.Errors)
+	}
+	if _, resp := getGraphQL(t, e, url.Values{"query": {query}, "extensions": {string(ext)}, "variables": {`{"pageSize":5}`}}); len(resp.Errors) > 0 {
+		t.Fatalf("GET registration = %+v", resp.Errors)
+	}
+	code, resp := getGraphQL(t, e, url.Values{"extensions": {string(ext)}, "variables": {`{"pageSize":5}`}, "operationName": {"apqGet"}})
+	if code != http.StatusOK || len(resp.Errors) > 0 {
+		t.Fatalf("GET with hash only = %d %+v", code, resp.Errors)
+	}
+	items := resp.Data["products"].(map[string]interface{})["items"].([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["sku"] != "MOCK-SKU-1" {
+		t.Errorf("items = %v", items)
+	}
+}
+
+func TestGraphQL_GET_RejectsMutations(t *testing.T) {
+	e := echo.New()
+	graphqlApi.RegisterGraphQLRoutesWithSchema(e, NewMockSchema())
+	doc := `query q { products { total_count } }
+	mutation m { createEmptyCart }`
+
+	code, resp := getGraphQL(t, e, url.Values{"query": {doc}, "operationName": {"m"}})
+	if code != http.StatusMethodNotAllowed || len(resp.Errors) == 0 || resp.Data != nil {
+		t.Errorf("GET mutation = %d %+v, want 405 and no data", code, resp)
+	}
+	code, resp = getGraphQL(t, e, url.Values{"query": {doc}, "operationName": {"q"}})
+	if code != http.StatusOK || len(resp.Errors) > 0 {
+		t.Errorf("GET query from the same document = %d %+v, want data", code, resp.Errors)
+	}
+	if _, resp := postGraphQL(t, e, map[string]interface{}{"query": doc, "operationName": "m"}); len(resp.Errors) > 0 {
+		t.Errorf("POST mutation = %+v, want data", resp.Errors)
+	}
+	code, resp = getGraphQL(t, e, url.Values{"query": {"\ufeffmutation { createEmptyCart }"}})
+	if code != http.StatusMethodNotAllowed || resp.Data != nil {
+		t.Errorf("GET mutation after a byte order mark = %d %+v, want 405 and no data", code, resp)
+	}
+	// A GET document the limits cannot parse is not provably a query.
+	code, resp = getGraphQL(t, e, url.Values{"query": {"mutation { createEmptyCart } %"}})
+	if code != http.StatusMethodNotAllowed || resp.Data != nil {
+		t.Errorf("unparsable GET document = %d %+v, want 405 and no data", code, resp)
+	}
+}
@@ -0,0 +1,172 @@
+package graphqltest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	graphqlApi "magento.GO/api/graphql"
+)
+
+func limitedServer(t *testing.T, env map[string]string) *echo.Echo {
+	t.Helper()
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	e := echo.New()
+	graphqlApi.RegisterGraphQLRoutesWithSchema(e, NewMockSchema())
+	return e
+}
+
+// expectRejected posts a query and checks it was rejected with an error mentioning want, without
+// data, and counted under limit.
+func expectRejected(t *testing.T, e *echo.Echo, body map[string]interface{}, limit, want string) {
+	t.Helper()
+	before := graphqlApi.RejectedQueries(limit)
+	_, resp := postGraphQL(t, e, body)
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, want) || resp.Data != nil {
+		t.Errorf("%v = %+v, want a %q error and no data", body["query"], resp, want)
+	}
+	if got := graphqlApi.RejectedQueries(limit); got != before+1 {
+		t.Errorf("rejected %s = %d, want %d", limit, got, before+1)
+	}
+}
+
+func expectAccepted(t *testing.T, e *echo.Echo, body map[string]interface{}) {
+	t.Helper()
+	if _, resp := postGraphQL(t, e, body); len(resp.Errors) > 0 {
+		t.Errorf("%v errors = %+v, want none", body["query"], resp.Errors)
+	}
+}
+
+func TestGraphQL_QueryLimits_Depth(t *testing.T) {
+	e := limitedServer(t, map[string]string{"GRAPHQL_MAX_DEPTH": "3"})
+
+	expectAccepted(t, e, map[string]interface{}{"query": `{ categoryTree { children { children { name } } } }`})
+	expectRejected(t, e, map[string]interface{}{"query": `{ categoryTree { children { children { children { name } } } } }`},
+		"depth", "query depth 4 exceeds the maximum of 3")
+	expectRejected(t, e, map[string]interface{}{"query": `{ categoryTree { ...Tree } }
+		fragment Tree on Category { children { children { children { name } } } }`}, "depth", "query depth 4")
+	// Introspection is limited by depth only.
+	expectAccepted(t, e, map[string]interface{}{"query": `{ __schema { types { name } } __typename }`})
+	expectRejected(t, e, map[string]interface{}{"query": `{ __schema { types { name fields { type { ofType { ofType { name } } } } } } }`},
+		"depth", "query depth 6 exceeds the maximum of 3")
+	expectRejected(t, e, map[string]interface{}{"query": `{ __type(name: "Query") { ...Deep } }
+		fragment Deep on __Type { fields { type { ofType { name } } } }`}, "depth", "query depth 4")
+}
+
+func TestGraphQL_QueryLimits_ByteOrderMark(t *testing.T) {
+	e := limitedServer(t, map[string]string{"GRAPHQL_MAX_DEPTH": "3"})
+
+	expectAccepted(t, e, map[string]interface{}{"query": "\ufeff{ categoryTree { children { children { name } } } }"})
+	expectRejected(t, e, map[string]interface{}{"query": "\ufeff{ categoryTree { children { children { children { name } } } } }"},
+		"depth", "query depth 4 exceeds the maximum of 3")
+}
+
+func TestGraphQL_QueryLimits_UnparsableDocumentsReachExecutor(t *testing.T) {
+	e := limitedServer(t, map[string]string{"GRAPHQL_MAX_DEPTH": "3"})
+
+	before := graphqlApi.RejectedQueries("depth")
+	for _, query := range []string{
+		`{ categoryTree { children { children { children { name } } } } } %`,
+		`{ categoryTree { children { children { children { name } } } }`,
+	} {
+		if _, resp := postGraphQL(t, e, map[string]interface{}{"query": query}); len(resp.Errors) == 0 ||
+			!strings.Contains(resp.Errors[0].Message, "syntax error") || resp.Data != nil {
+			t.Errorf("%q = %+v, want the executor's syntax error and no data", query, resp)
+		}
+	}
+	if got := graphqlApi.RejectedQueries("depth"); got != before {
+		t.Errorf("rejected depth = %d, want %d: the limits did not measure these documents", got, before)
+	}
+}
+
+// The executor enforces the depth limit as well, for documents the limits cannot measure.
+func TestGraphQL_QueryLimits_DepthInExecutor(t *testing.T) {
+	t.Setenv("GRAPHQL_MAX_DEPTH", "3")
+	schema := NewMockSchema()
+
+	if resp := schema.Exec(context.Background(), `{ categoryTree { children { children { name } } } }`, "", nil); len(resp.Errors) > 0 {
+		t.Errorf("depth 3 errors = %+v, want none", resp.Errors)
+	}
+	resp := schema.Exec(context.Background(), `{ categoryTree { children { children { children { name } } } } }`, "", nil)
+	if len(resp.Errors) == 0 || resp.Data != nil {
+		t.Errorf("depth 4 = %+v, want a depth error and no data", resp)
+	}
+}
+
+// introspectionQuery is the schema query of GraphiQL and other tools (graphql-js getIntrospectionQuery).
+const introspectionQuery = `query IntrospectionQuery {
+	__schema {
+		queryType { name } mutationType { name } subscriptionType { name }
+		types { ...FullType }
+		directives { name description locations args { ...InputValue } }
+	}
+}
+fragment FullType on __Type {
+	kind name description
+	fields(includeDeprecated: true) { name description args { ...InputValue } type { ...TypeRef } isDeprecated deprecationReason }
+	inputFields { ...InputValue }
+	interfaces { ...TypeRef }
+	enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }
+	possibleTypes { ...TypeRef }
+}
+fragment InputValue on __InputValue { name description type { ...TypeRef } defaultValue }
+fragment TypeRef on __Type {
+	kind name
+	ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+func TestGraphQL_QueryLimits_IntrospectionWithinDefaults(t *testing.T) {
+	e := limitedServer(t, nil)
+
+	expectAccepted(t, e, map[string]interface{}{"query": introspectionQuery})
+}
+
+func TestGraphQL_QueryLimits_PageSize(t *testing.T) {
+	e := limitedServer(t, map[string]string{"GRAPHQL_MAX_PAGE_SIZE": "100"})
+
+	expectAccepted(t, e, map[string]interface{}{"query": `{ products(pageSize: 100) { total_count } }`})
+	expectRejected(t, e, map[string]interface{}{"query": `{ products(pageSize: 100000) { items { sku } } }`},
+		"page_size", "pageSize 100000 of products exceeds the maximum of 100")
+	expectRejected(t, e, map[string]interface{}{
+		"query":     `query P($size: Int) { products(pageSize: $size) { total_count } }`,
+		"variables": map[string]interface{}{"size": 500},
+	}, "page_size", "pageSize 500")
+	expectRejected(t, e, map[string]interface{}{"query": `query P($size: Int = 101) { products(pageSize: $size) { total_count } }`},
+		"page_size", "pageSize 101")
+}
+
+func TestGraphQL_QueryLimits_Aliases(t *testing.T) {
+	e := limitedServer(t, map[string]string{"GRAPHQL_MAX_ALIASES": "2"})
+
+	expectAccepted(t, e, map[string]interface{}{"query": `{ a: categories { entity_id } b: categories { entity_id } }`})
+	expectRejected(t, e, map[string]interface{}{"query": `{ a: categories { entity_id } b: categories { entity_id } c: categories { entity_id } }`},
+		"aliases", "query has 3 aliases, the maximum is 2")
+}
+
+func TestGraphQL_QueryLimits_Complexity(t *testing.T) {
+	e := limitedServer(t, map[string]string{"GRAPHQL_MAX_COMPLEXITY": "100"})
+
+	// products + total_count + 5 × (items + sku + name) = 17
+	expectAccepted(t, e, map[string]interface{}{"query": `{ products(pageSize: 5) { total_count items { sku name } } }`})
+	// products + 50 × (items + sku + name) = 151
+	expectRejected(t, e, map[string]interface{}{"query": `{ products(pageSize: 50) { items { sku name } } }`},
+		"complexity", "query complexity 151 exceeds the maximum of 100")
+	// Lists without a page size count 10 entries per level: 10 × (1 + 10 × (1 + 10 × 2)) = 2110
+	expectRejected(t, e, map[string]interface{}{"query": `{ categoryTree { children { children { name } } } }`},
+		"complexity", "query complexity 2110")
+}
+
+func TestGraphQL_QueryLimits_MutationsAndDefaults(t *testing.T) {
+	e := limitedServer(t, nil)
+
+	expectAccepted(t, e, map[string]interface{}{"query": `mutation { addProductsToCart(cartId: "x", cartItems: [{ sku: "A", quantity: 1 }]) { cart { id } user_errors { code } } }`})
+	expectRejected(t, e, map[string]interface{}{"query": `{ products(pageSize: 100000) { items { sku } } }`}, "page_size", "pageSize 100000")
+	// A self-referencing fragment is reported by the executor, not looped over.
+	if _, resp := postGraphQL(t, e, map[string]interface{}{"query": `{ categoryTree { ...A } } fragment A on Category { children { ...A } }`}); len(resp.Errors) == 0 {
+		t.Error("fragment cycle was executed, want a validation error")
+	}
+}
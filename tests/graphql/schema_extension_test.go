@@ -0,0 +1,70 @@
+package graphqltest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	gql "github.com/graph-gophers/graphql-go"
+
+	"magento.GO/graphql"
+	gqlregistry "magento.GO/graphql/registry"
+)
+
+func TestMergeSchemaExtensions(t *testing.T) {
+	loyalty := gqlregistry.SchemaExtension{
+		Name:     "loyalty",
+		Types:    "enum LoyaltyTier { SILVER GOLD }\n\ntype LoyaltyAccount {\n  points: Int!\n  balance: Money!\n}",
+		Query:    "loyaltyAccount: LoyaltyAccount",
+		Mutation: "addLoyaltyPoints(points: Int!): LoyaltyAccount!",
+	}
+	// Extensions of existing types: core, another extension's, and Query through Types.
+	stars := gqlregistry.SchemaExtension{
+		Name:  "stars",
+		Types: "extend enum LoyaltyTier { PLATINUM }\n\nextend type LoyaltyAccount { stars: Int! }\n\nextend type PageInfo { has_next_page: Boolean! }\n\nextend type Query { loyaltyStars: Int! }",
+	}
+	merged, err := graphql.MergeSchemaExtensions(graphql.CoreSchema(), []gqlregistry.SchemaExtension{loyalty, stars})
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	schema, err := gql.ParseSchema(merged.SDL, nil)
+	if err != nil {
+		t.Fatalf("parse merged schema: %v", err)
+	}
+	if errs := schema.Validate(`{ loyaltyAccount { points stars balance { value } } loyaltyStars products { total_count page_info { has_next_page } } }`); len(errs) > 0 {
+		t.Errorf("validate: %v", errs)
+	}
+	want := map[string]map[string]string{
+		"query":    {"loyaltyAccount": "loyalty", "loyaltyStars": "stars"},
+		"mutation": {"addLoyaltyPoints": "loyalty"},
+	}
+	if !reflect.DeepEqual(merged.Owners, want) {
+		t.Errorf("owners = %v, want %v", merged.Owners, want)
+	}
+}
+
+func TestMergeSchemaExtensions_Conflicts(t *testing.T) {
+	loyalty := gqlregistry.SchemaExtension{Name: "loyalty", Types: "type LoyaltyAccount { points: Int! }", Query: "loyaltyAccount: LoyaltyAccount"}
+	tests := []struct {
+		name string
+		exts []gqlregistry.SchemaExtension
+		want string
+	}{
+		{"core type redefined", []gqlregistry.SchemaExtension{{Name: "money", Types: `"Money" type Money { amount: Int }`, Query: "money: Money"}}, `extension "money": type Money is already defined`},
+		{"type of another extension", []gqlregistry.SchemaExtension{loyalty, {Name: "other", Types: "type LoyaltyAccount { stars: Int }", Query: "stars: Int"}}, `extension "other": type LoyaltyAccount is already defined`},
+		{"unknown type extended", []gqlregistry.SchemaExtension{{Name: "money", Types: "extend type Cents { cents: Int }"}}, `extension "money": trying to extend unknown type "Cents"`},
+		{"existing field of a core type", []gqlregistry.SchemaExtension{{Name: "money", Types: "extend type Money { value: Float }"}}, `extension "money": extended field "value" already exists`},
+		{"subscription field", []gqlregistry.SchemaExtension{{Name: "live", Types: "extend type Subscription { points: Int }"}}, `extension "live": Subscription fields cannot be added`},
+		{"core field", []gqlregistry.SchemaExtension{{Name: "products", Query: "products: Int"}}, `extension "products"`},
+		{"field of another extension", []gqlregistry.SchemaExtension{loyalty, {Name: "other", Query: "loyaltyAccount: Int"}}, `extension "other"`},
+		{"empty", []gqlregistry.SchemaExtension{{Name: "empty"}}, `extension "empty" adds no types or fields`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := graphql.MergeSchemaExtensions(graphql.CoreSchema(), tt.exts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
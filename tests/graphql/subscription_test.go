@@ -0,0 +1,130 @@
+package graphqltest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+
+	graphqlApi "magento.GO/api/graphql"
+)
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func dialGraphQLWS(t *testing.T, protocol string) (*websocket.Conn, error) {
+	t.Helper()
+	e := echo.New()
+	graphqlApi.RegisterGraphQLRoutesWithSchema(e, NewMockSchema())
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/graphql", protocol, srv.URL)
+	if err == nil {
+		t.Cleanup(func() { ws.Close() })
+	}
+	return ws, err
+}
+
+func wsSend(t *testing.T, ws *websocket.Conn, msg string) {
+	t.Helper()
+	if err := websocket.Message.Send(ws, msg); err != nil {
+		t.Fatalf("send %s: %v", msg, err)
+	}
+}
+
+func wsReceive(t *testing.T, ws *websocket.Conn) wsMessage {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg wsMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	return msg
+}
+
+func TestGraphQLWS_Protocol(t *testing.T) {
+	ws, err := dialGraphQLWS(t, "graphql-transport-ws")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	wsSend(t, ws, `{"type":"connection_init","payload":{"Store":"1"}}`)
+	if msg := wsReceive(t, ws); msg.Type != "connection_ack" {
+		t.Fatalf("got %+v, want connection_ack", msg)
+	}
+	wsSend(t, ws, `{"type":"ping"}`)
+	if msg := wsReceive(t, ws); msg.Type != "pong" {
+		t.Fatalf("got %+v, want pong", msg)
+	}
+
+	wsSend(t, ws, `{"id":"1","type":"subscribe","payload":{"query":"subscription { stockChanged(skus: [\"A\", \"B\"]) { sku stock_status } }"}}`)
+	for _, sku := range []string{"A", "B"} {
+		msg := wsReceive(t, ws)
+		if msg.ID != "1" || msg.Type != "next" || !strings.Contains(string(msg.Payload), `"sku":"`+sku+`"`) {
+			t.Fatalf("got %+v, want next for %s", msg, sku)
+		}
+	}
+	if msg := wsReceive(t, ws); msg.ID != "1" || msg.Type != "complete" {
+		t.Fatalf("got %+v, want complete", msg)
+	}
+
+	// Queries run over the same connection: one next, then complete.
+	wsSend(t, ws, `{"id":"2","type":"subscribe","payload":{"query":"{ products { total_count } }"}}`)
+	if msg := wsReceive(t, ws); msg.ID != "2" || msg.Type != "next" || !strings.Contains(string(msg.Payload), "total_count") {
+		t.Fatalf("got %+v, want the query result", msg)
+	}
+	if msg := wsReceive(t, ws); msg.ID != "2" || msg.Type != "complete" {
+		t.Fatalf("got %+v, want complete", msg)
+	}
+
+	wsSend(t, ws, `{"id":"3","type":"subscribe","payload":{"query":"subscription { unknownField }"}}`)
+	if msg := wsReceive(t, ws); msg.ID != "3" || msg.Type != "error" || !strings.Contains(string(msg.Payload), "unknownField") {
+		t.Fatalf("got %+v, want an error message", msg)
+	}
+}
+
+func TestGraphQLWS_SubscribeBeforeInitClosesConnection(t *testing.T) {
+	ws, err := dialGraphQLWS(t, "graphql-transport-ws")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	wsSend(t, ws, `{"id":"1","type":"subscribe","payload":{"query":"subscription { stockChanged(skus: [\"A\"]) { sku } }"}}`)
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg wsMessage
+	if err := websocket.JSON.Receive(ws, &msg); err == nil {
+		t.Fatalf("got %+v, want the connection closed (4401)", msg)
+	}
+}
+
+func TestGraphQLWS_RequiresSubprotocol(t *testing.T) {
+	if _, err := dialGraphQLWS(t, "graphql-ws"); err == nil {
+		t.Fatal("dial with the legacy subprotocol succeeded, want the handshake rejected")
+	}
+}
+
+func TestGraphQLWS_QueryLimits(t *testing.T) {
+	t.Setenv("GRAPHQL_MAX_DEPTH", "3")
+	ws, err := dialGraphQLWS(t, "graphql-transport-ws")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	wsSend(t, ws, `{"type":"connection_init"}`)
+	if msg := wsReceive(t, ws); msg.Type != "connection_ack" {
+		t.Fatalf("got %+v, want connection_ack", msg)
+	}
+
+	wsSend(t, ws, `{"id":"1","type":"subscribe","payload":{"query":"\ufeff{ categoryTree { children { children { children { name } } } } }"}}`)
+	if msg := wsReceive(t, ws); msg.ID != "1" || msg.Type != "error" || !strings.Contains(string(msg.Payload), "query depth 4") {
+		t.Fatalf("got %+v, want the depth limit error", msg)
+	}
+	wsSend(t, ws, `{"id":"2","type":"subscribe","payload":{"query":"{ categoryTree { children { children { children { name } } } } } %"}}`)
+	if msg := wsReceive(t, ws); msg.ID != "2" || msg.Type != "error" || !strings.Contains(string(msg.Payload), "syntax error") {
+		t.Fatalf("got %+v, want the executor's syntax error", msg)
+	}
+}
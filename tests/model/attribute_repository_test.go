@@ -0,0 +1,103 @@
+package modeltest
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	entity "magento.GO/model/entity"
+	attributeRepo "magento.GO/model/repository/attribute"
+)
+
+func attributeRepoTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&entity.EavAttribute{},
+		&entity.CatalogEavAttribute{},
+		&entity.EavAttributeOption{},
+		&entity.EavAttributeOptionValue{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestAttributeRepository_FilterableAttributes(t *testing.T) {
+	db := attributeRepoTestDB(t)
+	attrs := []entity.EavAttribute{
+		{AttributeID: 73, EntityTypeID: 4, AttributeCode: "name", BackendType: "varchar", FrontendInput: strPtr("text")},
+		{AttributeID: 93, EntityTypeID: 4, AttributeCode: "color", BackendType: "int", FrontendInput: strPtr("select"), FrontendLabel: strPtr("Color")},
+		{AttributeID: 144, EntityTypeID: 4, AttributeCode: "size", BackendType: "int", FrontendInput: strPtr("select")},
+		{AttributeID: 45, EntityTypeID: 3, AttributeCode: "is_anchor", BackendType: "int"},
+	}
+	db.Create(&attrs)
+	db.Create(&[]entity.CatalogEavAttribute{
+		{AttributeID: 93, IsFilterable: 1, Position: 2},
+		{AttributeID: 144, IsFilterable: 2, Position: 1},
+		{AttributeID: 73, IsSearchable: 1, SearchWeight: 5},
+	})
+
+	repo := attributeRepo.NewAttributeRepository(db)
+	all, err := repo.ProductAttributes()
+	if err != nil {
+		t.Fatalf("ProductAttributes: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("product attributes = %d, want 3 (category attribute excluded)", len(all))
+	}
+	if a := all["name"]; !a.IsSearchable || a.SearchWeight != 5 {
+		t.Errorf("name = %+v, want searchable with weight 5", a)
+	}
+
+	filterable, err := repo.FilterableAttributes()
+	if err != nil {
+		t.Fatalf("FilterableAttributes: %v", err)
+	}
+	if len(filterable) != 2 || filterable[0].Code != "size" || filterable[1].Code != "color" {
+		t.Errorf("filterable = %+v, want [size color] by position", filterable)
+	}
+	if filterable[1].Label != "Color" {
+		t.Errorf("color label = %q, want Color", filterable[1].Label)
+	}
+}
+
+func TestAttributeRepository_ProductAttributeOptions(t *testing.T) {
+	db := attributeRepoTestDB(t)
+	db.Create(&entity.EavAttribute{AttributeID: 93, EntityTypeID: 4, AttributeCode: "color", BackendType: "int"})
+	db.Create(&[]entity.EavAttributeOption{
+		{OptionID: 49, AttributeID: 93, SortOrder: 2},
+		{OptionID: 50, AttributeID: 93, SortOrder: 1},
+	})
+	db.Create(&[]entity.EavAttributeOptionValue{
+		{OptionID: 49, StoreID: 0, Value: "Black"},
+		{OptionID: 49, StoreID: 2, Value: "Schwarz"},
+		{OptionID: 50, StoreID: 0, Value: "Blue"},
+	})
+
+	repo := attributeRepo.NewAttributeRepository(db)
+	opts, err := repo.ProductAttributeOptions(2)
+	if err != nil {
+		t.Fatalf("ProductAttributeOptions: %v", err)
+	}
+	color := opts[93]
+	if len(color) != 2 {
+		t.Fatalf("color options = %d, want 2", len(color))
+	}
+	if color[0].OptionID != 50 || color[0].Label != "Blue" {
+		t.Errorf("first option = %+v, want 50/Blue (sort_order, default label fallback)", color[0])
+	}
+	if color[1].Label != "Schwarz" {
+		t.Errorf("store label = %q, want Schwarz", color[1].Label)
+	}
+
+	def, _ := repo.ProductAttributeOptions(0)
+	if def[93][1].Label != "Black" {
+		t.Errorf("default store label = %q, want Black", def[93][1].Label)
+	}
+}
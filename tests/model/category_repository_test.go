@@ -194,6 +194,35 @@ func TestCategoryRepository_GetByIDsWithAttributesAndFlat(t *testing.T) {
 	}
 }
 
+func TestCategoryRepository_GetByIDsWithAttributesAndFlat_StoreFallback(t *testing.T) {
+	db := categoryRepoTestDB(t)
+	seedCategoryAttrs(t, db)
+	repo := categoryRepo.NewCategoryRepository(db)
+
+	cat := seedCategory(t, db, 0, "1", 0, "Shirts")
+	db.Create(&[]categoryEntity.CategoryVarchar{
+		{AttributeID: 41, StoreID: 2, EntityID: cat.EntityID, Value: "Chemises"},
+		{AttributeID: 119, StoreID: 0, EntityID: cat.EntityID, Value: "shirts"},
+	})
+	db.Create(&categoryEntity.CategoryInt{AttributeID: 42, StoreID: 0, EntityID: cat.EntityID, Value: 1})
+
+	for _, tt := range []struct {
+		storeID uint16
+		name    string
+	}{{0, "Shirts"}, {1, "Shirts"}, {2, "Chemises"}} {
+		_, flats, err := repo.GetByIDsWithAttributesAndFlat([]uint{cat.EntityID}, tt.storeID)
+		if err != nil || len(flats) != 1 {
+			t.Fatalf("store %d: %d flats, %v", tt.storeID, len(flats), err)
+		}
+		if got := flats[0]["name"]["value"]; got != tt.name {
+			t.Errorf("store %d: name = %v, want %s", tt.storeID, got, tt.name)
+		}
+		if flats[0]["url_key"]["value"] != "shirts" || flats[0]["is_active"]["value"] != 1 {
+			t.Errorf("store %d: default scope attributes missing: %v", tt.storeID, flats[0])
+		}
+	}
+}
+
 func TestCategoryRepository_BuildCategoryTree(t *testing.T) {
 	db := categoryRepoTestDB(t)
 	seedCategoryAttrs(t, db)
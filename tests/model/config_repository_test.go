@@ -0,0 +1,171 @@
+package modeltest
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	entity "magento.GO/model/entity"
+	storeEntity "magento.GO/model/entity/store"
+	configRepo "magento.GO/model/repository/config"
+	currencyRepo "magento.GO/model/repository/currency"
+	storeRepo "magento.GO/model/repository/store"
+)
+
+// storeTestDB has two websites: base (default; stores "default" and "fr") and b2b (store "b2b").
+func storeTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&storeEntity.Store{}, &storeEntity.StoreGroup{}, &storeEntity.StoreWebsite{}, &entity.CoreConfigData{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&[]storeEntity.StoreWebsite{
+		{WebsiteID: 1, Code: "base", Name: "Main Website", DefaultGroupID: 1, IsDefault: 1},
+		{WebsiteID: 2, Code: "b2b", Name: "B2B Website", DefaultGroupID: 2},
+	})
+	db.Create(&[]storeEntity.StoreGroup{
+		{GroupID: 1, WebsiteID: 1, Name: "Main Store", RootCategoryID: 2, DefaultStoreID: 1, Code: "main"},
+		{GroupID: 2, WebsiteID: 2, Name: "B2B Store", RootCategoryID: 3, DefaultStoreID: 3, Code: "b2b_store"},
+	})
+	db.Create(&[]storeEntity.Store{
+		{StoreID: 1, Code: "default", WebsiteID: 1, GroupID: 1, Name: "Default Store View", IsActive: 1},
+		{StoreID: 2, Code: "fr", WebsiteID: 1, GroupID: 1, Name: "French", SortOrder: 1, IsActive: 1},
+		{StoreID: 3, Code: "b2b", WebsiteID: 2, GroupID: 2, Name: "B2B", IsActive: 1},
+	})
+	db.Create(&[]entity.CoreConfigData{
+		{Scope: "default", Path: configRepo.PathLocale, Value: strPtr("en_GB")},
+		{Scope: "websites", ScopeID: 2, Path: configRepo.PathLocale, Value: strPtr("de_DE")},
+		{Scope: "stores", ScopeID: 2, Path: configRepo.PathLocale, Value: strPtr("fr_FR")},
+		{Scope: "default", Path: configRepo.PathUnsecureBaseURL, Value: strPtr("http://shop.test/")},
+		{Scope: "websites", ScopeID: 2, Path: configRepo.PathUnsecureBaseURL, Value: strPtr("http://b2b.test/")},
+		{Scope: "stores", ScopeID: 1, Path: configRepo.PathProductURLSuffix, Value: nil},
+	})
+	return db
+}
+
+func TestConfigRepository_ScopeFallback(t *testing.T) {
+	repo := configRepo.NewConfigRepository(storeTestDB(t))
+	cases := []struct {
+		path    string
+		storeID uint16
+		want    string
+	}{
+		{configRepo.PathLocale, 1, "en_GB"},           // default scope
+		{configRepo.PathLocale, 2, "fr_FR"},           // store scope
+		{configRepo.PathLocale, 3, "de_DE"},           // website scope
+		{configRepo.PathLocale, 0, "en_GB"},           // admin store reads default
+		{configRepo.PathProductURLSuffix, 1, ""},      // NULL store value overrides the default
+		{configRepo.PathProductURLSuffix, 2, ".html"}, // config.xml default
+	}
+	for _, c := range cases {
+		if got := repo.Get(c.path, c.storeID); got != c.want {
+			t.Errorf("Get(%s, %d) = %q, want %q", c.path, c.storeID, got, c.want)
+		}
+	}
+	if got := repo.GetURL(configRepo.PathUnsecureBaseMediaURL, 3); got != "http://b2b.test/media/" {
+		t.Errorf("b2b base media URL = %q", got)
+	}
+	if got := repo.GetURL(configRepo.PathSecureBaseLinkURL, 1); got != "http://shop.test/" {
+		t.Errorf("default secure link URL = %q", got)
+	}
+}
+
+func TestStoreRepository_DefaultAndWebsiteStores(t *testing.T) {
+	repo := storeRepo.NewStoreRepository(storeTestDB(t))
+	if s, ok := repo.DefaultStore(); !ok || s.Code != "default" {
+		t.Errorf("DefaultStore() = %+v, %v", s, ok)
+	}
+	if id := repo.ResolveStoreID(0); id != 1 {
+		t.Errorf("ResolveStoreID(0) = %d, want 1", id)
+	}
+	if id := repo.ResolveStoreID(3); id != 3 {
+		t.Errorf("ResolveStoreID(3) = %d, want 3", id)
+	}
+	if s, ok := repo.GetByCode("fr"); !ok || s.StoreID != 2 {
+		t.Errorf("GetByCode(fr) = %+v, %v", s, ok)
+	}
+	stores, err := repo.WebsiteStores(1, nil)
+	if err != nil || len(stores) != 2 || stores[0].Code != "default" || stores[1].Code != "fr" {
+		t.Errorf("WebsiteStores(1) = %+v, %v", stores, err)
+	}
+}
+
+func TestCurrencyRepository_Converter(t *testing.T) {
+	db := storeTestDB(t)
+	if err := db.AutoMigrate(&entity.DirectoryCurrencyRate{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&[]entity.CoreConfigData{
+		{Scope: "websites", ScopeID: 2, Path: configRepo.PathBaseCurrency, Value: strPtr("EUR")},
+		{Scope: "default", Path: configRepo.PathAllowedCurrencies, Value: strPtr("USD,EUR")},
+		{Scope: "websites", ScopeID: 2, Path: configRepo.PathAllowedCurrencies, Value: strPtr("EUR,USD")},
+	})
+	db.Create(&entity.DirectoryCurrencyRate{CurrencyFrom: "USD", CurrencyTo: "EUR", Rate: 0.8})
+	repo := currencyRepo.NewCurrencyRepository(db)
+
+	if c := repo.Converter(1, "eur"); c.Base != "USD" || c.Display != "EUR" || c.Convert(10) != 8 {
+		t.Errorf("store 1 EUR converter = %+v", c)
+	}
+	if c := repo.Converter(3, "USD"); c.Base != "EUR" || c.Display != "USD" || c.Convert(8) != 10 {
+		t.Errorf("store 3 USD converter = %+v, want the inverse rate", c)
+	}
+	if c := repo.Converter(1, "JPY"); c.Display != "USD" || c.Convert(10) != 10 {
+		t.Errorf("disallowed currency converter = %+v, want base USD", c)
+	}
+
+	product := map[string]interface{}{"sku": "A", "price": 10.0, "index_prices": []map[string]interface{}{{"final_price": 5.0}}}
+	converted := repo.Converter(1, "EUR").ConvertFlat(product)
+	if converted["price"] != 8.0 || converted["index_prices"].([]map[string]interface{})[0]["final_price"] != 4.0 {
+		t.Errorf("ConvertFlat = %v", converted)
+	}
+	if product["price"] != 10.0 || product["index_prices"].([]map[string]interface{})[0]["final_price"] != 5.0 {
+		t.Errorf("ConvertFlat modified its input: %v", product)
+	}
+}
+
+func TestStoreRepository_ParseStore(t *testing.T) {
+	repo := storeRepo.NewStoreRepository(storeTestDB(t))
+	cases := []struct {
+		v    string
+		want uint16
+		ok   bool
+	}{
+		{"2", 2, true},
+		{"fr", 2, true},
+		{"b2b", 3, true},
+		{"unknown", 0, false},
+	}
+	for _, c := range cases {
+		if id, ok := repo.ParseStore(c.v); id != c.want || ok != c.ok {
+			t.Errorf("ParseStore(%q) = %d, %v, want %d, %v", c.v, id, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestConfigRepository_StoreForURL(t *testing.T) {
+	db := storeTestDB(t)
+	db.Create(&entity.CoreConfigData{Scope: "stores", ScopeID: 2, Path: configRepo.PathUnsecureBaseURL, Value: strPtr("http://shop.test/fr/")})
+	repo := configRepo.NewConfigRepository(db)
+	cases := []struct {
+		host, path string
+		want       uint16
+		prefix     string
+		ok         bool
+	}{
+		{"shop.test", "/graphql", 1, "", true},
+		{"shop.test", "/fr/graphql", 2, "/fr", true},
+		{"shop.test", "/fr", 2, "/fr", true},
+		{"shop.test", "/french", 1, "", true},
+		{"B2B.test", "/api/products", 3, "", true},
+		{"other.test", "/graphql", 0, "", false},
+	}
+	for _, c := range cases {
+		id, prefix, ok := repo.StoreForURL(c.host, c.path)
+		if id != c.want || prefix != c.prefix || ok != c.ok {
+			t.Errorf("StoreForURL(%s, %s) = %d, %q, %v, want %d, %q, %v", c.host, c.path, id, prefix, ok, c.want, c.prefix, c.ok)
+		}
+	}
+}
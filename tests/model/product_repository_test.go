@@ -254,6 +254,44 @@ func TestProductRepository_FetchWithAllAttributesFlatByIDs(t *testing.T) {
 	}
 }
 
+func TestProductRepository_FetchWithAllAttributesFlatByIDs_StoreFallback(t *testing.T) {
+	os.Setenv("PRODUCT_FLAT_CACHE", "off")
+	defer os.Unsetenv("PRODUCT_FLAT_CACHE")
+
+	db := productRepoTestDB(t)
+	repo := productRepo.NewProductRepository(db)
+	db.Create(&[]entity.EavAttribute{
+		{AttributeID: 73, EntityTypeID: 4, AttributeCode: "name", BackendType: "varchar"},
+		{AttributeID: 77, EntityTypeID: 4, AttributeCode: "price", BackendType: "decimal"},
+		{AttributeID: 97, EntityTypeID: 4, AttributeCode: "status", BackendType: "int"},
+	})
+	prod := &productEntity.Product{AttributeSetID: 1, TypeID: "simple", SKU: "SCOPE-1"}
+	if err := repo.Create(prod); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	db.Create(&[]productEntity.ProductVarchar{
+		{AttributeID: 73, StoreID: 2, EntityID: prod.EntityID, Value: "Nom"},
+		{AttributeID: 73, StoreID: 0, EntityID: prod.EntityID, Value: "Name"},
+		{AttributeID: 73, StoreID: 3, EntityID: prod.EntityID, Value: "Naam"},
+	})
+	db.Create(&productEntity.ProductDecimal{AttributeID: 77, StoreID: 0, EntityID: prod.EntityID, Value: 25})
+	db.Create(&productEntity.ProductInt{AttributeID: 97, StoreID: 0, EntityID: prod.EntityID, Value: 1})
+
+	for _, tt := range []struct {
+		storeID uint16
+		name    string
+	}{{0, "Name"}, {1, "Name"}, {2, "Nom"}} {
+		flat, err := repo.FetchWithAllAttributesFlatByIDs([]uint{prod.EntityID}, tt.storeID)
+		if err != nil {
+			t.Fatalf("store %d: %v", tt.storeID, err)
+		}
+		p := flat[prod.EntityID]
+		if p["name"] != tt.name || p["price"] != 25.0 || p["status"] != 1 {
+			t.Errorf("store %d: name = %v, price = %v, status = %v, want %s, 25, 1", tt.storeID, p["name"], p["price"], p["status"], tt.name)
+		}
+	}
+}
+
 func TestProductRepository_LoadAttributeCodeMap(t *testing.T) {
 	db := productRepoTestDB(t)
 	if err := db.Create(&entity.EavAttribute{AttributeID: 73, EntityTypeID: 4, AttributeCode: "name", BackendType: "varchar"}).Error; err != nil {
@@ -0,0 +1,68 @@
+package modeltest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	quoteEntity "magento.GO/model/entity/quote"
+	quoteRepo "magento.GO/model/repository/quote"
+)
+
+func quoteTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // each :memory: connection is its own database
+	if err := db.AutoMigrate(&quoteEntity.Quote{}, &quoteEntity.QuoteItem{}, &quoteEntity.QuoteIDMask{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestQuoteRepository_TotalsKeepConcurrentQuoteChanges(t *testing.T) {
+	db := quoteTestDB(t)
+	repo := quoteRepo.NewQuoteRepository(db)
+	cartID, err := repo.CreateCart(1, 0, 0, quoteRepo.Currency{Base: "USD", Quote: "USD", Rate: 1}, "")
+	if err != nil {
+		t.Fatalf("CreateCart: %v", err)
+	}
+	q, err := repo.FindByMaskedID(cartID, 0)
+	if err != nil {
+		t.Fatalf("FindByMaskedID: %v", err)
+	}
+
+	// Magento reserves an order ID after the cart was loaded; adding an item keeps it.
+	db.Model(&quoteEntity.Quote{}).Where("entity_id = ?", q.EntityID).Update("reserved_order_id", "000000042")
+	if err := repo.AddItem(q, quoteRepo.NewItem{ProductID: 1, SKU: "TEE-1", Name: "Tee", ProductType: "simple", Qty: 2, BasePrice: 10}); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	var saved quoteEntity.Quote
+	db.First(&saved, q.EntityID)
+	if saved.ReservedOrderID == nil || *saved.ReservedOrderID != "000000042" || saved.ItemsQty != 2 || saved.GrandTotal != 20 {
+		t.Errorf("quote after AddItem = reserved %v, qty %v, grand total %v; want 000000042, 2, 20", saved.ReservedOrderID, saved.ItemsQty, saved.GrandTotal)
+	}
+
+	// Setting the same qty again writes unchanged totals, which MySQL reports as no affected rows.
+	items, _ := repo.Items(q.EntityID)
+	if err := repo.UpdateItemQty(q, items[0].ItemID, 2); err != nil {
+		t.Errorf("UpdateItemQty to the same qty: %v", err)
+	}
+
+	// The cart is ordered meanwhile: the change is refused and the cart stays inactive.
+	db.Model(&quoteEntity.Quote{}).Where("entity_id = ?", q.EntityID).Update("is_active", 0)
+	if err := repo.UpdateItemQty(q, items[0].ItemID, 5); !errors.Is(err, quoteRepo.ErrCartNotFound) {
+		t.Errorf("UpdateItemQty on an ordered cart = %v, want ErrCartNotFound", err)
+	}
+	db.First(&saved, q.EntityID)
+	if saved.IsActive != 0 || saved.ItemsQty != 2 {
+		t.Errorf("ordered quote = active %d, qty %v; want 0 and 2", saved.IsActive, saved.ItemsQty)
+	}
+	if items, _ := repo.Items(q.EntityID); items[0].Qty != 2 {
+		t.Errorf("item qty = %v, want 2 (rolled back)", items[0].Qty)
+	}
+}
@@ -1,6 +1,7 @@
 package modeltest
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/glebarez/sqlite"
@@ -8,6 +9,8 @@ import (
 
 	productEntity "magento.GO/model/entity/product"
 	salesEntity "magento.GO/model/entity/sales"
+	"magento.GO/model/repository"
+	attributeRepo "magento.GO/model/repository/attribute"
 	productRepo "magento.GO/model/repository/product"
 	categoryRepo "magento.GO/model/repository/category"
 	salesRepo "magento.GO/model/repository/sales"
@@ -65,6 +68,29 @@ func TestNewCategoryRepository(t *testing.T) {
 	}
 }
 
+func TestPerDB(t *testing.T) {
+	var repos repository.PerDB[*attributeRepo.AttributeRepository]
+	db, other := testDB(t), testDB(t)
+	first := repos.Get(db, attributeRepo.NewAttributeRepository)
+	if again := repos.Get(db, attributeRepo.NewAttributeRepository); again != first {
+		t.Error("second Get of the same DB created another repository")
+	}
+	if repos.Get(other, attributeRepo.NewAttributeRepository) == first {
+		t.Error("Get of another DB returned the first DB's repository")
+	}
+
+	failing := errors.New("no connection")
+	var fallible repository.PerDB[*attributeRepo.AttributeRepository]
+	if _, err := fallible.TryGet(db, func(*gorm.DB) (*attributeRepo.AttributeRepository, error) { return nil, failing }); err != failing {
+		t.Fatalf("TryGet error = %v, want %v", err, failing)
+	}
+	if r, err := fallible.TryGet(db, func(db *gorm.DB) (*attributeRepo.AttributeRepository, error) {
+		return attributeRepo.NewAttributeRepository(db), nil
+	}); err != nil || r == nil {
+		t.Errorf("TryGet after a failed create = %v, %v; want a new repository", r, err)
+	}
+}
+
 func TestNewSalesOrderGridRepository(t *testing.T) {
 	db := testDB(t)
 	repo := salesRepo.NewSalesOrderGridRepository(db)
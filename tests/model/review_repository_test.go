@@ -0,0 +1,76 @@
+package modeltest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	reviewEntity "magento.GO/model/entity/review"
+	reviewRepo "magento.GO/model/repository/review"
+)
+
+func reviewTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&reviewEntity.Review{}, &reviewEntity.ReviewDetail{}, &reviewEntity.ReviewStore{}, &reviewEntity.ReviewEntitySummary{},
+		&reviewEntity.Rating{}, &reviewEntity.RatingStore{}, &reviewEntity.RatingOption{},
+		&reviewEntity.RatingOptionVote{}, &reviewEntity.RatingOptionVoteAggregated{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestReviewRepository_SummariesFallBackToVoteAggregates(t *testing.T) {
+	db := reviewTestDB(t)
+	approved := uint16(70)
+	db.Create(&[]reviewEntity.ReviewEntitySummary{
+		{EntityPkValue: 1, EntityType: reviewEntity.EntityProduct, ReviewsCount: 3, RatingSummary: 90, StoreID: 1},
+		{EntityPkValue: 2, EntityType: reviewEntity.EntityProduct, ReviewsCount: 1, StoreID: 1},
+	})
+	db.Create(&reviewEntity.RatingOptionVoteAggregated{RatingID: 1, EntityPkValue: 2, VoteCount: 1, PercentApproved: &approved, StoreID: 1})
+
+	summaries, err := reviewRepo.NewReviewRepository(db).Summaries([]uint{1, 2, 3}, 1)
+	if err != nil {
+		t.Fatalf("Summaries: %v", err)
+	}
+	if s := summaries[1]; s.ReviewCount != 3 || s.RatingSummary != 90 {
+		t.Errorf("product 1 = %+v, want 3 reviews at 90", s)
+	}
+	if s := summaries[2]; s.ReviewCount != 1 || s.RatingSummary != 70 {
+		t.Errorf("product 2 = %+v, want the aggregated 70", s)
+	}
+	if _, ok := summaries[3]; ok {
+		t.Errorf("product 3 has a summary, want none")
+	}
+}
+
+func TestReviewRepository_CreatePendingRejectsForeignOption(t *testing.T) {
+	db := reviewTestDB(t)
+	quality := reviewEntity.Rating{EntityID: reviewEntity.EntityProduct, RatingCode: "Quality", IsActive: 1}
+	price := reviewEntity.Rating{EntityID: reviewEntity.EntityProduct, RatingCode: "Price", IsActive: 1}
+	db.Create(&quality)
+	db.Create(&price)
+	db.Create(&[]reviewEntity.RatingStore{{RatingID: quality.RatingID, StoreID: 1}, {RatingID: price.RatingID, StoreID: 1}})
+	priceOption := reviewEntity.RatingOption{RatingID: price.RatingID, Code: "1", Value: 1}
+	db.Create(&priceOption)
+
+	repo := reviewRepo.NewReviewRepository(db)
+	_, err := repo.CreatePending(reviewRepo.NewReview{
+		ProductID: 1, StoreID: 1, Nickname: "N", Title: "T", Detail: "D",
+		Votes: map[uint16]uint{quality.RatingID: priceOption.OptionID},
+	})
+	if !errors.Is(err, reviewRepo.ErrInvalidRating) {
+		t.Fatalf("CreatePending with another rating's option: err = %v, want ErrInvalidRating", err)
+	}
+	var count int64
+	db.Model(&reviewEntity.Review{}).Count(&count)
+	if count != 0 {
+		t.Errorf("reviews = %d after a rejected vote, want 0", count)
+	}
+}
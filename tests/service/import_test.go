@@ -12,6 +12,7 @@ import (
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
 
+	"magento.GO/core/events"
 	entity "magento.GO/model/entity"
 	productEntity "magento.GO/model/entity/product"
 	productService "magento.GO/service/product"
@@ -629,6 +630,34 @@ func TestImport_StockUpdate(t *testing.T) {
 	}
 }
 
+func TestImport_PublishesStockAndPriceChanges(t *testing.T) {
+	db := importDB(t)
+	seedAttributes(t, db)
+	stock := events.GetInstance().Subscribe(events.TopicStock, []string{"PUB-1", "PUB-2"})
+	defer stock.Close()
+	price := events.GetInstance().Subscribe(events.TopicPrice, []string{"PUB-1", "PUB-2"})
+	defer price.Close()
+
+	csv := "sku,price,qty\nPUB-1,10,5\nPUB-2,12,\n"
+	if _, err := productService.ImportProducts(db, strings.NewReader(csv), productService.ImportOptions{}); err != nil {
+		t.Fatalf("ImportProducts: %v", err)
+	}
+	if got := stock.Take(); len(got) != 1 || got[0] != "PUB-1" {
+		t.Errorf("stock changes = %v, want [PUB-1]", got)
+	}
+	if got := price.Take(); len(got) != 2 {
+		t.Errorf("price changes = %v, want [PUB-1 PUB-2]", got)
+	}
+
+	qty := 3.0
+	if _, err := productService.ImportStockJSON(db, []productService.StockItemInput{{SKU: "PUB-2", Qty: &qty}, {SKU: "MISSING", Qty: &qty}}, 0); err != nil {
+		t.Fatalf("ImportStockJSON: %v", err)
+	}
+	if got := stock.Take(); len(got) != 1 || got[0] != "PUB-2" {
+		t.Errorf("stock changes = %v, want [PUB-2]", got)
+	}
+}
+
 // ---------- Gallery sub-importer tests ----------
 
 func TestImport_Gallery(t *testing.T) {